@@ -0,0 +1,340 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// CutAtAntimeridian splits geometries that cross the antimeridian into parts
+// that stay within [-180, 180], so that bounding boxes, point-in-polygon
+// tests and renderers see them the right way round. An edge crosses when its
+// endpoints are more than 180° of longitude apart, and is taken the short way
+// across. The crossing point is placed on the great circle through the edge's
+// endpoints, at longitude 180 on the eastern side and -180 on the western
+// one.
+//
+// A LineString that crosses becomes a MultiLineString, and a Polygon becomes
+// a MultiPolygon as in CutAntimeridian; geometries that do not cross come back
+// unchanged. Multi-geometries are cut part by part, and features and
+// collections member by member, with a Feature's bbox cleared. Points pass
+// through. Rings that encircle a pole give an error.
+func CutAtAntimeridian(obj interface{}) (interface{}, error) {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return nil, err
+	}
+	switch g := geom.(type) {
+	case Point, MultiPoint:
+		return g, nil
+	case LineString:
+		parts := cutLineAtAntimeridian(g.Coordinates)
+		if len(parts) == 1 {
+			return g, nil
+		}
+		return NewMultiLineString(parts), nil
+	case MultiLineString:
+		var parts [][]Position
+		for _, line := range g.Coordinates {
+			parts = append(parts, cutLineAtAntimeridian(line)...)
+		}
+		return NewMultiLineString(parts), nil
+	case Polygon:
+		return cutPolygonAtAntimeridian(g)
+	case MultiPolygon:
+		var parts [][][]Position
+		for _, rings := range g.Coordinates {
+			cut, err := cutPolygonAtAntimeridian(NewPolygon(rings))
+			if err != nil {
+				return nil, err
+			}
+			switch c := cut.(type) {
+			case Polygon:
+				parts = append(parts, c.Coordinates)
+			case MultiPolygon:
+				parts = append(parts, c.Coordinates...)
+			}
+		}
+		return NewMultiPolygon(parts), nil
+	case GeometryCollection:
+		children := make([]interface{}, len(g.Geometries))
+		for i, child := range g.Geometries {
+			if children[i], err = CutAtAntimeridian(child); err != nil {
+				return nil, err
+			}
+		}
+		return NewGeometryCollection(children), nil
+	case Feature:
+		if g.Geometry, err = CutAtAntimeridian(g.Geometry); err != nil {
+			return nil, err
+		}
+		g.BBox = nil
+		return g, nil
+	case FeatureCollection:
+		features := make([]Feature, len(g.Features))
+		for i, f := range g.Features {
+			cut, err := CutAtAntimeridian(f)
+			if err != nil {
+				return nil, err
+			}
+			features[i] = cut.(Feature)
+		}
+		return NewFeatureCollection(features), nil
+	default:
+		return nil, fmt.Errorf("unsupported geojson type %T", obj)
+	}
+}
+
+// MergeAcrossAntimeridian undoes CutAtAntimeridian. In a MultiLineString, a
+// part ending on one side of the antimeridian is joined to the next part when
+// that starts at the same point on the other side. In a MultiPolygon, parts
+// touching longitude 180 and parts touching -180 are joined along their shared
+// edges on the meridian. The merged geometries cross the antimeridian with
+// edges more than 180° long, as in their original form.
+//
+// Vertices lying on the antimeridian where the merged geometry crosses it are
+// dropped, as they were most likely added by the cut. A MultiLineString or
+// MultiPolygon left with a single part becomes a LineString or Polygon; parts
+// that have nothing to merge with are kept as they are, and other geometries
+// come back unchanged. Features and collections are merged member by member,
+// with a Feature's bbox cleared.
+func MergeAcrossAntimeridian(obj interface{}) (interface{}, error) {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return nil, err
+	}
+	switch g := geom.(type) {
+	case Point, MultiPoint, LineString, Polygon:
+		return g, nil
+	case MultiLineString:
+		lines := mergeLinesAcrossAntimeridian(g.Coordinates)
+		if len(lines) == 1 {
+			return NewLineString(lines[0]), nil
+		}
+		return NewMultiLineString(lines), nil
+	case MultiPolygon:
+		polys := mergePolygonsAcrossAntimeridian(g.Coordinates)
+		if len(polys) == 1 {
+			return NewPolygon(polys[0]), nil
+		}
+		return NewMultiPolygon(polys), nil
+	case GeometryCollection:
+		children := make([]interface{}, len(g.Geometries))
+		for i, child := range g.Geometries {
+			if children[i], err = MergeAcrossAntimeridian(child); err != nil {
+				return nil, err
+			}
+		}
+		return NewGeometryCollection(children), nil
+	case Feature:
+		if g.Geometry, err = MergeAcrossAntimeridian(g.Geometry); err != nil {
+			return nil, err
+		}
+		g.BBox = nil
+		return g, nil
+	case FeatureCollection:
+		features := make([]Feature, len(g.Features))
+		for i, f := range g.Features {
+			merged, err := MergeAcrossAntimeridian(f)
+			if err != nil {
+				return nil, err
+			}
+			features[i] = merged.(Feature)
+		}
+		return NewFeatureCollection(features), nil
+	default:
+		return nil, fmt.Errorf("unsupported geojson type %T", obj)
+	}
+}
+
+// crossesAntimeridian reports whether the edge p-q is taken across the
+// antimeridian.
+func crossesAntimeridian(p, q Position) bool {
+	return math.Abs(q[0]-p[0]) > 180
+}
+
+// antimeridianCrossing returns the point where the edge p-q crosses the
+// antimeridian, at longitude 180 or -180 on p's side. The latitude is found by
+// bisecting the fraction along the great circle from p to q.
+func antimeridianCrossing(p, q Position) Position {
+	east := p[0] > 0
+	if math.Abs(p[0]) == 180 {
+		east = q[0] < 0
+	}
+	side := -180.0
+	if east {
+		side = 180
+	}
+	switch {
+	case math.Abs(p[0]) == 180:
+		return withAlt(side, p[1], p)
+	case math.Abs(q[0]) == 180:
+		return withAlt(side, q[1], q)
+	}
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 60; i++ {
+		mid := (lo + hi) / 2
+		if _, lon := GreatCircleIntermediatePoint(p[1], p[0], q[1], q[0], mid); (lon > 0) == east {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	f := (lo + hi) / 2
+	lat, _ := GreatCircleIntermediatePoint(p[1], p[0], q[1], q[0], f)
+	return withAltBetween(side, lat, p, q, f)
+}
+
+// cutLineAtAntimeridian splits a line at each antimeridian crossing, ending
+// one part and starting the next at the crossing point on either side.
+func cutLineAtAntimeridian(line []Position) [][]Position {
+	var parts [][]Position
+	current := make([]Position, 0, len(line))
+	for i, p := range line {
+		if i > 0 && crossesAntimeridian(line[i-1], p) {
+			c := antimeridianCrossing(line[i-1], p)
+			if !samePosition(c, line[i-1]) {
+				current = append(current, c)
+			}
+			parts = append(parts, current)
+			c = withAlt(-c[0], c[1], c)
+			current = []Position{c}
+			if samePosition(c, p) {
+				continue
+			}
+		}
+		current = append(current, p)
+	}
+	return append(parts, current)
+}
+
+// cutPolygonAtAntimeridian adds the great-circle crossing points to every
+// ring and cuts the polygon with CutAntimeridian, whose cut then runs through
+// those points.
+func cutPolygonAtAntimeridian(poly Polygon) (interface{}, error) {
+	rings := make([][]Position, len(poly.Coordinates))
+	crosses := false
+	for r, ring := range poly.Coordinates {
+		out := make([]Position, 0, len(ring))
+		for i, p := range ring {
+			if i > 0 && crossesAntimeridian(ring[i-1], p) {
+				crosses = true
+				if math.Abs(ring[i-1][0]) != 180 && math.Abs(p[0]) != 180 {
+					out = append(out, antimeridianCrossing(ring[i-1], p))
+				}
+			}
+			out = append(out, p)
+		}
+		rings[r] = out
+	}
+	if !crosses {
+		return poly, nil
+	}
+	return CutAntimeridian(NewPolygon(rings))
+}
+
+// mergeLinesAcrossAntimeridian joins consecutive lines where one ends on the
+// antimeridian and the next starts at the same latitude on its other side.
+func mergeLinesAcrossAntimeridian(lines [][]Position) [][]Position {
+	var out [][]Position
+	for _, line := range lines {
+		if len(out) > 0 && len(line) > 0 {
+			prev := out[len(out)-1]
+			end, start := prev[len(prev)-1], line[0]
+			if math.Abs(end[0]) == 180 && samePosition(start, Position{-end[0], end[1]}) {
+				out[len(out)-1] = append(prev[:len(prev)-1:len(prev)-1], line[1:]...)
+				continue
+			}
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// mergePolygonsAcrossAntimeridian joins polygons that meet along the
+// antimeridian. Parts touching -180 are shifted east by 360°, so that they
+// meet the parts touching 180 along shared edges in opposite directions; those
+// edges cancel and the rest are linked into rings, which are shifted back
+// into range afterwards.
+func mergePolygonsAcrossAntimeridian(polys [][][]Position) [][][]Position {
+	var kept [][][]Position
+	var edges [][2]Position
+	for _, poly := range polys {
+		minLon, maxLon := math.Inf(1), math.Inf(-1)
+		for _, ring := range poly {
+			for _, p := range ring {
+				minLon, maxLon = math.Min(minLon, p[0]), math.Max(maxLon, p[0])
+			}
+		}
+		west, east := minLon == -180, maxLon == 180
+		rings, err := overlayRings([][][]Position{poly})
+		if west == east || err != nil {
+			kept = append(kept, poly)
+			continue
+		}
+		if west {
+			for _, ring := range rings {
+				for i := range ring {
+					ring[i] = withAlt(ring[i][0]+360, ring[i][1], ring[i])
+				}
+			}
+		}
+		edges = append(edges, ringSegments(rings)...)
+	}
+	if len(edges) == 0 {
+		return kept
+	}
+
+	// Split the edges along the meridian at every vertex on it, so edges shared
+	// by neighboring parts match exactly.
+	var onMeridian []Position
+	for _, e := range edges {
+		if e[0][0] == 180 {
+			onMeridian = append(onMeridian, e[0])
+		}
+	}
+	cuts := make([][]Position, len(edges))
+	for i, e := range edges {
+		if e[0][0] != 180 || e[1][0] != 180 {
+			continue
+		}
+		for _, p := range onMeridian {
+			if p[1] > math.Min(e[0][1], e[1][1]) && p[1] < math.Max(e[0][1], e[1][1]) {
+				cuts[i] = append(cuts[i], p)
+			}
+		}
+	}
+
+	var rings [][]Position
+	for _, ring := range linkOverlayEdges(cancelEdges(cutSegments(edges, cuts))) {
+		var out []Position
+		for i, p := range ring {
+			prev, next := ring[(i+len(ring)-1)%len(ring)], ring[(i+1)%len(ring)]
+			if p[0] == 180 && (prev[0] < 180) != (next[0] < 180) && prev[0] != 180 && next[0] != 180 {
+				continue
+			}
+			out = append(out, p)
+		}
+		rings = append(rings, out)
+	}
+	var merged [][][]Position
+	switch g := assemblePolygons(rings).(type) {
+	case Polygon:
+		merged = [][][]Position{g.Coordinates}
+	case MultiPolygon:
+		merged = g.Coordinates
+	}
+	for _, poly := range merged {
+		for _, ring := range poly {
+			shift := true
+			for _, p := range ring {
+				shift = shift && p[0] >= 180
+			}
+			for i := range ring {
+				if shift || ring[i][0] > 180 {
+					ring[i] = withAlt(ring[i][0]-360, ring[i][1], ring[i])
+				}
+			}
+		}
+	}
+	return append(kept, merged...)
+}
@@ -0,0 +1,153 @@
+package geo
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// sphericalRingArea returns the area in square kilometers of a convex ring
+// with great-circle edges, fanned into triangles from its first vertex.
+func sphericalRingArea(ring []Position) float64 {
+	open := openRing(ring)
+	var area float64
+	for i := 1; i+1 < len(open); i++ {
+		a, b, c := open[0], open[i], open[i+1]
+		area += SphericalTriangleArea(LatLon{a[1], a[0]}, LatLon{b[1], b[0]}, LatLon{c[1], c[0]})
+	}
+	return area
+}
+
+func TestCutAtAntimeridianPolygon(t *testing.T) {
+	poly := NewPolygon([][]Position{{{170, -10}, {-170, -10}, {-170, 10}, {170, 10}, {170, -10}}})
+	got, err := CutAtAntimeridian(poly)
+	if err != nil {
+		t.Fatalf("CutAtAntimeridian() error = %v", err)
+	}
+	mp, ok := got.(MultiPolygon)
+	if !ok || len(mp.Coordinates) != 2 {
+		t.Fatalf("CutAtAntimeridian() = %v, want a MultiPolygon with 2 parts", got)
+	}
+
+	// The original edges along ±10° are great circles, so the region bulges
+	// poleward at the antimeridian; the cut has to follow them.
+	want := sphericalRingArea(poly.Coordinates[0])
+	var total float64
+	for _, part := range mp.Coordinates {
+		for _, p := range part[0] {
+			if p[0] < -180 || p[0] > 180 {
+				t.Errorf("part vertex %v out of range", p)
+			}
+			if math.Abs(p[0]) == 180 && math.Abs(math.Abs(p[1])-10) < 0.1 {
+				t.Errorf("crossing %v is not on the great circle", p)
+			}
+		}
+		total += sphericalRingArea(part[0])
+	}
+	if math.Abs(total-want) > 1e-6*want {
+		t.Errorf("parts cover %v km², want %v", total, want)
+	}
+}
+
+func TestCutAtAntimeridianLine(t *testing.T) {
+	line := NewLineString([]Position{{170, 0}, {-170, 0}, {-175, 10}, {175, 10}})
+	got, err := CutAtAntimeridian(NewFeature(line))
+	if err != nil {
+		t.Fatalf("CutAtAntimeridian() error = %v", err)
+	}
+	mls := got.(Feature).Geometry.(MultiLineString)
+	want := [][]Position{
+		{{170, 0}, {180, 0}},
+		{{-180, 0}, {-170, 0}, {-175, 10}, {-180, 10}},
+		{{180, 10}, {175, 10}},
+	}
+	if len(mls.Coordinates) != len(want) {
+		t.Fatalf("CutAtAntimeridian() = %v, want %v", mls.Coordinates, want)
+	}
+	for i, part := range mls.Coordinates {
+		if len(part) != len(want[i]) {
+			t.Fatalf("part %d = %v, want %v", i, part, want[i])
+		}
+		for j, p := range part {
+			if p[0] != want[i][j][0] || math.Abs(p[1]-want[i][j][1]) > 0.2 {
+				t.Errorf("part %d vertex %d = %v, want about %v", i, j, p, want[i][j])
+			}
+		}
+	}
+
+	merged, err := MergeAcrossAntimeridian(mls)
+	if err != nil {
+		t.Fatalf("MergeAcrossAntimeridian() error = %v", err)
+	}
+	if !reflect.DeepEqual(merged, line) {
+		t.Errorf("MergeAcrossAntimeridian() = %v, want %v", merged, line)
+	}
+
+	plain := NewLineString([]Position{{0, 0}, {1, 1}})
+	if got, err := CutAtAntimeridian(plain); err != nil || !reflect.DeepEqual(got, plain) {
+		t.Errorf("CutAtAntimeridian(plain) = %v, %v, want it unchanged", got, err)
+	}
+}
+
+func TestMergeAcrossAntimeridianFiji(t *testing.T) {
+	// A rough outline of Vanua Levu, which the antimeridian runs through, with
+	// a lagoon across the meridian and an island wholly to the west.
+	fiji := NewMultiPolygon([][][]Position{
+		{
+			{{178.5, -16.2}, {179.6, -16.1}, {-179.8, -16.3}, {-179.9, -16.9}, {179.9, -17.0}, {178.8, -16.8}, {178.5, -16.2}},
+			{{179.8, -16.4}, {179.8, -16.7}, {-179.95, -16.7}, {-179.95, -16.4}, {179.8, -16.4}},
+		},
+		{{{178.0, -17.5}, {178.7, -17.5}, {178.7, -18.2}, {178.0, -18.2}, {178.0, -17.5}}},
+	})
+	cut, err := CutAtAntimeridian(fiji)
+	if err != nil {
+		t.Fatalf("CutAtAntimeridian() error = %v", err)
+	}
+	if n := len(cut.(MultiPolygon).Coordinates); n != 3 {
+		t.Fatalf("CutAtAntimeridian() gave %d parts, want 3", n)
+	}
+	merged, err := MergeAcrossAntimeridian(cut)
+	if err != nil {
+		t.Fatalf("MergeAcrossAntimeridian() error = %v", err)
+	}
+	mp, ok := merged.(MultiPolygon)
+	if !ok || len(mp.Coordinates) != 2 {
+		t.Fatalf("MergeAcrossAntimeridian() = %v, want 2 polygons", merged)
+	}
+	for _, want := range fiji.Coordinates {
+		found := false
+		for _, got := range mp.Coordinates {
+			if sameRings(got, want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("polygon %v not found in %v", want, mp.Coordinates)
+		}
+	}
+}
+
+// sameRings reports whether two polygons have rings with the same vertices,
+// ignoring where each ring starts and which way it runs.
+func sameRings(a, b [][]Position) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	key := func(ring []Position) []Position {
+		out := append([]Position(nil), openRing(ring)...)
+		sort.Slice(out, func(i, j int) bool {
+			if out[i][0] != out[j][0] {
+				return out[i][0] < out[j][0]
+			}
+			return out[i][1] < out[j][1]
+		})
+		return out
+	}
+	for i := range a {
+		if !reflect.DeepEqual(key(a[i]), key(b[i])) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,387 @@
+package geo
+
+import "fmt"
+
+// BBoxClip returns the part of obj inside the box b, for cutting geometries
+// into map tiles. Lines are clipped segment by segment with Cohen-Sutherland
+// and split where they leave the box and come back, so a LineString may come
+// out as a MultiLineString. Polygon rings are clipped with Sutherland-Hodgman
+// when the outer ring enters the box at most once. Polygons whose outer ring
+// leaves the box and comes back, or with a hole that reaches the box's edge,
+// are intersected with the box as by Intersection instead, so they may come
+// out as a MultiPolygon and a cut hole becomes a notch in the outer ring.
+// Points on the box's edges are kept.
+//
+// Parts left with fewer than 2 positions, or rings without area, are dropped,
+// as is a polygon whose outer ring vanishes. Features and collections keep
+// only the members with something left, and a Feature's bbox is cleared. When
+// nothing at all remains the result is nil. The box is taken in the lon/lat
+// plane and must not cross the antimeridian.
+func BBoxClip(obj interface{}, b BBox) (interface{}, error) {
+	if b.IsEmpty() {
+		return nil, fmt.Errorf("invalid bbox %v", b)
+	}
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return nil, err
+	}
+	switch g := geom.(type) {
+	case Point:
+		if !bboxContains(b, g.Coordinates) {
+			return nil, nil
+		}
+		return g, nil
+	case MultiPoint:
+		var kept []Position
+		for _, p := range g.Coordinates {
+			if bboxContains(b, p) {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) == 0 {
+			return nil, nil
+		}
+		return NewMultiPoint(kept), nil
+	case LineString:
+		parts := clipLineToBBox(g.Coordinates, b)
+		switch len(parts) {
+		case 0:
+			return nil, nil
+		case 1:
+			return NewLineString(parts[0]), nil
+		}
+		return NewMultiLineString(parts), nil
+	case MultiLineString:
+		var parts [][]Position
+		for _, line := range g.Coordinates {
+			parts = append(parts, clipLineToBBox(line, b)...)
+		}
+		if len(parts) == 0 {
+			return nil, nil
+		}
+		return NewMultiLineString(parts), nil
+	case Polygon:
+		polys, err := clipPolygonToBBox(g.Coordinates, b)
+		if err != nil {
+			return nil, err
+		}
+		switch len(polys) {
+		case 0:
+			return nil, nil
+		case 1:
+			return NewPolygon(polys[0]), nil
+		}
+		return NewMultiPolygon(polys), nil
+	case MultiPolygon:
+		var polys [][][]Position
+		for _, poly := range g.Coordinates {
+			clipped, err := clipPolygonToBBox(poly, b)
+			if err != nil {
+				return nil, err
+			}
+			polys = append(polys, clipped...)
+		}
+		if len(polys) == 0 {
+			return nil, nil
+		}
+		return NewMultiPolygon(polys), nil
+	case GeometryCollection:
+		var kept []interface{}
+		for _, child := range g.Geometries {
+			clipped, err := BBoxClip(child, b)
+			if err != nil {
+				return nil, err
+			}
+			if clipped != nil {
+				kept = append(kept, clipped)
+			}
+		}
+		if len(kept) == 0 {
+			return nil, nil
+		}
+		return NewGeometryCollection(kept), nil
+	case Feature:
+		clipped, err := BBoxClip(g.Geometry, b)
+		if err != nil || clipped == nil {
+			return nil, err
+		}
+		g.Geometry = clipped
+		g.BBox = nil
+		return g, nil
+	case FeatureCollection:
+		var kept []Feature
+		for _, f := range g.Features {
+			clipped, err := BBoxClip(f, b)
+			if err != nil {
+				return nil, err
+			}
+			if clipped != nil {
+				kept = append(kept, clipped.(Feature))
+			}
+		}
+		if len(kept) == 0 {
+			return nil, nil
+		}
+		return NewFeatureCollection(kept), nil
+	default:
+		return nil, fmt.Errorf("unsupported geojson type %T", obj)
+	}
+}
+
+func bboxContains(b BBox, p Position) bool {
+	return p[0] >= b.MinLon && p[0] <= b.MaxLon && p[1] >= b.MinLat && p[1] <= b.MaxLat
+}
+
+// Cohen-Sutherland outcodes.
+const (
+	outLeft = 1 << iota
+	outRight
+	outBottom
+	outTop
+)
+
+func bboxOutcode(b BBox, p Position) int {
+	code := 0
+	if p[0] < b.MinLon {
+		code |= outLeft
+	} else if p[0] > b.MaxLon {
+		code |= outRight
+	}
+	if p[1] < b.MinLat {
+		code |= outBottom
+	} else if p[1] > b.MaxLat {
+		code |= outTop
+	}
+	return code
+}
+
+// clipSegmentToBBox clips the segment p-q to the box with Cohen-Sutherland.
+// ok is false when no part of the segment is inside.
+func clipSegmentToBBox(p, q Position, b BBox) (Position, Position, bool) {
+	codeP, codeQ := bboxOutcode(b, p), bboxOutcode(b, q)
+	for {
+		switch {
+		case codeP|codeQ == 0:
+			return p, q, true
+		case codeP&codeQ != 0:
+			return p, q, false
+		}
+		// Move the endpoint that is outside onto the box edge it is beyond.
+		code := codeP
+		if code == 0 {
+			code = codeQ
+		}
+		var t float64
+		switch {
+		case code&outTop != 0:
+			t = (b.MaxLat - p[1]) / (q[1] - p[1])
+		case code&outBottom != 0:
+			t = (b.MinLat - p[1]) / (q[1] - p[1])
+		case code&outRight != 0:
+			t = (b.MaxLon - p[0]) / (q[0] - p[0])
+		default:
+			t = (b.MinLon - p[0]) / (q[0] - p[0])
+		}
+		r := lerpPosition(p, q, t)
+		// Snap the coordinate that was solved for onto the edge exactly.
+		switch {
+		case code&outTop != 0:
+			r[1] = b.MaxLat
+		case code&outBottom != 0:
+			r[1] = b.MinLat
+		case code&outRight != 0:
+			r[0] = b.MaxLon
+		default:
+			r[0] = b.MinLon
+		}
+		if code == codeP {
+			p, codeP = r, bboxOutcode(b, r)
+		} else {
+			q, codeQ = r, bboxOutcode(b, r)
+		}
+	}
+}
+
+func lerpPosition(p, q Position, t float64) Position {
+	return withAltBetween(p[0]+t*(q[0]-p[0]), p[1]+t*(q[1]-p[1]), p, q, t)
+}
+
+// clipLineToBBox returns the parts of a line inside the box. A new part starts
+// wherever the line comes back into the box.
+func clipLineToBBox(line []Position, b BBox) [][]Position {
+	var parts [][]Position
+	var current []Position
+	flush := func() {
+		if len(current) >= 2 {
+			parts = append(parts, current)
+		}
+		current = nil
+	}
+	for i := 0; i+1 < len(line); i++ {
+		p, q, ok := clipSegmentToBBox(line[i], line[i+1], b)
+		if !ok {
+			flush()
+			continue
+		}
+		if len(current) == 0 || !samePosition(current[len(current)-1], p) {
+			flush()
+			current = []Position{p}
+		}
+		if !samePosition(q, p) {
+			current = append(current, q)
+		}
+		if !samePosition(q, line[i+1]) {
+			flush()
+		}
+	}
+	flush()
+	return parts
+}
+
+// clipPolygonToBBox clips every ring of a polygon to the box, dropping rings
+// without area, and returns the polygons left: none when the outer ring is
+// dropped, and otherwise usually one. Sutherland-Hodgman would join the
+// separate parts of an outer ring that enters the box more than once along
+// the box's edge, and a hole that reaches the box's edge would come out
+// sharing that edge with the outer ring. Such polygons are intersected with
+// the box by polygonOverlay instead, which splits the parts, joins the hole
+// into the outer ring and may leave several polygons.
+func clipPolygonToBBox(rings [][]Position, b BBox) ([][][]Position, error) {
+	if len(rings) == 0 {
+		return nil, nil
+	}
+	if !ringEntersBBoxOnce(rings[0], b) {
+		return overlayPolygonWithBBox(rings, b)
+	}
+	if len(rings) > 1 {
+		for _, hole := range rings[1:] {
+			if !ringClearOfBBoxEdges(hole, b) {
+				return overlayPolygonWithBBox(rings, b)
+			}
+		}
+	}
+	var out [][]Position
+	for i, ring := range rings {
+		clipped := clipRingToBBox(openRing(ring), b)
+		if area, _, _ := ringAreaCentroid(clipped); area == 0 {
+			if i == 0 {
+				return nil, nil
+			}
+			continue
+		}
+		out = append(out, closeRing(clipped))
+	}
+	return [][][]Position{out}, nil
+}
+
+// ringEntersBBoxOnce reports whether the part of the closed ring inside the
+// box is at most one run of the ring, so that clipping it gives a single
+// piece.
+func ringEntersBBoxOnce(ring []Position, b BBox) bool {
+	parts := clipLineToBBox(ring, b)
+	if len(parts) == 2 {
+		// A run through the ring's first vertex is split in two.
+		first, last := parts[0], parts[1]
+		return samePosition(first[0], ring[0]) && samePosition(last[len(last)-1], ring[len(ring)-1])
+	}
+	return len(parts) <= 1
+}
+
+// ringClearOfBBoxEdges reports whether every vertex of ring lies strictly inside the
+// box or wholly outside it on one side, so that clipping either keeps the
+// ring as it is or drops it without touching the box's edges.
+func ringClearOfBBoxEdges(ring []Position, b BBox) bool {
+	inside, west, east, south, north := true, true, true, true, true
+	for _, p := range ring {
+		inside = inside && p[0] > b.MinLon && p[0] < b.MaxLon && p[1] > b.MinLat && p[1] < b.MaxLat
+		west = west && p[0] <= b.MinLon
+		east = east && p[0] >= b.MaxLon
+		south = south && p[1] <= b.MinLat
+		north = north && p[1] >= b.MaxLat
+	}
+	return inside || west || east || south || north
+}
+
+// overlayPolygonWithBBox intersects a polygon with the box as Intersection
+// does.
+func overlayPolygonWithBBox(rings [][]Position, b BBox) ([][][]Position, error) {
+	box := [][]Position{{
+		{b.MinLon, b.MinLat}, {b.MaxLon, b.MinLat}, {b.MaxLon, b.MaxLat}, {b.MinLon, b.MaxLat}, {b.MinLon, b.MinLat},
+	}}
+	result, err := polygonOverlay([][][]Position{rings}, [][][]Position{box}, clipIntersection)
+	if err != nil {
+		return nil, err
+	}
+	switch g := result.(type) {
+	case Polygon:
+		return [][][]Position{g.Coordinates}, nil
+	case MultiPolygon:
+		return g.Coordinates, nil
+	}
+	return nil, nil
+}
+
+// clipRingToBBox clips an open ring to the box with Sutherland-Hodgman,
+// cutting it against each edge of the box in turn.
+func clipRingToBBox(ring []Position, b BBox) []Position {
+	edges := []struct {
+		inside func(Position) bool
+		cross  func(p, q Position) Position
+	}{
+		{
+			func(p Position) bool { return p[0] >= b.MinLon },
+			func(p, q Position) Position {
+				return snapLon(lerpPosition(p, q, (b.MinLon-p[0])/(q[0]-p[0])), b.MinLon)
+			},
+		},
+		{
+			func(p Position) bool { return p[0] <= b.MaxLon },
+			func(p, q Position) Position {
+				return snapLon(lerpPosition(p, q, (b.MaxLon-p[0])/(q[0]-p[0])), b.MaxLon)
+			},
+		},
+		{
+			func(p Position) bool { return p[1] >= b.MinLat },
+			func(p, q Position) Position {
+				return snapLat(lerpPosition(p, q, (b.MinLat-p[1])/(q[1]-p[1])), b.MinLat)
+			},
+		},
+		{
+			func(p Position) bool { return p[1] <= b.MaxLat },
+			func(p, q Position) Position {
+				return snapLat(lerpPosition(p, q, (b.MaxLat-p[1])/(q[1]-p[1])), b.MaxLat)
+			},
+		},
+	}
+	for _, e := range edges {
+		if len(ring) == 0 {
+			return nil
+		}
+		var out []Position
+		prev := ring[len(ring)-1]
+		for _, p := range ring {
+			switch pIn, prevIn := e.inside(p), e.inside(prev); {
+			case pIn && !prevIn:
+				out = append(out, e.cross(prev, p), p)
+			case pIn:
+				out = append(out, p)
+			case prevIn:
+				out = append(out, e.cross(prev, p))
+			}
+			prev = p
+		}
+		ring = openRing(out)
+	}
+	if len(ring) < 3 {
+		return nil
+	}
+	return ring
+}
+
+func snapLon(p Position, lon float64) Position {
+	return withAlt(lon, p[1], p)
+}
+
+func snapLat(p Position, lat float64) Position {
+	return withAlt(p[0], lat, p)
+}
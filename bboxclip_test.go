@@ -0,0 +1,146 @@
+package geo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBBoxClipLine(t *testing.T) {
+	box := BBox{MinLon: 0, MinLat: 0, MaxLon: 10, MaxLat: 10}
+
+	// In, out over the top, and back in.
+	line := NewLineString([]Position{{-5, 5}, {5, 5}, {5, 15}, {8, 15}, {8, 5}, {15, 5}})
+	got, err := BBoxClip(line, box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NewMultiLineString([][]Position{
+		{{0, 5}, {5, 5}, {5, 10}},
+		{{8, 10}, {8, 5}, {10, 5}},
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BBoxClip = %v, want %v", got, want)
+	}
+
+	inside := NewLineString([]Position{{1, 1}, {2, 2}, {3, 1}})
+	if got, _ := BBoxClip(inside, box); !reflect.DeepEqual(got, inside) {
+		t.Errorf("BBoxClip(inside) = %v, want it unchanged", got)
+	}
+	diagonal := NewLineString([]Position{{-10, -10}, {20, 20}})
+	if got, _ := BBoxClip(diagonal, box); !reflect.DeepEqual(got, NewLineString([]Position{{0, 0}, {10, 10}})) {
+		t.Errorf("BBoxClip(diagonal) = %v, want the box diagonal", got)
+	}
+	outside := NewLineString([]Position{{-5, -5}, {-1, 20}})
+	if got, err := BBoxClip(outside, box); got != nil || err != nil {
+		t.Errorf("BBoxClip(outside) = %v, %v; want nil", got, err)
+	}
+}
+
+func TestBBoxClipPolygon(t *testing.T) {
+	box := BBox{MinLon: 0, MinLat: 0, MaxLon: 10, MaxLat: 10}
+
+	// Half outside to the east, with a hole straddling the box edge and one
+	// wholly outside it.
+	poly := NewPolygon([][]Position{
+		{{5, 2}, {15, 2}, {15, 8}, {5, 8}, {5, 2}},
+		{{9, 4}, {9, 6}, {11, 6}, {11, 4}, {9, 4}},
+		{{12, 4}, {12, 6}, {14, 6}, {14, 4}, {12, 4}},
+	})
+	got, err := BBoxClip(poly, box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clipped, ok := got.(Polygon)
+	if !ok {
+		t.Fatalf("BBoxClip = %T, want Polygon", got)
+	}
+	// The hole cut by the box's edge becomes a notch in the outer ring rather
+	// than a hole sharing that edge.
+	if len(clipped.Coordinates) != 1 {
+		t.Fatalf("got %d rings, want one notched outer ring", len(clipped.Coordinates))
+	}
+	sameRingVertices(t, clipped.Coordinates[0], []Position{{5, 2}, {10, 2}, {10, 4}, {9, 4}, {9, 6}, {10, 6}, {10, 8}, {5, 8}})
+	if area := polygonPlanarArea(clipped); area != 30-2 {
+		t.Errorf("area = %v, want 28", area)
+	}
+
+	donut := NewPolygon([][]Position{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{4, 4}, {4, 6}, {6, 6}, {6, 4}, {4, 4}},
+	})
+	half := BBox{MinLon: 0, MinLat: 0, MaxLon: 5, MaxLat: 10}
+	got, err = BBoxClip(donut, half)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Intersection(donut, squarePolygon(0, 0, 5, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	notched, ok := got.(Polygon)
+	if !ok || len(notched.Coordinates) != 1 {
+		t.Fatalf("BBoxClip(donut) = %v, want a single notched ring", got)
+	}
+	sameRingVertices(t, notched.Coordinates[0], openRing(want.(Polygon).Coordinates[0]))
+	if area := polygonPlanarArea(notched); area != 50-2 {
+		t.Errorf("area = %v, want 48", area)
+	}
+
+	// Holes wholly inside the box are kept as holes.
+	got, _ = BBoxClip(donut, BBox{MinLon: 0, MinLat: 0, MaxLon: 8, MaxLat: 10})
+	if rings := got.(Polygon).Coordinates; len(rings) != 2 {
+		t.Errorf("got %d rings, want outer ring and hole", len(rings))
+	}
+
+	// A U whose arms leave through the top and whose bottom lies outside:
+	// the arms are separate polygons rather than joined along the top edge.
+	u := NewPolygon([][]Position{{{2, -5}, {8, -5}, {8, 15}, {6, 15}, {6, -2}, {4, -2}, {4, 15}, {2, 15}, {2, -5}}})
+	got, err = BBoxClip(u, BBox{MinLon: 0, MinLat: 0, MaxLon: 10, MaxLat: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arms, ok := got.(MultiPolygon)
+	if !ok || len(arms.Coordinates) != 2 {
+		t.Fatalf("BBoxClip(u) = %v, want two arms", got)
+	}
+	for _, arm := range arms.Coordinates {
+		if area := polygonPlanarArea(NewPolygon(arm)); area != 20 {
+			t.Errorf("arm %v has area %v, want 20", arm, area)
+		}
+		if k, _ := Kinks(NewPolygon(arm)); len(k) > 0 {
+			t.Errorf("arm %v self-intersects at %v", arm, k)
+		}
+	}
+
+	outside := NewPolygon([][]Position{{{20, 20}, {30, 20}, {30, 30}, {20, 20}}})
+	if got, err := BBoxClip(outside, box); got != nil || err != nil {
+		t.Errorf("BBoxClip(outside) = %v, %v; want nil", got, err)
+	}
+}
+
+func TestBBoxClipFeatures(t *testing.T) {
+	box := BBox{MinLon: 0, MinLat: 0, MaxLon: 10, MaxLat: 10}
+	fc := NewFeatureCollection([]Feature{
+		{Type: "Feature", Geometry: NewPoint(5, 5), Properties: map[string]interface{}{"name": "in"}},
+		{Type: "Feature", Geometry: NewPoint(50, 5), Properties: map[string]interface{}{"name": "out"}},
+		{Type: "Feature", Geometry: NewMultiPoint([]Position{{1, 1}, {20, 20}}), BBox: []float64{1, 1, 20, 20}},
+	})
+	got, err := BBoxClip(fc, box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NewFeatureCollection([]Feature{
+		{Type: "Feature", Geometry: NewPoint(5, 5), Properties: map[string]interface{}{"name": "in"}},
+		{Type: "Feature", Geometry: NewMultiPoint([]Position{{1, 1}})},
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BBoxClip = %#v, want %#v", got, want)
+	}
+
+	if _, err := BBoxClip(NewPoint(0, 0), BBox{MinLon: 1, MaxLon: 0}); err == nil {
+		t.Error("expected error for an empty box")
+	}
+	if _, err := BBoxClip("nope", box); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+}
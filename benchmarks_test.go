@@ -1,6 +1,11 @@
 package geo
 
-import "testing"
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"testing"
+)
 
 var (
 	sinkFloat float64
@@ -17,6 +22,28 @@ func BenchmarkGreatCircleDistance(b *testing.B) {
 	}
 }
 
+func BenchmarkGreatCircleInverse(b *testing.B) {
+	lat1, lon1 := 40.7128, -74.0060
+	lat2, lon2 := 51.5074, -0.1278
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d, initial, final := GreatCircleInverse(lat1, lon1, lat2, lon2)
+		sinkFloat = d + initial + final
+	}
+}
+
+func BenchmarkGreatCircleInverseSeparate(b *testing.B) {
+	lat1, lon1 := 40.7128, -74.0060
+	lat2, lon2 := 51.5074, -0.1278
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d := GreatCircleDistance(lat1, lon1, lat2, lon2)
+		initial := Bearing(lat1, lon1, lat2, lon2)
+		final := finalBearing(lat1, lon1, lat2, lon2)
+		sinkFloat = d + initial + final
+	}
+}
+
 func BenchmarkRhumbLineDistance(b *testing.B) {
 	lat1, lon1 := 40.7128, -74.0060
 	lat2, lon2 := 51.5074, -0.1278
@@ -71,6 +98,40 @@ func BenchmarkGreatCircleProjectToSegment(b *testing.B) {
 	}
 }
 
+// benchmarkWorldPositions returns n positions spread over the whole globe.
+func benchmarkWorldPositions(n int) []Position {
+	rng := rand.New(rand.NewSource(1))
+	points := make([]Position, n)
+	for i := range points {
+		points[i] = Position{rng.Float64()*360 - 180, rng.Float64()*180 - 90}
+	}
+	return points
+}
+
+func BenchmarkWithinRadius(b *testing.B) {
+	candidates := benchmarkWorldPositions(10000)
+	center := Position{-0.1278, 51.5074}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sinkSlice = WithinRadius(center, candidates, 500)
+	}
+}
+
+func BenchmarkWithinRadiusNaive(b *testing.B) {
+	candidates := benchmarkWorldPositions(10000)
+	center := Position{-0.1278, 51.5074}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var indices []int
+		for j, p := range candidates {
+			if GreatCircleDistance(center[1], center[0], p[1], p[0]) <= 500 {
+				indices = append(indices, j)
+			}
+		}
+		sinkSlice = indices
+	}
+}
+
 func BenchmarkGeohashEncode(b *testing.B) {
 	lat, lon := 37.7749, -122.4194
 	precision := 9
@@ -112,6 +173,89 @@ func BenchmarkDijkstra(b *testing.B) {
 	}
 }
 
+func BenchmarkDijkstraReuse(b *testing.B) {
+	const n = 1000
+	graph := NewGraph(n)
+	for i := 0; i < n-1; i++ {
+		graph.AddBidirectionalEdge(i, i+1, 1.0)
+	}
+	graph.AddBidirectionalEdge(0, n-1, 1.0)
+	var ws DijkstraWorkspace
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		result := graph.DijkstraReuse(0, &ws)
+		sinkFloat = result.Distances[n-1]
+	}
+}
+
+// denseGraph returns a complete directed graph on n nodes with random weights.
+func denseGraph(n int) *Graph {
+	rng := rand.New(rand.NewSource(1))
+	g := NewGraph(n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j {
+				g.AddEdge(i, j, 1+rng.Float64()*100)
+			}
+		}
+	}
+	return g
+}
+
+// lazyDijkstra is the lazy-deletion search Dijkstra used before decrease-key:
+// every improvement pushes a new entry and stale entries are skipped when
+// popped. It returns the distances and the number of heap operations.
+func lazyDijkstra(g *Graph, source int) ([]float64, int) {
+	distances := make([]float64, g.Nodes)
+	for i := range distances {
+		distances[i] = math.Inf(1)
+	}
+	distances[source] = 0
+	visited := make([]bool, g.Nodes)
+	pq := priorityQueue{}
+	heap.Push(&pq, &priorityQueueItem{node: source})
+	ops := 1
+	for pq.Len() > 0 {
+		u := heap.Pop(&pq).(*priorityQueueItem).node
+		ops++
+		if visited[u] {
+			continue
+		}
+		visited[u] = true
+		for _, edge := range g.Edges[u] {
+			if alt := distances[u] + edge.Weight; !visited[edge.To] && alt < distances[edge.To] {
+				distances[edge.To] = alt
+				heap.Push(&pq, &priorityQueueItem{node: edge.To, distance: alt})
+				ops++
+			}
+		}
+	}
+	return distances, ops
+}
+
+func BenchmarkDijkstraDense(b *testing.B) {
+	graph := denseGraph(300)
+	var ws DijkstraWorkspace
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		result := graph.DijkstraReuse(0, &ws)
+		sinkFloat = result.Distances[graph.Nodes-1]
+	}
+	b.ReportMetric(float64(ws.heapOps), "heapops/op")
+}
+
+func BenchmarkDijkstraDenseLazy(b *testing.B) {
+	graph := denseGraph(300)
+	var ops int
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var distances []float64
+		distances, ops = lazyDijkstra(graph, 0)
+		sinkFloat = distances[graph.Nodes-1]
+	}
+	b.ReportMetric(float64(ops), "heapops/op")
+}
+
 func BenchmarkTSPNearestNeighbor(b *testing.B) {
 	coords := []struct{ lat, lon float64 }{
 		{40.7128, -74.0060},
@@ -147,3 +291,102 @@ func BenchmarkTSPNearestNeighbor(b *testing.B) {
 		sinkFloat = result.Distance
 	}
 }
+
+func BenchmarkDistanceMatrixDense(b *testing.B) {
+	points := randomPositions(rand.New(rand.NewSource(1)), 500)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m := denseMatrixFromPoints(points)
+		sinkFloat = m[1][2]
+	}
+}
+
+func BenchmarkDistanceMatrixSymmetric(b *testing.B) {
+	points := randomPositions(rand.New(rand.NewSource(1)), 500)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m := SymmetricMatrixFromPoints(points)
+		sinkFloat = m.At(1, 2)
+	}
+}
+
+func BenchmarkTSP2OptDense(b *testing.B) {
+	points := randomPositions(rand.New(rand.NewSource(1)), 200)
+	m := denseMatrixFromPoints(points)
+	tour := TSPNearestNeighbor(m, 0).Tour
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sinkFloat = TSP2Opt(m, tour, 0).Distance
+	}
+}
+
+func BenchmarkTSP2OptSymmetric(b *testing.B) {
+	points := randomPositions(rand.New(rand.NewSource(1)), 200)
+	m := SymmetricMatrixFromPoints(points)
+	tour := TSPNearestNeighborFunc(m, 0).Tour
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sinkFloat = TSP2OptFunc(m, tour, 0).Distance
+	}
+}
+
+func BenchmarkTSP2Opt500(b *testing.B) {
+	points := randomPositions(rand.New(rand.NewSource(1)), 500)
+	m := denseMatrixFromPoints(points)
+	tour := TSPNearestNeighbor(m, 0).Tour
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sinkFloat = TSP2Opt(m, tour, 0).Distance
+	}
+	b.ReportMetric(sinkFloat, "km")
+}
+
+func BenchmarkTSP2OptNeighborList500(b *testing.B) {
+	points := randomPositions(rand.New(rand.NewSource(1)), 500)
+	m := denseMatrixFromPoints(points)
+	tour := TSPNearestNeighbor(m, 0).Tour
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sinkFloat = TSP2OptNeighborList(m, tour, 10, 0).Distance
+	}
+	b.ReportMetric(sinkFloat, "km")
+}
+
+func benchmarkLatLons(n int) []LatLon {
+	positions := randomPositions(rand.New(rand.NewSource(1)), n)
+	points := make([]LatLon, n)
+	for i, p := range positions {
+		points[i] = LatLon{Lat: p[1], Lon: p[0]}
+	}
+	return points
+}
+
+func BenchmarkDistanceMatrix(b *testing.B) {
+	points := benchmarkLatLons(300)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m := DistanceMatrix(points, UnitKilometers)
+		sinkFloat = m[1][2]
+	}
+}
+
+func BenchmarkDistanceMatrixNaive(b *testing.B) {
+	points := benchmarkLatLons(300)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m := make([][]float64, len(points))
+		for r := range m {
+			m[r] = make([]float64, len(points))
+			for c := range points {
+				if r != c {
+					m[r][c] = GreatCircleDistance(points[r].Lat, points[r].Lon, points[c].Lat, points[c].Lon)
+				}
+			}
+		}
+		sinkFloat = m[1][2]
+	}
+}
@@ -0,0 +1,294 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Buffer returns the area within distanceKm of obj as a Polygon, or a
+// MultiPolygon when the buffered parts do not touch. Points become circles,
+// lines become corridors with round joins and caps, and polygons grow outward
+// by distanceKm, shrinking or closing their holes. Multi-geometries, geometry
+// collections, features and feature collections are buffered part by part and
+// merged.
+//
+// steps is the number of segments used for a full circle; values below 3 use
+// 64, as in Circle. Offsets are computed on the sphere, while the parts are
+// merged with Union in the lon/lat plane, so geometries crossing the
+// antimeridian are not supported.
+func Buffer(obj interface{}, distanceKm float64, steps int) (interface{}, error) {
+	if !(distanceKm > 0) {
+		return nil, fmt.Errorf("buffer distance must be positive, got %v", distanceKm)
+	}
+	if steps < 3 {
+		steps = 64
+	}
+	b := bufferBuilder{distanceKm: distanceKm, steps: steps}
+	if err := b.add(obj); err != nil {
+		return nil, err
+	}
+	switch len(b.pieces) {
+	case 0:
+		return assemblePolygons(nil), nil
+	case 1:
+		return NewPolygon(b.pieces[0]), nil
+	}
+	region, err := unionAll(b.pieces)
+	if err != nil {
+		return nil, err
+	}
+	if len(region) == 1 {
+		return NewPolygon(region[0]), nil
+	}
+	return NewMultiPolygon(region), nil
+}
+
+// LineBuffer returns the corridor within distanceKm of line: the line offset
+// to both sides along perpendicular bearings, joined round at its vertices
+// and closed with semicircular caps of capSegments segments at its ends. It
+// is Buffer for a LineString, with capSegments below 2 using 32. A line that
+// crosses itself gives a polygon with holes where its loops enclose land
+// farther than distanceKm from it. An error is returned for a line with fewer
+// than 2 positions or a non-positive distance.
+func LineBuffer(line LineString, distanceKm float64, capSegments int) (Polygon, error) {
+	if len(line.Coordinates) < 2 {
+		return Polygon{}, fmt.Errorf("line buffer needs at least 2 positions, got %d", len(line.Coordinates))
+	}
+	if capSegments < 2 {
+		capSegments = 32
+	}
+	buffered, err := Buffer(line, distanceKm, 2*capSegments)
+	if err != nil {
+		return Polygon{}, err
+	}
+	poly, ok := buffered.(Polygon)
+	if !ok {
+		return Polygon{}, fmt.Errorf("line buffer is a %T, want Polygon", buffered)
+	}
+	return poly, nil
+}
+
+// bufferBuilder collects the overlapping polygons whose union is the buffer:
+// a circle around every vertex and a band along every segment.
+type bufferBuilder struct {
+	distanceKm float64
+	steps      int
+	pieces     [][][]Position
+}
+
+func (b *bufferBuilder) add(obj interface{}) error {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return err
+	}
+	switch g := geom.(type) {
+	case Point:
+		b.pieces = append(b.pieces, Circle(g, b.distanceKm, b.steps).Coordinates)
+	case MultiPoint:
+		for _, p := range g.Coordinates {
+			b.pieces = append(b.pieces, Circle(Point{Coordinates: p}, b.distanceKm, b.steps).Coordinates)
+		}
+	case LineString:
+		b.addLine(g.Coordinates)
+	case MultiLineString:
+		for _, line := range g.Coordinates {
+			b.addLine(line)
+		}
+	case Polygon:
+		b.addPolygon(g.Coordinates)
+	case MultiPolygon:
+		for _, poly := range g.Coordinates {
+			b.addPolygon(poly)
+		}
+	case GeometryCollection:
+		for _, child := range g.Geometries {
+			if err := b.add(child); err != nil {
+				return err
+			}
+		}
+	case Feature:
+		return b.add(g.Geometry)
+	case FeatureCollection:
+		for _, f := range g.Features {
+			if err := b.add(f); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported geojson type %T", obj)
+	}
+	return nil
+}
+
+// addPolygon adds the polygon itself and a corridor along each of its rings,
+// which together cover everything within the buffer distance of it.
+func (b *bufferBuilder) addPolygon(rings [][]Position) {
+	if len(rings) == 0 {
+		return
+	}
+	if area, _, _ := ringAreaCentroid(openRing(rings[0])); area != 0 {
+		b.pieces = append(b.pieces, rings)
+	}
+	for _, ring := range rings {
+		if len(ring) > 0 && !samePosition(ring[0], ring[len(ring)-1]) {
+			ring = append(ring[:len(ring):len(ring)], ring[0])
+		}
+		b.addLine(ring)
+	}
+}
+
+// addLine adds a band of half-width distanceKm along each segment and a circle
+// around each vertex. The band is offset perpendicular to the local bearing at
+// points spaced no more than distanceKm apart, so it follows the great circle.
+// Its corners are also vertices of the circles at the segment ends, so the
+// pieces meet exactly instead of leaving slivers. A closed line gets a single
+// circle at its first and last vertex.
+func (b *bufferBuilder) addLine(coords []Position) {
+	var pts []Position
+	for _, p := range coords {
+		if len(pts) == 0 || !samePosition(p, pts[len(pts)-1]) {
+			pts = append(pts, p)
+		}
+	}
+	if len(pts) == 0 {
+		return
+	}
+	vertices := len(pts)
+	if vertices > 2 && samePosition(pts[0], pts[vertices-1]) {
+		vertices--
+	}
+
+	tangents := make([][]float64, vertices)
+	for i := 0; i+1 < len(pts); i++ {
+		lat1, lon1 := positionLatLon(pts[i])
+		lat2, lon2 := positionLatLon(pts[i+1])
+		n := int(math.Ceil(GreatCircleDistance(lat1, lon1, lat2, lon2) / b.distanceKm))
+		if n < 1 {
+			n = 1
+		} else if n > b.steps {
+			n = b.steps
+		}
+
+		left := make([]Position, n+1)
+		right := make([]Position, n+1)
+		for k := 0; k <= n; k++ {
+			lat, lon := lat1, lon1
+			var lb, rb float64
+			switch k {
+			case 0:
+				bearing := Bearing(lat1, lon1, lat2, lon2)
+				lb = b.cornerBearing(&tangents[i], bearing-90)
+				rb = b.cornerBearing(&tangents[i], bearing+90)
+			case n:
+				lat, lon = lat2, lon2
+				bearing := finalBearing(lat1, lon1, lat2, lon2)
+				lb = b.cornerBearing(&tangents[(i+1)%vertices], bearing-90)
+				rb = b.cornerBearing(&tangents[(i+1)%vertices], bearing+90)
+			default:
+				lat, lon = GreatCircleIntermediatePoint(lat1, lon1, lat2, lon2, float64(k)/float64(n))
+				bearing := Bearing(lat, lon, lat2, lon2)
+				lb, rb = normalizeBearingDegrees(bearing-90), normalizeBearingDegrees(bearing+90)
+			}
+			left[k] = b.offset(lat, lon, lb)
+			right[k] = b.offset(lat, lon, rb)
+		}
+
+		ring := make([]Position, 0, 2*n+3)
+		ring = append(ring, left...)
+		for k := n; k >= 0; k-- {
+			ring = append(ring, right[k])
+		}
+		ring = append(ring, left[0])
+		b.pieces = append(b.pieces, [][]Position{ring})
+	}
+
+	for i, p := range pts[:vertices] {
+		b.pieces = append(b.pieces, [][]Position{b.circle(p, tangents[i])})
+	}
+}
+
+// cornerBearing returns the bearing of a band corner at a vertex and records
+// it in the vertex's corner bearings. A bearing practically equal to one
+// already recorded is replaced by it, so bands meeting in a straight line
+// share their corners.
+func (b *bufferBuilder) cornerBearing(corners *[]float64, bearing float64) float64 {
+	const bearingEpsilon = 1e-9
+	bearing = normalizeBearingDegrees(bearing)
+	for _, c := range *corners {
+		if math.Abs(BearingDifference(c, bearing)) < bearingEpsilon {
+			return c
+		}
+	}
+	*corners = append(*corners, bearing)
+	return bearing
+}
+
+// circle returns a closed ring around center with vertices at steps evenly
+// spaced bearings plus the given corner bearings. Regular bearings closer to a
+// corner than a tenth of their spacing are left out, as they would only add
+// a sliver for the union to resolve.
+func (b *bufferBuilder) circle(center Position, corners []float64) []Position {
+	minGap := 36 / float64(b.steps)
+	bearings := append([]float64(nil), corners...)
+	for i := 0; i < b.steps; i++ {
+		bearing := 360 * float64(i) / float64(b.steps)
+		near := false
+		for _, c := range corners {
+			if math.Abs(BearingDifference(c, bearing)) < minGap {
+				near = true
+				break
+			}
+		}
+		if !near {
+			bearings = append(bearings, bearing)
+		}
+	}
+	sort.Float64s(bearings)
+
+	lat, lon := positionLatLon(center)
+	ring := make([]Position, len(bearings)+1)
+	for i, bearing := range bearings {
+		ring[i] = b.offset(lat, lon, bearing)
+	}
+	ring[len(bearings)] = ring[0]
+	return ring
+}
+
+func (b *bufferBuilder) offset(lat, lon, bearing float64) Position {
+	dLat, dLon := GreatCircleDestination(lat, lon, b.distanceKm, bearing)
+	return Position{dLon, dLat}
+}
+
+// unionAll merges polygons pairwise, halving the list each round so that no
+// single overlay has to handle most of the edges more than a few times.
+func unionAll(polys [][][]Position) ([][][]Position, error) {
+	regions := make([][][][]Position, len(polys))
+	for i, p := range polys {
+		regions[i] = [][][]Position{p}
+	}
+	for len(regions) > 1 {
+		next := regions[:0:0]
+		for i := 0; i+1 < len(regions); i += 2 {
+			merged, err := polygonOverlay(regions[i], regions[i+1], clipUnion)
+			if err != nil {
+				return nil, err
+			}
+			switch m := merged.(type) {
+			case Polygon:
+				next = append(next, [][][]Position{m.Coordinates})
+			case MultiPolygon:
+				next = append(next, m.Coordinates)
+			}
+		}
+		if len(regions)%2 == 1 {
+			next = append(next, regions[len(regions)-1])
+		}
+		regions = next
+	}
+	if len(regions) == 0 || len(regions[0]) == 0 {
+		return nil, errors.New("buffer has no area")
+	}
+	return regions[0], nil
+}
@@ -0,0 +1,279 @@
+package geo
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBufferPointMatchesCircle(t *testing.T) {
+	center := NewPoint(13.4, 52.5)
+	got, err := Buffer(center, 3, 32)
+	if err != nil {
+		t.Fatalf("Buffer: %v", err)
+	}
+	want := Circle(center, 3, 32)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Buffer(point) = %v, want %v", got, want)
+	}
+}
+
+func TestBufferLineCorridorWidth(t *testing.T) {
+	const d = 5.0
+	line := NewLineString([]Position{{0, 0}, {1, 0}})
+	got, err := Buffer(line, d, 64)
+	if err != nil {
+		t.Fatalf("Buffer: %v", err)
+	}
+	poly, ok := got.(Polygon)
+	if !ok {
+		t.Fatalf("Buffer(line) = %T, want Polygon", got)
+	}
+
+	// Probe across the corridor from well outside on either side: the
+	// distances to the edge give the width.
+	probe := 3 * d / (EarthRadiusKm * math.Pi / 180)
+	for _, lon := range []float64{0.2, 0.5, 0.8} {
+		north, err := PolygonPointDistance(poly, NewPoint(lon, probe))
+		if err != nil {
+			t.Fatal(err)
+		}
+		south, err := PolygonPointDistance(poly, NewPoint(lon, -probe))
+		if err != nil {
+			t.Fatal(err)
+		}
+		width := 6*d - north - south
+		if math.Abs(width-2*d)/(2*d) > 0.01 {
+			t.Errorf("lon %v: corridor width %.4f km, want %.4f km", lon, width, 2*d)
+		}
+		middle, err := PolygonPointDistance(poly, NewPoint(lon, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(-middle-d)/d > 0.01 {
+			t.Errorf("lon %v: center line %.4f km from edge, want %.4f km", lon, -middle, d)
+		}
+	}
+
+	// Round caps reach distanceKm beyond each end.
+	beyond := 2 * d / (EarthRadiusKm * math.Pi / 180)
+	for _, lon := range []float64{-beyond, 1 + beyond} {
+		dist, err := PolygonPointDistance(poly, NewPoint(lon, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(dist-d)/d > 0.01 {
+			t.Errorf("cap at lon %v: distance %.4f km, want %.4f km", lon, dist, d)
+		}
+	}
+}
+
+func TestBufferLineWithJoins(t *testing.T) {
+	line := NewLineString([]Position{{0, 0}, {0.5, 0}, {0.5, 0.5}, {0.1, 0.1}})
+	got, err := Buffer(line, 2, 32)
+	if err != nil {
+		t.Fatalf("Buffer: %v", err)
+	}
+	poly, ok := got.(Polygon)
+	if !ok {
+		t.Fatalf("Buffer(line) = %T, want Polygon", got)
+	}
+	for _, p := range line.Coordinates {
+		if dist, _ := PolygonPointDistance(poly, Point{Coordinates: p}); dist >= 0 {
+			t.Errorf("vertex %v not inside buffer (distance %v)", p, dist)
+		}
+	}
+	if dist, _ := PolygonPointDistance(poly, NewPoint(0.25, 0.2)); dist <= 0 {
+		t.Errorf("point far from the line is inside the buffer")
+	}
+}
+
+func TestBufferCurvedLines(t *testing.T) {
+	// A gentle curve puts the band corners at each join almost on top of
+	// the vertex circle's own vertices.
+	gentle := NewLineString([]Position{{0, 0}, {0.01, 0.01 * math.Sin(0.2)}, {0.02, 0.01 * math.Sin(0.4)}})
+	for _, steps := range []int{16, 32, 64} {
+		checkLineBuffer(t, gentle, 1, steps)
+	}
+
+	// GPS-track-like lines: short steps with a drifting heading.
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		lat, lon := rng.Float64()*120-60, rng.Float64()*100-50
+		bearing := rng.Float64() * 360
+		coords := []Position{{lon, lat}}
+		for n := 3 + rng.Intn(8); len(coords) < n; {
+			bearing += rng.NormFloat64() * 20
+			lat, lon = GreatCircleDestination(lat, lon, 0.5+rng.Float64(), bearing)
+			coords = append(coords, Position{lon, lat})
+		}
+		checkLineBuffer(t, NewLineString(coords), 0.5+2*rng.Float64(), 64)
+	}
+}
+
+func TestBufferLongWigglyLine(t *testing.T) {
+	coords := make([]Position, 300)
+	for i := range coords {
+		coords[i] = Position{float64(i) * 0.01, 0.01 * math.Sin(float64(i)*0.2)}
+	}
+	start := time.Now()
+	checkLineBuffer(t, NewLineString(coords), 1, 64)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Buffer took %v", elapsed)
+	}
+}
+
+// checkLineBuffer buffers line and checks that the result is a single valid
+// polygon containing every vertex.
+func checkLineBuffer(t *testing.T, line LineString, distanceKm float64, steps int) {
+	t.Helper()
+	got, err := Buffer(line, distanceKm, steps)
+	if err != nil {
+		t.Errorf("Buffer(%v, %v, %d): %v", line.Coordinates, distanceKm, steps, err)
+		return
+	}
+	poly, ok := got.(Polygon)
+	if !ok {
+		t.Errorf("Buffer(%v, %v, %d) = %T, want Polygon", line.Coordinates, distanceKm, steps, got)
+		return
+	}
+	if kinks, _ := Kinks(poly); len(kinks) > 0 {
+		t.Errorf("Buffer(%v, %v, %d) self-intersects at %v", line.Coordinates, distanceKm, steps, kinks)
+	}
+	for _, p := range line.Coordinates {
+		if dist, _ := PolygonPointDistance(poly, Point{Coordinates: p}); dist >= 0 {
+			t.Errorf("Buffer(%v, %v, %d): vertex %v not inside", line.Coordinates, distanceKm, steps, p)
+		}
+	}
+}
+
+func TestBufferPolygon(t *testing.T) {
+	square := NewPolygon([][]Position{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}})
+	const d = 10.0
+	got, err := Buffer(square, d, 32)
+	if err != nil {
+		t.Fatalf("Buffer: %v", err)
+	}
+	poly, ok := got.(Polygon)
+	if !ok {
+		t.Fatalf("Buffer(polygon) = %T, want Polygon", got)
+	}
+	if len(poly.Coordinates) != 1 {
+		t.Errorf("buffered square has %d rings, want 1", len(poly.Coordinates))
+	}
+	deg := 1 / (EarthRadiusKm * math.Pi / 180)
+	cases := []struct {
+		p      Point
+		inside bool
+	}{
+		{NewPoint(0.5, 0.5), true},
+		{NewPoint(0.5, -0.5*d*deg), true},
+		{NewPoint(1+0.5*d*deg, 0.5), true},
+		{NewPoint(0.5, 1+1.5*d*deg), false},
+		{NewPoint(-1.5*d*deg, 0.5), false},
+	}
+	for _, tc := range cases {
+		dist, err := PolygonPointDistance(poly, tc.p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (dist < 0) != tc.inside {
+			t.Errorf("point %v: distance %v, want inside=%v", tc.p.Coordinates, dist, tc.inside)
+		}
+	}
+}
+
+func TestBufferClosesSmallHoles(t *testing.T) {
+	donut := NewPolygon([][]Position{
+		{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}},
+		{{0.4, 0.4}, {0.4, 0.6}, {0.6, 0.6}, {0.6, 0.4}, {0.4, 0.4}},
+	})
+	small, err := Buffer(donut, 5, 32)
+	if err != nil {
+		t.Fatalf("Buffer: %v", err)
+	}
+	if p, ok := small.(Polygon); !ok || len(p.Coordinates) != 2 {
+		t.Errorf("small buffer = %v, want a polygon that keeps its hole", small)
+	}
+	large, err := Buffer(donut, 20, 32)
+	if err != nil {
+		t.Fatalf("Buffer: %v", err)
+	}
+	if p, ok := large.(Polygon); !ok || len(p.Coordinates) != 1 {
+		t.Errorf("large buffer = %v, want a polygon without holes", large)
+	}
+}
+
+func TestBufferMultiAndErrors(t *testing.T) {
+	mp := NewMultiPoint([]Position{{0, 0}, {10, 0}})
+	got, err := Buffer(mp, 5, 16)
+	if err != nil {
+		t.Fatalf("Buffer: %v", err)
+	}
+	if m, ok := got.(MultiPolygon); !ok || len(m.Coordinates) != 2 {
+		t.Errorf("Buffer(distant points) = %v, want MultiPolygon with 2 parts", got)
+	}
+	got, err = Buffer(NewMultiPoint([]Position{{0, 0}, {0.01, 0}}), 5, 16)
+	if err != nil {
+		t.Fatalf("Buffer: %v", err)
+	}
+	if _, ok := got.(Polygon); !ok {
+		t.Errorf("Buffer(close points) = %T, want Polygon", got)
+	}
+
+	if _, err := Buffer(NewPoint(0, 0), 0, 16); err == nil {
+		t.Error("expected error for zero distance")
+	}
+	if _, err := Buffer("nope", 1, 16); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+}
+
+func TestLineBuffer(t *testing.T) {
+	const d = 4.0
+	line := NewLineString([]Position{{10, 45}, {10.4, 45.1}, {10.8, 44.9}})
+	poly, err := LineBuffer(line, d, 16)
+	if err != nil {
+		t.Fatalf("LineBuffer: %v", err)
+	}
+	if len(poly.Coordinates) != 1 {
+		t.Fatalf("LineBuffer() has %d rings, want 1", len(poly.Coordinates))
+	}
+
+	// Points exactly d off the middle of each segment, on either side, lie on
+	// the boundary.
+	for i := 0; i+1 < len(line.Coordinates); i++ {
+		lat1, lon1 := positionLatLon(line.Coordinates[i])
+		lat2, lon2 := positionLatLon(line.Coordinates[i+1])
+		midLat, midLon := GreatCircleIntermediatePoint(lat1, lon1, lat2, lon2, 0.5)
+		bearing := Bearing(midLat, midLon, lat2, lon2)
+		for _, side := range []float64{90, -90} {
+			lat, lon := GreatCircleDestination(midLat, midLon, d, bearing+side)
+			dist, err := PolygonPointDistance(poly, NewPoint(lon, lat))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(dist) > 0.01*d {
+				t.Errorf("segment %d side %v: point %.4f km from the boundary", i, side, dist)
+			}
+		}
+	}
+
+	// The gentle curve that used to defeat the union must not come back
+	// empty.
+	gentle := NewLineString([]Position{{0, 0}, {0.01, 0.01 * math.Sin(0.2)}, {0.02, 0.01 * math.Sin(0.4)}})
+	for _, caps := range []int{16, 32} {
+		if got, err := LineBuffer(gentle, 1, caps); err != nil || len(got.Coordinates) != 1 {
+			t.Errorf("LineBuffer(curve, %d) = %v, %v, want a polygon", caps, got, err)
+		}
+	}
+
+	if _, err := LineBuffer(NewLineString([]Position{{0, 0}}), d, 8); err == nil {
+		t.Error("expected error for a single point")
+	}
+	if _, err := LineBuffer(line, 0, 8); err == nil {
+		t.Error("expected error for zero distance")
+	}
+}
@@ -0,0 +1,716 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// clipOp selects the boolean operation performed by polygonOverlay.
+type clipOp int
+
+const (
+	clipUnion clipOp = iota
+	clipIntersection
+	clipDifference
+)
+
+// clipEpsilon is the tolerance, in degrees, used to snap intersection points
+// onto nearby segment endpoints.
+const clipEpsilon = 1e-12
+
+// overlaySnap is the distance, in degrees, within which the overlay treats
+// the vertices and intersection points of its inputs as one node. It is about
+// a tenth of a millimetre on the ground; nodes closer than that would leave
+// edges too short to classify reliably.
+const overlaySnap = 1e-9
+
+// Union returns the union of two polygons as a Polygon, or a MultiPolygon when
+// the inputs are disjoint. Polygons are clipped in the lon/lat plane, so edges
+// are treated as straight lines in degree space rather than great circles.
+func Union(a, b Polygon) (interface{}, error) {
+	return polygonOverlay([][][]Position{a.Coordinates}, [][][]Position{b.Coordinates}, clipUnion)
+}
+
+// Intersection returns the area covered by both polygons as a Polygon, or a
+// MultiPolygon when the overlap has several parts. Disjoint inputs give an
+// empty MultiPolygon. Like Union, clipping happens in the lon/lat plane.
+func Intersection(a, b Polygon) (interface{}, error) {
+	return polygonOverlay([][][]Position{a.Coordinates}, [][][]Position{b.Coordinates}, clipIntersection)
+}
+
+// Difference returns the part of a that is not covered by b as a Polygon, or a
+// MultiPolygon when b splits a into several parts. When b covers a entirely the
+// result is an empty MultiPolygon. Like Union, clipping happens in the lon/lat
+// plane.
+func Difference(a, b Polygon) (interface{}, error) {
+	return polygonOverlay([][][]Position{a.Coordinates}, [][][]Position{b.Coordinates}, clipDifference)
+}
+
+// CutAntimeridian splits a polygon that crosses the antimeridian into a
+// MultiPolygon with a part on each side, inserting vertices on the meridian at
+// longitude 180 on the eastern parts and -180 on the western ones. A crossing
+// is any edge spanning more than 180° of longitude; such edges are taken the
+// short way across the antimeridian. A polygon without one is returned as a
+// Polygon unchanged. Rings that encircle a pole cannot be cut this way and
+// give an error.
+func CutAntimeridian(poly Polygon) (interface{}, error) {
+	if len(poly.Coordinates) == 0 || len(poly.Coordinates[0]) == 0 {
+		return nil, errors.New("polygon has no coordinates")
+	}
+	crosses := false
+	for _, ring := range poly.Coordinates {
+		for i := 1; i < len(ring); i++ {
+			if math.Abs(ring[i][0]-ring[i-1][0]) > 180 {
+				crosses = true
+			}
+		}
+	}
+	if !crosses {
+		return poly, nil
+	}
+
+	// Make the longitudes continuous, so the polygon extends past ±180
+	// instead of jumping, with every ring on the same side as the outer one.
+	ref := poly.Coordinates[0][0][0]
+	rings := make([][]Position, len(poly.Coordinates))
+	minLon, maxLon := math.Inf(1), math.Inf(-1)
+	for r, ring := range poly.Coordinates {
+		if len(ring) == 0 {
+			continue
+		}
+		unwrapped := make([]Position, len(ring))
+		prev := ref
+		for i, p := range ring {
+			lon := p[0] + 360*math.Round((prev-p[0])/360)
+			unwrapped[i] = withAlt(lon, p[1], p)
+			prev = lon
+			minLon = math.Min(minLon, lon)
+			maxLon = math.Max(maxLon, lon)
+		}
+		if first, last := unwrapped[0], unwrapped[len(unwrapped)-1]; math.Abs(last[0]-first[0]) > 180 {
+			return nil, errors.New("polygon ring encircles a pole")
+		}
+		rings[r] = unwrapped
+	}
+
+	// Intersect with each 360° wide strip the polygon reaches and shift the
+	// pieces back into [-180, 180].
+	var parts [][][]Position
+	for k := math.Floor((minLon + 180) / 360); k*360-180 < maxLon; k++ {
+		west, east := k*360-180, k*360+180
+		strip := [][]Position{{{west, -90}, {east, -90}, {east, 90}, {west, 90}, {west, -90}}}
+		clipped, err := polygonOverlay([][][]Position{rings}, [][][]Position{strip}, clipIntersection)
+		if err != nil {
+			return nil, err
+		}
+		var pieces [][][]Position
+		switch c := clipped.(type) {
+		case Polygon:
+			pieces = [][][]Position{c.Coordinates}
+		case MultiPolygon:
+			pieces = c.Coordinates
+		}
+		for _, piece := range pieces {
+			for _, ring := range piece {
+				for i := range ring {
+					ring[i] = withAlt(ring[i][0]-k*360, ring[i][1], ring[i])
+				}
+			}
+			parts = append(parts, piece)
+		}
+	}
+	return NewMultiPolygon(parts), nil
+}
+
+// PolygonInPolygon reports whether inner lies entirely within outer: every
+// vertex of inner's outer ring is inside or on outer, no edge of inner crosses
+// an edge of outer, and no part of outer's boundary, such as a hole, lies
+// strictly inside inner. Shared boundaries are allowed. Like Union, the test
+// works in the lon/lat plane.
+func PolygonInPolygon(inner, outer Polygon) bool {
+	if len(inner.Coordinates) == 0 || len(inner.Coordinates[0]) < 3 || len(outer.Coordinates) == 0 {
+		return false
+	}
+	ring := inner.Coordinates[0]
+	for _, p := range ring {
+		if !pointInPolygon(p, outer) {
+			return false
+		}
+	}
+	innerSegs := ringSegments([][]Position{openRing(ring)})
+	var outerRings [][]Position
+	for _, r := range outer.Coordinates {
+		outerRings = append(outerRings, openRing(r))
+	}
+	outerSegs := ringSegments(outerRings)
+	for _, e := range innerSegs {
+		// A vertex of outer lying on e can let e leave outer without a
+		// proper crossing; its midpoint catches the simple cases.
+		if !pointInPolygon(segmentMidpoint(e), outer) {
+			return false
+		}
+		for _, f := range outerSegs {
+			if segmentsCross(e[0], e[1], f[0], f[1]) {
+				return false
+			}
+		}
+	}
+	for _, f := range outerSegs {
+		if pointInRing(f[0], ring) && !pointOnRing(f[0], ring) {
+			return false
+		}
+	}
+	return true
+}
+
+// segmentsCross reports whether segments p1p2 and q1q2 cross at a single point
+// interior to both.
+func segmentsCross(p1, p2, q1, q2 Position) bool {
+	d1 := orient2D(q1, q2, p1)
+	d2 := orient2D(q1, q2, p2)
+	d3 := orient2D(p1, p2, q1)
+	d4 := orient2D(p1, p2, q2)
+	return ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) &&
+		((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0))
+}
+
+// pointOnRing reports whether pt lies on an edge of ring.
+func pointOnRing(pt Position, ring []Position) bool {
+	for i := 0; i+1 < len(ring); i++ {
+		if pointOnSegment(pt, ring[i], ring[i+1]) {
+			return true
+		}
+	}
+	n := len(ring)
+	return n > 1 && !samePosition(ring[0], ring[n-1]) && pointOnSegment(pt, ring[n-1], ring[0])
+}
+
+// polygonOverlay computes a boolean operation between two regions, each given
+// as the coordinates of one or more non-overlapping polygons.
+//
+// Every edge of each region is split wherever it meets an edge of the other
+// region. Each resulting piece is then classified as inside or outside the
+// other region, or as shared with an edge of the other region. The pieces
+// selected by the operation are linked back into rings. Working on the
+// classified pieces rather than walking intersection lists keeps shared
+// vertices and overlapping edges from needing special cases.
+func polygonOverlay(a, b [][][]Position, op clipOp) (interface{}, error) {
+	ra, err := overlayRings(a)
+	if err != nil {
+		return nil, err
+	}
+	rb, err := overlayRings(b)
+	if err != nil {
+		return nil, err
+	}
+
+	aEdges, bEdges := splitOverlayEdges(ringSegments(ra), ringSegments(rb))
+
+	bSet := make(map[[2][2]float64]bool, len(bEdges))
+	for _, e := range bEdges {
+		bSet[edgeKey(e)] = true
+	}
+	aSet := make(map[[2][2]float64]bool, len(aEdges))
+	for _, e := range aEdges {
+		aSet[edgeKey(e)] = true
+	}
+
+	aIndex, bIndex := newRegionIndex(ra), newRegionIndex(rb)
+	var selected [][2]Position
+	for _, e := range aEdges {
+		same := bSet[edgeKey(e)]
+		opposite := bSet[edgeKey([2]Position{e[1], e[0]})]
+		inside := !same && !opposite && bIndex.contains(segmentMidpoint(e))
+		switch op {
+		case clipUnion:
+			if same || (!opposite && !inside) {
+				selected = append(selected, e)
+			}
+		case clipIntersection:
+			if same || inside {
+				selected = append(selected, e)
+			}
+		case clipDifference:
+			if opposite || (!same && !inside) {
+				selected = append(selected, e)
+			}
+		}
+	}
+	for _, e := range bEdges {
+		if aSet[edgeKey(e)] || aSet[edgeKey([2]Position{e[1], e[0]})] {
+			continue
+		}
+		inside := aIndex.contains(segmentMidpoint(e))
+		switch op {
+		case clipUnion:
+			if !inside {
+				selected = append(selected, e)
+			}
+		case clipIntersection:
+			if inside {
+				selected = append(selected, e)
+			}
+		case clipDifference:
+			if inside {
+				selected = append(selected, [2]Position{e[1], e[0]})
+			}
+		}
+	}
+
+	return assemblePolygons(linkOverlayEdges(selected)), nil
+}
+
+// overlayRings returns the rings of the polygons as open vertex lists with
+// outer rings counterclockwise and holes clockwise. A ring's role is taken from
+// its position in the polygon: the first ring is the outer ring.
+func overlayRings(polys [][][]Position) ([][]Position, error) {
+	var out [][]Position
+	for _, rings := range polys {
+		for i, ring := range rings {
+			open := openRing(ring)
+			if len(open) < 3 {
+				continue
+			}
+			area, _, _ := ringAreaCentroid(open)
+			if area == 0 {
+				continue
+			}
+			if (i == 0) != (area > 0) {
+				open = reversedRing(open)
+			}
+			out = append(out, open)
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("polygon has no rings with area")
+	}
+	return out, nil
+}
+
+// openRing returns the ring without its closing position and without
+// consecutive duplicate positions.
+func openRing(ring []Position) []Position {
+	out := make([]Position, 0, len(ring))
+	for _, p := range ring {
+		if len(out) > 0 && samePosition(out[len(out)-1], p) {
+			continue
+		}
+		out = append(out, p)
+	}
+	for len(out) > 1 && samePosition(out[0], out[len(out)-1]) {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+func reversedRing(ring []Position) []Position {
+	out := make([]Position, len(ring))
+	for i, p := range ring {
+		out[len(ring)-1-i] = p
+	}
+	return out
+}
+
+// ringSegments returns the edges of open rings, including the closing edge.
+func ringSegments(rings [][]Position) [][2]Position {
+	var segs [][2]Position
+	for _, ring := range rings {
+		for i := range ring {
+			segs = append(segs, [2]Position{ring[i], ring[(i+1)%len(ring)]})
+		}
+	}
+	return segs
+}
+
+// edgeKey returns the endpoints of e by longitude and latitude, for indexing
+// edges in maps.
+func edgeKey(e [2]Position) [2][2]float64 {
+	return [2][2]float64{lonLat(e[0]), lonLat(e[1])}
+}
+
+// splitOverlayEdges splits every segment of a and b at the points where it
+// meets a segment of the other set. Each intersection is computed once and
+// shared by both pieces, so coincident pieces compare equal exactly. As in
+// selfIntersections, segments are swept in order of their western ends so
+// that only pairs whose longitude ranges overlap are tested. Vertices and
+// intersection points within overlaySnap of each other are merged into one
+// node, and pieces that collapse to a point are dropped.
+func splitOverlayEdges(a, b [][2]Position) ([][2]Position, [][2]Position) {
+	segs := append(a[:len(a):len(a)], b...)
+	order := make([]int, len(segs))
+	for i := range order {
+		order[i] = i
+	}
+	west := func(i int) float64 { return math.Min(segs[i][0][0], segs[i][1][0]) }
+	east := func(i int) float64 { return math.Max(segs[i][0][0], segs[i][1][0]) }
+	sort.SliceStable(order, func(x, y int) bool { return west(order[x]) < west(order[y]) })
+
+	aCuts := make([][]Position, len(a))
+	bCuts := make([][]Position, len(b))
+	for x, i := range order {
+		for _, j := range order[x+1:] {
+			if west(j) > east(i)+clipEpsilon {
+				break
+			}
+			if (i < len(a)) == (j < len(a)) {
+				continue
+			}
+			ai, bj := min(i, j), max(i, j)-len(a)
+			for _, p := range segmentIntersections(a[ai][0], a[ai][1], b[bj][0], b[bj][1]) {
+				aCuts[ai] = append(aCuts[ai], p)
+				bCuts[bj] = append(bCuts[bj], p)
+			}
+		}
+	}
+
+	// Register the input vertices first so that they, rather than computed
+	// intersection points, become the nodes that nearby points snap to.
+	nodes := make(nodeSnapper)
+	for _, s := range segs {
+		nodes.snap(s[0])
+		nodes.snap(s[1])
+	}
+	return nodes.snapSegments(cutSegments(a, aCuts)), nodes.snapSegments(cutSegments(b, bCuts))
+}
+
+// cutSegments splits each segment at its cut points, ordered along the segment.
+func cutSegments(segs [][2]Position, cuts [][]Position) [][2]Position {
+	var out [][2]Position
+	for i, s := range segs {
+		pts := cuts[i]
+		dx := s[1][0] - s[0][0]
+		dy := s[1][1] - s[0][1]
+		sort.Slice(pts, func(x, y int) bool {
+			return (pts[x][0]-s[0][0])*dx+(pts[x][1]-s[0][1])*dy <
+				(pts[y][0]-s[0][0])*dx+(pts[y][1]-s[0][1])*dy
+		})
+		prev := s[0]
+		for _, p := range pts {
+			if samePosition(p, prev) || samePosition(p, s[1]) {
+				continue
+			}
+			out = append(out, [2]Position{prev, p})
+			prev = p
+		}
+		out = append(out, [2]Position{prev, s[1]})
+	}
+	return out
+}
+
+// nodeSnapper maps points to the first point seen within overlaySnap of
+// them. Points are bucketed in a grid of overlaySnap cells, so a lookup only
+// checks the surrounding cells.
+type nodeSnapper map[[2]int64][]Position
+
+func (n nodeSnapper) snap(p Position) Position {
+	cx := int64(math.Floor(p[0] / overlaySnap))
+	cy := int64(math.Floor(p[1] / overlaySnap))
+	best, bestDist := Position(nil), overlaySnap
+	for x := cx - 1; x <= cx+1; x++ {
+		for y := cy - 1; y <= cy+1; y++ {
+			for _, q := range n[[2]int64{x, y}] {
+				if d := math.Hypot(p[0]-q[0], p[1]-q[1]); d <= bestDist {
+					best, bestDist = q, d
+				}
+			}
+		}
+	}
+	if best != nil {
+		return best
+	}
+	key := [2]int64{cx, cy}
+	n[key] = append(n[key], p)
+	return p
+}
+
+// snapSegments snaps the ends of each segment to their nodes, dropping the
+// segments whose ends become the same node.
+func (n nodeSnapper) snapSegments(segs [][2]Position) [][2]Position {
+	out := segs[:0]
+	for _, s := range segs {
+		a, b := n.snap(s[0]), n.snap(s[1])
+		if !samePosition(a, b) {
+			out = append(out, [2]Position{a, b})
+		}
+	}
+	return out
+}
+
+// segmentIntersections returns the points where segments p1-p2 and q1-q2 meet
+// in the lon/lat plane: nothing, a single crossing or touching point, or the
+// two ends of a collinear overlap. Points within clipEpsilon of an endpoint
+// are snapped to that endpoint.
+func segmentIntersections(p1, p2, q1, q2 Position) []Position {
+	if math.Max(p1[0], p2[0]) < math.Min(q1[0], q2[0])-clipEpsilon ||
+		math.Max(q1[0], q2[0]) < math.Min(p1[0], p2[0])-clipEpsilon ||
+		math.Max(p1[1], p2[1]) < math.Min(q1[1], q2[1])-clipEpsilon ||
+		math.Max(q1[1], q2[1]) < math.Min(p1[1], p2[1])-clipEpsilon {
+		return nil
+	}
+
+	// Endpoints lying on the other segment are the touching points or the
+	// ends of an overlap. Checking them first keeps nearly collinear segments
+	// from being treated as crossing at a single point.
+	var pts []Position
+	for _, c := range []Position{p1, p2} {
+		if pointOnSegment(c, q1, q2) {
+			pts = append(pts, c)
+		}
+	}
+	for _, c := range []Position{q1, q2} {
+		if pointOnSegment(c, p1, p2) && !samePosition(c, p1) && !samePosition(c, p2) {
+			pts = append(pts, c)
+		}
+	}
+	if len(pts) > 0 {
+		return pts
+	}
+
+	rx, ry := p2[0]-p1[0], p2[1]-p1[1]
+	sx, sy := q2[0]-q1[0], q2[1]-q1[1]
+	denom := rx*sy - ry*sx
+	qpx, qpy := q1[0]-p1[0], q1[1]-p1[1]
+	if math.Abs(denom) <= clipEpsilon*math.Hypot(rx, ry)*math.Hypot(sx, sy) {
+		return nil
+	}
+
+	t := (qpx*sy - qpy*sx) / denom
+	u := (qpx*ry - qpy*rx) / denom
+	if t < -clipEpsilon || t > 1+clipEpsilon || u < -clipEpsilon || u > 1+clipEpsilon {
+		return nil
+	}
+	p := Position{p1[0] + t*rx, p1[1] + t*ry}
+	for _, c := range []Position{p1, p2, q1, q2} {
+		if math.Abs(p[0]-c[0]) <= clipEpsilon && math.Abs(p[1]-c[1]) <= clipEpsilon {
+			return []Position{c}
+		}
+	}
+	return []Position{p}
+}
+
+func segmentMidpoint(e [2]Position) Position {
+	return Position{(e[0][0] + e[1][0]) / 2, (e[0][1] + e[1][1]) / 2}
+}
+
+// regionIndex answers whether points lie inside an odd number of a set of
+// open rings, counting a point on a ring's boundary as inside that ring, as
+// pointInRing does. The ring edges are bucketed into bands of latitude so that
+// each query only looks at the edges whose latitude range covers the point.
+type regionIndex struct {
+	minLat, bandHeight float64
+	bands              [][]regionEdge
+	parity, boundary   []bool
+	touched            []int
+}
+
+type regionEdge struct {
+	a, b Position
+	ring int
+}
+
+func newRegionIndex(rings [][]Position) *regionIndex {
+	var edges []regionEdge
+	minLat, maxLat := math.Inf(1), math.Inf(-1)
+	for r, ring := range rings {
+		for i := range ring {
+			edges = append(edges, regionEdge{ring[i], ring[(i+1)%len(ring)], r})
+			minLat, maxLat = math.Min(minLat, ring[i][1]), math.Max(maxLat, ring[i][1])
+		}
+	}
+	idx := &regionIndex{
+		minLat:   minLat,
+		parity:   make([]bool, len(rings)),
+		boundary: make([]bool, len(rings)),
+	}
+	if len(edges) == 0 {
+		return idx
+	}
+	n := len(edges)/4 + 1
+	idx.bandHeight = (maxLat - minLat) / float64(n)
+	idx.bands = make([][]regionEdge, n)
+	for _, e := range edges {
+		lo := idx.band(math.Min(e.a[1], e.b[1]) - clipEpsilon)
+		hi := idx.band(math.Max(e.a[1], e.b[1]) + clipEpsilon)
+		for k := lo; k <= hi; k++ {
+			idx.bands[k] = append(idx.bands[k], e)
+		}
+	}
+	return idx
+}
+
+// band returns the index of the band holding latitude lat, clamped to the
+// bands that exist.
+func (idx *regionIndex) band(lat float64) int {
+	if !(idx.bandHeight > 0) {
+		return 0
+	}
+	k := int(math.Floor((lat - idx.minLat) / idx.bandHeight))
+	return max(0, min(k, len(idx.bands)-1))
+}
+
+// contains reports whether pt lies inside an odd number of the rings.
+func (idx *regionIndex) contains(pt Position) bool {
+	if len(idx.bands) == 0 {
+		return false
+	}
+	x, y := pt[0], pt[1]
+	for _, e := range idx.bands[idx.band(y)] {
+		if !idx.parity[e.ring] && !idx.boundary[e.ring] {
+			idx.touched = append(idx.touched, e.ring)
+		}
+		if pointOnSegment(pt, e.a, e.b) {
+			idx.boundary[e.ring] = true
+		}
+		if (e.a[1] > y) != (e.b[1] > y) && x < (e.b[0]-e.a[0])*(y-e.a[1])/(e.b[1]-e.a[1])+e.a[0] {
+			idx.parity[e.ring] = !idx.parity[e.ring]
+		}
+	}
+	inside := false
+	for _, r := range idx.touched {
+		if idx.parity[r] || idx.boundary[r] {
+			inside = !inside
+		}
+		idx.parity[r], idx.boundary[r] = false, false
+	}
+	idx.touched = idx.touched[:0]
+	return inside
+}
+
+// linkOverlayEdges joins directed edges into closed rings. Where several edges
+// leave the same vertex, the one turning furthest left is taken, which keeps
+// rings that only touch at a vertex apart.
+func linkOverlayEdges(edges [][2]Position) [][]Position {
+	outgoing := make(map[[2]float64][]int)
+	for i, e := range edges {
+		outgoing[lonLat(e[0])] = append(outgoing[lonLat(e[0])], i)
+	}
+	used := make([]bool, len(edges))
+
+	var rings [][]Position
+	for i := range edges {
+		if used[i] {
+			continue
+		}
+		used[i] = true
+		start := edges[i][0]
+		ring := []Position{start}
+		current := edges[i]
+		closed := false
+		for {
+			ring = append(ring, current[1])
+			if samePosition(current[1], start) {
+				closed = true
+				break
+			}
+			next := -1
+			bestTurn := math.Inf(-1)
+			inX, inY := current[1][0]-current[0][0], current[1][1]-current[0][1]
+			for _, j := range outgoing[lonLat(current[1])] {
+				if used[j] {
+					continue
+				}
+				outX, outY := edges[j][1][0]-edges[j][0][0], edges[j][1][1]-edges[j][0][1]
+				turn := math.Atan2(inX*outY-inY*outX, inX*outX+inY*outY)
+				if turn > bestTurn {
+					bestTurn = turn
+					next = j
+				}
+			}
+			if next == -1 {
+				break
+			}
+			used[next] = true
+			current = edges[next]
+		}
+		if closed {
+			ring = removeCollinear(ring[:len(ring)-1])
+			if len(ring) >= 3 {
+				rings = append(rings, ring)
+			}
+		}
+	}
+	return rings
+}
+
+// removeCollinear drops vertices of an open ring that lie on the straight line
+// between their neighbors.
+func removeCollinear(ring []Position) []Position {
+	changed := true
+	for changed && len(ring) >= 3 {
+		changed = false
+		for i := 0; i < len(ring); i++ {
+			prev := ring[(i+len(ring)-1)%len(ring)]
+			next := ring[(i+1)%len(ring)]
+			if math.Abs(orient2D(prev, ring[i], next)) <= clipEpsilon {
+				ring = append(ring[:i:i], ring[i+1:]...)
+				changed = true
+				break
+			}
+		}
+	}
+	return ring
+}
+
+// assemblePolygons turns open rings into a Polygon or MultiPolygon. Counter-
+// clockwise rings are outer rings; each clockwise ring becomes a hole of the
+// smallest outer ring containing it. Rings are closed on output. No rings
+// yield an empty MultiPolygon.
+func assemblePolygons(rings [][]Position) interface{} {
+	type shell struct {
+		ring  []Position
+		area  float64
+		holes [][]Position
+	}
+	var shells []*shell
+	var holes [][]Position
+	for _, ring := range rings {
+		area, _, _ := ringAreaCentroid(ring)
+		if area > 0 {
+			shells = append(shells, &shell{ring: ring, area: area})
+		} else if area < 0 {
+			holes = append(holes, ring)
+		}
+	}
+
+	for _, hole := range holes {
+		var owner *shell
+		for _, s := range shells {
+			contains := true
+			for _, p := range hole {
+				if !pointInRing(p, s.ring) {
+					contains = false
+					break
+				}
+			}
+			if contains && (owner == nil || s.area < owner.area) {
+				owner = s
+			}
+		}
+		if owner != nil {
+			owner.holes = append(owner.holes, hole)
+		}
+	}
+
+	polys := make([][][]Position, 0, len(shells))
+	for _, s := range shells {
+		poly := [][]Position{closeRing(s.ring)}
+		for _, h := range s.holes {
+			poly = append(poly, closeRing(h))
+		}
+		polys = append(polys, poly)
+	}
+	if len(polys) == 1 {
+		return NewPolygon(polys[0])
+	}
+	return NewMultiPolygon(polys)
+}
+
+// closeRing returns a copy of an open ring with the first position repeated
+// at the end.
+func closeRing(ring []Position) []Position {
+	out := make([]Position, len(ring)+1)
+	copy(out, ring)
+	out[len(ring)] = ring[0]
+	return out
+}
@@ -0,0 +1,338 @@
+package geo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func squarePolygon(minLon, minLat, maxLon, maxLat float64) Polygon {
+	return NewPolygon([][]Position{{
+		{minLon, minLat},
+		{maxLon, minLat},
+		{maxLon, maxLat},
+		{minLon, maxLat},
+		{minLon, minLat},
+	}})
+}
+
+// polygonPlanarArea returns the shoelace area of a polygon in square degrees,
+// outer ring minus holes.
+func polygonPlanarArea(poly Polygon) float64 {
+	var area float64
+	for i, ring := range poly.Coordinates {
+		a, _, _ := ringAreaCentroid(ring)
+		if i == 0 {
+			area += math.Abs(a)
+		} else {
+			area -= math.Abs(a)
+		}
+	}
+	return area
+}
+
+func sameRingVertices(t *testing.T, ring []Position, want []Position) {
+	t.Helper()
+	if len(ring) != len(want)+1 || !reflect.DeepEqual(ring[0], ring[len(ring)-1]) {
+		t.Fatalf("ring = %v, want closed ring over %v", ring, want)
+	}
+	got := make(map[[2]float64]bool)
+	for _, p := range ring[:len(ring)-1] {
+		got[lonLat(p)] = true
+	}
+	for _, p := range want {
+		if !got[lonLat(p)] {
+			t.Errorf("ring %v is missing vertex %v", ring, p)
+		}
+	}
+}
+
+func TestUnionOverlappingSquares(t *testing.T) {
+	result, err := Union(squarePolygon(0, 0, 2, 2), squarePolygon(1, 1, 3, 3))
+	if err != nil {
+		t.Fatalf("Union() error = %v", err)
+	}
+	poly, ok := result.(Polygon)
+	if !ok {
+		t.Fatalf("Union() = %T, want Polygon", result)
+	}
+	if len(poly.Coordinates) != 1 {
+		t.Fatalf("rings = %d, want 1", len(poly.Coordinates))
+	}
+	sameRingVertices(t, poly.Coordinates[0], []Position{
+		{0, 0}, {2, 0}, {2, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 2}, {0, 2},
+	})
+	if area, _, _ := ringAreaCentroid(poly.Coordinates[0]); math.Abs(area-7) > 1e-12 {
+		t.Errorf("area = %v, want 7 (counterclockwise)", area)
+	}
+}
+
+func TestUnionDisjoint(t *testing.T) {
+	result, err := Union(squarePolygon(0, 0, 1, 1), squarePolygon(5, 5, 6, 6))
+	if err != nil {
+		t.Fatalf("Union() error = %v", err)
+	}
+	mp, ok := result.(MultiPolygon)
+	if !ok {
+		t.Fatalf("Union() = %T, want MultiPolygon", result)
+	}
+	if len(mp.Coordinates) != 2 {
+		t.Errorf("polygons = %d, want 2", len(mp.Coordinates))
+	}
+}
+
+func TestUnionContained(t *testing.T) {
+	result, err := Union(squarePolygon(0, 0, 4, 4), squarePolygon(1, 1, 2, 2))
+	if err != nil {
+		t.Fatalf("Union() error = %v", err)
+	}
+	poly, ok := result.(Polygon)
+	if !ok {
+		t.Fatalf("Union() = %T, want Polygon", result)
+	}
+	sameRingVertices(t, poly.Coordinates[0], []Position{{0, 0}, {4, 0}, {4, 4}, {0, 4}})
+}
+
+func TestUnionSharedEdgeAndClockwiseInput(t *testing.T) {
+	// The second square is given clockwise and shares the edge lon=1.
+	b := NewPolygon([][]Position{{{1, 0}, {1, 1}, {2, 1}, {2, 0}, {1, 0}}})
+	result, err := Union(squarePolygon(0, 0, 1, 1), b)
+	if err != nil {
+		t.Fatalf("Union() error = %v", err)
+	}
+	poly, ok := result.(Polygon)
+	if !ok {
+		t.Fatalf("Union() = %T, want Polygon", result)
+	}
+	sameRingVertices(t, poly.Coordinates[0], []Position{{0, 0}, {2, 0}, {2, 1}, {0, 1}})
+}
+
+func TestUnionSharedEdgeIgnoresAltitude(t *testing.T) {
+	// The shared edge has altitudes in one square only; it must still cancel.
+	a := NewPolygon([][]Position{{{0, 0}, {1, 0, 50}, {1, 1, 80}, {0, 1}, {0, 0}}})
+	b := squarePolygon(1, 0, 2, 1)
+	result, err := Union(a, b)
+	if err != nil {
+		t.Fatalf("Union() error = %v", err)
+	}
+	poly, ok := result.(Polygon)
+	if !ok {
+		t.Fatalf("Union() = %T, want Polygon", result)
+	}
+	sameRingVertices(t, poly.Coordinates[0], []Position{{0, 0}, {2, 0}, {2, 1}, {0, 1}})
+}
+
+func TestUnionKeepsHoles(t *testing.T) {
+	withHole := NewPolygon([][]Position{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {1, 2}, {2, 2}, {2, 1}, {1, 1}},
+	})
+	result, err := Union(withHole, squarePolygon(3, 3, 5, 5))
+	if err != nil {
+		t.Fatalf("Union() error = %v", err)
+	}
+	poly, ok := result.(Polygon)
+	if !ok {
+		t.Fatalf("Union() = %T, want Polygon", result)
+	}
+	if len(poly.Coordinates) != 2 {
+		t.Fatalf("rings = %d, want 2", len(poly.Coordinates))
+	}
+	if area := polygonPlanarArea(poly); math.Abs(area-(16-1+3)) > 1e-12 {
+		t.Errorf("area = %v, want 18", area)
+	}
+}
+
+func TestSegmentIntersections(t *testing.T) {
+	cross := segmentIntersections(Position{0, 0}, Position{2, 2}, Position{0, 2}, Position{2, 0})
+	if len(cross) != 1 || !reflect.DeepEqual(cross[0], Position{1, 1}) {
+		t.Errorf("crossing = %v, want [(1, 1)]", cross)
+	}
+	overlap := segmentIntersections(Position{0, 0}, Position{2, 0}, Position{1, 0}, Position{3, 0})
+	if len(overlap) != 2 {
+		t.Errorf("overlap = %v, want 2 points", overlap)
+	}
+	if got := segmentIntersections(Position{0, 0}, Position{1, 0}, Position{0, 1}, Position{1, 1}); len(got) != 0 {
+		t.Errorf("parallel = %v, want none", got)
+	}
+}
+
+func TestIntersectionOverlappingSquares(t *testing.T) {
+	result, err := Intersection(squarePolygon(0, 0, 2, 2), squarePolygon(1, 1, 3, 3))
+	if err != nil {
+		t.Fatalf("Intersection() error = %v", err)
+	}
+	poly, ok := result.(Polygon)
+	if !ok {
+		t.Fatalf("Intersection() = %T, want Polygon", result)
+	}
+	if len(poly.Coordinates) != 1 {
+		t.Fatalf("rings = %d, want 1", len(poly.Coordinates))
+	}
+	sameRingVertices(t, poly.Coordinates[0], []Position{{1, 1}, {2, 1}, {2, 2}, {1, 2}})
+	if area := polygonPlanarArea(poly); math.Abs(area-1) > 1e-12 {
+		t.Errorf("area = %v, want 1", area)
+	}
+}
+
+func TestIntersectionDisjoint(t *testing.T) {
+	result, err := Intersection(squarePolygon(0, 0, 1, 1), squarePolygon(5, 5, 6, 6))
+	if err != nil {
+		t.Fatalf("Intersection() error = %v", err)
+	}
+	mp, ok := result.(MultiPolygon)
+	if !ok || len(mp.Coordinates) != 0 {
+		t.Errorf("Intersection() = %#v, want empty MultiPolygon", result)
+	}
+}
+
+func TestDifferenceOverlappingSquares(t *testing.T) {
+	result, err := Difference(squarePolygon(0, 0, 2, 2), squarePolygon(1, 1, 3, 3))
+	if err != nil {
+		t.Fatalf("Difference() error = %v", err)
+	}
+	poly, ok := result.(Polygon)
+	if !ok {
+		t.Fatalf("Difference() = %T, want Polygon", result)
+	}
+	if len(poly.Coordinates) != 1 {
+		t.Fatalf("rings = %d, want 1", len(poly.Coordinates))
+	}
+	sameRingVertices(t, poly.Coordinates[0], []Position{
+		{0, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 2}, {0, 2},
+	})
+	if area, _, _ := ringAreaCentroid(poly.Coordinates[0]); math.Abs(area-3) > 1e-12 {
+		t.Errorf("area = %v, want 3 (counterclockwise)", area)
+	}
+}
+
+func TestDifferenceCutsHole(t *testing.T) {
+	result, err := Difference(squarePolygon(0, 0, 4, 4), squarePolygon(1, 1, 2, 2))
+	if err != nil {
+		t.Fatalf("Difference() error = %v", err)
+	}
+	poly, ok := result.(Polygon)
+	if !ok {
+		t.Fatalf("Difference() = %T, want Polygon", result)
+	}
+	if len(poly.Coordinates) != 2 {
+		t.Fatalf("rings = %d, want 2", len(poly.Coordinates))
+	}
+	if area := polygonPlanarArea(poly); math.Abs(area-15) > 1e-12 {
+		t.Errorf("area = %v, want 15", area)
+	}
+}
+
+func TestDifferenceSplits(t *testing.T) {
+	result, err := Difference(squarePolygon(0, 0, 3, 1), squarePolygon(1, -1, 2, 2))
+	if err != nil {
+		t.Fatalf("Difference() error = %v", err)
+	}
+	mp, ok := result.(MultiPolygon)
+	if !ok {
+		t.Fatalf("Difference() = %T, want MultiPolygon", result)
+	}
+	if len(mp.Coordinates) != 2 {
+		t.Errorf("polygons = %d, want 2", len(mp.Coordinates))
+	}
+}
+
+func TestDifferenceCovered(t *testing.T) {
+	result, err := Difference(squarePolygon(1, 1, 2, 2), squarePolygon(0, 0, 4, 4))
+	if err != nil {
+		t.Fatalf("Difference() error = %v", err)
+	}
+	mp, ok := result.(MultiPolygon)
+	if !ok || len(mp.Coordinates) != 0 {
+		t.Errorf("Difference() = %#v, want empty MultiPolygon", result)
+	}
+}
+
+func TestPolygonInPolygon(t *testing.T) {
+	big := squarePolygon(0, 0, 10, 10)
+	withHole := NewPolygon(append(big.Coordinates, []Position{{4, 4}, {4, 6}, {6, 6}, {6, 4}, {4, 4}}))
+	// A C shape open to the east; its notch spans lat 4..6 from lon 3.
+	cShape := NewPolygon([][]Position{{{0, 0}, {10, 0}, {10, 4}, {3, 4}, {3, 6}, {10, 6}, {10, 10}, {0, 10}, {0, 0}}})
+
+	tests := []struct {
+		name         string
+		inner, outer Polygon
+		want         bool
+	}{
+		{"small inside big", squarePolygon(2, 2, 4, 4), big, true},
+		{"straddling border", squarePolygon(8, 8, 12, 12), big, false},
+		{"outside", squarePolygon(20, 20, 21, 21), big, false},
+		{"big inside small", big, squarePolygon(2, 2, 4, 4), false},
+		{"identical", big, big, true},
+		{"sharing an edge", squarePolygon(0, 0, 5, 5), big, true},
+		{"covering the hole", squarePolygon(3, 3, 7, 7), withHole, false},
+		{"beside the hole", squarePolygon(1, 1, 3, 3), withHole, true},
+		{"inside the hole", squarePolygon(4.5, 4.5, 5.5, 5.5), withHole, false},
+		// Every vertex is inside the C, but the square spans the notch.
+		{"spanning a notch", squarePolygon(5, 3, 7, 7), cShape, false},
+		{"empty", Polygon{}, big, false},
+	}
+	for _, tt := range tests {
+		if got := PolygonInPolygon(tt.inner, tt.outer); got != tt.want {
+			t.Errorf("%s: PolygonInPolygon() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCutAntimeridian(t *testing.T) {
+	poly := NewPolygon([][]Position{{{170, -10}, {-170, -10}, {-170, 10}, {170, 10}, {170, -10}}})
+	got, err := CutAntimeridian(poly)
+	if err != nil {
+		t.Fatalf("CutAntimeridian: %v", err)
+	}
+	mp, ok := got.(MultiPolygon)
+	if !ok || len(mp.Coordinates) != 2 {
+		t.Fatalf("CutAntimeridian = %v, want MultiPolygon with 2 parts", got)
+	}
+	var sawEast, sawWest bool
+	for _, part := range mp.Coordinates {
+		box := NewPolygon(part).Bounds()
+		switch {
+		case box == BBox{MinLon: 170, MinLat: -10, MaxLon: 180, MaxLat: 10}:
+			sawEast = true
+		case box == BBox{MinLon: -180, MinLat: -10, MaxLon: -170, MaxLat: 10}:
+			sawWest = true
+		default:
+			t.Errorf("unexpected part with bounds %v", box)
+		}
+		if len(part) != 1 {
+			t.Errorf("part has %d rings, want 1", len(part))
+		}
+	}
+	if !sawEast || !sawWest {
+		t.Errorf("parts = %v, want one on each side of the antimeridian", mp.Coordinates)
+	}
+
+	// A hole straddling the meridian is cut along with the outer ring.
+	holed := NewPolygon([][]Position{
+		{{170, -10}, {-170, -10}, {-170, 10}, {170, 10}, {170, -10}},
+		{{175, -5}, {175, 5}, {-175, 5}, {-175, -5}, {175, -5}},
+	})
+	got, err = CutAntimeridian(holed)
+	if err != nil {
+		t.Fatalf("CutAntimeridian: %v", err)
+	}
+	mp = got.(MultiPolygon)
+	total := 0.0
+	for _, part := range mp.Coordinates {
+		total += polygonPlanarArea(NewPolygon(part))
+	}
+	if math.Abs(total-300) > 1e-9 {
+		t.Errorf("parts cover %v square degrees, want 300", total)
+	}
+
+	plain := NewPolygon([][]Position{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}})
+	if got, err := CutAntimeridian(plain); err != nil || !reflect.DeepEqual(got, plain) {
+		t.Errorf("CutAntimeridian(plain) = %v, %v; want the polygon unchanged", got, err)
+	}
+
+	polar := NewPolygon([][]Position{{{0, 80}, {120, 80}, {-120, 80}, {0, 80}}})
+	if _, err := CutAntimeridian(polar); err == nil {
+		t.Error("expected error for a ring around the pole")
+	}
+}
@@ -0,0 +1,301 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// ClusterKMeans partitions points into k clusters with Lloyd's k-means
+// algorithm on the sphere. Points are assigned to the centroid with the
+// smallest great-circle distance, and each centroid is the SphericalMean of its
+// points, so a cluster straddling the antimeridian keeps its centroid there.
+//
+// Initial centroids are picked with k-means++ from a random source seeded with
+// seed, so the same input and seed always give the same clusters. A cluster
+// that loses all its points is re-seeded at the point farthest from its
+// centroid. Iteration stops when no assignment changes or after maxIterations
+// rounds. assignments[i] is the cluster of points[i], in [0, k).
+func ClusterKMeans(points []Position, k int, maxIterations int, seed int64) (assignments []int, centroids []Position, err error) {
+	if k <= 0 || k > len(points) {
+		return nil, nil, fmt.Errorf("k must be in [1, %d], got %d", len(points), k)
+	}
+	if maxIterations <= 0 {
+		return nil, nil, errors.New("max iterations must be greater than 0")
+	}
+
+	dist := func(p, q Position) float64 {
+		lat1, lon1 := positionLatLon(p)
+		lat2, lon2 := positionLatLon(q)
+		return GreatCircleDistance(lat1, lon1, lat2, lon2)
+	}
+
+	// k-means++: each further centroid is a point drawn with probability
+	// proportional to its squared distance from the nearest centroid so far.
+	rng := rand.New(rand.NewSource(seed))
+	centroids = make([]Position, 0, k)
+	centroids = append(centroids, points[rng.Intn(len(points))])
+	nearest := make([]float64, len(points))
+	for i, p := range points {
+		nearest[i] = dist(p, centroids[0])
+	}
+	for len(centroids) < k {
+		var total float64
+		for _, d := range nearest {
+			total += d * d
+		}
+		pick := len(points) - 1
+		if total > 0 {
+			r := rng.Float64() * total
+			for i, d := range nearest {
+				if r -= d * d; r < 0 {
+					pick = i
+					break
+				}
+			}
+		} else {
+			// Every point sits on a centroid already.
+			pick = rng.Intn(len(points))
+		}
+		centroids = append(centroids, points[pick])
+		for i, p := range points {
+			nearest[i] = math.Min(nearest[i], dist(p, points[pick]))
+		}
+	}
+
+	assignments = make([]int, len(points))
+	for i := range assignments {
+		assignments[i] = -1
+	}
+	members := make([][]Position, k)
+	for iter := 0; iter < maxIterations; iter++ {
+		changed := false
+		for i, p := range points {
+			best, bestDist := 0, math.Inf(1)
+			for c, centroid := range centroids {
+				if d := dist(p, centroid); d < bestDist {
+					best, bestDist = c, d
+				}
+			}
+			nearest[i] = bestDist
+			if assignments[i] != best {
+				assignments[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+
+		for c := range members {
+			members[c] = members[c][:0]
+		}
+		for i, p := range points {
+			members[assignments[i]] = append(members[assignments[i]], p)
+		}
+		for c := range centroids {
+			if len(members[c]) > 0 {
+				centroids[c] = SphericalMean(members[c])
+				continue
+			}
+			far := 0
+			for i := range points {
+				if nearest[i] > nearest[far] {
+					far = i
+				}
+			}
+			centroids[c] = points[far]
+			nearest[far] = 0
+		}
+	}
+	return assignments, centroids, nil
+}
+
+// ClusterDBSCAN groups points with the DBSCAN algorithm. A point with at
+// least minPoints points, itself included, within epsilonKm great-circle
+// distance is a core point; core points within epsilonKm of each other share
+// a cluster, and every point within epsilonKm of a core point joins that
+// core's cluster as a border point. All other points are noise.
+//
+// assignments[i] is the cluster of points[i], numbered from 0 in the order
+// clusters are found, or -1 for noise. A border point in reach of several
+// clusters joins the first one found. Neighbors are looked up through a
+// geohash bucket index, so points are only compared with those in nearby
+// cells.
+func ClusterDBSCAN(points []Position, epsilonKm float64, minPoints int) (assignments []int, err error) {
+	if !(epsilonKm > 0) {
+		return nil, errors.New("epsilon must be greater than 0")
+	}
+	if minPoints < 1 {
+		return nil, errors.New("min points must be at least 1")
+	}
+
+	index := newGeohashIndex(points, epsilonKm)
+	region := func(i int) []int {
+		lat1, lon1 := positionLatLon(points[i])
+		var near []int
+		for _, j := range index.candidates(points[i]) {
+			lat2, lon2 := positionLatLon(points[j])
+			if GreatCircleDistance(lat1, lon1, lat2, lon2) <= epsilonKm {
+				near = append(near, j)
+			}
+		}
+		return near
+	}
+
+	assignments = make([]int, len(points))
+	for i := range assignments {
+		assignments[i] = -1
+	}
+	visited := make([]bool, len(points))
+	cluster := 0
+	for i := range points {
+		if visited[i] {
+			continue
+		}
+		visited[i] = true
+		queue := region(i)
+		if len(queue) < minPoints {
+			continue
+		}
+		assignments[i] = cluster
+		for k := 0; k < len(queue); k++ {
+			q := queue[k]
+			if assignments[q] == -1 {
+				assignments[q] = cluster
+			}
+			if visited[q] {
+				continue
+			}
+			visited[q] = true
+			if near := region(q); len(near) >= minPoints {
+				queue = append(queue, near...)
+			}
+		}
+		cluster++
+	}
+	return assignments, nil
+}
+
+// geohashIndex buckets points by geohash cell. Cells are at least radiusKm
+// across, so every point within radiusKm of a query lies in the query's cell
+// or one of its 8 neighbors.
+type geohashIndex struct {
+	precision int
+	buckets   map[string][]int
+	all       []int // every point, when no cell size fits
+}
+
+func newGeohashIndex(points []Position, radiusKm float64) *geohashIndex {
+	maxAbsLat := 0.0
+	for _, p := range points {
+		maxAbsLat = math.Max(maxAbsLat, math.Abs(p[1]))
+	}
+	kmPerDegree := EarthRadiusKm * math.Pi / 180
+
+	// Pick the finest precision whose cells are at least radiusKm tall, and
+	// wide enough that two points a cell width apart in longitude are more
+	// than radiusKm apart even at the most poleward latitude present.
+	index := &geohashIndex{}
+	for precision := 12; precision >= 1; precision-- {
+		lonBits := (5*precision + 1) / 2
+		latBits := 5 * precision / 2
+		lonDeg := 360 / math.Exp2(float64(lonBits))
+		latDeg := 180 / math.Exp2(float64(latBits))
+		width := 2 * EarthRadiusKm * math.Asin(math.Cos(toRadians(maxAbsLat))*math.Sin(toRadians(lonDeg)/2))
+		if latDeg*kmPerDegree >= radiusKm && width >= radiusKm {
+			index.precision = precision
+			break
+		}
+	}
+	if index.precision == 0 {
+		// Cells that wide would wrap around a pole: compare everything.
+		index.all = make([]int, len(points))
+		for i := range points {
+			index.all[i] = i
+		}
+		return index
+	}
+
+	index.buckets = make(map[string][]int)
+	for i, p := range points {
+		key := Geohash(p[1], normalizeLongitude(p[0]), index.precision)
+		index.buckets[key] = append(index.buckets[key], i)
+	}
+	return index
+}
+
+// candidates returns the points in the cell of p and the cells around it.
+func (ix *geohashIndex) candidates(p Position) []int {
+	if ix.buckets == nil {
+		return ix.all
+	}
+	lat, lon, latErr, lonErr := GeohashDecode(Geohash(p[1], normalizeLongitude(p[0]), ix.precision))
+	var out []int
+	seen := make(map[string]bool, 9)
+	for _, dLat := range []float64{-2 * latErr, 0, 2 * latErr} {
+		for _, dLon := range []float64{-2 * lonErr, 0, 2 * lonErr} {
+			// Wrap across the antimeridian; beyond a pole the cell is
+			// clamped to the polar row, which is already included.
+			key := Geohash(lat+dLat, normalizeLongitude(lon+dLon), ix.precision)
+			if !seen[key] {
+				seen[key] = true
+				out = append(out, ix.buckets[key]...)
+			}
+		}
+	}
+	return out
+}
+
+// ClusterKMeansFeatures runs ClusterKMeans over the Point features of fc and
+// returns a copy of the collection in which every feature has a "cluster"
+// property holding its cluster index. The input features' properties are not
+// modified. Every feature must have a Point geometry.
+func ClusterKMeansFeatures(fc FeatureCollection, k int, maxIterations int, seed int64) (FeatureCollection, error) {
+	points, err := featurePoints(fc)
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+	assignments, _, err := ClusterKMeans(points, k, maxIterations, seed)
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+	return withClusterProperty(fc, assignments), nil
+}
+
+// featurePoints returns the positions of the Point features of fc, erroring
+// on any other geometry.
+func featurePoints(fc FeatureCollection) ([]Position, error) {
+	points := make([]Position, len(fc.Features))
+	for i, f := range fc.Features {
+		geom, err := geometryOf(f.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		pt, ok := geom.(Point)
+		if !ok {
+			return nil, fmt.Errorf("feature %d: expected Point geometry, got %T", i, geom)
+		}
+		points[i] = pt.Coordinates
+	}
+	return points, nil
+}
+
+// withClusterProperty copies fc, setting the "cluster" property of feature i
+// to clusters[i].
+func withClusterProperty(fc FeatureCollection, clusters []int) FeatureCollection {
+	features := make([]Feature, len(fc.Features))
+	for i, f := range fc.Features {
+		props := make(map[string]interface{}, len(f.Properties)+1)
+		for key, value := range f.Properties {
+			props[key] = value
+		}
+		props["cluster"] = clusters[i]
+		f.Properties = props
+		features[i] = f
+	}
+	out := fc
+	out.Features = features
+	return out
+}
@@ -0,0 +1,235 @@
+package geo
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// blob returns n points scattered within about spread degrees of center.
+func blob(rng *rand.Rand, center Position, spread float64, n int) []Position {
+	points := make([]Position, n)
+	for i := range points {
+		lon := center[0] + (rng.Float64()*2-1)*spread
+		if lon > 180 {
+			lon -= 360
+		} else if lon < -180 {
+			lon += 360
+		}
+		points[i] = Position{lon, center[1] + (rng.Float64()*2-1)*spread}
+	}
+	return points
+}
+
+func TestClusterKMeansSeparatesBlobs(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	a := blob(rng, Position{10, 50}, 0.5, 30)
+	b := blob(rng, Position{-70, -20}, 0.5, 30)
+	points := append(append([]Position{}, a...), b...)
+
+	assignments, centroids, err := ClusterKMeans(points, 2, 50, 7)
+	if err != nil {
+		t.Fatalf("ClusterKMeans() error = %v", err)
+	}
+	if len(centroids) != 2 {
+		t.Fatalf("got %d centroids, want 2", len(centroids))
+	}
+	for i := range points {
+		wantSame := i < len(a)
+		if (assignments[i] == assignments[0]) != wantSame {
+			t.Fatalf("point %d in cluster %d, blobs are mixed: %v", i, assignments[i], assignments)
+		}
+	}
+	centroid := centroids[assignments[0]]
+	if GreatCircleDistance(centroid[1], centroid[0], 50, 10) > 50 {
+		t.Errorf("centroid of first blob = %v, want near (10, 50)", centroid)
+	}
+
+	again, _, err := ClusterKMeans(points, 2, 50, 7)
+	if err != nil || !reflect.DeepEqual(assignments, again) {
+		t.Errorf("same seed gave %v, then %v", assignments, again)
+	}
+}
+
+func TestClusterKMeansAntimeridian(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	points := append(blob(rng, Position{180, 0}, 1, 40), blob(rng, Position{0, 0}, 1, 40)...)
+	assignments, centroids, err := ClusterKMeans(points, 2, 50, 1)
+	if err != nil {
+		t.Fatalf("ClusterKMeans() error = %v", err)
+	}
+	c := centroids[assignments[0]]
+	if math.Abs(math.Abs(c[0])-180) > 1 || math.Abs(c[1]) > 1 {
+		t.Errorf("antimeridian blob centroid = %v, want near lon 180", c)
+	}
+}
+
+func TestClusterKMeansErrors(t *testing.T) {
+	points := []Position{{0, 0}, {1, 1}}
+	for _, k := range []int{0, -1, 3} {
+		if _, _, err := ClusterKMeans(points, k, 10, 1); err == nil {
+			t.Errorf("k = %d: expected error", k)
+		}
+	}
+	if _, _, err := ClusterKMeans(points, 1, 0, 1); err == nil {
+		t.Error("expected error for zero iterations")
+	}
+}
+
+func TestClusterKMeansFeatures(t *testing.T) {
+	fc := NewFeatureCollection([]Feature{
+		{Type: "Feature", Geometry: NewPoint(0, 0), Properties: map[string]interface{}{"name": "a"}},
+		NewFeature(NewPoint(0.1, 0)),
+		NewFeature(NewPoint(90, 0)),
+		NewFeature(NewPoint(90.1, 0)),
+	})
+	out, err := ClusterKMeansFeatures(fc, 2, 20, 3)
+	if err != nil {
+		t.Fatalf("ClusterKMeansFeatures() error = %v", err)
+	}
+	cluster := func(i int) interface{} { return out.Features[i].Properties["cluster"] }
+	if cluster(0) != cluster(1) || cluster(2) != cluster(3) || cluster(0) == cluster(2) {
+		t.Errorf("clusters = %v %v %v %v", cluster(0), cluster(1), cluster(2), cluster(3))
+	}
+	if out.Features[0].Properties["name"] != "a" {
+		t.Errorf("existing property lost: %v", out.Features[0].Properties)
+	}
+	if _, ok := fc.Features[0].Properties["cluster"]; ok {
+		t.Error("input feature properties were modified")
+	}
+
+	bad := NewFeatureCollection([]Feature{NewFeature(NewLineString([]Position{{0, 0}, {1, 1}}))})
+	if _, err := ClusterKMeansFeatures(bad, 1, 10, 1); err == nil {
+		t.Error("expected error for non-point feature")
+	}
+}
+
+func TestClusterDBSCAN(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	a := blob(rng, Position{2.35, 48.85}, 0.02, 25)
+	b := blob(rng, Position{179.98, -16.5}, 0.02, 25)
+	noise := []Position{{-40, 10}, {60, -30}, {100, 70}, {-120, -60}, {2.35, 49.5}}
+	points := append(append(append([]Position{}, a...), b...), noise...)
+
+	assignments, err := ClusterDBSCAN(points, 2, 4)
+	if err != nil {
+		t.Fatalf("ClusterDBSCAN() error = %v", err)
+	}
+	for i := range a {
+		if assignments[i] != assignments[0] || assignments[i] == -1 {
+			t.Fatalf("first blob split: %v", assignments)
+		}
+	}
+	for i := range b {
+		if j := len(a) + i; assignments[j] != assignments[len(a)] || assignments[j] == -1 {
+			t.Fatalf("second blob split: %v", assignments)
+		}
+	}
+	if assignments[0] == assignments[len(a)] {
+		t.Errorf("both blobs in cluster %d", assignments[0])
+	}
+	for i := range noise {
+		if c := assignments[len(a)+len(b)+i]; c != -1 {
+			t.Errorf("noise point %v in cluster %d", noise[i], c)
+		}
+	}
+}
+
+func TestClusterDBSCANOrderIndependent(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+	var points []Position
+	for _, c := range []Position{{10, 10}, {10.3, 10}, {-75, 40}, {150, -35}} {
+		points = append(points, blob(rng, c, 0.05, 15)...)
+	}
+	for i := 0; i < 10; i++ {
+		points = append(points, Position{rng.Float64()*360 - 180, rng.Float64()*160 - 80})
+	}
+	const eps, minPoints = 5.0, 5
+
+	base, err := ClusterDBSCAN(points, eps, minPoints)
+	if err != nil {
+		t.Fatalf("ClusterDBSCAN() error = %v", err)
+	}
+	perm := rng.Perm(len(points))
+	shuffled := make([]Position, len(points))
+	for i, j := range perm {
+		shuffled[i] = points[j]
+	}
+	other, err := ClusterDBSCAN(shuffled, eps, minPoints)
+	if err != nil {
+		t.Fatalf("ClusterDBSCAN() error = %v", err)
+	}
+	again := make([]int, len(points))
+	for i, j := range perm {
+		again[j] = other[i]
+	}
+
+	// Core points must be grouped the same way, whatever the cluster numbers.
+	isCore := func(i int) bool {
+		n := 0
+		for _, q := range points {
+			if GreatCircleDistance(points[i][1], points[i][0], q[1], q[0]) <= eps {
+				n++
+			}
+		}
+		return n >= minPoints
+	}
+	var core []int
+	for i := range points {
+		if isCore(i) {
+			core = append(core, i)
+		}
+	}
+	if len(core) == 0 {
+		t.Fatal("no core points")
+	}
+	for _, i := range core {
+		for _, j := range core {
+			if (base[i] == base[j]) != (again[i] == again[j]) {
+				t.Fatalf("core points %d and %d grouped differently", i, j)
+			}
+		}
+	}
+}
+
+func TestGeohashIndexMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(9))
+	var points []Position
+	for i := 0; i < 300; i++ {
+		points = append(points, Position{rng.Float64()*4 - 2 + 180*float64(i%2), rng.Float64()*2 + 60})
+	}
+	for i := range points {
+		points[i][0] = normalizeLongitude(points[i][0])
+	}
+	index := newGeohashIndex(points, 15)
+	if index.buckets == nil {
+		t.Fatal("expected a bucketed index")
+	}
+	for i, p := range points {
+		want := 0
+		for _, q := range points {
+			if GreatCircleDistance(p[1], p[0], q[1], q[0]) <= 15 {
+				want++
+			}
+		}
+		found := 0
+		for _, j := range index.candidates(p) {
+			if GreatCircleDistance(p[1], p[0], points[j][1], points[j][0]) <= 15 {
+				found++
+			}
+		}
+		if found != want {
+			t.Fatalf("point %d: index finds %d neighbors, brute force %d", i, found, want)
+		}
+	}
+}
+
+func TestClusterDBSCANErrors(t *testing.T) {
+	if _, err := ClusterDBSCAN([]Position{{0, 0}}, 0, 2); err == nil {
+		t.Error("expected error for zero epsilon")
+	}
+	if _, err := ClusterDBSCAN([]Position{{0, 0}}, 1, 0); err == nil {
+		t.Error("expected error for zero min points")
+	}
+}
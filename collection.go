@@ -0,0 +1,194 @@
+package geo
+
+import "fmt"
+
+// Explode returns every position of a GeoJSON object as a Point feature, in
+// order. Polygon rings keep their closing position, so a ring of n positions
+// gives n points. Each point gets its own copy of the properties of the
+// Feature it came from; bare geometries give points without properties.
+func Explode(obj interface{}) (FeatureCollection, error) {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+	if err := checkMembers(geom); err != nil {
+		return FeatureCollection{}, err
+	}
+	var features []Feature
+	explode := func(g Geometry, props map[string]interface{}) {
+		g.ForEachPosition(func(p Position) {
+			f := NewFeature(Point{Type: "Point", Coordinates: p})
+			f.Properties = copyProperties(props)
+			features = append(features, f)
+		})
+	}
+	switch g := geom.(type) {
+	case Feature:
+		if g.Geometry != nil {
+			child, _ := geometryOf(g.Geometry)
+			explode(child, g.Properties)
+		}
+	case FeatureCollection:
+		for _, f := range g.Features {
+			if f.Geometry != nil {
+				child, _ := geometryOf(f.Geometry)
+				explode(child, f.Properties)
+			}
+		}
+	default:
+		explode(g, nil)
+	}
+	return NewFeatureCollection(features), nil
+}
+
+// Flatten splits multi-part geometries into one Feature per part: a
+// MultiPoint, MultiLineString or MultiPolygon gives a Feature for each of its
+// points, lines or polygons, and a GeometryCollection one for each of its
+// members, flattened in turn. Each part gets its own copy of its Feature's
+// properties but not its id, which would no longer be unique. Features that
+// already hold a single geometry are kept as they are.
+func Flatten(obj interface{}) (FeatureCollection, error) {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+	switch g := geom.(type) {
+	case FeatureCollection:
+		var features []Feature
+		for _, f := range g.Features {
+			flat, err := Flatten(f)
+			if err != nil {
+				return FeatureCollection{}, err
+			}
+			features = append(features, flat.Features...)
+		}
+		return NewFeatureCollection(features), nil
+	case Feature:
+		child, err := geometryOf(g.Geometry)
+		if err != nil {
+			return FeatureCollection{}, err
+		}
+		switch child.(type) {
+		case MultiPoint, MultiLineString, MultiPolygon, GeometryCollection:
+		default:
+			return NewFeatureCollection([]Feature{g}), nil
+		}
+		parts, err := singleGeometries(child)
+		if err != nil {
+			return FeatureCollection{}, err
+		}
+		features := make([]Feature, len(parts))
+		for i, part := range parts {
+			features[i] = NewFeature(part)
+			features[i].Properties = copyProperties(g.Properties)
+		}
+		return NewFeatureCollection(features), nil
+	default:
+		return Flatten(NewFeature(g))
+	}
+}
+
+// singleGeometries returns the single geometries making up g.
+func singleGeometries(g Geometry) ([]Geometry, error) {
+	var parts []Geometry
+	switch g := g.(type) {
+	case MultiPoint:
+		for _, p := range g.Coordinates {
+			parts = append(parts, Point{Type: "Point", Coordinates: p})
+		}
+	case MultiLineString:
+		for _, line := range g.Coordinates {
+			parts = append(parts, NewLineString(line))
+		}
+	case MultiPolygon:
+		for _, rings := range g.Coordinates {
+			parts = append(parts, NewPolygon(rings))
+		}
+	case GeometryCollection:
+		for _, child := range g.Geometries {
+			member, err := geometryOf(child)
+			if err != nil {
+				return nil, err
+			}
+			sub, err := singleGeometries(member)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, sub...)
+		}
+	default:
+		parts = []Geometry{g}
+	}
+	return parts, nil
+}
+
+// Combine merges the features of fc by kind of geometry: points and
+// multipoints into one MultiPoint Feature, lines into one MultiLineString and
+// polygons into one MultiPolygon, in that order and only for kinds present.
+// Properties are not merged: each combined Feature has a "collectedProperties"
+// property listing the properties of the features it was built from, in
+// order. Features holding a GeometryCollection, or no geometry, give an error.
+func Combine(fc FeatureCollection) (FeatureCollection, error) {
+	var points []Position
+	var lines [][]Position
+	var polys [][][]Position
+	var pointProps, lineProps, polyProps []interface{}
+	for i, f := range fc.Features {
+		geom, err := geometryOf(f.Geometry)
+		if err != nil {
+			return FeatureCollection{}, fmt.Errorf("feature %d: %w", i, err)
+		}
+		props := copyProperties(f.Properties)
+		switch g := geom.(type) {
+		case Point:
+			points = append(points, g.Coordinates)
+			pointProps = append(pointProps, props)
+		case MultiPoint:
+			points = append(points, g.Coordinates...)
+			pointProps = append(pointProps, props)
+		case LineString:
+			lines = append(lines, g.Coordinates)
+			lineProps = append(lineProps, props)
+		case MultiLineString:
+			lines = append(lines, g.Coordinates...)
+			lineProps = append(lineProps, props)
+		case Polygon:
+			polys = append(polys, g.Coordinates)
+			polyProps = append(polyProps, props)
+		case MultiPolygon:
+			polys = append(polys, g.Coordinates...)
+			polyProps = append(polyProps, props)
+		default:
+			return FeatureCollection{}, fmt.Errorf("feature %d: cannot combine %T", i, geom)
+		}
+	}
+
+	var features []Feature
+	add := func(geom interface{}, props []interface{}) {
+		f := NewFeature(geom)
+		f.Properties = map[string]interface{}{"collectedProperties": props}
+		features = append(features, f)
+	}
+	if pointProps != nil {
+		add(NewMultiPoint(points), pointProps)
+	}
+	if lineProps != nil {
+		add(NewMultiLineString(lines), lineProps)
+	}
+	if polyProps != nil {
+		add(NewMultiPolygon(polys), polyProps)
+	}
+	return NewFeatureCollection(features), nil
+}
+
+// copyProperties returns a shallow copy of props, or nil for nil.
+func copyProperties(props map[string]interface{}) map[string]interface{} {
+	if props == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(props))
+	for k, v := range props {
+		out[k] = v
+	}
+	return out
+}
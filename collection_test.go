@@ -0,0 +1,113 @@
+package geo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExplode(t *testing.T) {
+	poly := NewPolygon([][]Position{
+		squarePolygon(0, 0, 10, 10).Coordinates[0],
+		{{2, 2}, {2, 4}, {4, 4}, {2, 2}},
+	})
+	f := NewFeature(poly)
+	f.Properties = map[string]interface{}{"name": "donut"}
+	fc, err := Explode(NewFeatureCollection([]Feature{f, NewFeature(NewPoint(20, 20))}))
+	if err != nil {
+		t.Fatalf("Explode() error = %v", err)
+	}
+	if want := 5 + 4 + 1; len(fc.Features) != want {
+		t.Fatalf("Explode() gave %d points, want %d", len(fc.Features), want)
+	}
+	for i, pt := range fc.Features[:9] {
+		if pt.Properties["name"] != "donut" {
+			t.Errorf("point %d properties = %v, want the polygon's", i, pt.Properties)
+		}
+	}
+	fc.Features[0].Properties["name"] = "changed"
+	if f.Properties["name"] != "donut" || fc.Features[1].Properties["name"] != "donut" {
+		t.Error("exploded points share a properties map")
+	}
+	if got := fc.Features[6].Geometry.(Point).Coordinates; !reflect.DeepEqual(got, Position{2, 4}) {
+		t.Errorf("point 6 = %v, want (2, 4)", got)
+	}
+	if fc.Features[9].Properties != nil {
+		t.Errorf("point of a feature without properties has %v", fc.Features[9].Properties)
+	}
+}
+
+func TestFlattenCombine(t *testing.T) {
+	mp := NewMultiPolygon([][][]Position{
+		squarePolygon(0, 0, 1, 1).Coordinates,
+		squarePolygon(2, 2, 3, 3).Coordinates,
+	})
+	f := NewFeature(mp)
+	f.ID = "a"
+	f.Properties = map[string]interface{}{"kind": "park"}
+
+	flat, err := Flatten(f)
+	if err != nil {
+		t.Fatalf("Flatten() error = %v", err)
+	}
+	if len(flat.Features) != 2 {
+		t.Fatalf("Flatten() gave %d features, want 2", len(flat.Features))
+	}
+	for i, part := range flat.Features {
+		if _, ok := part.Geometry.(Polygon); !ok || part.Properties["kind"] != "park" || part.ID != nil {
+			t.Errorf("part %d = %+v, want a Polygon with the properties and no id", i, part)
+		}
+	}
+
+	combined, err := Combine(flat)
+	if err != nil {
+		t.Fatalf("Combine() error = %v", err)
+	}
+	if len(combined.Features) != 1 {
+		t.Fatalf("Combine() gave %d features, want 1", len(combined.Features))
+	}
+	if got := combined.Features[0].Geometry; !reflect.DeepEqual(got, mp) {
+		t.Errorf("combined geometry = %v, want %v", got, mp)
+	}
+	collected := combined.Features[0].Properties["collectedProperties"].([]interface{})
+	if len(collected) != 2 || collected[1].(map[string]interface{})["kind"] != "park" {
+		t.Errorf("collectedProperties = %v", collected)
+	}
+}
+
+func TestFlattenCollection(t *testing.T) {
+	line := NewLineString([]Position{{0, 0}, {1, 1}})
+	gc := NewGeometryCollection([]interface{}{
+		NewPoint(5, 5),
+		NewGeometryCollection([]interface{}{line, NewMultiPoint([]Position{{1, 2}, {3, 4}})}),
+	})
+	kept := NewFeature(line)
+	kept.ID = 7
+	flat, err := Flatten(NewFeatureCollection([]Feature{NewFeature(gc), kept}))
+	if err != nil {
+		t.Fatalf("Flatten() error = %v", err)
+	}
+	var types []string
+	for _, f := range flat.Features {
+		types = append(types, f.Geometry.(Geometry).GeometryType())
+	}
+	if want := []string{"Point", "LineString", "Point", "Point", "LineString"}; !reflect.DeepEqual(types, want) {
+		t.Errorf("Flatten() types = %v, want %v", types, want)
+	}
+	if flat.Features[4].ID != 7 {
+		t.Errorf("single-geometry feature lost its id: %+v", flat.Features[4])
+	}
+
+	combined, err := Combine(flat)
+	if err != nil {
+		t.Fatalf("Combine() error = %v", err)
+	}
+	if len(combined.Features) != 2 {
+		t.Fatalf("Combine() gave %d features, want 2", len(combined.Features))
+	}
+	if got := combined.Features[0].Geometry.(MultiPoint).Coordinates; len(got) != 3 {
+		t.Errorf("combined points = %v, want 3", got)
+	}
+	if _, err := Combine(NewFeatureCollection([]Feature{NewFeature(gc)})); err == nil {
+		t.Error("expected error for a GeometryCollection")
+	}
+}
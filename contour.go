@@ -0,0 +1,345 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Isolines draws contour lines through a grid of values with marching
+// squares. The grid is a FeatureCollection of Point features with "row" and
+// "col" properties, such as PointGrid returns, each holding its value in the
+// valueField property. Rows and columns must form a regular lattice; cells
+// with a missing or non-numeric corner are left out.
+//
+// The result has one Feature per break, in the order given, whose geometry is
+// a MultiLineString and whose "value" property is the break. Crossings are
+// found by linear interpolation along cell edges, and lines run with values
+// at or above the break on their left; lines around a peak are closed rings.
+//
+// A cell whose corners alternate above and below a break is ambiguous. It is
+// resolved with the mean of the four corners as the value at the cell
+// center: the cell is split into four triangles around the center, so the
+// high corners are joined through the middle when the mean reaches the break
+// and kept apart when it does not.
+func Isolines(grid FeatureCollection, valueField string, breaks []float64) (FeatureCollection, error) {
+	if len(breaks) == 0 {
+		return FeatureCollection{}, errors.New("no breaks given")
+	}
+	g, err := newValueGrid(grid, valueField)
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+	features := make([]Feature, len(breaks))
+	for i, b := range breaks {
+		edges := g.bandEdges(b, math.Inf(1))
+		var lines [][]Position
+		for _, line := range linkContourEdges(edges) {
+			lines = append(lines, g.positions(line))
+		}
+		f := NewFeature(NewMultiLineString(lines))
+		f.Properties = map[string]interface{}{"value": b}
+		features[i] = f
+	}
+	return NewFeatureCollection(features), nil
+}
+
+// Isobands fills the areas between consecutive breaks of a grid of values,
+// read as in Isolines. The result has one Feature per pair of breaks whose
+// geometry is a Polygon or MultiPolygon, possibly empty, covering the points
+// with lower <= value < upper, and whose "lower" and "upper" properties are
+// the two breaks. The band edges follow the Isolines of the breaks, with the
+// same resolution of ambiguous cells. Breaks must be strictly increasing;
+// use +Inf as the last break for an open top band.
+func Isobands(grid FeatureCollection, valueField string, breaks []float64) (FeatureCollection, error) {
+	if len(breaks) < 2 {
+		return FeatureCollection{}, errors.New("isobands need at least 2 breaks")
+	}
+	for i := 1; i < len(breaks); i++ {
+		if !(breaks[i] > breaks[i-1]) {
+			return FeatureCollection{}, fmt.Errorf("breaks must be strictly increasing, got %v after %v", breaks[i], breaks[i-1])
+		}
+	}
+	g, err := newValueGrid(grid, valueField)
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+	features := make([]Feature, len(breaks)-1)
+	for i := range features {
+		lower, upper := breaks[i], breaks[i+1]
+		rings := linkOverlayEdges(g.bandEdges(lower, upper))
+		for j, ring := range rings {
+			rings[j] = g.positions(ring)
+		}
+		f := NewFeature(assemblePolygons(rings))
+		f.Properties = map[string]interface{}{"lower": lower, "upper": upper}
+		features[i] = f
+	}
+	return NewFeatureCollection(features), nil
+}
+
+// valueGrid holds grid values and positions by row and column. Contouring
+// works in grid space, where x is the column and y the row, and positions are
+// only looked up at the end.
+type valueGrid struct {
+	rows, cols int
+	values     []float64 // row-major; NaN where missing
+	coords     []Position
+}
+
+func newValueGrid(fc FeatureCollection, valueField string) (*valueGrid, error) {
+	type cell struct {
+		row, col int
+		pos      Position
+		value    float64
+	}
+	cells := make([]cell, 0, len(fc.Features))
+	rows, cols := 0, 0
+	for i, f := range fc.Features {
+		geom, err := geometryOf(f.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		pt, ok := geom.(Point)
+		if !ok {
+			return nil, fmt.Errorf("feature %d: expected Point geometry, got %T", i, geom)
+		}
+		row, okRow := numericValue(f.Properties["row"])
+		col, okCol := numericValue(f.Properties["col"])
+		if !okRow || !okCol || row < 0 || col < 0 || row != math.Trunc(row) || col != math.Trunc(col) {
+			return nil, fmt.Errorf("feature %d has no valid row and col properties", i)
+		}
+		value, ok := numericValue(f.Properties[valueField])
+		if !ok {
+			value = math.NaN()
+		}
+		c := cell{int(row), int(col), pt.Coordinates, value}
+		cells = append(cells, c)
+		rows, cols = max(rows, c.row+1), max(cols, c.col+1)
+	}
+	if rows < 2 || cols < 2 {
+		return nil, errors.New("grid needs at least 2 rows and 2 columns")
+	}
+
+	g := &valueGrid{
+		rows:   rows,
+		cols:   cols,
+		values: make([]float64, rows*cols),
+		coords: make([]Position, rows*cols),
+	}
+	for i := range g.values {
+		g.values[i] = math.NaN()
+	}
+	for _, c := range cells {
+		g.values[c.row*cols+c.col] = c.value
+		g.coords[c.row*cols+c.col] = c.pos
+	}
+	return g, nil
+}
+
+// gridVertex is a point in grid space with its interpolated value.
+type gridVertex struct {
+	x, y, v float64
+}
+
+// bandEdges returns the directed boundary edges, in grid space, of the area
+// where lower <= value < upper. Each cell contributes its part of the band as
+// counterclockwise polygons; edges shared by neighboring parts cancel out.
+func (g *valueGrid) bandEdges(lower, upper float64) [][2]Position {
+	var edges [][2]Position
+	for r := 0; r+1 < g.rows; r++ {
+		for c := 0; c+1 < g.cols; c++ {
+			corners := []gridVertex{
+				{float64(c), float64(r), g.values[r*g.cols+c]},
+				{float64(c + 1), float64(r), g.values[r*g.cols+c+1]},
+				{float64(c + 1), float64(r + 1), g.values[(r+1)*g.cols+c+1]},
+				{float64(c), float64(r + 1), g.values[(r+1)*g.cols+c]},
+			}
+			missing := false
+			for _, v := range corners {
+				missing = missing || math.IsNaN(v.v)
+			}
+			if missing {
+				continue
+			}
+
+			pieces := [][]gridVertex{corners}
+			if isSaddle(corners, lower) || isSaddle(corners, upper) {
+				center := gridVertex{
+					float64(c) + 0.5, float64(r) + 0.5,
+					(corners[0].v + corners[1].v + corners[2].v + corners[3].v) / 4,
+				}
+				pieces = nil
+				for i := range corners {
+					pieces = append(pieces, []gridVertex{corners[i], corners[(i+1)%4], center})
+				}
+			}
+			for _, piece := range pieces {
+				piece = clipGridPolygon(piece, lower, true)
+				piece = clipGridPolygon(piece, upper, false)
+				for i := range piece {
+					p, q := piece[i], piece[(i+1)%len(piece)]
+					e := [2]Position{{p.x, p.y}, {q.x, q.y}}
+					if !samePosition(e[0], e[1]) {
+						edges = append(edges, e)
+					}
+				}
+			}
+		}
+	}
+	return cancelEdges(edges)
+}
+
+// isSaddle reports whether the corners alternate between at or above the
+// break and below it.
+func isSaddle(corners []gridVertex, brk float64) bool {
+	in := func(i int) bool { return corners[i].v >= brk }
+	return in(0) == in(2) && in(1) == in(3) && in(0) != in(1)
+}
+
+// clipGridPolygon clips a polygon with Sutherland-Hodgman to the vertices
+// with value >= brk, or < brk when above is false. Crossings are interpolated
+// from the edge's endpoints taken in a fixed order, so neighboring cells find
+// exactly the same point on a shared edge.
+func clipGridPolygon(poly []gridVertex, brk float64, above bool) []gridVertex {
+	if len(poly) == 0 {
+		return nil
+	}
+	inside := func(v gridVertex) bool { return (v.v >= brk) == above }
+	cross := func(p, q gridVertex) gridVertex {
+		if q.x < p.x || q.x == p.x && q.y < p.y {
+			p, q = q, p
+		}
+		t := (brk - p.v) / (q.v - p.v)
+		return gridVertex{p.x + t*(q.x-p.x), p.y + t*(q.y-p.y), brk}
+	}
+	var out []gridVertex
+	prev := poly[len(poly)-1]
+	for _, p := range poly {
+		switch pIn, prevIn := inside(p), inside(prev); {
+		case pIn && !prevIn:
+			out = append(out, cross(prev, p), p)
+		case pIn:
+			out = append(out, p)
+		case prevIn:
+			out = append(out, cross(prev, p))
+		}
+		prev = p
+	}
+	// Drop repeated vertices left where a crossing falls on a corner.
+	kept := out[:0]
+	for i, v := range out {
+		if i == 0 || v.x != kept[len(kept)-1].x || v.y != kept[len(kept)-1].y {
+			kept = append(kept, v)
+		}
+	}
+	for len(kept) > 1 && kept[0].x == kept[len(kept)-1].x && kept[0].y == kept[len(kept)-1].y {
+		kept = kept[:len(kept)-1]
+	}
+	if len(kept) < 3 {
+		return nil
+	}
+	return kept
+}
+
+// cancelEdges removes pairs of edges that run between the same points in
+// opposite directions, keeping the others in order.
+func cancelEdges(edges [][2]Position) [][2]Position {
+	count := make(map[[2][2]float64]int, len(edges))
+	for _, e := range edges {
+		if reverse := edgeKey([2]Position{e[1], e[0]}); count[reverse] > 0 {
+			count[reverse]--
+		} else {
+			count[edgeKey(e)]++
+		}
+	}
+	out := edges[:0]
+	for _, e := range edges {
+		if count[edgeKey(e)] > 0 {
+			count[edgeKey(e)]--
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// linkContourEdges joins the boundary edges of a band that are not on grid
+// lines, which are the contour segments, into lines. Lines that start or end
+// at the edge of the data come first; closed lines repeat their first vertex.
+func linkContourEdges(edges [][2]Position) [][]Position {
+	onGridLine := func(e [2]Position) bool {
+		return e[0][0] == e[1][0] && e[0][0] == math.Trunc(e[0][0]) ||
+			e[0][1] == e[1][1] && e[0][1] == math.Trunc(e[0][1])
+	}
+	var segs [][2]Position
+	for _, e := range edges {
+		if !onGridLine(e) {
+			segs = append(segs, e)
+		}
+	}
+
+	outgoing := make(map[[2]float64][]int, len(segs))
+	incoming := make(map[[2]float64]int, len(segs))
+	for i, s := range segs {
+		outgoing[lonLat(s[0])] = append(outgoing[lonLat(s[0])], i)
+		incoming[lonLat(s[1])]++
+	}
+	used := make([]bool, len(segs))
+	follow := func(i int) []Position {
+		line := []Position{segs[i][0]}
+		for i >= 0 {
+			used[i] = true
+			line = append(line, segs[i][1])
+			next := -1
+			for _, j := range outgoing[lonLat(segs[i][1])] {
+				if !used[j] {
+					next = j
+					break
+				}
+			}
+			i = next
+		}
+		return line
+	}
+
+	var lines [][]Position
+	starts := make([]int, 0)
+	for i, s := range segs {
+		if incoming[lonLat(s[0])] < len(outgoing[lonLat(s[0])]) {
+			starts = append(starts, i)
+		}
+	}
+	sort.Ints(starts)
+	for _, i := range starts {
+		if !used[i] {
+			lines = append(lines, follow(i))
+		}
+	}
+	for i := range segs {
+		if !used[i] {
+			lines = append(lines, follow(i))
+		}
+	}
+	return lines
+}
+
+// positions maps grid-space points to coordinates, interpolating bilinearly
+// between the positions of the surrounding grid points.
+func (g *valueGrid) positions(points []Position) []Position {
+	out := make([]Position, len(points))
+	for i, p := range points {
+		r := min(int(math.Floor(p[1])), g.rows-2)
+		c := min(int(math.Floor(p[0])), g.cols-2)
+		fx, fy := p[0]-float64(c), p[1]-float64(r)
+		p00 := g.coords[r*g.cols+c]
+		p10 := g.coords[r*g.cols+c+1]
+		p01 := g.coords[(r+1)*g.cols+c]
+		p11 := g.coords[(r+1)*g.cols+c+1]
+		out[i] = make(Position, 2)
+		for k := range out[i] {
+			out[i][k] = (1-fx)*(1-fy)*p00[k] + fx*(1-fy)*p10[k] + (1-fx)*fy*p01[k] + fx*fy*p11[k]
+		}
+	}
+	return out
+}
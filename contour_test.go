@@ -0,0 +1,196 @@
+package geo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// radialGrid returns a PointGrid around (0, 0) whose "dist" property is each
+// point's distance in km from the origin.
+func radialGrid(halfDeg, spacingKm float64) FeatureCollection {
+	grid := PointGrid(BBox{MinLon: -halfDeg, MinLat: -halfDeg, MaxLon: halfDeg, MaxLat: halfDeg}, spacingKm)
+	for i, f := range grid.Features {
+		p := f.Geometry.(Point).Coordinates
+		grid.Features[i].Properties["dist"] = GreatCircleDistance(0, 0, p[1], p[0])
+	}
+	return grid
+}
+
+func TestIsolinesConcentric(t *testing.T) {
+	grid := radialGrid(0.6, 2)
+	breaks := []float64{15, 30, 45}
+	fc, err := Isolines(grid, "dist", breaks)
+	if err != nil {
+		t.Fatalf("Isolines() error = %v", err)
+	}
+	if len(fc.Features) != len(breaks) {
+		t.Fatalf("got %d features, want %d", len(fc.Features), len(breaks))
+	}
+	for i, f := range fc.Features {
+		if f.Properties["value"] != breaks[i] {
+			t.Errorf("feature %d value = %v, want %v", i, f.Properties["value"], breaks[i])
+		}
+		lines := f.Geometry.(MultiLineString).Coordinates
+		if len(lines) != 1 {
+			t.Fatalf("break %v: got %d lines, want 1 ring", breaks[i], len(lines))
+		}
+		ring := lines[0]
+		if !reflect.DeepEqual(ring[0], ring[len(ring)-1]) || len(ring) < 20 {
+			t.Fatalf("break %v: line of %d vertices is not a closed ring", breaks[i], len(ring))
+		}
+		for _, p := range ring {
+			if r := GreatCircleDistance(0, 0, p[1], p[0]); math.Abs(r-breaks[i]) > 0.2 {
+				t.Errorf("break %v: vertex %v at radius %v", breaks[i], p, r)
+			}
+		}
+		// Higher values are on the left, so rings around a pit run clockwise.
+		if area, _, _ := ringAreaCentroid(ring); area >= 0 {
+			t.Errorf("break %v: ring around the low center is counterclockwise", breaks[i])
+		}
+	}
+
+	// A break above every value gives no lines.
+	fc, err = Isolines(grid, "dist", []float64{1000})
+	if err != nil {
+		t.Fatalf("Isolines() error = %v", err)
+	}
+	if lines := fc.Features[0].Geometry.(MultiLineString).Coordinates; len(lines) != 0 {
+		t.Errorf("got %d lines above the maximum, want 0", len(lines))
+	}
+}
+
+func TestIsolinesOpenAndSaddle(t *testing.T) {
+	// A plane rising to the east crosses the grid as straight open lines.
+	grid := PointGrid(BBox{MaxLon: 1, MaxLat: 1}, 20)
+	for i, f := range grid.Features {
+		grid.Features[i].Properties["v"] = f.Geometry.(Point).Coordinates[0]
+	}
+	fc, err := Isolines(grid, "v", []float64{0.5})
+	if err != nil {
+		t.Fatalf("Isolines() error = %v", err)
+	}
+	lines := fc.Features[0].Geometry.(MultiLineString).Coordinates
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want 1", len(lines))
+	}
+	line := lines[0]
+	if line[0][1] <= line[len(line)-1][1] {
+		t.Errorf("line runs from %v to %v, want north to south with higher values on the left", line[0], line[len(line)-1])
+	}
+	for _, p := range line {
+		if math.Abs(p[0]-0.5) > 1e-9 {
+			t.Errorf("vertex %v off the 0.5 contour", p)
+		}
+	}
+
+	// A single saddle cell with high corners southwest and northeast.
+	saddle := func(low float64) FeatureCollection {
+		values := []float64{1, low, 1, low} // sw, se, ne, nw
+		var features []Feature
+		for i, rc := range [][2]int{{0, 0}, {0, 1}, {1, 1}, {1, 0}} {
+			f := NewFeature(NewPoint(float64(rc[1]), float64(rc[0])))
+			f.Properties = map[string]interface{}{"row": rc[0], "col": rc[1], "v": values[i]}
+			features = append(features, f)
+		}
+		return NewFeatureCollection(features)
+	}
+	for _, tc := range []struct {
+		low, brk float64
+		joined   bool
+	}{
+		{0, 0.5, true},     // mean 0.5 reaches the break
+		{0.2, 0.55, true},  // mean 0.6
+		{0, 0.6, false},    // mean 0.5 is below the break
+		{0.2, 0.65, false}, // mean 0.6
+	} {
+		fc, err := Isolines(saddle(tc.low), "v", []float64{tc.brk})
+		if err != nil {
+			t.Fatalf("Isolines() error = %v", err)
+		}
+		lines := fc.Features[0].Geometry.(MultiLineString).Coordinates
+		if len(lines) != 2 {
+			t.Fatalf("break %v: got %d lines, want 2", tc.brk, len(lines))
+		}
+		// Each line cuts off the corner nearest its midpoint. Joined high
+		// corners leave the low corners cut off, and the other way round.
+		for _, line := range lines {
+			a, b := line[0], line[len(line)-1]
+			x, y := math.Round((a[0]+b[0])/2), math.Round((a[1]+b[1])/2)
+			if high := x == y; high == tc.joined {
+				t.Errorf("low %v break %v: line %v cuts off corner (%v, %v)", tc.low, tc.brk, line, x, y)
+			}
+		}
+	}
+}
+
+func TestIsobandsArea(t *testing.T) {
+	grid := radialGrid(0.6, 2)
+	fc, err := Isobands(grid, "dist", []float64{0, 20, 40})
+	if err != nil {
+		t.Fatalf("Isobands() error = %v", err)
+	}
+	if len(fc.Features) != 2 {
+		t.Fatalf("got %d bands, want 2", len(fc.Features))
+	}
+	kmPerDeg := EarthRadiusKm * math.Pi / 180
+	for i, want := range []float64{math.Pi * 20 * 20, math.Pi * (40*40 - 20*20)} {
+		poly, ok := fc.Features[i].Geometry.(Polygon)
+		if !ok {
+			t.Fatalf("band %d = %T, want Polygon", i, fc.Features[i].Geometry)
+		}
+		if wantRings := i + 1; len(poly.Coordinates) != wantRings {
+			t.Errorf("band %d has %d rings, want %d", i, len(poly.Coordinates), wantRings)
+		}
+		area := polygonPlanarArea(poly) * kmPerDeg * kmPerDeg
+		if math.Abs(area-want)/want > 0.01 {
+			t.Errorf("band %d area = %.1f km², want %.1f", i, area, want)
+		}
+		if fc.Features[i].Properties["upper"] != []float64{20, 40}[i] {
+			t.Errorf("band %d properties = %v", i, fc.Features[i].Properties)
+		}
+	}
+
+	// Bands below and above every value cover the whole grid.
+	fc, err = Isobands(grid, "dist", []float64{math.Inf(-1), 50, math.Inf(1)})
+	if err != nil {
+		t.Fatalf("Isobands() error = %v", err)
+	}
+	total := 0.0
+	for _, f := range fc.Features {
+		switch g := f.Geometry.(type) {
+		case Polygon:
+			total += polygonPlanarArea(g)
+		case MultiPolygon:
+			for _, p := range g.Coordinates {
+				total += polygonPlanarArea(NewPolygon(p))
+			}
+		}
+	}
+	var minLon, maxLon, minLat, maxLat float64
+	for _, f := range grid.Features {
+		p := f.Geometry.(Point).Coordinates
+		minLon, maxLon = math.Min(minLon, p[0]), math.Max(maxLon, p[0])
+		minLat, maxLat = math.Min(minLat, p[1]), math.Max(maxLat, p[1])
+	}
+	if want := (maxLon - minLon) * (maxLat - minLat); math.Abs(total-want) > 1e-9 {
+		t.Errorf("bands cover %v square degrees, want %v", total, want)
+	}
+}
+
+func TestContourErrors(t *testing.T) {
+	grid := radialGrid(0.2, 5)
+	if _, err := Isolines(grid, "dist", nil); err == nil {
+		t.Error("expected error for no breaks")
+	}
+	if _, err := Isobands(grid, "dist", []float64{10}); err == nil {
+		t.Error("expected error for a single break")
+	}
+	if _, err := Isobands(grid, "dist", []float64{10, 5}); err == nil {
+		t.Error("expected error for decreasing breaks")
+	}
+	bad := NewFeatureCollection([]Feature{NewFeature(NewPoint(0, 0))})
+	if _, err := Isolines(bad, "dist", []float64{1}); err == nil {
+		t.Error("expected error for features without row and col")
+	}
+}
@@ -0,0 +1,261 @@
+package geo
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CSVOptions configures PointsFromCSV.
+type CSVOptions struct {
+	// LatColumn and LonColumn name the coordinate columns. When empty they
+	// are detected from common header names: lat, latitude or y, and lon, lng,
+	// long, longitude or x, ignoring case.
+	LatColumn string
+	LonColumn string
+	// Comma is the field delimiter; 0 means ','.
+	Comma rune
+	// SkipInvalid skips rows with bad or out-of-range coordinates, and rows
+	// that are not valid CSV, instead of failing on the first one. Skipped, if
+	// set, receives how many were skipped.
+	SkipInvalid bool
+	Skipped     *int
+	// DetectSwapped swaps the coordinate columns when the latitude column
+	// holds values beyond ±90 while every longitude is within ±90, which is
+	// what a file with its columns mislabeled looks like.
+	DetectSwapped bool
+}
+
+var (
+	csvLatNames = []string{"lat", "latitude", "y"}
+	csvLonNames = []string{"lon", "lng", "long", "longitude", "x"}
+)
+
+// PointsFromCSV reads a CSV file with a header row into a FeatureCollection of
+// Points, one per row. Every column other than the coordinates becomes a
+// property, typed as an int64, float64 or bool when the whole cell parses as
+// one and as a string otherwise; empty cells are left out. Errors name the
+// line of the offending row.
+func PointsFromCSV(r io.Reader, opts CSVOptions) (FeatureCollection, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1 // short and long rows are reported per row below
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	header, err := cr.Read()
+	if err == io.EOF {
+		return FeatureCollection{}, errors.New("csv has no header row")
+	}
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+	latCol, err := csvColumn(header, opts.LatColumn, csvLatNames)
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+	lonCol, err := csvColumn(header, opts.LonColumn, csvLonNames)
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+
+	// Rows are read one at a time so that, with SkipInvalid, a row the CSV
+	// reader cannot parse is skipped rather than ending the whole file.
+	var rows [][]string
+	var lines []int
+	skipped := 0
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if opts.SkipInvalid && errors.As(err, &parseErr) {
+			skipped++
+			continue
+		}
+		if err != nil {
+			return FeatureCollection{}, err
+		}
+		line, _ := cr.FieldPos(0)
+		rows = append(rows, row)
+		lines = append(lines, line)
+	}
+	if opts.DetectSwapped && csvColumnsSwapped(rows, latCol, lonCol) {
+		latCol, lonCol = lonCol, latCol
+	}
+
+	features := []Feature{}
+	for i, row := range rows {
+		line := lines[i]
+		lat, lon, err := csvCoordinates(row, latCol, lonCol)
+		if err == nil && len(row) > len(header) {
+			err = fmt.Errorf("row has %d fields, header has %d", len(row), len(header))
+		}
+		if err != nil {
+			if opts.SkipInvalid {
+				skipped++
+				continue
+			}
+			return FeatureCollection{}, fmt.Errorf("csv line %d: %v", line, err)
+		}
+		f := NewFeature(NewPoint(lon, lat))
+		for j, cell := range row {
+			if j == latCol || j == lonCol || cell == "" {
+				continue
+			}
+			if f.Properties == nil {
+				f.Properties = make(map[string]interface{})
+			}
+			f.Properties[header[j]] = inferCSVValue(cell)
+		}
+		features = append(features, f)
+	}
+	if opts.Skipped != nil {
+		*opts.Skipped = skipped
+	}
+	return NewFeatureCollection(features), nil
+}
+
+// csvColumn returns the index of the named column, or of the first column
+// matching one of candidates when name is empty.
+func csvColumn(header []string, name string, candidates []string) (int, error) {
+	for i, h := range header {
+		h = strings.TrimSpace(h)
+		if name != "" {
+			if h == name {
+				return i, nil
+			}
+			continue
+		}
+		for _, c := range candidates {
+			if strings.EqualFold(h, c) {
+				return i, nil
+			}
+		}
+	}
+	if name != "" {
+		return 0, fmt.Errorf("csv has no column %q", name)
+	}
+	return 0, fmt.Errorf("csv has no %s column", candidates[0])
+}
+
+// csvColumnsSwapped reports whether the latitude column has values outside
+// ±90 while the longitude column has none. Unparsable cells are ignored.
+func csvColumnsSwapped(rows [][]string, latCol, lonCol int) bool {
+	latBeyond, lonBeyond := false, false
+	for _, row := range rows {
+		if latCol < len(row) {
+			if v, err := strconv.ParseFloat(strings.TrimSpace(row[latCol]), 64); err == nil && math.Abs(v) > 90 {
+				latBeyond = true
+			}
+		}
+		if lonCol < len(row) {
+			if v, err := strconv.ParseFloat(strings.TrimSpace(row[lonCol]), 64); err == nil && math.Abs(v) > 90 {
+				lonBeyond = true
+			}
+		}
+	}
+	return latBeyond && !lonBeyond
+}
+
+// csvCoordinates parses and range-checks the coordinates of a row.
+func csvCoordinates(row []string, latCol, lonCol int) (float64, float64, error) {
+	if latCol >= len(row) || lonCol >= len(row) {
+		return 0, 0, errors.New("missing coordinate columns")
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(row[latCol]), 64)
+	if err != nil || math.IsNaN(lat) {
+		return 0, 0, fmt.Errorf("invalid latitude %q", row[latCol])
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(row[lonCol]), 64)
+	if err != nil || math.IsNaN(lon) {
+		return 0, 0, fmt.Errorf("invalid longitude %q", row[lonCol])
+	}
+	if lat < -90 || lat > 90 {
+		return 0, 0, fmt.Errorf("latitude %v out of range [-90, 90]", lat)
+	}
+	if lon < -180 || lon > 180 {
+		return 0, 0, fmt.Errorf("longitude %v out of range [-180, 180]", lon)
+	}
+	return lat, lon, nil
+}
+
+// inferCSVValue returns cell as an int64, float64 or bool if it parses as one,
+// and as a string otherwise.
+func inferCSVValue(cell string) interface{} {
+	if v, err := strconv.ParseInt(cell, 10, 64); err == nil {
+		return v
+	}
+	if v, err := strconv.ParseFloat(cell, 64); err == nil && !math.IsNaN(v) && !math.IsInf(v, 0) {
+		return v
+	}
+	switch strings.ToLower(cell) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return cell
+}
+
+// PointsToCSV writes the Point features of fc as CSV with a header row of
+// lat, lon and then columns, which name the properties to write. With nil
+// columns all properties are written, sorted by name. Missing properties give
+// empty cells. Features with other geometries are an error.
+func PointsToCSV(w io.Writer, fc FeatureCollection, columns []string) error {
+	if columns == nil {
+		seen := make(map[string]bool)
+		for _, f := range fc.Features {
+			for k := range f.Properties {
+				if !seen[k] {
+					seen[k] = true
+					columns = append(columns, k)
+				}
+			}
+		}
+		sort.Strings(columns)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"lat", "lon"}, columns...)); err != nil {
+		return err
+	}
+	for i, f := range fc.Features {
+		geom, err := geometryOf(f.Geometry)
+		if err != nil {
+			return fmt.Errorf("feature %d: %v", i, err)
+		}
+		pt, ok := geom.(Point)
+		if !ok {
+			return fmt.Errorf("feature %d is a %s, not a Point", i, geom.GeometryType())
+		}
+		row := make([]string, 0, len(columns)+2)
+		row = append(row, formatCSVValue(pt.Coordinates[1]), formatCSVValue(pt.Coordinates[0]))
+		for _, c := range columns {
+			row = append(row, formatCSVValue(f.Properties[c]))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatCSVValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
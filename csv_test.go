@@ -0,0 +1,148 @@
+package geo
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const csvCities = `name,Latitude,Longitude,population,capital
+Stockholm,59.3293,18.0686,975551,true
+Oslo,59.9139,10.7522,709037,true
+Gothenburg,57.7089,11.9746,,false
+Broken,north,11.0,1,false
+Nowhere,95.5,10.0,0,false
+`
+
+func TestPointsFromCSV(t *testing.T) {
+	_, err := PointsFromCSV(strings.NewReader(csvCities), CSVOptions{})
+	if err == nil || !strings.Contains(err.Error(), "line 5") {
+		t.Fatalf("PointsFromCSV() error = %v, want error on line 5", err)
+	}
+
+	var skipped int
+	fc, err := PointsFromCSV(strings.NewReader(csvCities), CSVOptions{SkipInvalid: true, Skipped: &skipped})
+	if err != nil {
+		t.Fatalf("PointsFromCSV() error = %v", err)
+	}
+	if skipped != 2 {
+		t.Errorf("skipped = %d, want 2", skipped)
+	}
+	if len(fc.Features) != 3 {
+		t.Fatalf("features = %d, want 3", len(fc.Features))
+	}
+	first := fc.Features[0]
+	if pt := first.Geometry.(Point); !reflect.DeepEqual(pt.Coordinates, Position{18.0686, 59.3293}) {
+		t.Errorf("coordinates = %v", pt.Coordinates)
+	}
+	want := map[string]interface{}{"name": "Stockholm", "population": int64(975551), "capital": true}
+	if !reflect.DeepEqual(first.Properties, want) {
+		t.Errorf("properties = %#v, want %#v", first.Properties, want)
+	}
+	if _, ok := fc.Features[2].Properties["population"]; ok {
+		t.Error("empty cell should be left out")
+	}
+}
+
+func TestPointsFromCSVColumns(t *testing.T) {
+	data := "id;north;east;score\n1;10.5;20.25;0.75\n"
+	fc, err := PointsFromCSV(strings.NewReader(data), CSVOptions{LatColumn: "north", LonColumn: "east", Comma: ';'})
+	if err != nil {
+		t.Fatalf("PointsFromCSV() error = %v", err)
+	}
+	f := fc.Features[0]
+	if pt := f.Geometry.(Point); !reflect.DeepEqual(pt.Coordinates, Position{20.25, 10.5}) {
+		t.Errorf("coordinates = %v", pt.Coordinates)
+	}
+	if f.Properties["score"] != 0.75 || f.Properties["id"] != int64(1) {
+		t.Errorf("properties = %#v", f.Properties)
+	}
+
+	if _, err := PointsFromCSV(strings.NewReader(data), CSVOptions{}); err == nil {
+		t.Error("expected error when no coordinate column is found")
+	}
+	if _, err := PointsFromCSV(strings.NewReader(""), CSVOptions{}); err == nil {
+		t.Error("expected error for empty input")
+	}
+	if _, err := PointsFromCSV(strings.NewReader("lat,lon\n1\n"), CSVOptions{}); err == nil {
+		t.Error("expected error for short row")
+	}
+
+	long := "lat,lon,name\n1,2,a,extra\n3,4,b\n"
+	if _, err := PointsFromCSV(strings.NewReader(long), CSVOptions{}); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("PointsFromCSV() error = %v, want error on line 2 for long row", err)
+	}
+	var skipped int
+	fc, err = PointsFromCSV(strings.NewReader(long), CSVOptions{SkipInvalid: true, Skipped: &skipped})
+	if err != nil || skipped != 1 || len(fc.Features) != 1 {
+		t.Errorf("PointsFromCSV(SkipInvalid) = %d features, %d skipped, error %v, want 1, 1, nil", len(fc.Features), skipped, err)
+	}
+
+	// A stray quote makes a row unparsable as CSV; it is skipped like a row
+	// with bad coordinates instead of ending the file.
+	quoted := "lat,lon,name\n1,2,a\"b\n3,4,c\n5,x,d\n"
+	if _, err := PointsFromCSV(strings.NewReader(quoted), CSVOptions{}); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("PointsFromCSV() error = %v, want error on line 2 for bad quote", err)
+	}
+	fc, err = PointsFromCSV(strings.NewReader(quoted), CSVOptions{SkipInvalid: true, Skipped: &skipped})
+	if err != nil || skipped != 2 || len(fc.Features) != 1 || fc.Features[0].Properties["name"] != "c" {
+		t.Errorf("PointsFromCSV(SkipInvalid) = %v, %d skipped, error %v, want row c only", fc.Features, skipped, err)
+	}
+}
+
+func TestPointsFromCSVSwapped(t *testing.T) {
+	// The header is the wrong way round: "lat" holds longitudes beyond ±90.
+	data := "lat,lon\n-122.42,37.77\n151.21,-33.87\n"
+	if _, err := PointsFromCSV(strings.NewReader(data), CSVOptions{}); err == nil {
+		t.Fatal("expected out-of-range error without DetectSwapped")
+	}
+	fc, err := PointsFromCSV(strings.NewReader(data), CSVOptions{DetectSwapped: true})
+	if err != nil {
+		t.Fatalf("PointsFromCSV() error = %v", err)
+	}
+	if pt := fc.Features[0].Geometry.(Point); !reflect.DeepEqual(pt.Coordinates, Position{-122.42, 37.77}) {
+		t.Errorf("coordinates = %v, want [-122.42 37.77]", pt.Coordinates)
+	}
+
+	// Values within ±90 in both columns are left alone.
+	fc, _ = PointsFromCSV(strings.NewReader("lat,lon\n10,20\n"), CSVOptions{DetectSwapped: true})
+	if pt := fc.Features[0].Geometry.(Point); !reflect.DeepEqual(pt.Coordinates, Position{20, 10}) {
+		t.Errorf("coordinates = %v, want [20 10]", pt.Coordinates)
+	}
+}
+
+func TestPointsToCSV(t *testing.T) {
+	fc, err := PointsFromCSV(strings.NewReader(csvCities), CSVOptions{SkipInvalid: true})
+	if err != nil {
+		t.Fatalf("PointsFromCSV() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := PointsToCSV(&buf, fc, []string{"name", "population"}); err != nil {
+		t.Fatalf("PointsToCSV() error = %v", err)
+	}
+	want := "lat,lon,name,population\n59.3293,18.0686,Stockholm,975551\n59.9139,10.7522,Oslo,709037\n57.7089,11.9746,Gothenburg,\n"
+	if buf.String() != want {
+		t.Errorf("PointsToCSV() =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := PointsToCSV(&buf, fc, nil); err != nil {
+		t.Fatalf("PointsToCSV() error = %v", err)
+	}
+	if header := strings.SplitN(buf.String(), "\n", 2)[0]; header != "lat,lon,capital,name,population" {
+		t.Errorf("header = %q", header)
+	}
+	back, err := PointsFromCSV(&buf, CSVOptions{})
+	if err != nil {
+		t.Fatalf("PointsFromCSV() error = %v", err)
+	}
+	if !reflect.DeepEqual(back, fc) {
+		t.Errorf("round trip = %#v, want %#v", back, fc)
+	}
+
+	fc.Features = append(fc.Features, NewFeature(NewLineString([]Position{{0, 0}, {1, 1}})))
+	if err := PointsToCSV(&buf, fc, nil); err == nil {
+		t.Error("expected error for non-point feature")
+	}
+}
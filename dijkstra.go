@@ -2,6 +2,8 @@ package geo
 
 import (
 	"container/heap"
+	"context"
+	"fmt"
 	"math"
 )
 
@@ -87,34 +89,146 @@ func (g *Graph) Dijkstra(source int) *DijkstraResult {
 	if source < 0 || source >= g.Nodes {
 		return nil
 	}
+	result, _ := g.DijkstraCtx(context.Background(), source)
+	return result
+}
+
+// DijkstraCtx is Dijkstra with cancellation. The context is checked every
+// ctxCheckInterval settled nodes; once it is done the partial result is
+// returned together with ctx.Err(). In a partial result the distances of
+// settled nodes are final and the others are upper bounds or +Inf.
+func (g *Graph) DijkstraCtx(ctx context.Context, source int) (*DijkstraResult, error) {
+	if source < 0 || source >= g.Nodes {
+		return nil, fmt.Errorf("source node %d out of range [0, %d)", source, g.Nodes)
+	}
 
-	// Initialize distances and previous nodes
-	distances := make([]float64, g.Nodes)
-	previous := make([]int, g.Nodes)
-	for i := range distances {
-		distances[i] = math.Inf(1)
-		previous[i] = -1
+	var ws DijkstraWorkspace
+	return g.dijkstra(ctx, []int{source}, &ws)
+}
+
+// DijkstraMultiSource computes, for every node, the shortest distance from the
+// nearest of several sources and which source that is. The search starts from
+// all sources at distance 0, so it costs the same as a single Dijkstra run.
+// nearestSource[v] is the source that v's shortest path starts from, or -1
+// when v is unreachable; a node equally far from several sources goes to
+// whichever reaches it first. It returns nil slices if sources is empty or
+// holds a node out of range.
+func (g *Graph) DijkstraMultiSource(sources []int) (distances []float64, nearestSource []int) {
+	if len(sources) == 0 {
+		return nil, nil
+	}
+	for _, s := range sources {
+		if s < 0 || s >= g.Nodes {
+			return nil, nil
+		}
+	}
+	var ws DijkstraWorkspace
+	result, _ := g.dijkstra(context.Background(), sources, &ws)
+
+	// Follow each node's path back to the source it starts from, remembering
+	// the answer for every node passed on the way.
+	nearestSource = make([]int, g.Nodes)
+	for v := range nearestSource {
+		nearestSource[v] = -2 // not yet known
+	}
+	var path []int
+	for v := range nearestSource {
+		u := v
+		for nearestSource[u] == -2 && result.Previous[u] != -1 {
+			path = append(path, u)
+			u = result.Previous[u]
+		}
+		owner := nearestSource[u]
+		if owner == -2 {
+			owner = -1
+			if !math.IsInf(result.Distances[u], 1) {
+				owner = u
+			}
+			nearestSource[u] = owner
+		}
+		for _, w := range path {
+			nearestSource[w] = owner
+		}
+		path = path[:0]
 	}
-	distances[source] = 0
+	return result.Distances, nearestSource
+}
 
-	// Initialize priority queue
-	pq := make(priorityQueue, 0)
-	heap.Init(&pq)
-	heap.Push(&pq, &priorityQueueItem{
-		node:     source,
-		distance: 0,
-	})
+// DijkstraWorkspace holds the buffers used by DijkstraReuse so repeated
+// queries do not allocate. The zero value is ready to use and grows to fit the
+// largest graph it has seen. A workspace must not be shared between goroutines.
+type DijkstraWorkspace struct {
+	result  DijkstraResult
+	visited []bool
+	items   []priorityQueueItem // queue item of each node, index -1 when not queued
+	pq      priorityQueue
+	heapOps int // pushes, pops and decrease-keys of the last query
+}
 
-	visited := make([]bool, g.Nodes)
+// DijkstraReuse is Dijkstra using the buffers in ws instead of allocating new
+// ones. The returned result is owned by ws and is overwritten by the next query
+// with the same workspace; copy it if it must outlive that.
+func (g *Graph) DijkstraReuse(source int, ws *DijkstraWorkspace) *DijkstraResult {
+	if source < 0 || source >= g.Nodes {
+		return nil
+	}
+	result, _ := g.dijkstra(context.Background(), []int{source}, ws)
+	return result
+}
 
-	for pq.Len() > 0 {
-		current := heap.Pop(&pq).(*priorityQueueItem)
-		u := current.node
+// reset sizes the buffers for n nodes and clears them for a new query.
+func (ws *DijkstraWorkspace) reset(n int) {
+	if cap(ws.result.Distances) < n {
+		ws.result.Distances = make([]float64, n)
+		ws.result.Previous = make([]int, n)
+		ws.visited = make([]bool, n)
+		ws.items = make([]priorityQueueItem, n)
+		ws.pq = make(priorityQueue, 0, n)
+	}
+	ws.result.Distances = ws.result.Distances[:n]
+	ws.result.Previous = ws.result.Previous[:n]
+	ws.visited = ws.visited[:n]
+	ws.items = ws.items[:n]
+	for i := 0; i < n; i++ {
+		ws.result.Distances[i] = math.Inf(1)
+		ws.result.Previous[i] = -1
+		ws.visited[i] = false
+		ws.items[i] = priorityQueueItem{node: i, index: -1}
+	}
+	ws.pq = ws.pq[:0]
+	ws.heapOps = 0
+}
 
-		if visited[u] {
+// dijkstra runs the search from the given sources in ws. Every node has one
+// queue item, so a node is in the heap at most once: finding a shorter path to
+// a queued node lowers its key in place instead of pushing a duplicate entry.
+func (g *Graph) dijkstra(ctx context.Context, sources []int, ws *DijkstraWorkspace) (*DijkstraResult, error) {
+	ws.reset(g.Nodes)
+	distances := ws.result.Distances
+	previous := ws.result.Previous
+	visited := ws.visited
+	for _, source := range sources {
+		if ws.items[source].index >= 0 {
 			continue
 		}
+		distances[source] = 0
+		heap.Push(&ws.pq, &ws.items[source])
+		ws.heapOps++
+	}
+
+	settled := 0
+	var err error
+
+	for ws.pq.Len() > 0 {
+		if settled%ctxCheckInterval == 0 {
+			if err = ctx.Err(); err != nil {
+				break
+			}
+		}
+		u := heap.Pop(&ws.pq).(*priorityQueueItem).node
+		ws.heapOps++
 		visited[u] = true
+		settled++
 
 		// Explore neighbors
 		for _, edge := range g.Edges[u] {
@@ -127,18 +241,19 @@ func (g *Graph) Dijkstra(source int) *DijkstraResult {
 			if alt < distances[v] {
 				distances[v] = alt
 				previous[v] = u
-				heap.Push(&pq, &priorityQueueItem{
-					node:     v,
-					distance: alt,
-				})
+				item := &ws.items[v]
+				item.distance = alt
+				if item.index >= 0 {
+					heap.Fix(&ws.pq, item.index)
+				} else {
+					heap.Push(&ws.pq, item)
+				}
+				ws.heapOps++
 			}
 		}
 	}
 
-	return &DijkstraResult{
-		Distances: distances,
-		Previous:  previous,
-	}
+	return &ws.result, err
 }
 
 // GetPath reconstructs the shortest path from source to target
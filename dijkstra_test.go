@@ -1,7 +1,9 @@
 package geo
 
 import (
+	"context"
 	"math"
+	"reflect"
 	"testing"
 )
 
@@ -163,3 +165,154 @@ func equalPath(a, b []int) bool {
 	}
 	return true
 }
+
+func TestDijkstraCtx(t *testing.T) {
+	const n = 5000
+	g := NewGraph(n)
+	for i := 0; i < n-1; i++ {
+		g.AddBidirectionalEdge(i, i+1, 1.0)
+	}
+
+	result, err := g.DijkstraCtx(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("DijkstraCtx() error = %v", err)
+	}
+	if result.Distances[n-1] != n-1 {
+		t.Errorf("distance to last node = %v, want %d", result.Distances[n-1], n-1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	partial, err := g.DijkstraCtx(ctx, 0)
+	if err != context.Canceled {
+		t.Fatalf("error = %v, want %v", err, context.Canceled)
+	}
+	if partial == nil {
+		t.Fatal("expected a partial result")
+	}
+	if partial.Distances[0] != 0 {
+		t.Errorf("source distance = %v, want 0", partial.Distances[0])
+	}
+	if !math.IsInf(partial.Distances[n-1], 1) {
+		t.Errorf("distance to last node = %v, want +Inf in a cancelled run", partial.Distances[n-1])
+	}
+
+	if _, err := g.DijkstraCtx(context.Background(), n); err == nil {
+		t.Error("expected error for out-of-range source")
+	}
+}
+
+func TestDijkstraReuse(t *testing.T) {
+	g := NewGraph(6)
+	g.AddEdge(0, 1, 7)
+	g.AddEdge(0, 2, 9)
+	g.AddEdge(0, 5, 14)
+	g.AddEdge(1, 2, 10)
+	g.AddEdge(1, 3, 15)
+	g.AddEdge(2, 3, 11)
+	g.AddEdge(2, 5, 2)
+	g.AddEdge(3, 4, 6)
+	g.AddEdge(5, 4, 9)
+
+	var ws DijkstraWorkspace
+	for _, source := range []int{0, 2, 0, 5, 3} {
+		got := g.DijkstraReuse(source, &ws)
+		want := g.Dijkstra(source)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("source %d: got %+v, want %+v", source, got, want)
+		}
+	}
+
+	// The same workspace serves a larger graph afterwards.
+	big := NewGraph(10)
+	for i := 0; i < 9; i++ {
+		big.AddBidirectionalEdge(i, i+1, 1)
+	}
+	got := big.DijkstraReuse(0, &ws)
+	if len(got.Distances) != 10 || got.Distances[9] != 9 {
+		t.Errorf("larger graph: got %+v", got)
+	}
+	if g.DijkstraReuse(-1, &ws) != nil {
+		t.Error("expected nil for an out-of-range source")
+	}
+}
+
+func TestDijkstraDecreaseKey(t *testing.T) {
+	// Node 3 is reached through ever shorter paths as 0, 1 and 2 settle,
+	// so its key is lowered twice while it sits in the queue.
+	g := NewGraph(4)
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(0, 2, 2)
+	g.AddEdge(0, 3, 10)
+	g.AddEdge(1, 3, 5)
+	g.AddEdge(2, 3, 1)
+	g.AddEdge(1, 2, 0.5)
+
+	var ws DijkstraWorkspace
+	result := g.DijkstraReuse(0, &ws)
+	want := []float64{0, 1, 1.5, 2.5}
+	if !reflect.DeepEqual(result.Distances, want) {
+		t.Errorf("distances = %v, want %v", result.Distances, want)
+	}
+	if path := result.GetPath(3); !equalPath(path, []int{0, 1, 2, 3}) {
+		t.Errorf("path = %v, want [0 1 2 3]", path)
+	}
+	if ws.pq.Len() != 0 {
+		t.Errorf("queue not drained: %d items left", ws.pq.Len())
+	}
+
+	// Each node is pushed and popped once; everything else is a decrease-key.
+	g = denseGraph(60)
+	result = g.DijkstraReuse(0, &ws)
+	lazy, lazyOps := lazyDijkstra(g, 0)
+	if !reflect.DeepEqual(result.Distances, lazy) {
+		t.Errorf("distances differ from lazy-deletion search")
+	}
+	if ws.heapOps >= lazyOps {
+		t.Errorf("heap operations = %d, want fewer than lazy deletion's %d", ws.heapOps, lazyOps)
+	}
+}
+
+func TestDijkstraMultiSource(t *testing.T) {
+	// A line 0 - 1 - 2 - 3 - 4 with warehouses at both ends.
+	line := func(weights ...float64) *Graph {
+		g := NewGraph(len(weights) + 2)
+		for i, w := range weights {
+			g.AddBidirectionalEdge(i, i+1, w)
+		}
+		return g
+	}
+
+	g := line(1, 1, 2, 1)
+	distances, nearest := g.DijkstraMultiSource([]int{0, 4})
+	if want := []float64{0, 1, 2, 1, 0, math.Inf(1)}; !reflect.DeepEqual(distances, want) {
+		t.Errorf("distances = %v, want %v", distances, want)
+	}
+	if want := []int{0, 0, 0, 4, 4, -1}; !reflect.DeepEqual(nearest, want) {
+		t.Errorf("nearest sources = %v, want %v", nearest, want)
+	}
+
+	// Making the western edge longer hands the middle node to the east.
+	g = line(1, 3, 2, 1)
+	distances, nearest = g.DijkstraMultiSource([]int{0, 4})
+	if nearest[2] != 4 || distances[2] != 3 {
+		t.Errorf("middle node owned by %d at %v, want 4 at 3", nearest[2], distances[2])
+	}
+	if nearest[1] != 0 || nearest[3] != 4 {
+		t.Errorf("nearest sources = %v", nearest)
+	}
+
+	// A single source matches Dijkstra.
+	result := g.Dijkstra(0)
+	distances, _ = g.DijkstraMultiSource([]int{0, 0})
+	if !reflect.DeepEqual(distances, result.Distances) {
+		t.Errorf("single source distances = %v, want %v", distances, result.Distances)
+	}
+
+	if d, n := g.DijkstraMultiSource(nil); d != nil || n != nil {
+		t.Error("expected nil for no sources")
+	}
+	if d, n := g.DijkstraMultiSource([]int{0, 9}); d != nil || n != nil {
+		t.Error("expected nil for out-of-range source")
+	}
+}
@@ -39,8 +39,13 @@ func normalizeBearingDegrees(deg float64) float64 {
 	return deg
 }
 
-// normalizeLongitude keeps longitude in the [-180, 180] range.
+// normalizeLongitude keeps longitude in the [-180, 180] range. Longitudes
+// already inside it are returned unchanged, as shifting them by 180 and back
+// would round away their last digits.
 func normalizeLongitude(lon float64) float64 {
+	if lon > -180.0 && lon < 180.0 {
+		return lon
+	}
 	lon = math.Mod(lon+180.0, 360.0)
 	if lon < 0 {
 		lon += 360.0
@@ -69,6 +74,15 @@ func angularDistanceRad(lat1, lon1, lat2, lon2 float64) float64 {
 	a := math.Sin(Δφ/2)*math.Sin(Δφ/2) +
 		math.Cos(φ1)*math.Cos(φ2)*
 			math.Sin(Δλ/2)*math.Sin(Δλ/2)
+	return haversineAngle(a)
+}
+
+// haversineAngle returns the central angle in radians for the haversine term
+// a = sin²(Δφ/2) + cos φ1 cos φ2 sin²(Δλ/2). For nearly antipodal points
+// rounding can push a just above 1, which would make sqrt(1-a) NaN, so a is
+// clamped to [0, 1] first.
+func haversineAngle(a float64) float64 {
+	a = math.Max(0, math.Min(1, a))
 	return 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
 }
 
@@ -86,7 +100,7 @@ func GreatCircleDistance(lat1, lon1, lat2, lon2 float64) float64 {
 	a := math.Sin(Δφ/2)*math.Sin(Δφ/2) +
 		math.Cos(φ1)*math.Cos(φ2)*
 			math.Sin(Δλ/2)*math.Sin(Δλ/2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	c := haversineAngle(a)
 
 	return EarthRadiusKm * c
 }
@@ -98,6 +112,51 @@ func Bearing(lat1, lon1, lat2, lon2 float64) float64 {
 	return normalizeBearingDegrees(toDegrees(bearingRad))
 }
 
+// GreatCircleInverse returns the great circle distance in kilometers together
+// with the initial and final bearings in degrees, in the range [0, 360). It
+// gives the results of GreatCircleDistance, Bearing and the bearing on
+// arrival while evaluating the shared trigonometric terms only once.
+func GreatCircleInverse(lat1, lon1, lat2, lon2 float64) (distanceKm, initialBearing, finalBearing float64) {
+	φ1 := toRadians(lat1)
+	φ2 := toRadians(lat2)
+	Δφ := φ2 - φ1
+	Δλ := toRadians(lon2 - lon1)
+
+	sinφ1, cosφ1 := math.Sincos(φ1)
+	sinφ2, cosφ2 := math.Sincos(φ2)
+	sinΔλ, cosΔλ := math.Sincos(Δλ)
+	sinHalfΔφ := math.Sin(Δφ / 2)
+	sinHalfΔλ := math.Sin(Δλ / 2)
+
+	a := sinHalfΔφ*sinHalfΔφ + cosφ1*cosφ2*sinHalfΔλ*sinHalfΔλ
+	c := haversineAngle(a)
+
+	θ1 := math.Atan2(sinΔλ*cosφ2, cosφ1*sinφ2-sinφ1*cosφ2*cosΔλ)
+	// The final bearing is the reverse of the initial bearing from point 2.
+	θ2 := math.Atan2(sinΔλ*cosφ1, sinφ2*cosφ1*cosΔλ-cosφ2*sinφ1)
+
+	return EarthRadiusKm * c, normalizeBearingDegrees(toDegrees(θ1)), normalizeBearingDegrees(toDegrees(θ2))
+}
+
+// BearingDifference returns the signed angle in degrees to turn from bearing
+// from to bearing to, in the range (-180, 180]. Positive values are clockwise
+// (a right turn), negative values counterclockwise.
+func BearingDifference(from, to float64) float64 {
+	diff := math.Mod(to-from, 360)
+	if diff <= -180 {
+		diff += 360
+	} else if diff > 180 {
+		diff -= 360
+	}
+	return diff
+}
+
+// finalBearing returns the bearing in degrees on arrival at point 2 when
+// following the great circle from point 1.
+func finalBearing(lat1, lon1, lat2, lon2 float64) float64 {
+	return normalizeBearingDegrees(Bearing(lat2, lon2, lat1, lon1) + 180)
+}
+
 // GreatCircleProject projects a point onto the great circle path between two coordinates.
 // Returns the projected point (lat, lon), cross-track distance (km), and along-track
 // distance from the start (km). Along-track can be negative or exceed total distance,
@@ -128,11 +187,33 @@ func GreatCircleProject(lat1, lon1, lat2, lon2, latP, lonP float64) (float64, fl
 	return projLat, projLon, crossTrackKm, alongTrackKm
 }
 
+// SteeringCorrection returns the cross-track error of a point off the great
+// circle path from point 1 to point 2, and the change of heading needed to head
+// straight for point 2 from there instead of following the path's course.
+// Cross-track is signed as in GreatCircleProject: positive to the right of the
+// path, negative to the left. The correction is in degrees in (-180, 180],
+// positive for a turn to the right, so a point left of the track gets a
+// rightward correction.
+func SteeringCorrection(lat1, lon1, lat2, lon2, latP, lonP float64) (crossTrackKm, correctionDeg float64) {
+	projLat, projLon, crossTrackKm, _ := GreatCircleProject(lat1, lon1, lat2, lon2, latP, lonP)
+	if GreatCircleDistance(latP, lonP, lat2, lon2) == 0 {
+		return crossTrackKm, 0
+	}
+	course := Bearing(projLat, projLon, lat2, lon2)
+	if GreatCircleDistance(projLat, projLon, lat2, lon2) == 0 {
+		course = finalBearing(lat1, lon1, lat2, lon2)
+	}
+	return crossTrackKm, BearingDifference(course, Bearing(latP, lonP, lat2, lon2))
+}
+
 // GreatCircleProjectToSegment projects a point onto the great circle route segment.
 // If the perpendicular projection falls outside the segment, the nearest endpoint
 // is returned. Along-track distance is clamped to [0, total]. Cross-track distance
-// is signed only when the projection falls within the segment; otherwise it is the
-// (positive) distance to the nearest endpoint.
+// is signed only when the projection falls within the segment, as in
+// GreatCircleProject: positive to the right of the path from start to end and
+// negative to the left. Otherwise it is the (positive) distance to the nearest
+// endpoint, so its absolute value is always the distance from the point to the
+// segment.
 func GreatCircleProjectToSegment(lat1, lon1, lat2, lon2, latP, lonP float64) (float64, float64, float64, float64) {
 	projLat, projLon, crossTrackKm, alongTrackKm := GreatCircleProject(lat1, lon1, lat2, lon2, latP, lonP)
 	total := GreatCircleDistance(lat1, lon1, lat2, lon2)
@@ -150,6 +231,47 @@ func GreatCircleProjectToSegment(lat1, lon1, lat2, lon2, latP, lonP float64) (fl
 	return projLat, projLon, crossTrackKm, alongTrackKm
 }
 
+// GreatCircleDeviation returns how far, in kilometers, a segment drawn as a
+// straight line in the lon/lat plane strays from the great circle between its
+// endpoints. It is measured at the middle of the straight segment, where the
+// two are about farthest apart, as the unsigned cross-track distance of that point
+// from the great circle. The straight segment takes the short way in
+// longitude, across the antimeridian if need be. Use it to decide whether a
+// segment needs densifying before it is drawn or clipped in degree space.
+func GreatCircleDeviation(lat1, lon1, lat2, lon2 float64) float64 {
+	midLat := (lat1 + lat2) / 2
+	midLon := normalizeLongitude(lon1 + normalizeLongitude(lon2-lon1)/2)
+	_, _, crossTrackKm, _ := GreatCircleProject(lat1, lon1, lat2, lon2, midLat, midLon)
+	return math.Abs(crossTrackKm)
+}
+
+// GreatCircleMaxLatitude returns the northernmost point of the great circle
+// path between two coordinates, in degrees. By Clairaut's theorem that is the
+// northern vertex of the great circle, where the path runs due east or west,
+// when the path passes through it; otherwise it is the endpoint with the
+// higher latitude, or the start when both have the same latitude. A path along
+// the equator gives latitude 0 at the start.
+func GreatCircleMaxLatitude(lat1, lon1, lat2, lon2 float64) (maxLat, lonAtMax float64) {
+	maxLat, lonAtMax = lat1, lon1
+	if lat2 > lat1 {
+		maxLat, lonAtMax = lat2, lon2
+	}
+	path := lineArcs([]Position{{lon1, lat1}, {lon2, lat2}})[0]
+	n := path.normal
+	// The northern vertex is where the plane of the great circle comes
+	// closest to the north pole: the polar axis with its component along the
+	// normal removed.
+	top := [3]float64{-n[2] * n[0], -n[2] * n[1], 1 - n[2]*n[2]}
+	if norm3(top) <= arcEpsilon {
+		return maxLat, normalizeLongitude(lonAtMax)
+	}
+	top = scale3(top, 1/norm3(top))
+	if lat := toDegrees(math.Asin(math.Min(1, top[2]))); path.contains(top) && lat > maxLat {
+		maxLat, lonAtMax = lat, toDegrees(math.Atan2(top[1], top[0]))
+	}
+	return maxLat, normalizeLongitude(lonAtMax)
+}
+
 // GreatCircleIntermediatePoint returns the point at the given fraction along the
 // great circle path between two coordinates. Fraction 0 returns the start point,
 // fraction 1 returns the end point. Coordinates are in degrees (latitude, longitude).
@@ -164,7 +286,7 @@ func GreatCircleIntermediatePoint(lat1, lon1, lat2, lon2, fraction float64) (flo
 	a := math.Sin(Δφ/2)*math.Sin(Δφ/2) +
 		math.Cos(φ1)*math.Cos(φ2)*
 			math.Sin(Δλ/2)*math.Sin(Δλ/2)
-	δ := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	δ := haversineAngle(a)
 	if δ == 0 {
 		return lat1, normalizeLongitude(lon1)
 	}
@@ -198,12 +320,60 @@ func GreatCirclePointAtDistance(lat1, lon1, lat2, lon2, distanceKm float64) (flo
 	return GreatCircleIntermediatePoint(lat1, lon1, lat2, lon2, distanceKm/total)
 }
 
+// GreatCircleDestination returns the point reached by traveling distanceKm along
+// a great circle from (lat, lon) with the given initial bearing in degrees.
+// Returns coordinates in degrees (latitude, longitude).
+func GreatCircleDestination(lat, lon, distanceKm, bearingDeg float64) (float64, float64) {
+	φ1 := toRadians(lat)
+	λ1 := toRadians(lon)
+	θ := toRadians(bearingDeg)
+	δ := distanceKm / EarthRadiusKm
+
+	φ2 := math.Asin(math.Sin(φ1)*math.Cos(δ) + math.Cos(φ1)*math.Sin(δ)*math.Cos(θ))
+	λ2 := λ1 + math.Atan2(math.Sin(θ)*math.Sin(δ)*math.Cos(φ1), math.Cos(δ)-math.Sin(φ1)*math.Sin(φ2))
+	return toDegrees(φ2), normalizeLongitude(toDegrees(λ2))
+}
+
 // GreatCirclePointAtSpeed returns the point after traveling at speedKmh for durationHours
 // along the great circle path between two coordinates.
 func GreatCirclePointAtSpeed(lat1, lon1, lat2, lon2, speedKmh, durationHours float64) (float64, float64) {
 	return GreatCirclePointAtDistance(lat1, lon1, lat2, lon2, speedKmh*durationHours)
 }
 
+// GreatCircleWaypointsByLongitude returns waypoints along the great circle path
+// between two coordinates at every stepLonDeg degrees of longitude, starting at
+// the first point and ending at the second. The latitude at each longitude is
+// found with the standard latitude-at-longitude (Clairaut) formula. Waypoints
+// are [longitude, latitude] Positions. Paths along a meridian, including those
+// between opposite meridians that run over a pole, or a non-positive step,
+// yield only the two endpoints.
+func GreatCircleWaypointsByLongitude(lat1, lon1, lat2, lon2, stepLonDeg float64) []Position {
+	start := Position{normalizeLongitude(lon1), lat1}
+	end := Position{normalizeLongitude(lon2), lat2}
+
+	Δlon := normalizeLongitude(lon2 - lon1)
+	if stepLonDeg <= 0 || Δlon == 0 || math.Abs(Δlon) == 180 || math.Abs(lat1) == 90 || math.Abs(lat2) == 90 {
+		return []Position{start, end}
+	}
+
+	φ1 := toRadians(lat1)
+	φ2 := toRadians(lat2)
+	λ1 := toRadians(lon1)
+	λ2 := toRadians(lon2)
+	denom := math.Cos(φ1) * math.Cos(φ2) * math.Sin(λ1-λ2)
+
+	step := math.Copysign(stepLonDeg, Δlon)
+	waypoints := []Position{start}
+	for offset := step; math.Abs(offset) < math.Abs(Δlon); offset += step {
+		λ := toRadians(lon1 + offset)
+		num := math.Sin(φ1)*math.Cos(φ2)*math.Sin(λ-λ2) -
+			math.Sin(φ2)*math.Cos(φ1)*math.Sin(λ-λ1)
+		φ := math.Atan(num / denom)
+		waypoints = append(waypoints, Position{normalizeLongitude(lon1 + offset), toDegrees(φ)})
+	}
+	return append(waypoints, end)
+}
+
 // GreatCircleDistanceMeters returns the great circle distance in meters.
 func GreatCircleDistanceMeters(lat1, lon1, lat2, lon2 float64) float64 {
 	return GreatCircleDistance(lat1, lon1, lat2, lon2) * MetersPerKm
@@ -214,6 +384,140 @@ func GreatCircleDistanceNauticalMiles(lat1, lon1, lat2, lon2 float64) float64 {
 	return GreatCircleDistance(lat1, lon1, lat2, lon2) / KmPerNauticalMile
 }
 
+// WithinRadius returns the indices, in order, of the candidates whose great
+// circle distance from center is at most radiusKm. Most far candidates are
+// ruled out first by comparing their latitude and longitude with the bounds of
+// the circle; the rest are compared by their haversine term against that of the
+// radius, without the square root and arctangent of GreatCircleDistance. When
+// the circle reaches a pole only the latitude bound is used. A negative radius
+// matches nothing.
+func WithinRadius(center Position, candidates []Position, radiusKm float64) []int {
+	if !(radiusKm >= 0) {
+		return nil
+	}
+	δ := radiusKm / EarthRadiusKm
+	var indices []int
+	if δ >= math.Pi {
+		for i := range candidates {
+			indices = append(indices, i)
+		}
+		return indices
+	}
+
+	lat0, lon0 := positionLatLon(center)
+	cosφ0 := math.Cos(toRadians(lat0))
+	// The bounds are widened a little so that rounding never rules out a
+	// candidate that the exact test would accept.
+	const slack = 1e-9
+	dLat := toDegrees(δ) + slack
+	dLon := 360.0
+	if lat0+dLat < 90 && lat0-dLat > -90 {
+		// The widest point of the circle in longitude, where its edge runs
+		// north-south.
+		dLon = toDegrees(math.Asin(math.Sin(δ)/cosφ0)) + slack
+	}
+	sinHalfδ := math.Sin(δ / 2)
+	limit := sinHalfδ * sinHalfδ
+
+	for i, p := range candidates {
+		lat, lon := positionLatLon(p)
+		if math.Abs(lat-lat0) > dLat || math.Abs(normalizeLongitude(lon-lon0)) > dLon {
+			continue
+		}
+		sinHalfΔφ := math.Sin(toRadians(lat-lat0) / 2)
+		sinHalfΔλ := math.Sin(toRadians(lon-lon0) / 2)
+		a := sinHalfΔφ*sinHalfΔφ + cosφ0*math.Cos(toRadians(lat))*sinHalfΔλ*sinHalfΔλ
+		if a <= limit {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// LatLon is a coordinate in degrees in latitude, longitude order.
+type LatLon struct {
+	Lat float64
+	Lon float64
+}
+
+// RouteDistance returns the great-circle length of the route through points in
+// order, in the requested unit. Fewer than two points give 0.
+func RouteDistance(points []LatLon, unit DistanceUnit) float64 {
+	var total float64
+	for i := 1; i < len(points); i++ {
+		total += GreatCircleDistance(points[i-1].Lat, points[i-1].Lon, points[i].Lat, points[i].Lon)
+	}
+	return ConvertDistanceFromKm(total, unit)
+}
+
+// SphericalTriangleArea returns the area in square kilometers of the spherical
+// triangle with corners a, b and c joined by great-circle arcs. It uses the
+// spherical excess: the sum of the interior angles minus π, times R².
+func SphericalTriangleArea(a, b, c LatLon) float64 {
+	angle := func(p, q, r LatLon) float64 {
+		return math.Abs(BearingDifference(
+			Bearing(p.Lat, p.Lon, q.Lat, q.Lon),
+			Bearing(p.Lat, p.Lon, r.Lat, r.Lon),
+		))
+	}
+	sum := toRadians(angle(a, b, c) + angle(b, c, a) + angle(c, a, b))
+	excess := math.Max(sum-math.Pi, 0)
+	return excess * EarthRadiusKm * EarthRadiusKm
+}
+
+// unitVector returns the Earth-centered unit vector of a position.
+func unitVector(p Position) [3]float64 {
+	φ := toRadians(p[1])
+	λ := toRadians(p[0])
+	return [3]float64{math.Cos(φ) * math.Cos(λ), math.Cos(φ) * math.Sin(λ), math.Sin(φ)}
+}
+
+// SphericalMean returns the average of points on the sphere: each point is
+// turned into a unit vector, the vectors are summed and the sum is converted
+// back to longitude and latitude, so points on either side of the antimeridian
+// average near it rather than near the prime meridian. Altitudes are averaged
+// arithmetically over the points that have one. An empty slice, or points
+// whose vectors cancel out, yield Position{0, 0}.
+func SphericalMean(points []Position) Position {
+	var x, y, z, alt float64
+	altCount := 0
+	for _, p := range points {
+		v := unitVector(p)
+		x += v[0]
+		y += v[1]
+		z += v[2]
+		if p.HasAlt() {
+			alt += p[2]
+			altCount++
+		}
+	}
+	if len(points) == 0 || math.Hypot(math.Hypot(x, y), z) < 1e-12 {
+		return Position{0, 0}
+	}
+	lat := toDegrees(math.Atan2(z, math.Hypot(x, y)))
+	lon := toDegrees(math.Atan2(y, x))
+	if altCount == 0 {
+		return Position{lon, lat}
+	}
+	return Position{lon, lat, alt / float64(altCount)}
+}
+
+// Distance3D returns the slant distance between two points at different altitudes,
+// combining the great circle ground distance with the vertical separation.
+// Altitudes are in meters. Returns distance in kilometers.
+func Distance3D(lat1, lon1, alt1Meters, lat2, lon2, alt2Meters float64) float64 {
+	ground := GreatCircleDistance(lat1, lon1, lat2, lon2)
+	vertical := (alt2Meters - alt1Meters) / MetersPerKm
+	return math.Hypot(ground, vertical)
+}
+
+// GreatCircleDistance3D is Distance3D under the name of its surface
+// counterpart: the great circle distance combined with the altitude delta.
+// Altitudes are in meters. Returns distance in kilometers.
+func GreatCircleDistance3D(lat1, lon1, alt1m, lat2, lon2, alt2m float64) float64 {
+	return Distance3D(lat1, lon1, alt1m, lat2, lon2, alt2m)
+}
+
 // RhumbLineDistance calculates the rhumb line (loxodrome) distance between two points.
 // A rhumb line is a path of constant bearing. Coordinates are in degrees (latitude, longitude).
 // Returns distance in kilometers.
@@ -271,6 +575,37 @@ func RhumbLineBearing(lat1, lon1, lat2, lon2 float64) float64 {
 	return normalizeBearingDegrees(toDegrees(θ))
 }
 
+// RhumbLineInverse returns the rhumb line distance in kilometers and the
+// constant bearing in degrees from point 1 to point 2, computing the shared
+// terms once. The results match RhumbLineDistance and RhumbLineBearing.
+func RhumbLineInverse(lat1, lon1, lat2, lon2 float64) (distanceKm, bearingDeg float64) {
+	φ1 := toRadians(lat1)
+	φ2 := toRadians(lat2)
+	Δφ := φ2 - φ1
+	Δλ := toRadians(lon2 - lon1)
+
+	// Handle crossing antimeridian
+	if math.Abs(Δλ) > math.Pi {
+		if Δλ > 0 {
+			Δλ = -(2*math.Pi - Δλ)
+		} else {
+			Δλ = 2*math.Pi + Δλ
+		}
+	}
+
+	Δψ := math.Log(math.Tan(φ2/2+math.Pi/4) / math.Tan(φ1/2+math.Pi/4))
+	var q float64
+	if math.Abs(Δψ) > 1e-12 {
+		q = Δφ / Δψ
+	} else {
+		q = math.Cos(φ1)
+	}
+
+	δ := math.Sqrt(Δφ*Δφ + q*q*Δλ*Δλ)
+	θ := math.Atan2(Δλ, Δψ)
+	return δ * EarthRadiusKm, normalizeBearingDegrees(toDegrees(θ))
+}
+
 // RhumbLineDestination returns the destination point after traveling along a rhumb line.
 // Bearing is in degrees from true north. Distance is in kilometers.
 // Returns coordinates in degrees (latitude, longitude).
@@ -301,6 +636,61 @@ func RhumbLineDestination(lat, lon, distanceKm, bearingDeg float64) (float64, fl
 	return toDegrees(φ2), normalizeLongitude(toDegrees(λ2))
 }
 
+// RhumbLineDestinationUnits is RhumbLineDestination with the distance given
+// in the requested unit. The bearing to follow between two points comes from
+// RhumbLineBearing.
+func RhumbLineDestinationUnits(lat, lon, distance float64, unit DistanceUnit, bearingDeg float64) (float64, float64) {
+	return RhumbLineDestination(lat, lon, ConvertDistanceToKm(distance, unit), bearingDeg)
+}
+
+// RhumbLineIntermediatePoint returns the point at the given fraction along the
+// rhumb line between two coordinates. Fraction 0 returns the start point,
+// fraction 1 returns the end point. Coordinates are in degrees (latitude, longitude).
+func RhumbLineIntermediatePoint(lat1, lon1, lat2, lon2, fraction float64) (float64, float64) {
+	if fraction <= 0 {
+		return lat1, normalizeLongitude(lon1)
+	}
+	if fraction >= 1 {
+		return lat2, normalizeLongitude(lon2)
+	}
+	distance := RhumbLineDistance(lat1, lon1, lat2, lon2)
+	bearing := RhumbLineBearing(lat1, lon1, lat2, lon2)
+	return RhumbLineDestination(lat1, lon1, distance*fraction, bearing)
+}
+
+// RhumbLineMidpoint returns the point halfway along the rhumb line between two
+// coordinates: the mean latitude, at the longitude where the loxodrome reaches
+// it. Points on the same parallel give the mean longitude. The shorter way
+// around the antimeridian is taken. Coordinates are in degrees (latitude,
+// longitude).
+func RhumbLineMidpoint(lat1, lon1, lat2, lon2 float64) (float64, float64) {
+	φ1 := toRadians(lat1)
+	φ2 := toRadians(lat2)
+	λ1 := toRadians(lon1)
+	λ2 := toRadians(lon2)
+	if math.Abs(λ2-λ1) > math.Pi {
+		if λ1 < λ2 {
+			λ1 += 2 * math.Pi
+		} else {
+			λ2 += 2 * math.Pi
+		}
+	}
+
+	φ3 := (φ1 + φ2) / 2
+	f1 := math.Tan(math.Pi/4 + φ1/2)
+	f2 := math.Tan(math.Pi/4 + φ2/2)
+	f3 := math.Tan(math.Pi/4 + φ3/2)
+
+	var λ3 float64
+	if math.Abs(math.Log(f2/f1)) < 1e-12 {
+		// Same parallel: the isometric latitudes are equal.
+		λ3 = (λ1 + λ2) / 2
+	} else {
+		λ3 = ((λ2-λ1)*math.Log(f3) + λ1*math.Log(f2) - λ2*math.Log(f1)) / math.Log(f2/f1)
+	}
+	return toDegrees(φ3), normalizeLongitude(toDegrees(λ3))
+}
+
 // RhumbLineDistanceUnits returns rhumb line distance in the requested unit.
 func RhumbLineDistanceUnits(lat1, lon1, lat2, lon2 float64, unit DistanceUnit) float64 {
 	return ConvertDistanceFromKm(RhumbLineDistance(lat1, lon1, lat2, lon2), unit)
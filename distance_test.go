@@ -2,6 +2,8 @@ package geo
 
 import (
 	"math"
+	"math/rand"
+	"reflect"
 	"testing"
 )
 
@@ -54,6 +56,112 @@ func TestGreatCircleDistance(t *testing.T) {
 	}
 }
 
+func TestGreatCircleDistanceAntipodes(t *testing.T) {
+	want := math.Pi * EarthRadiusKm
+	cases := [][4]float64{
+		{0, 0, 0, 180},
+		{90, 0, -90, 0},
+		{-85.46, -165.4, 85.46, 14.6},
+		{37.5, 127, -37.5, -53},
+	}
+	for _, c := range cases {
+		got := GreatCircleDistance(c[0], c[1], c[2], c[3])
+		if math.IsNaN(got) || math.Abs(got-want) > 1e-6 {
+			t.Errorf("GreatCircleDistance(%v) = %v, want %v", c, got, want)
+		}
+		if d, _, _ := GreatCircleInverse(c[0], c[1], c[2], c[3]); math.IsNaN(d) {
+			t.Errorf("GreatCircleInverse(%v) distance is NaN", c)
+		}
+	}
+}
+
+func TestDistance3D(t *testing.T) {
+	vertical := Distance3D(45.0, 7.0, 500.0, 45.0, 7.0, 1500.0)
+	if math.Abs(vertical-1.0) > 1e-12 {
+		t.Errorf("Distance3D() vertical = %v, want 1", vertical)
+	}
+
+	ground := GreatCircleDistance(40.7128, -74.0060, 51.5074, -0.1278)
+	flat := Distance3D(40.7128, -74.0060, 100.0, 51.5074, -0.1278, 100.0)
+	if math.Abs(flat-ground) > 1e-9 {
+		t.Errorf("Distance3D() level = %v, want %v", flat, ground)
+	}
+
+	slant := Distance3D(0.0, 0.0, 0.0, 0.0, 0.01, 10000.0)
+	expected := math.Sqrt(math.Pow(GreatCircleDistance(0.0, 0.0, 0.0, 0.01), 2) + 100)
+	if math.Abs(slant-expected) > 1e-9 {
+		t.Errorf("Distance3D() slant = %v, want %v", slant, expected)
+	}
+}
+
+func TestSphericalMean(t *testing.T) {
+	mean := SphericalMean([]Position{{179, 10}, {-179, -10}})
+	if math.Abs(math.Abs(mean[0])-180) > 1e-9 || math.Abs(mean[1]) > 1e-9 {
+		t.Errorf("SphericalMean() across antimeridian = %v, want [180 0]", mean)
+	}
+
+	mean = SphericalMean([]Position{{0, 0, 100}, {90, 0, 300}, {0, 90}})
+	wantLat := toDegrees(math.Atan2(1, math.Sqrt2))
+	if math.Abs(mean[0]-45) > 1e-9 || math.Abs(mean[1]-wantLat) > 1e-9 {
+		t.Errorf("SphericalMean() = %v, want [45 %v]", mean, wantLat)
+	}
+	// The position without an altitude is left out of the altitude mean.
+	if math.Abs(mean.Alt()-200) > 1e-9 {
+		t.Errorf("SphericalMean() altitude = %v, want 200", mean.Alt())
+	}
+	if mean = SphericalMean([]Position{{0, 0}, {1, 0}}); mean.HasAlt() {
+		t.Errorf("SphericalMean() without altitudes = %v, want no altitude", mean)
+	}
+
+	if got := SphericalMean(nil); !reflect.DeepEqual(got, Position{0, 0}) {
+		t.Errorf("SphericalMean(nil) = %v, want zero", got)
+	}
+	if got := SphericalMean([]Position{{0, 0}, {180, 0}}); !reflect.DeepEqual(got, Position{0, 0}) {
+		t.Errorf("SphericalMean(antipodes) = %v, want zero", got)
+	}
+}
+
+func TestGreatCircleDistance3D(t *testing.T) {
+	got := GreatCircleDistance3D(40.7128, -74.0060, 0, 51.5074, -0.1278, 11000)
+	want := Distance3D(40.7128, -74.0060, 0, 51.5074, -0.1278, 11000)
+	if got != want {
+		t.Errorf("GreatCircleDistance3D() = %v, want %v", got, want)
+	}
+	if got := GreatCircleDistance3D(0, 0, 1000, 0, 0, 4000); math.Abs(got-3) > 1e-12 {
+		t.Errorf("GreatCircleDistance3D() vertical = %v, want 3", got)
+	}
+}
+
+func TestSteeringCorrection(t *testing.T) {
+	// Track due east along the equator; the point is north, left of track.
+	xte, correction := SteeringCorrection(0, 0, 0, 10, 0.5, 2)
+	wantXTE := -GreatCircleDistance(0, 2, 0.5, 2)
+	if math.Abs(xte-wantXTE) > 1e-6 {
+		t.Errorf("cross-track = %v, want %v", xte, wantXTE)
+	}
+	if correction <= 0 {
+		t.Errorf("correction = %v, want a rightward (positive) turn", correction)
+	}
+	if want := BearingDifference(90, Bearing(0.5, 2, 0, 10)); math.Abs(correction-want) > 1e-6 {
+		t.Errorf("correction = %v, want %v", correction, want)
+	}
+
+	// Mirror image: right of track steers left.
+	xte, correction = SteeringCorrection(0, 0, 0, 10, -0.5, 2)
+	if xte <= 0 || correction >= 0 {
+		t.Errorf("right of track: cross-track = %v, correction = %v", xte, correction)
+	}
+
+	// On track there is nothing to correct.
+	xte, correction = SteeringCorrection(0, 0, 0, 10, 0, 4)
+	if math.Abs(xte) > 1e-9 || math.Abs(correction) > 1e-9 {
+		t.Errorf("on track: cross-track = %v, correction = %v", xte, correction)
+	}
+	if _, correction = SteeringCorrection(0, 0, 0, 10, 0, 10); correction != 0 {
+		t.Errorf("at destination: correction = %v, want 0", correction)
+	}
+}
+
 func TestRhumbLineDistance(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -121,6 +229,25 @@ func TestRhumbLineBearing(t *testing.T) {
 	}
 }
 
+func TestRhumbLineInverse(t *testing.T) {
+	cases := [][4]float64{
+		{51.127, 1.338, 50.964, 1.853},
+		{0, 0, 0, 10},
+		{40, -74, 51.5, -0.1},
+		{30, 175, 35, -170},
+		{-33.9, 151.2, -33.9, 18.4},
+		{10, 20, 10, 20},
+	}
+	for _, c := range cases {
+		dist, bearing := RhumbLineInverse(c[0], c[1], c[2], c[3])
+		wantDist := RhumbLineDistance(c[0], c[1], c[2], c[3])
+		wantBearing := RhumbLineBearing(c[0], c[1], c[2], c[3])
+		if dist != wantDist || bearing != wantBearing {
+			t.Errorf("RhumbLineInverse(%v) = (%v, %v), want (%v, %v)", c, dist, bearing, wantDist, wantBearing)
+		}
+	}
+}
+
 func TestRhumbLineDestination(t *testing.T) {
 	lat, lon := RhumbLineDestination(0.0, 0.0, 1000.0, 90.0)
 	expectedLon := toDegrees(1000.0 / EarthRadiusKm)
@@ -129,6 +256,71 @@ func TestRhumbLineDestination(t *testing.T) {
 	}
 }
 
+func TestRhumbLineDestinationSameMeridian(t *testing.T) {
+	// Due north or south the rhumb line is the meridian itself.
+	for _, bearing := range []float64{0, 180} {
+		lat, lon := RhumbLineDestination(10, -73.5, 2000, bearing)
+		if lon != -73.5 {
+			t.Errorf("bearing %v: longitude = %v, want -73.5", bearing, lon)
+		}
+		if want := 10 + math.Cos(toRadians(bearing))*toDegrees(2000/EarthRadiusKm); math.Abs(lat-want) > 1e-9 {
+			t.Errorf("bearing %v: latitude = %v, want %v", bearing, lat, want)
+		}
+	}
+
+	// Due east once around the 45th parallel comes back to the start.
+	around := 2 * math.Pi * EarthRadiusKm * math.Cos(toRadians(45))
+	lat, lon := RhumbLineDestination(45, 12, around, 90)
+	if math.Abs(lat-45) > 1e-9 || math.Abs(BearingDifference(lon, 12)) > 1e-9 {
+		t.Errorf("RhumbLineDestination() around the parallel = (%v, %v), want (45, 12)", lat, lon)
+	}
+
+	// Following the bearing for the distance from RhumbLineInverse arrives.
+	dist, bearing := RhumbLineInverse(51.5074, -0.1278, 40.7128, -74.0060)
+	lat, lon = RhumbLineDestination(51.5074, -0.1278, dist, bearing)
+	if math.Abs(lat-40.7128) > 1e-9 || math.Abs(lon+74.0060) > 1e-9 {
+		t.Errorf("RhumbLineDestination() = (%v, %v), want (40.7128, -74.0060)", lat, lon)
+	}
+}
+
+func TestRhumbLineDestinationUnits(t *testing.T) {
+	wantLat, wantLon := RhumbLineDestination(40, -70, 500, 63)
+	for _, unit := range []DistanceUnit{UnitKilometers, UnitMeters, UnitMiles, UnitNauticalMiles} {
+		lat, lon := RhumbLineDestinationUnits(40, -70, ConvertDistanceFromKm(500, unit), unit, 63)
+		if math.Abs(lat-wantLat) > 1e-9 || math.Abs(lon-wantLon) > 1e-9 {
+			t.Errorf("unit %v: RhumbLineDestinationUnits() = (%v, %v), want (%v, %v)", unit, lat, lon, wantLat, wantLon)
+		}
+	}
+}
+
+func TestRhumbLineMidpoint(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+	}{
+		{"london to new york", 51.5074, -0.1278, 40.7128, -74.0060},
+		{"southeast", -10, 20, -50, 60},
+		{"across antimeridian", 10, 170, 20, -160},
+	}
+	for _, tt := range tests {
+		lat, lon := RhumbLineMidpoint(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+		toMid := RhumbLineBearing(tt.lat1, tt.lon1, lat, lon)
+		toEnd := RhumbLineBearing(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+		if math.Abs(BearingDifference(toMid, toEnd)) > 1e-9 {
+			t.Errorf("%s: bearing to midpoint = %v, to end = %v", tt.name, toMid, toEnd)
+		}
+		wantLat, wantLon := RhumbLineIntermediatePoint(tt.lat1, tt.lon1, tt.lat2, tt.lon2, 0.5)
+		if math.Abs(lat-wantLat) > 1e-9 || math.Abs(BearingDifference(lon, wantLon)) > 1e-9 {
+			t.Errorf("%s: RhumbLineMidpoint() = (%v, %v), want (%v, %v)", tt.name, lat, lon, wantLat, wantLon)
+		}
+	}
+
+	lat, lon := RhumbLineMidpoint(30, 175, 30, -165)
+	if math.Abs(lat-30) > 1e-9 || math.Abs(lon+175) > 1e-9 {
+		t.Errorf("RhumbLineMidpoint() along parallel = (%v, %v), want (30, -175)", lat, lon)
+	}
+}
+
 func TestRhumbLineDistanceUnits(t *testing.T) {
 	km := RhumbLineDistance(0.0, 0.0, 0.0, 10.0)
 	nm := RhumbLineDistanceUnits(0.0, 0.0, 0.0, 10.0, UnitNauticalMiles)
@@ -265,6 +457,96 @@ func TestGreatCirclePointAtDistance(t *testing.T) {
 	})
 }
 
+func TestNormalizeLongitude(t *testing.T) {
+	cases := []struct {
+		lon, want float64
+	}{
+		{-0.1278, -0.1278},
+		{179.9999, 179.9999},
+		{190, -170},
+		{-190, 170},
+		{540, -180},
+		{180, -180},
+	}
+	for _, tc := range cases {
+		if got := normalizeLongitude(tc.lon); got != tc.want {
+			t.Errorf("normalizeLongitude(%v) = %v, want %v", tc.lon, got, tc.want)
+		}
+	}
+}
+
+func TestGreatCircleWaypointsByLongitude(t *testing.T) {
+	t.Run("mid-latitude crossing", func(t *testing.T) {
+		lat1, lon1 := 40.7128, -74.0060
+		lat2, lon2 := 51.5074, -0.1278
+
+		waypoints := GreatCircleWaypointsByLongitude(lat1, lon1, lat2, lon2, 10.0)
+		if len(waypoints) != 9 {
+			t.Fatalf("len(waypoints) = %v, want 9", len(waypoints))
+		}
+		first, last := waypoints[0], waypoints[len(waypoints)-1]
+		if !reflect.DeepEqual(first, Position{lon1, lat1}) || !reflect.DeepEqual(last, Position{lon2, lat2}) {
+			t.Errorf("endpoints = %v, %v", first, last)
+		}
+
+		// Each interior waypoint must lie on the great circle: walking the path
+		// with GreatCircleIntermediatePoint until the same longitude gives the
+		// same latitude.
+		total := GreatCircleDistance(lat1, lon1, lat2, lon2)
+		for _, wp := range waypoints[1 : len(waypoints)-1] {
+			if math.Abs(wp[0]-(lon1+10*math.Round((wp[0]-lon1)/10))) > 1e-9 {
+				t.Errorf("waypoint longitude %v not on a 10° step", wp[0])
+			}
+			lo, hi := 0.0, 1.0
+			for i := 0; i < 60; i++ {
+				mid := (lo + hi) / 2
+				_, lon := GreatCircleIntermediatePoint(lat1, lon1, lat2, lon2, mid)
+				if lon < wp[0] {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			lat, _ := GreatCircleIntermediatePoint(lat1, lon1, lat2, lon2, lo)
+			if math.Abs(lat-wp[1]) > 1e-6 {
+				t.Errorf("waypoint %v: latitude %v, want %v", wp, wp[1], lat)
+			}
+			_, _, crossTrackKm, _ := GreatCircleProject(lat1, lon1, lat2, lon2, wp[1], wp[0])
+			if math.Abs(crossTrackKm) > 1e-6*total {
+				t.Errorf("waypoint %v is %v km off the route", wp, crossTrackKm)
+			}
+		}
+	})
+
+	t.Run("crosses antimeridian", func(t *testing.T) {
+		waypoints := GreatCircleWaypointsByLongitude(10.0, 170.0, 20.0, -170.0, 5.0)
+		wantLons := []float64{170, 175, 180, -175, -170}
+		if len(waypoints) != len(wantLons) {
+			t.Fatalf("len(waypoints) = %v, want %v", len(waypoints), len(wantLons))
+		}
+		for i, want := range wantLons {
+			if math.Abs(math.Abs(waypoints[i][0])-math.Abs(want)) > 1e-9 {
+				t.Errorf("waypoint %d longitude = %v, want %v", i, waypoints[i][0], want)
+			}
+		}
+	})
+
+	t.Run("meridian", func(t *testing.T) {
+		waypoints := GreatCircleWaypointsByLongitude(0.0, 10.0, 50.0, 10.0, 1.0)
+		if len(waypoints) != 2 {
+			t.Errorf("len(waypoints) = %v, want 2", len(waypoints))
+		}
+	})
+
+	t.Run("over a pole", func(t *testing.T) {
+		waypoints := GreatCircleWaypointsByLongitude(10.0, 0.0, 20.0, 180.0, 1.0)
+		want := []Position{{0, 10}, {-180, 20}}
+		if !reflect.DeepEqual(waypoints, want) {
+			t.Errorf("waypoints = %v, want %v", waypoints, want)
+		}
+	})
+}
+
 func TestGreatCirclePointAtSpeed(t *testing.T) {
 	lat1, lon1 := 34.0522, -118.2437
 	lat2, lon2 := 51.5074, -0.1278
@@ -317,6 +599,42 @@ func TestGreatCircleProject(t *testing.T) {
 }
 
 func TestGreatCircleProjectToSegment(t *testing.T) {
+	// An eastbound segment along the equator: north is to the left of it and
+	// south to the right.
+	t.Run("left of segment is negative", func(t *testing.T) {
+		projLat, projLon, crossTrackKm, alongTrackKm := GreatCircleProjectToSegment(0, 0, 0, 30, 5, 10)
+		if math.Abs(projLat) > 1e-9 || math.Abs(projLon-10) > 1e-9 {
+			t.Errorf("projected = (%v, %v), want (0, 10)", projLat, projLon)
+		}
+		want := GreatCircleDistance(0, 10, 5, 10)
+		if math.Abs(crossTrackKm+want) > 1e-6 {
+			t.Errorf("cross-track = %v, want %v", crossTrackKm, -want)
+		}
+		if want := GreatCircleDistance(0, 0, 0, 10); math.Abs(alongTrackKm-want) > 1e-6 {
+			t.Errorf("along-track = %v, want %v", alongTrackKm, want)
+		}
+	})
+
+	t.Run("right of segment is positive", func(t *testing.T) {
+		projLat, projLon, crossTrackKm, _ := GreatCircleProjectToSegment(0, 0, 0, 30, -5, 20)
+		if math.Abs(projLat) > 1e-9 || math.Abs(projLon-20) > 1e-9 {
+			t.Errorf("projected = (%v, %v), want (0, 20)", projLat, projLon)
+		}
+		if want := GreatCircleDistance(0, 20, -5, 20); math.Abs(crossTrackKm-want) > 1e-6 {
+			t.Errorf("cross-track = %v, want %v", crossTrackKm, want)
+		}
+	})
+
+	t.Run("clamped distance is positive on either side", func(t *testing.T) {
+		for _, latP := range []float64{5, -5} {
+			_, _, crossTrackKm, alongTrackKm := GreatCircleProjectToSegment(0, 0, 0, 30, latP, -10)
+			want := GreatCircleDistance(0, 0, latP, -10)
+			if math.Abs(crossTrackKm-want) > 1e-6 || alongTrackKm != 0 {
+				t.Errorf("lat %v: cross-track, along-track = %v, %v, want %v, 0", latP, crossTrackKm, alongTrackKm, want)
+			}
+		}
+	})
+
 	t.Run("clamps before start", func(t *testing.T) {
 		lat1, lon1 := 0.0, 0.0
 		lat2, lon2 := 0.0, 30.0
@@ -354,3 +672,234 @@ func TestGreatCircleProjectToSegment(t *testing.T) {
 		}
 	})
 }
+
+func TestWithinRadius(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	candidates := make([]Position, 2000)
+	for i := range candidates {
+		candidates[i] = Position{rng.Float64()*360 - 180, rng.Float64()*180 - 90}
+	}
+	// Put a few candidates on the awkward spots: the poles, the antimeridian
+	// and the centers themselves.
+	candidates = append(candidates, Position{0, 90}, Position{0, -90}, Position{180, 10}, Position{-180, 10})
+
+	centers := []Position{{0, 0}, {179.9, 10}, {-170, -60}, {30, 89.5}, {10, 45}}
+	for _, center := range centers {
+		candidates = append(candidates, center)
+	}
+	for _, center := range centers {
+		for _, radius := range []float64{0, 100, 1500, 8000, 20000, 30000} {
+			got := map[int]bool{}
+			for _, i := range WithinRadius(center, candidates, radius) {
+				got[i] = true
+			}
+			for i, p := range candidates {
+				d := GreatCircleDistance(center[1], center[0], p[1], p[0])
+				switch {
+				case d < radius-1e-6 && !got[i]:
+					t.Errorf("center %v radius %v: %v at %.3f km missing", center, radius, p, d)
+				case d > radius+1e-6 && got[i]:
+					t.Errorf("center %v radius %v: %v at %.3f km included", center, radius, p, d)
+				}
+			}
+			if radius == 0 && len(got) == 0 {
+				t.Errorf("center %v radius 0: want the center itself", center)
+			}
+		}
+	}
+
+	if got := WithinRadius(Position{0, 0}, candidates, -1); got != nil {
+		t.Errorf("WithinRadius() with a negative radius = %v, want nil", got)
+	}
+	if got := WithinRadius(Position{0, 0}, nil, 100); got != nil {
+		t.Errorf("WithinRadius() without candidates = %v, want nil", got)
+	}
+}
+
+func TestRouteDistance(t *testing.T) {
+	cities := []LatLon{
+		{40.7128, -74.0060},  // New York
+		{34.0522, -118.2437}, // Los Angeles
+		{41.8781, -87.6298},  // Chicago
+		{29.7604, -95.3698},  // Houston
+		{33.4484, -112.0740}, // Phoenix
+	}
+	want := 0.0
+	for i := 1; i < len(cities); i++ {
+		want += GreatCircleDistance(cities[i-1].Lat, cities[i-1].Lon, cities[i].Lat, cities[i].Lon)
+	}
+	// NY-LA 3936 km, LA-Chicago 2805 km, Chicago-Houston 1515 km,
+	// Houston-Phoenix 1634 km.
+	if math.Abs(want-9890) > 10 {
+		t.Fatalf("reference route = %v km, want about 9890 km", want)
+	}
+
+	if got := RouteDistance(cities, UnitKilometers); math.Abs(got-want) > 1e-9 {
+		t.Errorf("RouteDistance(km) = %v, want %v", got, want)
+	}
+	if got := RouteDistance(cities, UnitMiles); math.Abs(got-want/KmPerMile) > 1e-9 {
+		t.Errorf("RouteDistance(mi) = %v, want %v", got, want/KmPerMile)
+	}
+	if got := RouteDistance(cities[:1], UnitKilometers); got != 0 {
+		t.Errorf("RouteDistance(single point) = %v, want 0", got)
+	}
+
+	coords := make([]Position, len(cities))
+	for i, c := range cities {
+		coords[i] = Position{c.Lon, c.Lat}
+	}
+	got, err := RouteDistanceGeoJSON(NewLineString(coords), UnitMeters)
+	if err != nil {
+		t.Fatalf("RouteDistanceGeoJSON() error = %v", err)
+	}
+	if math.Abs(got-want*MetersPerKm) > 1e-6 {
+		t.Errorf("RouteDistanceGeoJSON(m) = %v, want %v", got, want*MetersPerKm)
+	}
+}
+
+func TestGreatCircleInverse(t *testing.T) {
+	cases := [][4]float64{
+		{40.7128, -74.0060, 51.5074, -0.1278},
+		{0, 0, 0, 90},
+		{-33.9, 151.2, 35.7, 139.7},
+		{60, 170, 62, -170},
+		{0, 0, 0, 180},
+	}
+	for _, c := range cases {
+		dist, initial, final := GreatCircleInverse(c[0], c[1], c[2], c[3])
+		wantDist := GreatCircleDistance(c[0], c[1], c[2], c[3])
+		wantInitial := Bearing(c[0], c[1], c[2], c[3])
+		wantFinal := finalBearing(c[0], c[1], c[2], c[3])
+		if math.Abs(dist-wantDist) > 1e-9 ||
+			math.Abs(BearingDifference(wantInitial, initial)) > 1e-9 ||
+			math.Abs(BearingDifference(wantFinal, final)) > 1e-9 {
+			t.Errorf("GreatCircleInverse(%v) = (%v, %v, %v), want (%v, %v, %v)",
+				c, dist, initial, final, wantDist, wantInitial, wantFinal)
+		}
+	}
+}
+
+func TestBearingDifference(t *testing.T) {
+	tests := []struct {
+		from, to, want float64
+	}{
+		{0, 90, 90},
+		{90, 0, -90},
+		{350, 10, 20},
+		{10, 350, -20},
+		{0, 180, 180},
+		{180, 0, 180},
+		{45, 45, 0},
+	}
+	for _, tt := range tests {
+		if got := BearingDifference(tt.from, tt.to); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("BearingDifference(%v, %v) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestSphericalTriangleArea(t *testing.T) {
+	// One octant of the sphere: three right-angle corners.
+	octant := SphericalTriangleArea(
+		LatLon{Lat: 0, Lon: 0},
+		LatLon{Lat: 0, Lon: 90},
+		LatLon{Lat: 90, Lon: 0},
+	)
+	want := 4 * math.Pi * EarthRadiusKm * EarthRadiusKm / 8
+	if math.Abs(octant-want)/want > 1e-9 {
+		t.Errorf("octant area = %v, want %v", octant, want)
+	}
+
+	// A small triangle is close to its planar area.
+	small := SphericalTriangleArea(
+		LatLon{Lat: 0, Lon: 0},
+		LatLon{Lat: 0, Lon: 0.1},
+		LatLon{Lat: 0.1, Lon: 0},
+	)
+	leg := GreatCircleDistance(0, 0, 0, 0.1)
+	if planar := leg * leg / 2; math.Abs(small-planar)/planar > 1e-3 {
+		t.Errorf("small triangle area = %v, want about %v", small, planar)
+	}
+
+	if got := SphericalTriangleArea(LatLon{0, 0}, LatLon{0, 10}, LatLon{0, 20}); got > 1e-6 {
+		t.Errorf("degenerate triangle area = %v, want 0", got)
+	}
+}
+
+func TestGreatCircleDestination(t *testing.T) {
+	lat, lon := GreatCircleDestination(51.5074, -0.1278, 500, 45)
+	if d := GreatCircleDistance(51.5074, -0.1278, lat, lon); math.Abs(d-500) > 1e-6 {
+		t.Errorf("distance to destination = %v, want 500", d)
+	}
+	if b := Bearing(51.5074, -0.1278, lat, lon); math.Abs(b-45) > 1e-6 {
+		t.Errorf("bearing to destination = %v, want 45", b)
+	}
+	lat, lon = GreatCircleDestination(0, 0, EarthRadiusKm*math.Pi/2, 90)
+	if math.Abs(lat) > 1e-9 || math.Abs(lon-90) > 1e-9 {
+		t.Errorf("quarter circle east = (%v, %v), want (0, 90)", lat, lon)
+	}
+}
+
+func TestGreatCircleMaxLatitude(t *testing.T) {
+	// A transpolar-ish route from Scandinavia to the Bering Strait.
+	lat1, lon1, lat2, lon2 := 60.0, 10.0, 65.0, -165.0
+	maxLat, lonAtMax := GreatCircleMaxLatitude(lat1, lon1, lat2, lon2)
+
+	// Clairaut: cos(maxLat) = |sin(bearing) cos(lat1)|.
+	bearing := toRadians(Bearing(lat1, lon1, lat2, lon2))
+	want := toDegrees(math.Acos(math.Abs(math.Sin(bearing) * math.Cos(toRadians(lat1)))))
+	if math.Abs(maxLat-want) > 1e-9 {
+		t.Errorf("max latitude = %v, want %v", maxLat, want)
+	}
+	// Sampling the path finds the same peak.
+	best, bestLon := -90.0, 0.0
+	for i := 0; i <= 10000; i++ {
+		lat, lon := GreatCircleIntermediatePoint(lat1, lon1, lat2, lon2, float64(i)/10000)
+		if lat > best {
+			best, bestLon = lat, lon
+		}
+	}
+	if math.Abs(maxLat-best) > 1e-4 || GreatCircleDistance(maxLat, lonAtMax, best, bestLon) > 1 {
+		t.Errorf("vertex (%v, %v), sampled peak (%v, %v)", maxLat, lonAtMax, best, bestLon)
+	}
+	if maxLat < 80 {
+		t.Errorf("max latitude = %v, want a high-latitude vertex", maxLat)
+	}
+
+	// Southern hemisphere: the path bulges south, so its highest points are
+	// the endpoints.
+	if lat, lon := GreatCircleMaxLatitude(-40, 0, -40, 90); lat != -40 || lon != 0 {
+		t.Errorf("southern route = (%v, %v), want the start (-40, 0)", lat, lon)
+	}
+	// Crossing the equator northward past nothing higher than the end.
+	if lat, lon := GreatCircleMaxLatitude(-60, 0, 20, 120); lat != 20 || lon != 120 {
+		t.Errorf("equator-crossing route = (%v, %v), want the endpoint (20, 120)", lat, lon)
+	}
+	// Vertex outside the path: the endpoint with the higher latitude.
+	if lat, lon := GreatCircleMaxLatitude(10, 0, 30, 5); lat != 30 || lon != 5 {
+		t.Errorf("got (%v, %v), want the endpoint (30, 5)", lat, lon)
+	}
+	if lat, lon := GreatCircleMaxLatitude(0, 10, 0, 50); lat != 0 || lon != 10 {
+		t.Errorf("equatorial route = (%v, %v), want (0, 10)", lat, lon)
+	}
+}
+
+func TestGreatCircleDeviation(t *testing.T) {
+	// Along 60°N the great circle bulges poleward to its vertex halfway.
+	high := GreatCircleDeviation(60, 0, 60, 90)
+	maxLat, _ := GreatCircleMaxLatitude(60, 0, 60, 90)
+	if want := GreatCircleDistance(60, 45, maxLat, 45); math.Abs(high-want) > 1e-6 {
+		t.Errorf("GreatCircleDeviation(high) = %v, want %v", high, want)
+	}
+	short := GreatCircleDeviation(0, 0, 0.5, 1)
+	if short >= 0.1 || high < 100*short {
+		t.Errorf("deviations: high-latitude %v km, short equatorial %v km", high, short)
+	}
+	if d := GreatCircleDeviation(10, 20, 50, 20); d > 1e-9 {
+		t.Errorf("GreatCircleDeviation(meridian) = %v, want 0", d)
+	}
+	// Across the antimeridian the short way round, not through Greenwich.
+	if d, want := GreatCircleDeviation(60, 170, 60, -170), GreatCircleDeviation(60, -10, 60, 10); math.Abs(d-want) > 1e-6 {
+		t.Errorf("GreatCircleDeviation(antimeridian) = %v, want %v", d, want)
+	}
+}
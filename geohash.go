@@ -1,6 +1,8 @@
 package geo
 
 import (
+	"fmt"
+	"math"
 	"strings"
 )
 
@@ -105,6 +107,34 @@ func GeohashDecode(geohash string) (lat, lon, latErr, lonErr float64) {
 	return
 }
 
+// SnapToGeohash returns the center of the geohash cell of the given precision
+// that contains the coordinate, so that nearby points snap to the same place.
+func SnapToGeohash(lat, lon float64, precision int) (float64, float64) {
+	cLat, cLon, _, _ := GeohashDecode(Geohash(lat, lon, precision))
+	return cLat, cLon
+}
+
+// GeohashRange returns the key range [start, end) that holds the geohash and
+// every longer geohash inside its cell, for prefix scans in sorted key-value
+// stores. The base32 alphabet is in ASCII order, so end is simply the next
+// geohash of the same length, with trailing 'z's carried over: the range of
+// "u0z" ends at "u1". An end of "" means the range is unbounded above, which
+// is the case for the empty geohash and for ones made only of 'z'.
+func GeohashRange(hash string) (start, end string) {
+	for i := len(hash) - 1; i >= 0; i-- {
+		if hash[i] == 'z' {
+			continue
+		}
+		idx := strings.IndexByte(base32, hash[i])
+		next := hash[i] + 1
+		if idx >= 0 {
+			next = base32[idx+1]
+		}
+		return hash, hash[:i] + string(next)
+	}
+	return hash, ""
+}
+
 // GeohashNeighbors returns the 8 neighboring geohashes around the given geohash.
 // Returns neighbors in order: N, NE, E, SE, S, SW, W, NW
 func GeohashNeighbors(geohash string) [8]string {
@@ -125,3 +155,67 @@ func GeohashNeighbors(geohash string) [8]string {
 
 	return neighbors
 }
+
+// geohashDirections names the entries of GeohashNeighbors in order.
+var geohashDirections = [8]string{"n", "ne", "e", "se", "s", "sw", "w", "nw"}
+
+// GeohashNeighborsMap returns the 8 neighboring geohashes keyed by direction:
+// "n", "ne", "e", "se", "s", "sw", "w" and "nw".
+func GeohashNeighborsMap(hash string) map[string]string {
+	neighbors := GeohashNeighbors(hash)
+	m := make(map[string]string, len(neighbors))
+	for i, dir := range geohashDirections {
+		m[dir] = neighbors[i]
+	}
+	return m
+}
+
+// GeohashNeighbor returns the neighboring geohash in one direction, named as
+// in GeohashNeighborsMap. Directions are case-insensitive.
+func GeohashNeighbor(hash, direction string) (string, error) {
+	dir := strings.ToLower(direction)
+	for i, d := range geohashDirections {
+		if d == dir {
+			return GeohashNeighbors(hash)[i], nil
+		}
+	}
+	return "", fmt.Errorf("unknown geohash direction %q", direction)
+}
+
+// GeohashStepToward returns the neighbor of hash whose center is closest, by
+// great-circle distance, to the target coordinate. If the target already lies
+// in hash, hash is returned, so stepping repeatedly ends in the target's cell
+// at the same precision.
+func GeohashStepToward(hash string, targetLat, targetLon float64) string {
+	if Geohash(targetLat, targetLon, len(hash)) == hash {
+		return hash
+	}
+	best, bestDist := hash, math.Inf(1)
+	for _, n := range GeohashNeighbors(hash) {
+		lat, lon, _, _ := GeohashDecode(n)
+		if d := GreatCircleDistance(lat, lon, targetLat, targetLon); d < bestDist {
+			best, bestDist = n, d
+		}
+	}
+	return best
+}
+
+// GeohashForBBox returns the longest geohash whose cell contains the whole
+// box: the longest common prefix of the 12-character geohashes of its
+// corners. Geohash cells are lon/lat rectangles, so a cell holding the
+// southwest and northeast corners holds the rest of the box too. A box that
+// straddles a cell boundary near the top of the hierarchy, such as one across
+// the equator or the prime meridian, gets an empty string, as does an empty
+// box.
+func GeohashForBBox(box BBox) string {
+	if box.IsEmpty() {
+		return ""
+	}
+	sw := Geohash(box.MinLat, box.MinLon, 12)
+	ne := Geohash(box.MaxLat, box.MaxLon, 12)
+	n := 0
+	for n < len(sw) && sw[n] == ne[n] {
+		n++
+	}
+	return sw[:n]
+}
@@ -1,6 +1,7 @@
 package geo
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -118,6 +119,58 @@ func TestGeohashRoundTrip(t *testing.T) {
 	}
 }
 
+func TestSnapToGeohash(t *testing.T) {
+	lat1, lon1 := SnapToGeohash(57.64911, 10.40744, 6)
+	lat2, lon2 := SnapToGeohash(57.64905, 10.40752, 6)
+	if lat1 != lat2 || lon1 != lon2 {
+		t.Errorf("nearby points snapped to (%v, %v) and (%v, %v)", lat1, lon1, lat2, lon2)
+	}
+	wantLat, wantLon, _, _ := GeohashDecode("u4pruy")
+	if lat1 != wantLat || lon1 != wantLon {
+		t.Errorf("SnapToGeohash() = (%v, %v), want center of u4pruy (%v, %v)", lat1, lon1, wantLat, wantLon)
+	}
+
+	lat3, lon3 := SnapToGeohash(57.66, 10.40744, 6)
+	if lat3 == lat1 && lon3 == lon1 {
+		t.Error("distant point snapped to the same cell")
+	}
+}
+
+func TestGeohashRange(t *testing.T) {
+	tests := []struct {
+		hash, start, end string
+	}{
+		{"u4pruyd", "u4pruyd", "u4pruye"},
+		{"u09", "u09", "u0b"},
+		{"u0z", "u0z", "u1"},
+		{"bzz", "bzz", "c"},
+		{"zz", "zz", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		start, end := GeohashRange(tt.hash)
+		if start != tt.start || end != tt.end {
+			t.Errorf("GeohashRange(%q) = [%q, %q), want [%q, %q)", tt.hash, start, end, tt.start, tt.end)
+		}
+	}
+
+	parent := Geohash(57.64911, 10.40744, 5)
+	start, end := GeohashRange(parent)
+	for _, precision := range []int{5, 6, 9, 12} {
+		child := Geohash(57.64911, 10.40744, precision)
+		if child < start || child >= end {
+			t.Errorf("child %q outside range [%q, %q) of %q", child, start, end, parent)
+		}
+	}
+	// The neighbors at higher precision fall outside.
+	for _, n := range GeohashNeighbors(parent) {
+		child := n + "0000"
+		if child >= start && child < end {
+			t.Errorf("neighbor cell %q inside range [%q, %q) of %q", child, start, end, parent)
+		}
+	}
+}
+
 func TestGeohashNeighbors(t *testing.T) {
 	geohash := "9q8yy"
 	neighbors := GeohashNeighbors(geohash)
@@ -154,3 +207,84 @@ func abs(x float64) float64 {
 	}
 	return x
 }
+
+func TestGeohashNeighborsMap(t *testing.T) {
+	hash := "u4pruyd"
+	arr := GeohashNeighbors(hash)
+	m := GeohashNeighborsMap(hash)
+	keys := []string{"n", "ne", "e", "se", "s", "sw", "w", "nw"}
+	if len(m) != len(keys) {
+		t.Fatalf("map has %d entries, want %d", len(m), len(keys))
+	}
+	for i, k := range keys {
+		if m[k] != arr[i] {
+			t.Errorf("m[%q] = %q, want %q", k, m[k], arr[i])
+		}
+	}
+	if m["n"] != arr[0] {
+		t.Errorf("north = %q, want %q", m["n"], arr[0])
+	}
+
+	got, err := GeohashNeighbor(hash, "SW")
+	if err != nil || got != arr[5] {
+		t.Errorf("GeohashNeighbor(SW) = %q, %v; want %q", got, err, arr[5])
+	}
+	if _, err := GeohashNeighbor(hash, "up"); err == nil {
+		t.Error("expected error for unknown direction")
+	}
+}
+
+func TestGeohashStepToward(t *testing.T) {
+	targetLat, targetLon := 48.8584, 2.2945
+	for _, start := range []string{Geohash(52.52, 13.405, 4), Geohash(40.4168, -3.7038, 4), Geohash(48.0, 2.0, 4)} {
+		want := Geohash(targetLat, targetLon, 4)
+		hash := start
+		for steps := 0; hash != want; steps++ {
+			if steps > 100 {
+				t.Fatalf("from %s: no convergence, stuck at %s", start, hash)
+			}
+			next := GeohashStepToward(hash, targetLat, targetLon)
+			if next == hash {
+				t.Fatalf("from %s: stopped at %s, want %s", start, hash, want)
+			}
+			isNeighbor := false
+			for _, n := range GeohashNeighbors(hash) {
+				isNeighbor = isNeighbor || n == next
+			}
+			if !isNeighbor {
+				t.Fatalf("step from %s to %s is not to a neighbor", hash, next)
+			}
+			hash = next
+		}
+		if got := GeohashStepToward(hash, targetLat, targetLon); got != hash {
+			t.Errorf("step from the target cell = %s, want %s", got, hash)
+		}
+	}
+}
+
+func TestGeohashForBBox(t *testing.T) {
+	// A box of about 20 m around the center of a 7-character cell in
+	// Copenhagen.
+	cLat, cLon, _, _ := GeohashDecode("u3buw8k")
+	tiny := BBox{MinLon: cLon - 0.0001, MinLat: cLat - 0.0001, MaxLon: cLon + 0.0001, MaxLat: cLat + 0.0001}
+	hash := GeohashForBBox(tiny)
+	if hash != "u3buw8k" {
+		t.Errorf("GeohashForBBox(tiny) = %q, want %q", hash, "u3buw8k")
+	}
+	lat, lon, latErr, lonErr := GeohashDecode(hash)
+	if tiny.MinLat < lat-latErr || tiny.MaxLat > lat+latErr || tiny.MinLon < lon-lonErr || tiny.MaxLon > lon+lonErr {
+		t.Errorf("cell %q does not contain %+v", hash, tiny)
+	}
+
+	// Roughly Australia.
+	continent := BBox{MinLon: 113, MinLat: -44, MaxLon: 154, MaxLat: -10}
+	if hash := GeohashForBBox(continent); len(hash) > 1 {
+		t.Errorf("GeohashForBBox(continent) = %q, want at most 1 character", hash)
+	}
+	if hash := GeohashForBBox(continent); !strings.HasPrefix(Geohash(-25, 135, 12), hash) {
+		t.Errorf("GeohashForBBox(continent) = %q, not a prefix of a point inside", hash)
+	}
+	if hash := GeohashForBBox(BBox{MinLon: 1, MinLat: 1, MaxLon: 0, MaxLat: 0}); hash != "" {
+		t.Errorf("GeohashForBBox(empty) = %q, want empty", hash)
+	}
+}
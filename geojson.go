@@ -1,55 +1,178 @@
 package geo
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 )
 
-// Position represents a GeoJSON coordinate [longitude, latitude].
-type Position [2]float64
+// Position represents a GeoJSON coordinate [longitude, latitude] with an
+// optional altitude in meters as the third element. A position has an
+// altitude exactly when it has more than two elements, so an altitude of 0 is
+// kept and encoded. Helpers that return input vertices keep their altitude;
+// newly computed positions have none unless stated otherwise.
+//
+// Geometry operations compare and index positions by longitude and latitude
+// only: vertices that differ just in altitude count as the same point.
+type Position []float64
+
+// Lon returns the longitude.
+func (p Position) Lon() float64 { return p[0] }
+
+// Lat returns the latitude.
+func (p Position) Lat() float64 { return p[1] }
+
+// Alt returns the altitude in meters, 0 when unset.
+func (p Position) Alt() float64 {
+	if len(p) < 3 {
+		return 0
+	}
+	return p[2]
+}
+
+// HasAlt reports whether the position has an altitude.
+func (p Position) HasAlt() bool { return len(p) > 2 }
+
+// MarshalJSON encodes the position as [lon, lat] or, when it has an altitude,
+// [lon, lat, alt].
+func (p Position) MarshalJSON() ([]byte, error) {
+	if len(p) < 2 {
+		return nil, fmt.Errorf("position must have at least 2 elements, got %d", len(p))
+	}
+	if p.HasAlt() {
+		return json.Marshal([3]float64{p[0], p[1], p[2]})
+	}
+	return json.Marshal([2]float64{p[0], p[1]})
+}
+
+// UnmarshalJSON decodes a GeoJSON position with two or more elements. A third
+// element is kept as the altitude; further elements are ignored.
+func (p *Position) UnmarshalJSON(data []byte) error {
+	var values []float64
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	if len(values) < 2 {
+		return fmt.Errorf("position must have at least 2 elements, got %d", len(values))
+	}
+	if len(values) > 3 {
+		values = values[:3:3]
+	}
+	*p = Position(values)
+	return nil
+}
+
+// lonLat returns the longitude and latitude of p as a comparable value, for
+// matching vertices and as a map key.
+func lonLat(p Position) [2]float64 {
+	return [2]float64{p[0], p[1]}
+}
+
+// samePosition reports whether a and b have the same longitude and latitude.
+func samePosition(a, b Position) bool {
+	return a[0] == b[0] && a[1] == b[1]
+}
+
+// withAltBetween returns a new position at lon, lat with the altitude a
+// fraction f of the way from p to q, if either has one.
+func withAltBetween(lon, lat float64, p, q Position, f float64) Position {
+	if p.HasAlt() || q.HasAlt() {
+		return Position{lon, lat, p.Alt() + f*(q.Alt()-p.Alt())}
+	}
+	return Position{lon, lat}
+}
+
+// withAlt returns a new position at lon, lat carrying the altitude of from,
+// if it has one.
+func withAlt(lon, lat float64, from Position) Position {
+	if from.HasAlt() {
+		return Position{lon, lat, from[2]}
+	}
+	return Position{lon, lat}
+}
 
 // Point is a GeoJSON Point geometry.
 type Point struct {
-	Type        string   `json:"type"`
-	Coordinates Position `json:"coordinates"`
+	Type        string    `json:"type"`
+	Coordinates Position  `json:"coordinates"`
+	BBox        []float64 `json:"bbox,omitempty"`
 }
 
 // LineString is a GeoJSON LineString geometry.
 type LineString struct {
 	Type        string     `json:"type"`
 	Coordinates []Position `json:"coordinates"`
+	BBox        []float64  `json:"bbox,omitempty"`
 }
 
 // Polygon is a GeoJSON Polygon geometry.
 type Polygon struct {
 	Type        string       `json:"type"`
 	Coordinates [][]Position `json:"coordinates"`
+	BBox        []float64    `json:"bbox,omitempty"`
+}
+
+// MultiPoint is a GeoJSON MultiPoint geometry.
+type MultiPoint struct {
+	Type        string     `json:"type"`
+	Coordinates []Position `json:"coordinates"`
+	BBox        []float64  `json:"bbox,omitempty"`
 }
 
 // MultiLineString is a GeoJSON MultiLineString geometry.
 type MultiLineString struct {
 	Type        string       `json:"type"`
 	Coordinates [][]Position `json:"coordinates"`
+	BBox        []float64    `json:"bbox,omitempty"`
 }
 
 // MultiPolygon is a GeoJSON MultiPolygon geometry.
 type MultiPolygon struct {
 	Type        string         `json:"type"`
 	Coordinates [][][]Position `json:"coordinates"`
+	BBox        []float64      `json:"bbox,omitempty"`
+}
+
+// GeometryCollection is a GeoJSON GeometryCollection. Geometries holds values
+// of the geometry types of this package, or user types implementing Geometry.
+type GeometryCollection struct {
+	Type       string        `json:"type"`
+	Geometries []interface{} `json:"geometries"`
+	BBox       []float64     `json:"bbox,omitempty"`
 }
 
 // Feature is a GeoJSON Feature.
+//
+// ID holds the optional "id" member: a string, or a json.Number for numeric ids
+// decoded from JSON so they survive a round trip unchanged. Extra holds any
+// other top-level members (foreign members such as "crs"), which are decoded
+// and encoded as is.
 type Feature struct {
-	Type       string                 `json:"type"`
-	Geometry   interface{}            `json:"geometry"`
-	Properties map[string]interface{} `json:"properties,omitempty"`
+	Type       string                     `json:"type"`
+	ID         interface{}                `json:"id,omitempty"`
+	Geometry   interface{}                `json:"geometry"`
+	Properties map[string]interface{}     `json:"properties,omitempty"`
+	BBox       []float64                  `json:"bbox,omitempty"`
+	Extra      map[string]json.RawMessage `json:"-"`
+}
+
+// featureMembers are the Feature members with a field of their own.
+var featureMembers = map[string]bool{
+	"type":       true,
+	"id":         true,
+	"geometry":   true,
+	"properties": true,
+	"bbox":       true,
 }
 
 // FeatureCollection is a GeoJSON FeatureCollection.
 type FeatureCollection struct {
 	Type     string    `json:"type"`
 	Features []Feature `json:"features"`
+	BBox     []float64 `json:"bbox,omitempty"`
 }
 
 // NewPoint creates a GeoJSON Point.
@@ -57,36 +180,342 @@ func NewPoint(lon, lat float64) Point {
 	return Point{Type: "Point", Coordinates: Position{lon, lat}}
 }
 
-// NewLineString creates a GeoJSON LineString.
+// NewPointLatLon creates a GeoJSON Point from a latitude and longitude given
+// in that order, as in most CSV files. NewPoint takes them the GeoJSON way
+// round, longitude first.
+func NewPointLatLon(lat, lon float64) Point {
+	return NewPoint(lon, lat)
+}
+
+// PositionFromLatLon returns the Position of a latitude and longitude given in
+// that order. Positions store longitude first.
+func PositionFromLatLon(lat, lon float64) Position {
+	return Position{lon, lat}
+}
+
+// NewLineString creates a GeoJSON LineString. As with the other geometry
+// constructors, nil coordinates are replaced by an empty slice so the geometry
+// marshals "coordinates":[] rather than null.
 func NewLineString(coords []Position) LineString {
+	if coords == nil {
+		coords = []Position{}
+	}
 	return LineString{Type: "LineString", Coordinates: coords}
 }
 
 // NewPolygon creates a GeoJSON Polygon.
 func NewPolygon(coords [][]Position) Polygon {
+	if coords == nil {
+		coords = [][]Position{}
+	}
 	return Polygon{Type: "Polygon", Coordinates: coords}
 }
 
+// NewMultiPoint creates a GeoJSON MultiPoint.
+func NewMultiPoint(coords []Position) MultiPoint {
+	if coords == nil {
+		coords = []Position{}
+	}
+	return MultiPoint{Type: "MultiPoint", Coordinates: coords}
+}
+
 // NewMultiLineString creates a GeoJSON MultiLineString.
 func NewMultiLineString(coords [][]Position) MultiLineString {
+	if coords == nil {
+		coords = [][]Position{}
+	}
 	return MultiLineString{Type: "MultiLineString", Coordinates: coords}
 }
 
 // NewMultiPolygon creates a GeoJSON MultiPolygon.
 func NewMultiPolygon(coords [][][]Position) MultiPolygon {
+	if coords == nil {
+		coords = [][][]Position{}
+	}
 	return MultiPolygon{Type: "MultiPolygon", Coordinates: coords}
 }
 
+// NewGeometryCollection creates a GeoJSON GeometryCollection.
+func NewGeometryCollection(geometries []interface{}) GeometryCollection {
+	if geometries == nil {
+		geometries = []interface{}{}
+	}
+	return GeometryCollection{Type: "GeometryCollection", Geometries: geometries}
+}
+
 // NewFeature creates a GeoJSON Feature.
 func NewFeature(geom interface{}) Feature {
 	return Feature{Type: "Feature", Geometry: geom}
 }
 
-// NewFeatureCollection creates a GeoJSON FeatureCollection.
+// NewFeatureCollection creates a GeoJSON FeatureCollection. A nil slice is
+// replaced by an empty one so the collection marshals "features":[] rather
+// than null.
 func NewFeatureCollection(features []Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
 	return FeatureCollection{Type: "FeatureCollection", Features: features}
 }
 
+// ParseGeoJSON decodes a GeoJSON object into the matching type of this
+// package: Point, MultiPoint, LineString, Polygon, MultiLineString,
+// MultiPolygon, GeometryCollection, Feature or FeatureCollection. Values, not
+// pointers, are returned, so the result can be passed straight to helpers such
+// as GeoJSONCenter. Altitudes in positions are kept.
+func ParseGeoJSON(data []byte) (interface{}, error) {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	switch header.Type {
+	case "Feature":
+		var f Feature
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, err
+		}
+		return f, nil
+	case "FeatureCollection":
+		var fc FeatureCollection
+		if err := json.Unmarshal(data, &fc); err != nil {
+			return nil, err
+		}
+		return fc, nil
+	default:
+		return parseGeometry(header.Type, data)
+	}
+}
+
+// Decode reads one GeoJSON object from r and returns it as ParseGeoJSON does,
+// with the type chosen by its "type" member. Only the first JSON value in r is
+// decoded.
+func Decode(r io.Reader) (interface{}, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	return ParseGeoJSON(raw)
+}
+
+// Encode writes obj to w as GeoJSON followed by a newline. obj may be any
+// geometry, Feature or FeatureCollection of this package, or a pointer to one.
+func Encode(w io.Writer, obj interface{}) error {
+	if _, err := geometryOf(obj); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(obj)
+}
+
+// MarshalJSON encodes a Feature together with its foreign members. Members in
+// Extra never replace the standard members.
+func (f Feature) MarshalJSON() ([]byte, error) {
+	type feature Feature // drops the methods to avoid recursion
+	data, err := json.Marshal(feature(f))
+	if err != nil || len(f.Extra) == 0 {
+		return data, err
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	for k, v := range f.Extra {
+		if !featureMembers[k] {
+			members[k] = v
+		}
+	}
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON decodes a Feature, turning its geometry into the concrete
+// geometry type named by its "type" member instead of a generic map.
+// A null geometry decodes to nil. A numeric id is kept as a json.Number, and
+// unrecognized members are collected in Extra.
+func (f *Feature) UnmarshalJSON(data []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	var raw struct {
+		Type       string                 `json:"type"`
+		Properties map[string]interface{} `json:"properties"`
+		BBox       []float64              `json:"bbox"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var geom interface{}
+	if g := members["geometry"]; len(g) > 0 && !bytes.Equal(g, []byte("null")) {
+		parsed, err := decodeGeometry(g)
+		if err != nil {
+			return err
+		}
+		geom = parsed
+	}
+
+	var id interface{}
+	if rawID, ok := members["id"]; ok && !bytes.Equal(rawID, []byte("null")) {
+		dec := json.NewDecoder(bytes.NewReader(rawID))
+		dec.UseNumber()
+		if err := dec.Decode(&id); err != nil {
+			return err
+		}
+		switch id.(type) {
+		case string, json.Number:
+		default:
+			return fmt.Errorf("feature id must be a string or number, got %s", rawID)
+		}
+	}
+
+	var extra map[string]json.RawMessage
+	for k, v := range members {
+		if featureMembers[k] {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]json.RawMessage)
+		}
+		extra[k] = v
+	}
+
+	f.Type = raw.Type
+	f.ID = id
+	f.Geometry = geom
+	f.Properties = raw.Properties
+	f.BBox = raw.BBox
+	f.Extra = extra
+	return nil
+}
+
+// PropertyString returns the property key when it holds a string. It reports
+// false when the property is missing or holds anything else, numbers included.
+func (f Feature) PropertyString(key string) (string, bool) {
+	s, ok := f.Properties[key].(string)
+	return s, ok
+}
+
+// PropertyFloat returns the property key as a float64 when it holds a number
+// of any Go numeric type or a json.Number. It reports false when the property
+// is missing or holds anything else; numeric strings are not converted.
+func (f Feature) PropertyFloat(key string) (float64, bool) {
+	return numericValue(f.Properties[key])
+}
+
+// PropertyInt returns the property key as an int when it holds a whole number
+// that fits in an int. Numbers decoded from JSON as float64 qualify when they
+// have no fractional part, so 3.0 gives 3 but 3.5 reports false, as do
+// missing properties and values that are not numbers.
+func (f Feature) PropertyInt(key string) (int, bool) {
+	switch n := f.Properties[key].(type) {
+	case int:
+		return n, true
+	case int64:
+		if n >= math.MinInt && n <= math.MaxInt {
+			return int(n), true
+		}
+		return 0, false
+	case uint64:
+		if n <= math.MaxInt {
+			return int(n), true
+		}
+		return 0, false
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			if i >= math.MinInt && i <= math.MaxInt {
+				return int(i), true
+			}
+			return 0, false
+		}
+	}
+	v, ok := numericValue(f.Properties[key])
+	// -MinInt is a power of two, so it converts to float64 exactly.
+	if !ok || v != math.Trunc(v) || v < math.MinInt || v >= -math.MinInt {
+		return 0, false
+	}
+	return int(v), true
+}
+
+// UnmarshalJSON decodes a GeometryCollection, turning each member into the
+// concrete geometry type named by its "type" member.
+func (gc *GeometryCollection) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type       string            `json:"type"`
+		Geometries []json.RawMessage `json:"geometries"`
+		BBox       []float64         `json:"bbox"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var geometries []interface{}
+	for _, g := range raw.Geometries {
+		parsed, err := decodeGeometry(g)
+		if err != nil {
+			return err
+		}
+		geometries = append(geometries, parsed)
+	}
+	*gc = GeometryCollection{Type: raw.Type, Geometries: geometries, BBox: raw.BBox}
+	return nil
+}
+
+// decodeGeometry decodes a GeoJSON geometry object into the type named by its
+// "type" member.
+func decodeGeometry(data []byte) (interface{}, error) {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	return parseGeometry(header.Type, data)
+}
+
+func parseGeometry(typ string, data []byte) (interface{}, error) {
+	var (
+		geom interface{}
+		err  error
+	)
+	switch typ {
+	case "Point":
+		var g Point
+		err = json.Unmarshal(data, &g)
+		geom = g
+	case "MultiPoint":
+		var g MultiPoint
+		err = json.Unmarshal(data, &g)
+		geom = g
+	case "LineString":
+		var g LineString
+		err = json.Unmarshal(data, &g)
+		geom = g
+	case "Polygon":
+		var g Polygon
+		err = json.Unmarshal(data, &g)
+		geom = g
+	case "MultiLineString":
+		var g MultiLineString
+		err = json.Unmarshal(data, &g)
+		geom = g
+	case "MultiPolygon":
+		var g MultiPolygon
+		err = json.Unmarshal(data, &g)
+		geom = g
+	case "GeometryCollection":
+		var g GeometryCollection
+		err = json.Unmarshal(data, &g)
+		geom = g
+	default:
+		return nil, fmt.Errorf("unsupported geojson type %q", typ)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return geom, nil
+}
+
 func positionLatLon(p Position) (lat, lon float64) {
 	return p[1], p[0]
 }
@@ -95,15 +524,21 @@ func pointFromLatLon(lat, lon float64) Point {
 	return NewPoint(lon, lat)
 }
 
+// positionPoint returns a Point at p, keeping its altitude.
+func positionPoint(p Position) Point {
+	return Point{Type: "Point", Coordinates: p}
+}
+
 // LineStringPointAtDistance returns a Point at a specified distance along the LineString.
 // Distance is in kilometers. If distance is <= 0, the start point is returned.
 // If distance exceeds the line length, the end point is returned.
+// Altitude is interpolated linearly between the surrounding vertices.
 func LineStringPointAtDistance(line LineString, distanceKm float64) (Point, error) {
 	if len(line.Coordinates) < 2 {
 		return Point{}, errors.New("linestring must have at least 2 coordinates")
 	}
 	if distanceKm <= 0 {
-		return pointFromLatLon(positionLatLon(line.Coordinates[0])), nil
+		return positionPoint(line.Coordinates[0]), nil
 	}
 
 	remaining := distanceKm
@@ -116,13 +551,119 @@ func LineStringPointAtDistance(line LineString, distanceKm float64) (Point, erro
 		if remaining <= seg {
 			f := remaining / seg
 			lat, lon := GreatCircleIntermediatePoint(lat1, lon1, lat2, lon2, f)
-			return pointFromLatLon(lat, lon), nil
+			return positionPoint(withAltBetween(lon, lat, start, end, f)), nil
 		}
 		remaining -= seg
 	}
 
-	last := line.Coordinates[len(line.Coordinates)-1]
-	return pointFromLatLon(positionLatLon(last)), nil
+	return positionPoint(line.Coordinates[len(line.Coordinates)-1]), nil
+}
+
+// LineSliceAlong returns the part of line between startKm and stopKm measured
+// along it from the first coordinate. The distances are clamped to the line.
+// The slice starts and ends at interpolated points, as LineStringPointAtDistance
+// places them, and keeps the original vertices in between; a cut that falls
+// exactly on a vertex uses the vertex itself.
+func LineSliceAlong(line LineString, startKm, stopKm float64) (LineString, error) {
+	cum, err := CumulativeDistances(line, UnitKilometers)
+	if err != nil {
+		return LineString{}, err
+	}
+	if startKm > stopKm {
+		return LineString{}, fmt.Errorf("start distance %v exceeds stop distance %v", startKm, stopKm)
+	}
+	return lineSlice(line.Coordinates, cum, startKm, stopKm), nil
+}
+
+// LineChunk splits line into consecutive pieces segmentLengthKm long, the last
+// one shorter, using LineSliceAlong. Each piece ends where the next begins. A
+// line no longer than segmentLengthKm gives a single copy of itself.
+func LineChunk(line LineString, segmentLengthKm float64) ([]LineString, error) {
+	if !(segmentLengthKm > 0) {
+		return nil, fmt.Errorf("segment length must be positive, got %v", segmentLengthKm)
+	}
+	cum, err := CumulativeDistances(line, UnitKilometers)
+	if err != nil {
+		return nil, err
+	}
+	total := cum[len(cum)-1]
+	if total <= segmentLengthKm {
+		return []LineString{NewLineString(append([]Position(nil), line.Coordinates...))}, nil
+	}
+	var chunks []LineString
+	for i := 0; float64(i)*segmentLengthKm < total; i++ {
+		start := float64(i) * segmentLengthKm
+		chunks = append(chunks, lineSlice(line.Coordinates, cum, start, start+segmentLengthKm))
+	}
+	return chunks, nil
+}
+
+// lineSlice implements LineSliceAlong given the cumulative distance of every
+// vertex.
+func lineSlice(coords []Position, cum []float64, startKm, stopKm float64) LineString {
+	total := cum[len(cum)-1]
+	startKm = math.Max(0, math.Min(startKm, total))
+	stopKm = math.Max(0, math.Min(stopKm, total))
+
+	out := []Position{pointAlong(coords, cum, startKm)}
+	for i, d := range cum {
+		if d > startKm && d < stopKm {
+			out = append(out, coords[i])
+		}
+	}
+	return NewLineString(append(out, pointAlong(coords, cum, stopKm)))
+}
+
+// pointAlong returns the position distanceKm along the line, which must lie
+// within [0, length]. A distance at a vertex returns that vertex.
+func pointAlong(coords []Position, cum []float64, distanceKm float64) Position {
+	for i := 0; i < len(coords)-1; i++ {
+		if distanceKm == cum[i] {
+			return coords[i]
+		}
+		if distanceKm < cum[i+1] {
+			start, end := coords[i], coords[i+1]
+			lat1, lon1 := positionLatLon(start)
+			lat2, lon2 := positionLatLon(end)
+			f := (distanceKm - cum[i]) / (cum[i+1] - cum[i])
+			lat, lon := GreatCircleIntermediatePoint(lat1, lon1, lat2, lon2, f)
+			return withAltBetween(lon, lat, start, end, f)
+		}
+	}
+	return coords[len(coords)-1]
+}
+
+// SegmentBearings returns the initial great-circle bearing in degrees of each
+// segment of the line, one per segment.
+func SegmentBearings(line LineString) []float64 {
+	if len(line.Coordinates) < 2 {
+		return nil
+	}
+	bearings := make([]float64, len(line.Coordinates)-1)
+	for i := range bearings {
+		lat1, lon1 := positionLatLon(line.Coordinates[i])
+		lat2, lon2 := positionLatLon(line.Coordinates[i+1])
+		bearings[i] = Bearing(lat1, lon1, lat2, lon2)
+	}
+	return bearings
+}
+
+// TurnAngles returns the signed turn in degrees at each interior vertex of the
+// line: the difference between the bearing on arrival at the vertex and the
+// bearing of the next segment. Right turns are positive and left turns
+// negative; a straight continuation gives 0.
+func TurnAngles(line LineString) []float64 {
+	if len(line.Coordinates) < 3 {
+		return nil
+	}
+	outgoing := SegmentBearings(line)
+	turns := make([]float64, len(line.Coordinates)-2)
+	for i := range turns {
+		lat1, lon1 := positionLatLon(line.Coordinates[i])
+		lat2, lon2 := positionLatLon(line.Coordinates[i+1])
+		turns[i] = BearingDifference(finalBearing(lat1, lon1, lat2, lon2), outgoing[i+1])
+	}
+	return turns
 }
 
 // GeoJSONBearing returns the great-circle bearing between two GeoJSON Points.
@@ -133,6 +674,23 @@ func GeoJSONBearing(start, end Point) float64 {
 	return Bearing(lat1, lon1, lat2, lon2)
 }
 
+// PositionBearing returns the great-circle bearing from a to b, taking GeoJSON
+// [lon, lat] positions. Bearing is in degrees from true north, in the range
+// [0, 360).
+func PositionBearing(a, b Position) float64 {
+	lat1, lon1 := positionLatLon(a)
+	lat2, lon2 := positionLatLon(b)
+	return Bearing(lat1, lon1, lat2, lon2)
+}
+
+// PositionDistance returns the great-circle distance between GeoJSON [lon, lat]
+// positions in the requested unit. Altitude is ignored.
+func PositionDistance(a, b Position, unit DistanceUnit) float64 {
+	lat1, lon1 := positionLatLon(a)
+	lat2, lon2 := positionLatLon(b)
+	return ConvertDistanceFromKm(GreatCircleDistance(lat1, lon1, lat2, lon2), unit)
+}
+
 // GeoJSONRhumbBearing returns the rhumb line bearing between two GeoJSON Points.
 // Bearing is in degrees from true north, in the range [0, 360).
 func GeoJSONRhumbBearing(start, end Point) float64 {
@@ -157,33 +715,28 @@ func GeoJSONRhumbDistance(start, end Point, unit DistanceUnit) float64 {
 }
 
 // GeoJSONCenter returns the bbox center of all coordinates in a Feature or FeatureCollection.
+// The bbox is the one from ComputeBBox, so for geometries that cross the
+// antimeridian the center lies on the antimeridian side, e.g. a line from 170
+// to -176 gives a center at longitude 177, and so it does for points that
+// straddle it: points at 179 and -179 give a center at longitude ±180. Wide
+// geometries that do not cross keep the center of their plain longitude range.
 func GeoJSONCenter(obj interface{}) (Point, error) {
-	positions, err := collectPositions(obj)
+	g, err := geometryOf(obj)
 	if err != nil {
 		return Point{}, err
 	}
-	if len(positions) == 0 {
-		return Point{}, errors.New("no coordinates found")
+	if err := checkMembers(g); err != nil {
+		return Point{}, err
 	}
-
-	minLon, maxLon := positions[0][0], positions[0][0]
-	minLat, maxLat := positions[0][1], positions[0][1]
-	for _, p := range positions[1:] {
-		if p[0] < minLon {
-			minLon = p[0]
-		}
-		if p[0] > maxLon {
-			maxLon = p[0]
-		}
-		if p[1] < minLat {
-			minLat = p[1]
-		}
-		if p[1] > maxLat {
-			maxLat = p[1]
-		}
+	bbox, err := ComputeBBox(g)
+	if err != nil {
+		return Point{}, err
 	}
-
-	return NewPoint((minLon+maxLon)/2, (minLat+maxLat)/2), nil
+	west, south, east, north := bbox[0], bbox[1], bbox[2], bbox[3]
+	if west > east {
+		east += 360
+	}
+	return NewPoint(normalizeLongitude((west+east)/2), (south+north)/2), nil
 }
 
 // GeoJSONCenterOfMass returns a center-of-mass point.
@@ -208,75 +761,237 @@ func GeoJSONCenterOfMass(obj interface{}) (Point, error) {
 	}
 }
 
+// WeightedCentroid returns the mean of the Point features of fc weighted by
+// the numeric property weightField, such as a population count. Like
+// SphericalMean it averages on the sphere, so points on either side of the
+// antimeridian average near it, and it weights altitudes the same way.
+// Features that are not Points, or whose weight is missing, not a number or
+// not positive, are skipped. An error is returned when no feature has a
+// positive weight or the weighted points cancel out.
+func WeightedCentroid(fc FeatureCollection, weightField string) (Point, error) {
+	var x, y, z, alt, altTotal, total float64
+	for _, f := range fc.Features {
+		geom, err := geometryOf(f.Geometry)
+		if err != nil {
+			continue
+		}
+		pt, ok := geom.(Point)
+		if !ok {
+			continue
+		}
+		w, ok := numericValue(f.Properties[weightField])
+		if !ok || !(w > 0) || math.IsInf(w, 1) {
+			continue
+		}
+		v := unitVector(pt.Coordinates)
+		x += w * v[0]
+		y += w * v[1]
+		z += w * v[2]
+		if pt.Coordinates.HasAlt() {
+			alt += w * pt.Coordinates[2]
+			altTotal += w
+		}
+		total += w
+	}
+	if total == 0 {
+		return Point{}, fmt.Errorf("no point features with a positive %q weight", weightField)
+	}
+	if math.Hypot(math.Hypot(x, y), z) < 1e-12*total {
+		return Point{}, errors.New("weighted points cancel out")
+	}
+	lat := toDegrees(math.Atan2(z, math.Hypot(x, y)))
+	lon := toDegrees(math.Atan2(y, x))
+	if altTotal == 0 {
+		return NewPoint(lon, lat), nil
+	}
+	return positionPoint(Position{lon, lat, alt / altTotal}), nil
+}
+
+// numericValue converts a property value holding a number to float64.
+func numericValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
 // GeoJSONPointOnSurface returns a Point guaranteed to lie on the feature's surface.
 func GeoJSONPointOnSurface(obj interface{}) (Point, error) {
-	switch g := obj.(type) {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return Point{}, err
+	}
+	switch g := geom.(type) {
 	case Point:
 		return g, nil
-	case *Point:
-		if g == nil {
-			return Point{}, errors.New("nil point")
-		}
-		return *g, nil
 	case LineString:
 		return lineMidpoint(g)
-	case *LineString:
-		if g == nil {
-			return Point{}, errors.New("nil linestring")
-		}
-		return lineMidpoint(*g)
 	case Polygon:
 		return polygonPointOnSurface(g)
-	case *Polygon:
-		if g == nil {
-			return Point{}, errors.New("nil polygon")
-		}
-		return polygonPointOnSurface(*g)
 	case MultiLineString:
 		return multiLinePointOnSurface(g)
-	case *MultiLineString:
-		if g == nil {
-			return Point{}, errors.New("nil multilinestring")
-		}
-		return multiLinePointOnSurface(*g)
 	case MultiPolygon:
 		return multiPolygonPointOnSurface(g)
-	case *MultiPolygon:
-		if g == nil {
-			return Point{}, errors.New("nil multipolygon")
-		}
-		return multiPolygonPointOnSurface(*g)
 	case Feature:
 		return GeoJSONPointOnSurface(g.Geometry)
-	case *Feature:
-		if g == nil {
-			return Point{}, errors.New("nil feature")
-		}
-		return GeoJSONPointOnSurface(g.Geometry)
 	case FeatureCollection:
 		return featureCollectionPointOnSurface(g)
-	case *FeatureCollection:
-		if g == nil {
-			return Point{}, errors.New("nil featurecollection")
-		}
-		return featureCollectionPointOnSurface(*g)
 	default:
-		return Point{}, fmt.Errorf("unsupported geojson type %T", obj)
+		return nearestVertexToCenter(g)
 	}
 }
 
+// Circle returns a Polygon approximating the circle of points radiusKm from
+// center, measured along great circles, with steps vertices. The ring is
+// counterclockwise and closed. Fewer than 3 steps uses 64. Circles that reach
+// a pole or cross the antimeridian are not split.
+func Circle(center Point, radiusKm float64, steps int) Polygon {
+	if steps < 3 {
+		steps = 64
+	}
+	lat, lon := positionLatLon(center.Coordinates)
+	ring := make([]Position, steps+1)
+	for i := 0; i < steps; i++ {
+		bearing := -360 * float64(i) / float64(steps)
+		dLat, dLon := GreatCircleDestination(lat, lon, radiusKm, bearing)
+		ring[i] = Position{dLon, dLat}
+	}
+	ring[steps] = ring[0]
+	return NewPolygon([][]Position{ring})
+}
+
+// Isochrone returns the ring of points reachable from center in the given
+// number of hours at speedKmh, traveling along great circles. It is a Circle
+// with radius speedKmh·hours.
+func Isochrone(center Point, speedKmh, hours float64, steps int) Polygon {
+	return Circle(center, speedKmh*hours, steps)
+}
+
 // GreatCircleGeoJSON returns a great-circle route as a LineString or MultiLineString.
 // If the path crosses the antimeridian, a MultiLineString is returned.
 // If start and end are the same, a LineString with duplicate coordinates is returned.
+// An npoints below 2 means 2, the endpoints alone.
 func GreatCircleGeoJSON(start, end Point, npoints int) (interface{}, error) {
-	if npoints <= 0 {
+	return GreatCircleGeoJSONWithOptions(start, end, GreatCircleGeoJSONOptions{NPoints: npoints})
+}
+
+// GreatCircleGeoJSONOptions sets how GreatCircleGeoJSONWithOptions samples a
+// route.
+type GreatCircleGeoJSONOptions struct {
+	// MaxSegmentKm, if positive, caps the length of every segment; the route
+	// gets as many evenly spaced points as that takes.
+	MaxSegmentKm float64
+	// NPoints is the least number of points, endpoints included. Values below
+	// 2 mean 2.
+	NPoints int
+}
+
+// maxRoutePoints bounds the number of points of a sampled route, so a tiny
+// spacing gives an error rather than exhausting memory.
+const maxRoutePoints = 1 << 20
+
+// GreatCircleGeoJSONWithOptions is GreatCircleGeoJSON with the number of
+// points taken from opts: at least opts.NPoints, and more if that is needed to
+// keep every segment within opts.MaxSegmentKm. The points are evenly spaced
+// along the route, which is split at the antimeridian in the same way.
+// Coordinates that are not finite, and antipodal endpoints, which have no
+// unique great circle between them, give an error.
+func GreatCircleGeoJSONWithOptions(start, end Point, opts GreatCircleGeoJSONOptions) (interface{}, error) {
+	if math.IsNaN(opts.MaxSegmentKm) || math.IsInf(opts.MaxSegmentKm, 0) {
+		return nil, fmt.Errorf("invalid maximum segment length %v", opts.MaxSegmentKm)
+	}
+	lat1, lon1, lat2, lon2, err := greatCircleEndpoints(start, end)
+	if err != nil {
+		return nil, err
+	}
+	npoints := opts.NPoints
+	if npoints < 2 {
+		npoints = 2
+	}
+	if opts.MaxSegmentKm > 0 {
+		segments := math.Ceil(GreatCircleDistance(lat1, lon1, lat2, lon2) / opts.MaxSegmentKm)
+		if segments+1 > maxRoutePoints {
+			return nil, fmt.Errorf("route would have more than %d points", maxRoutePoints)
+		}
+		if int(segments)+1 > npoints {
+			npoints = int(segments) + 1
+		}
+	}
+	if npoints > maxRoutePoints {
+		return nil, fmt.Errorf("route would have more than %d points", maxRoutePoints)
+	}
+
+	if samePosition(start.Coordinates, end.Coordinates) {
+		coords := make([]Position, npoints)
+		for i := 0; i < npoints; i++ {
+			coords[i] = start.Coordinates
+		}
+		return NewLineString(coords), nil
+	}
+
+	coords := greatCircleCoordsByNPoints(lat1, lon1, lat2, lon2, npoints)
+	return splitAntimeridian(coords)
+}
+
+// greatCircleEndpoints returns the latitudes and longitudes of the ends of a
+// great-circle route, or an error when they do not define one.
+func greatCircleEndpoints(start, end Point) (lat1, lon1, lat2, lon2 float64, err error) {
+	for _, p := range []Position{start.Coordinates, end.Coordinates} {
+		for _, v := range p[:2] {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return 0, 0, 0, 0, fmt.Errorf("invalid coordinates %v", p)
+			}
+		}
+	}
+	lat1, lon1 = positionLatLon(start.Coordinates)
+	lat2, lon2 = positionLatLon(end.Coordinates)
+	if math.Pi-angularDistanceRad(lat1, lon1, lat2, lon2) < 1e-9 {
+		return 0, 0, 0, 0, errors.New("antipodal points have no unique great circle")
+	}
+	return lat1, lon1, lat2, lon2, nil
+}
+
+// RhumbLineGeoJSON returns a constant-bearing (rhumb line) route sampled at
+// npoints positions as a LineString or MultiLineString. Rhumb lines are straight
+// on Mercator maps. If the path crosses the antimeridian, a MultiLineString is
+// returned. If start and end are the same, a LineString with duplicate
+// coordinates is returned.
+func RhumbLineGeoJSON(start, end Point, npoints int) (interface{}, error) {
+	if npoints < 2 {
 		npoints = 2
 	}
 
 	startPos := start.Coordinates
 	endPos := end.Coordinates
 
-	if startPos == endPos {
+	if samePosition(startPos, endPos) {
 		coords := make([]Position, npoints)
 		for i := 0; i < npoints; i++ {
 			coords[i] = startPos
@@ -287,35 +1002,187 @@ func GreatCircleGeoJSON(start, end Point, npoints int) (interface{}, error) {
 	lat1, lon1 := positionLatLon(startPos)
 	lat2, lon2 := positionLatLon(endPos)
 
-	coords := greatCircleCoordsByNPoints(lat1, lon1, lat2, lon2, npoints)
+	coords := make([]Position, npoints)
+	for i := 0; i < npoints; i++ {
+		f := float64(i) / float64(npoints-1)
+		lat, lon := RhumbLineIntermediatePoint(lat1, lon1, lat2, lon2, f)
+		coords[i] = Position{lon, lat}
+	}
 	return splitAntimeridian(coords)
 }
 
 // GreatCircleGeoJSONByDistance returns a great-circle route split by distance steps.
 // Distance is in kilometers. If the path crosses the antimeridian, a MultiLineString
 // is returned. If start and end are the same, a LineString with two duplicate points
-// is returned.
+// is returned. Like GreatCircleGeoJSONWithOptions it rejects coordinates that are
+// not finite and antipodal endpoints.
 func GreatCircleGeoJSONByDistance(start, end Point, distanceKm float64) (interface{}, error) {
-	if distanceKm <= 0 {
+	if !(distanceKm > 0) || math.IsInf(distanceKm, 1) {
 		return nil, errors.New("distance must be greater than 0")
 	}
+	lat1, lon1, lat2, lon2, err := greatCircleEndpoints(start, end)
+	if err != nil {
+		return nil, err
+	}
 
 	startPos := start.Coordinates
 	endPos := end.Coordinates
 
-	if startPos == endPos {
+	if samePosition(startPos, endPos) {
 		return NewLineString([]Position{startPos, endPos}), nil
 	}
 
-	lat1, lon1 := positionLatLon(startPos)
-	lat2, lon2 := positionLatLon(endPos)
-
+	if GreatCircleDistance(lat1, lon1, lat2, lon2)/distanceKm+1 > maxRoutePoints {
+		return nil, fmt.Errorf("route would have more than %d points", maxRoutePoints)
+	}
 	coords := greatCircleCoordsByDistance(lat1, lon1, lat2, lon2, distanceKm)
 	return splitAntimeridian(coords)
 }
 
+// LineStringLength returns the great-circle length of a LineString in the
+// requested unit.
+func LineStringLength(line LineString, unit DistanceUnit) (float64, error) {
+	km, err := lineStringLengthKm(line)
+	if err != nil {
+		return 0, err
+	}
+	return ConvertDistanceFromKm(km, unit), nil
+}
+
+// CumulativeDistances returns, for every vertex of line, the great-circle
+// distance along the line from the start to that vertex in the requested unit.
+// The first element is 0 and the last is the length of the line.
+func CumulativeDistances(line LineString, unit DistanceUnit) ([]float64, error) {
+	if len(line.Coordinates) < 2 {
+		return nil, errors.New("linestring must have at least 2 coordinates")
+	}
+	out := make([]float64, len(line.Coordinates))
+	var km float64
+	for i := 1; i < len(line.Coordinates); i++ {
+		lat1, lon1 := positionLatLon(line.Coordinates[i-1])
+		lat2, lon2 := positionLatLon(line.Coordinates[i])
+		km += GreatCircleDistance(lat1, lon1, lat2, lon2)
+		out[i] = ConvertDistanceFromKm(km, unit)
+	}
+	return out, nil
+}
+
+// RouteDistanceGeoJSON is RouteDistance for a LineString; it is an alias for
+// LineStringLength.
+func RouteDistanceGeoJSON(line LineString, unit DistanceUnit) (float64, error) {
+	return LineStringLength(line, unit)
+}
+
+// GeoJSONLength returns the great-circle length of a GeoJSON object in the
+// requested unit: the length of lines, the perimeter of polygons including
+// their holes, and the sum over the members of multi geometries, collections
+// and features. Points have no length.
+func GeoJSONLength(obj interface{}, unit DistanceUnit) (float64, error) {
+	return geoJSONLength(obj, unit, GreatCircleDistance)
+}
+
+// GeoJSONRhumbLength is GeoJSONLength with every segment measured along a
+// rhumb line instead of a great circle.
+func GeoJSONRhumbLength(obj interface{}, unit DistanceUnit) (float64, error) {
+	return geoJSONLength(obj, unit, RhumbLineDistance)
+}
+
+// PolygonPerimeter returns the great-circle length of the exterior ring of
+// poly in the requested unit, adding the lengths of its holes when
+// includeHoles is set. Rings that are not closed are measured as if they
+// were.
+func PolygonPerimeter(poly Polygon, includeHoles bool, unit DistanceUnit) float64 {
+	rings := poly.Coordinates
+	if !includeHoles && len(rings) > 1 {
+		rings = rings[:1]
+	}
+	km, _ := lengthKm(Polygon{Type: poly.Type, Coordinates: rings}, GreatCircleDistance)
+	return ConvertDistanceFromKm(km, unit)
+}
+
+func geoJSONLength(obj interface{}, unit DistanceUnit, segmentKm func(lat1, lon1, lat2, lon2 float64) float64) (float64, error) {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkMembers(geom); err != nil {
+		return 0, err
+	}
+	km, err := lengthKm(geom, segmentKm)
+	if err != nil {
+		return 0, err
+	}
+	return ConvertDistanceFromKm(km, unit), nil
+}
+
+// lengthKm sums segmentKm over the segments of the lines and rings of g.
+func lengthKm(g Geometry, segmentKm func(lat1, lon1, lat2, lon2 float64) float64) (float64, error) {
+	path := func(coords []Position, closed bool) float64 {
+		var total float64
+		for i := 1; i < len(coords); i++ {
+			lat1, lon1 := positionLatLon(coords[i-1])
+			lat2, lon2 := positionLatLon(coords[i])
+			total += segmentKm(lat1, lon1, lat2, lon2)
+		}
+		if n := len(coords); closed && n > 2 && !samePosition(coords[0], coords[n-1]) {
+			lat1, lon1 := positionLatLon(coords[n-1])
+			lat2, lon2 := positionLatLon(coords[0])
+			total += segmentKm(lat1, lon1, lat2, lon2)
+		}
+		return total
+	}
+
+	var total float64
+	switch g := g.(type) {
+	case Point, MultiPoint:
+	case LineString:
+		total = path(g.Coordinates, false)
+	case MultiLineString:
+		for _, line := range g.Coordinates {
+			total += path(line, false)
+		}
+	case Polygon:
+		for _, ring := range g.Coordinates {
+			total += path(ring, true)
+		}
+	case MultiPolygon:
+		for _, poly := range g.Coordinates {
+			for _, ring := range poly {
+				total += path(ring, true)
+			}
+		}
+	case GeometryCollection:
+		for _, child := range g.Geometries {
+			member, _ := geometryOf(child)
+			km, err := lengthKm(member, segmentKm)
+			if err != nil {
+				return 0, err
+			}
+			total += km
+		}
+	case Feature:
+		if g.Geometry == nil {
+			return 0, nil
+		}
+		member, _ := geometryOf(g.Geometry)
+		return lengthKm(member, segmentKm)
+	case FeatureCollection:
+		for _, f := range g.Features {
+			km, err := lengthKm(f, segmentKm)
+			if err != nil {
+				return 0, err
+			}
+			total += km
+		}
+	default:
+		return 0, fmt.Errorf("unsupported geojson type %T", g)
+	}
+	return total, nil
+}
+
 // CrossTrackDistanceToLine returns the distance between a point and the nearest point on a line.
-// Distance is returned in kilometers.
+// Each segment is clamped to its endpoints, so the result is the ground distance to the
+// nearest point of the line. Distance is returned in kilometers.
 func CrossTrackDistanceToLine(line LineString, point Point) (float64, error) {
 	if len(line.Coordinates) < 2 {
 		return 0, errors.New("linestring must have at least 2 coordinates")
@@ -329,8 +1196,11 @@ func CrossTrackDistanceToLine(line LineString, point Point) (float64, error) {
 		end := line.Coordinates[i+1]
 		lat1, lon1 := positionLatLon(start)
 		lat2, lon2 := positionLatLon(end)
-		_, _, crossTrackKm, _ := GreatCircleProjectToSegment(lat1, lon1, lat2, lon2, latP, lonP)
-		dist := math.Abs(crossTrackKm)
+		// Measure to the nearest point on the segment itself, not to the
+		// segment's great circle, so points beyond a segment's ends get the
+		// distance to the nearest endpoint.
+		nearLat, nearLon, _, _ := GreatCircleProjectToSegment(lat1, lon1, lat2, lon2, latP, lonP)
+		dist := GreatCircleDistance(latP, lonP, nearLat, nearLon)
 		if dist < minDist {
 			minDist = dist
 		}
@@ -344,133 +1214,242 @@ func LinePointDistance(line LineString, point Point) (float64, error) {
 	return CrossTrackDistanceToLine(line, point)
 }
 
+// NearestPointOnLine returns the point of line nearest to p, the index of the
+// segment it lies on, its distance from p and its distance along the line from
+// the first coordinate, all in kilometers. Passing locationAlongKm to
+// LineStringPointAtDistance gives back nearest. When several segments are
+// equally near the first wins, so a nearest point at an inner vertex is
+// reported on the segment ending there. The altitude of nearest is
+// interpolated between the segment's ends.
+func NearestPointOnLine(line LineString, p Point) (nearest Point, segmentIndex int, distanceKm float64, locationAlongKm float64, err error) {
+	if len(line.Coordinates) < 2 {
+		return Point{}, 0, 0, 0, errors.New("linestring must have at least 2 coordinates")
+	}
+
+	latP, lonP := positionLatLon(p.Coordinates)
+	distanceKm = math.Inf(1)
+	traveled := 0.0
+	for i := 0; i < len(line.Coordinates)-1; i++ {
+		start := line.Coordinates[i]
+		end := line.Coordinates[i+1]
+		lat1, lon1 := positionLatLon(start)
+		lat2, lon2 := positionLatLon(end)
+		seg := GreatCircleDistance(lat1, lon1, lat2, lon2)
+		nearLat, nearLon, _, along := GreatCircleProjectToSegment(lat1, lon1, lat2, lon2, latP, lonP)
+		if dist := GreatCircleDistance(latP, lonP, nearLat, nearLon); dist < distanceKm {
+			f := 0.0
+			if seg > 0 {
+				f = along / seg
+			}
+			nearest = positionPoint(withAltBetween(nearLon, nearLat, start, end, f))
+			segmentIndex = i
+			distanceKm = dist
+			locationAlongKm = traveled + along
+		}
+		traveled += seg
+	}
+	return nearest, segmentIndex, distanceKm, locationAlongKm, nil
+}
+
+// LinesNearestPoints returns the closest pair of points on two lines, the
+// first on a and the second on b, and the great-circle distance between them
+// in kilometers. Lines that cross or touch are 0 apart at a meeting point.
+// Otherwise the closest approach of two segments is at an end of one of them,
+// so each segment end is projected onto the other line's segments with
+// GreatCircleProjectToSegment.
+func LinesNearestPoints(a, b LineString) (Point, Point, float64, error) {
+	if len(a.Coordinates) < 2 || len(b.Coordinates) < 2 {
+		return Point{}, Point{}, 0, errors.New("linestring must have at least 2 coordinates")
+	}
+	if hits := LineIntersections(a, b); len(hits) > 0 {
+		return hits[0], hits[0], 0, nil
+	}
+
+	var nearA, nearB Position
+	best := math.Inf(1)
+	// project moves each end of the segments of from onto the segments of to.
+	project := func(from, to []Position, swap bool) {
+		for _, p := range from {
+			latP, lonP := positionLatLon(p)
+			for i := 0; i+1 < len(to); i++ {
+				lat1, lon1 := positionLatLon(to[i])
+				lat2, lon2 := positionLatLon(to[i+1])
+				lat, lon, _, _ := GreatCircleProjectToSegment(lat1, lon1, lat2, lon2, latP, lonP)
+				if d := GreatCircleDistance(latP, lonP, lat, lon); d < best {
+					best = d
+					nearA, nearB = p, Position{lon, lat}
+					if swap {
+						nearA, nearB = nearB, nearA
+					}
+				}
+			}
+		}
+	}
+	project(a.Coordinates, b.Coordinates, false)
+	project(b.Coordinates, a.Coordinates, true)
+	return positionPoint(nearA), positionPoint(nearB), best, nil
+}
+
 // PolygonPointDistance returns signed distance from a point to the edges of a polygon or multipolygon.
 // Distances are in kilometers. Negative values indicate the point is inside the polygon.
 // A hole is treated as exterior.
 func PolygonPointDistance(obj interface{}, point Point) (float64, error) {
-	switch g := obj.(type) {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return 0, err
+	}
+	switch g := geom.(type) {
 	case Polygon:
 		return polygonPointDistance(g, point)
-	case *Polygon:
-		if g == nil {
-			return 0, errors.New("nil polygon")
-		}
-		return polygonPointDistance(*g, point)
 	case MultiPolygon:
 		return multiPolygonPointDistance(g, point)
-	case *MultiPolygon:
-		if g == nil {
-			return 0, errors.New("nil multipolygon")
-		}
-		return multiPolygonPointDistance(*g, point)
 	case Feature:
 		return PolygonPointDistance(g.Geometry, point)
-	case *Feature:
-		if g == nil {
-			return 0, errors.New("nil feature")
-		}
-		return PolygonPointDistance(g.Geometry, point)
 	case FeatureCollection:
 		return polygonDistanceFromCollection(g, point)
-	case *FeatureCollection:
-		if g == nil {
-			return 0, errors.New("nil featurecollection")
-		}
-		return polygonDistanceFromCollection(*g, point)
 	default:
 		return 0, fmt.Errorf("unsupported geojson type %T", obj)
 	}
 }
 
-// ---------------- Helpers ----------------
-
-func collectPositions(obj interface{}) ([]Position, error) {
-	var positions []Position
-	if err := collectPositionsInto(obj, &positions); err != nil {
-		return nil, err
+// PointInPolygon reports whether p lies inside obj, which may be a Polygon,
+// MultiPolygon, GeometryCollection, Feature or FeatureCollection; for the
+// collections it is enough to be inside any member. Points in a hole are
+// outside. Points on the boundary, which includes the edges of holes, count as
+// inside unless ignoreBoundary is set.
+//
+// The test is planar in longitude and latitude. An edge spanning more than
+// 180° of longitude is taken to cross the antimeridian, as in CutAntimeridian,
+// so a polygon from 170° to -170° contains points at 179.9°.
+func PointInPolygon(p Point, obj interface{}, ignoreBoundary bool) (bool, error) {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return false, err
 	}
-	return positions, nil
-}
-
-func collectPositionsInto(obj interface{}, positions *[]Position) error {
-	switch g := obj.(type) {
-	case Point:
-		*positions = append(*positions, g.Coordinates)
-	case *Point:
-		if g == nil {
-			return errors.New("nil point")
-		}
-		*positions = append(*positions, g.Coordinates)
-	case LineString:
-		*positions = append(*positions, g.Coordinates...)
-	case *LineString:
-		if g == nil {
-			return errors.New("nil linestring")
-		}
-		*positions = append(*positions, g.Coordinates...)
+	switch g := geom.(type) {
 	case Polygon:
-		for _, ring := range g.Coordinates {
-			*positions = append(*positions, ring...)
-		}
-	case *Polygon:
-		if g == nil {
-			return errors.New("nil polygon")
-		}
-		for _, ring := range g.Coordinates {
-			*positions = append(*positions, ring...)
-		}
-	case MultiLineString:
-		for _, line := range g.Coordinates {
-			*positions = append(*positions, line...)
-		}
-	case *MultiLineString:
-		if g == nil {
-			return errors.New("nil multilinestring")
-		}
-		for _, line := range g.Coordinates {
-			*positions = append(*positions, line...)
-		}
+		return locateInPolygon(p.Coordinates, g.Coordinates, ignoreBoundary), nil
 	case MultiPolygon:
 		for _, poly := range g.Coordinates {
-			for _, ring := range poly {
-				*positions = append(*positions, ring...)
+			if locateInPolygon(p.Coordinates, poly, ignoreBoundary) {
+				return true, nil
 			}
 		}
-	case *MultiPolygon:
-		if g == nil {
-			return errors.New("nil multipolygon")
-		}
-		for _, poly := range g.Coordinates {
-			for _, ring := range poly {
-				*positions = append(*positions, ring...)
+		return false, nil
+	case GeometryCollection:
+		for _, child := range g.Geometries {
+			if inside, err := PointInPolygon(p, child, ignoreBoundary); err != nil || inside {
+				return inside, err
 			}
 		}
+		return false, nil
 	case Feature:
-		return collectPositionsInto(g.Geometry, positions)
-	case *Feature:
-		if g == nil {
-			return errors.New("nil feature")
-		}
-		return collectPositionsInto(g.Geometry, positions)
+		return PointInPolygon(p, g.Geometry, ignoreBoundary)
 	case FeatureCollection:
-		for i := range g.Features {
-			if err := collectPositionsInto(g.Features[i], positions); err != nil {
-				return err
+		for _, f := range g.Features {
+			if inside, err := PointInPolygon(p, f, ignoreBoundary); err != nil || inside {
+				return inside, err
 			}
 		}
-	case *FeatureCollection:
-		if g == nil {
-			return errors.New("nil featurecollection")
+		return false, nil
+	default:
+		return false, fmt.Errorf("unsupported geojson type %T", obj)
+	}
+}
+
+// locateInPolygon implements PointInPolygon for the rings of one polygon.
+func locateInPolygon(pt Position, rings [][]Position, ignoreBoundary bool) bool {
+	if len(rings) == 0 {
+		return false
+	}
+	inside := false
+	for i, ring := range rings {
+		in, on := locateInRing(pt, ring)
+		if on {
+			return !ignoreBoundary
 		}
-		for i := range g.Features {
-			if err := collectPositionsInto(g.Features[i], positions); err != nil {
-				return err
+		if i == 0 {
+			inside = in
+		} else if in {
+			inside = false
+		}
+	}
+	return inside
+}
+
+// locateInRing reports whether pt lies strictly inside the ring or on its
+// edges. The ring's longitudes are first made continuous across the
+// antimeridian, and pt is tried at its longitude and one turn either way.
+func locateInRing(pt Position, ring []Position) (inside, onEdge bool) {
+	if len(ring) < 3 {
+		return false, false
+	}
+	unwrapped := make([]Position, len(ring))
+	prev := ring[0][0]
+	for i, q := range ring {
+		lon := q[0] + 360*math.Round((prev-q[0])/360)
+		unwrapped[i] = Position{lon, q[1]}
+		prev = lon
+	}
+	n := len(unwrapped)
+	if samePosition(unwrapped[0], unwrapped[n-1]) {
+		n--
+	}
+	for _, shift := range []float64{0, 360, -360} {
+		q := Position{pt[0] + shift, pt[1]}
+		for i := 0; i < n; i++ {
+			if pointOnSegment(q, unwrapped[i], unwrapped[(i+1)%n]) {
+				return false, true
 			}
 		}
-	default:
-		return fmt.Errorf("unsupported geojson type %T", obj)
+		if pointInRing(q, unwrapped) {
+			inside = true
+		}
 	}
-	return nil
+	return inside, false
+}
+
+// PointInPolygonSpherical reports whether point lies inside poly, treating the
+// edges as great-circle arcs rather than straight lines in longitude/latitude.
+// Points on the outer boundary count as inside, points in or on a hole as
+// outside, as with the planar test used by PolygonPointDistance.
+//
+// Prefer it over planar tests for polygons that span a large range of
+// longitudes, cross the antimeridian or come close to a pole, where straight
+// lines in degree space stray far from the real edges. A ring encloses the
+// smaller of the two areas it divides the sphere into, whatever its winding
+// order, so polygons covering more than a hemisphere are not supported.
+func PointInPolygonSpherical(point Point, poly Polygon) bool {
+	if len(poly.Coordinates) == 0 {
+		return false
+	}
+	p := unitVector(point.Coordinates)
+	if !sphericalPointInRing(p, poly.Coordinates[0]) {
+		return false
+	}
+	for _, hole := range poly.Coordinates[1:] {
+		if sphericalPointInRing(p, hole) {
+			return false
+		}
+	}
+	return true
+}
+
+// ---------------- Helpers ----------------
+
+func collectPositions(obj interface{}) ([]Position, error) {
+	g, err := geometryOf(obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMembers(g); err != nil {
+		return nil, err
+	}
+	var positions []Position
+	g.ForEachPosition(func(p Position) {
+		positions = append(positions, p)
+	})
+	return positions, nil
 }
 
 type massAccumulator struct {
@@ -486,74 +1465,41 @@ type massAccumulator struct {
 }
 
 func (m *massAccumulator) add(obj interface{}) error {
-	switch g := obj.(type) {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return err
+	}
+	switch g := geom.(type) {
 	case Point:
 		m.addPoint(g.Coordinates)
-	case *Point:
-		if g == nil {
-			return errors.New("nil point")
-		}
-		m.addPoint(g.Coordinates)
 	case LineString:
 		m.addLine(g)
-	case *LineString:
-		if g == nil {
-			return errors.New("nil linestring")
-		}
-		m.addLine(*g)
 	case Polygon:
 		m.addPolygon(g)
-	case *Polygon:
-		if g == nil {
-			return errors.New("nil polygon")
-		}
-		m.addPolygon(*g)
 	case MultiLineString:
 		for _, line := range g.Coordinates {
 			m.addLine(LineString{Coordinates: line})
 		}
-	case *MultiLineString:
-		if g == nil {
-			return errors.New("nil multilinestring")
-		}
-		for _, line := range g.Coordinates {
-			m.addLine(LineString{Coordinates: line})
-		}
 	case MultiPolygon:
 		for _, poly := range g.Coordinates {
 			m.addPolygon(Polygon{Coordinates: poly})
 		}
-	case *MultiPolygon:
-		if g == nil {
-			return errors.New("nil multipolygon")
-		}
-		for _, poly := range g.Coordinates {
-			m.addPolygon(Polygon{Coordinates: poly})
+	case GeometryCollection:
+		for _, child := range g.Geometries {
+			if err := m.add(child); err != nil {
+				return err
+			}
 		}
 	case Feature:
 		return m.add(g.Geometry)
-	case *Feature:
-		if g == nil {
-			return errors.New("nil feature")
-		}
-		return m.add(g.Geometry)
 	case FeatureCollection:
 		for i := range g.Features {
 			if err := m.add(g.Features[i]); err != nil {
 				return err
 			}
 		}
-	case *FeatureCollection:
-		if g == nil {
-			return errors.New("nil featurecollection")
-		}
-		for i := range g.Features {
-			if err := m.add(g.Features[i]); err != nil {
-				return err
-			}
-		}
 	default:
-		return fmt.Errorf("unsupported geojson type %T", obj)
+		g.ForEachPosition(m.addPoint)
 	}
 	return nil
 }
@@ -593,9 +1539,9 @@ func lineMidpoint(line LineString) (Point, error) {
 		return Point{}, err
 	}
 	if length == 0 {
-		return pointFromLatLon(positionLatLon(line.Coordinates[0])), nil
+		return positionPoint(line.Coordinates[0]), nil
 	}
-	return NewPoint(mid[0], mid[1]), nil
+	return positionPoint(mid), nil
 }
 
 func lineMidpointWithLength(line LineString) (float64, Position, error) {
@@ -636,7 +1582,10 @@ func polygonPointOnSurface(poly Polygon) (Point, error) {
 	if ok && pointInPolygon(centroid, poly) {
 		return NewPoint(centroid[0], centroid[1]), nil
 	}
-	return NewPoint(poly.Coordinates[0][0][0], poly.Coordinates[0][0][1]), nil
+	if pole, _, err := PoleOfInaccessibility(poly, 0); err == nil {
+		return pole, nil
+	}
+	return positionPoint(poly.Coordinates[0][0]), nil
 }
 
 func multiLinePointOnSurface(ml MultiLineString) (Point, error) {
@@ -686,7 +1635,11 @@ func featureCollectionPointOnSurface(fc FeatureCollection) (Point, error) {
 	var firstPoint *Point
 
 	for i := range fc.Features {
-		switch g := fc.Features[i].Geometry.(type) {
+		geom, err := geometryOf(fc.Features[i].Geometry)
+		if err != nil {
+			continue
+		}
+		switch g := geom.(type) {
 		case Point:
 			if firstPoint == nil {
 				p := g
@@ -729,6 +1682,32 @@ func featureCollectionPointOnSurface(fc FeatureCollection) (Point, error) {
 	return Point{}, errors.New("featurecollection has no supported geometries")
 }
 
+// nearestVertexToCenter returns the position of g closest to the average of
+// its positions. It is the point on surface for user-defined geometries, whose
+// shape is unknown.
+func nearestVertexToCenter(g Geometry) (Point, error) {
+	var positions []Position
+	var lonSum, latSum float64
+	g.ForEachPosition(func(p Position) {
+		positions = append(positions, p)
+		lonSum += p[0]
+		latSum += p[1]
+	})
+	if len(positions) == 0 {
+		return Point{}, errors.New("no coordinates found")
+	}
+	lon := lonSum / float64(len(positions))
+	lat := latSum / float64(len(positions))
+	best := positions[0]
+	bestDist := math.Inf(1)
+	for _, p := range positions {
+		if d := GreatCircleDistance(lat, lon, p[1], p[0]); d < bestDist {
+			best, bestDist = p, d
+		}
+	}
+	return positionPoint(best), nil
+}
+
 func polygonPointDistance(poly Polygon, point Point) (float64, error) {
 	if len(poly.Coordinates) == 0 {
 		return 0, errors.New("polygon has no coordinates")
@@ -788,7 +1767,11 @@ func polygonDistanceFromCollection(fc FeatureCollection, point Point) (float64,
 	inside := false
 
 	for i := range fc.Features {
-		switch g := fc.Features[i].Geometry.(type) {
+		geom, err := geometryOf(fc.Features[i].Geometry)
+		if err != nil {
+			continue
+		}
+		switch g := geom.(type) {
 		case Polygon:
 			dist, err := polygonPointDistance(g, point)
 			if err == nil {
@@ -826,8 +1809,9 @@ func ringDistance(ring []Position, point Point) (float64, error) {
 		return 0, errors.New("ring must have at least 2 coordinates")
 	}
 	coords := ring
-	if ring[0] != ring[len(ring)-1] {
-		coords = append(coords, ring[0])
+	if !samePosition(ring[0], ring[len(ring)-1]) {
+		// closeRing copies, so the caller's backing array is left alone.
+		coords = closeRing(ring)
 	}
 	return CrossTrackDistanceToLine(LineString{Coordinates: coords}, point)
 }
@@ -847,6 +1831,67 @@ func pointInPolygon(pt Position, poly Polygon) bool {
 	return true
 }
 
+// sphericalWinding returns the angle in radians swept by the ring as seen from
+// the unit vector p, positive counterclockwise: ±2π when the ring goes around
+// p, 0 when it does not. onEdge is set when p lies on the ring.
+func sphericalWinding(p [3]float64, ring []Position) (winding float64, onEdge bool) {
+	n := len(ring)
+	if n > 1 && samePosition(ring[0], ring[n-1]) {
+		n--
+	}
+	for i := 0; i < n; i++ {
+		a := unitVector(ring[i])
+		b := unitVector(ring[(i+1)%n])
+		// Signed angle at p between the great circles towards a and b.
+		cross := [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+		y := p[0]*cross[0] + p[1]*cross[1] + p[2]*cross[2]
+		ap := a[0]*p[0] + a[1]*p[1] + a[2]*p[2]
+		bp := b[0]*p[0] + b[1]*p[1] + b[2]*p[2]
+		x := a[0]*b[0] + a[1]*b[1] + a[2]*b[2] - ap*bp
+		if ap > 1-1e-15 || bp > 1-1e-15 {
+			return 0, true
+		}
+		angle := math.Atan2(y, x)
+		if math.Abs(angle) > math.Pi-1e-9 {
+			return 0, true
+		}
+		winding += angle
+	}
+	return winding, false
+}
+
+// sphericalPointInRing reports whether the unit vector p lies inside or on the
+// ring, taking the inside to be the smaller area the ring bounds.
+func sphericalPointInRing(p [3]float64, ring []Position) bool {
+	if len(ring) < 3 {
+		return false
+	}
+	winding, onEdge := sphericalWinding(p, ring)
+	if onEdge {
+		return true
+	}
+	if math.Abs(winding) < math.Pi {
+		return false
+	}
+	// The ring goes around p. p is inside unless the ring winds the other way
+	// around the area it encloses, in which case p is beyond it. The winding
+	// around the mean of the vertices gives that direction; for rings that do
+	// not enclose their mean, the planar orientation stands in.
+	var c [3]float64
+	for _, v := range ring {
+		u := unitVector(v)
+		c[0], c[1], c[2] = c[0]+u[0], c[1]+u[1], c[2]+u[2]
+	}
+	if norm := math.Sqrt(c[0]*c[0] + c[1]*c[1] + c[2]*c[2]); norm > 0 {
+		c = [3]float64{c[0] / norm, c[1] / norm, c[2] / norm}
+	}
+	orientation, _ := sphericalWinding(c, ring)
+	if math.Abs(orientation) < math.Pi {
+		orientation, _, _ = ringAreaCentroid(ring)
+	}
+	return winding*orientation > 0
+}
+
 func pointInRing(pt Position, ring []Position) bool {
 	n := len(ring)
 	if n < 3 {
@@ -859,7 +1904,7 @@ func pointInRing(pt Position, ring []Position) bool {
 			return true
 		}
 	}
-	if ring[0] != ring[n-1] && pointOnSegment(pt, ring[n-1], ring[0]) {
+	if !samePosition(ring[0], ring[n-1]) && pointOnSegment(pt, ring[n-1], ring[0]) {
 		return true
 	}
 
@@ -883,22 +1928,32 @@ func pointInRing(pt Position, ring []Position) bool {
 	return inside
 }
 
+// pointOnSegment reports whether p lies within 1e-12 degrees of the segment
+// a-b. The tolerance is a distance, so it is the same for short and long
+// segments.
 func pointOnSegment(p, a, b Position) bool {
 	const eps = 1e-12
 	ax, ay := a[0], a[1]
 	bx, by := b[0], b[1]
 	px, py := p[0], p[1]
 
+	if px < math.Min(ax, bx)-eps || px > math.Max(ax, bx)+eps ||
+		py < math.Min(ay, by)-eps || py > math.Max(ay, by)+eps {
+		return false
+	}
+	length := math.Hypot(bx-ax, by-ay)
+	if length == 0 {
+		return math.Hypot(px-ax, py-ay) <= eps
+	}
 	cross := (px-ax)*(by-ay) - (py-ay)*(bx-ax)
-	if math.Abs(cross) > eps {
+	if math.Abs(cross) > eps*length {
 		return false
 	}
 	dot := (px-ax)*(bx-ax) + (py-ay)*(by-ay)
-	if dot < -eps {
+	if dot < -eps*length {
 		return false
 	}
-	sqLen := (bx-ax)*(bx-ax) + (by-ay)*(by-ay)
-	if dot-sqLen > eps {
+	if dot-length*length > eps*length {
 		return false
 	}
 	return true
@@ -984,8 +2039,10 @@ func greatCircleCoordsByDistance(lat1, lon1, lat2, lon2, distanceKm float64) []P
 	}
 
 	coords := make([]Position, 0, int(math.Ceil(total/distanceKm))+1)
-	for dist := 0.0; dist < total; dist += distanceKm {
-		lat, lon := GreatCirclePointAtDistance(lat1, lon1, lat2, lon2, dist)
+	// Step by index rather than adding up distanceKm, which could stop
+	// advancing when it is tiny next to the distance covered.
+	for i := 0; float64(i)*distanceKm < total; i++ {
+		lat, lon := GreatCirclePointAtDistance(lat1, lon1, lat2, lon2, float64(i)*distanceKm)
 		coords = append(coords, Position{lon, lat})
 	}
 	coords = append(coords, Position{lon2, lat2})
@@ -1,7 +1,10 @@
 package geo
 
 import (
+	"bytes"
+	"encoding/json"
 	"math"
+	"reflect"
 	"testing"
 )
 
@@ -33,6 +36,20 @@ func TestGeoJSONBearing(t *testing.T) {
 	}
 }
 
+func TestPositionBearingAndDistance(t *testing.T) {
+	a := Position{-74.0060, 40.7128}
+	b := Position{-0.1278, 51.5074, 35}
+	if got, want := PositionBearing(a, b), Bearing(40.7128, -74.0060, 51.5074, -0.1278); got != want {
+		t.Errorf("PositionBearing() = %v, want %v", got, want)
+	}
+	km := GreatCircleDistance(40.7128, -74.0060, 51.5074, -0.1278)
+	for _, unit := range []DistanceUnit{UnitKilometers, UnitMeters, UnitMiles, UnitNauticalMiles} {
+		if got, want := PositionDistance(a, b, unit), ConvertDistanceFromKm(km, unit); got != want {
+			t.Errorf("PositionDistance(%v) = %v, want %v", unit, got, want)
+		}
+	}
+}
+
 func TestGeoJSONCenter(t *testing.T) {
 	fc := NewFeatureCollection([]Feature{
 		NewFeature(NewPoint(0, 0)),
@@ -47,6 +64,39 @@ func TestGeoJSONCenter(t *testing.T) {
 	}
 }
 
+func TestGeoJSONCenterAntimeridian(t *testing.T) {
+	fc := NewFeatureCollection([]Feature{
+		NewFeature(NewPoint(179, 10)),
+		NewFeature(NewPoint(-179, 20)),
+	})
+	center, err := GeoJSONCenter(fc)
+	if err != nil {
+		t.Fatalf("GeoJSONCenter() error = %v", err)
+	}
+	if math.Abs(math.Abs(center.Coordinates[0])-180) > 1e-9 || math.Abs(center.Coordinates[1]-15) > 1e-9 {
+		t.Errorf("center = (%v, %v), want (±180, 15)", center.Coordinates[0], center.Coordinates[1])
+	}
+
+	line := NewLineString([]Position{{170, 0}, {-176, 0}})
+	center, err = GeoJSONCenter(line)
+	if err != nil {
+		t.Fatalf("GeoJSONCenter() error = %v", err)
+	}
+	if math.Abs(center.Coordinates[0]-177) > 1e-9 {
+		t.Errorf("center longitude = %v, want 177", center.Coordinates[0])
+	}
+
+	// A wide polygon with no edge across the antimeridian is not wrapped.
+	wide := NewPolygon([][]Position{{{-170, 0}, {-10, 0}, {150, 0}, {150, 10}, {-10, 10}, {-170, 10}, {-170, 0}}})
+	center, err = GeoJSONCenter(wide)
+	if err != nil {
+		t.Fatalf("GeoJSONCenter() error = %v", err)
+	}
+	if math.Abs(center.Coordinates[0]+10) > 1e-9 || math.Abs(center.Coordinates[1]-5) > 1e-9 {
+		t.Errorf("center = (%v, %v), want (-10, 5)", center.Coordinates[0], center.Coordinates[1])
+	}
+}
+
 func TestGeoJSONCenterOfMassPolygon(t *testing.T) {
 	poly := NewPolygon([][]Position{
 		{
@@ -88,6 +138,110 @@ func TestGreatCircleGeoJSON(t *testing.T) {
 	}
 }
 
+func TestGreatCircleGeoJSONPointCounts(t *testing.T) {
+	for _, npoints := range []int{-1, 0, 1, 2} {
+		geom, err := GreatCircleGeoJSON(NewPoint(0, 0), NewPoint(10, 10), npoints)
+		if err != nil {
+			t.Fatalf("GreatCircleGeoJSON(%d) error = %v", npoints, err)
+		}
+		ls := geom.(LineString)
+		if len(ls.Coordinates) != 2 || !reflect.DeepEqual(ls.Coordinates[0], Position{0, 0}) {
+			t.Errorf("GreatCircleGeoJSON(%d) = %v, want the two endpoints", npoints, ls.Coordinates)
+		}
+		if end := ls.Coordinates[1]; math.Abs(end[0]-10) > 1e-9 || math.Abs(end[1]-10) > 1e-9 {
+			t.Errorf("GreatCircleGeoJSON(%d) ends at %v, want [10 10]", npoints, ls.Coordinates[1])
+		}
+	}
+
+	geom, err := GreatCircleGeoJSON(NewPoint(5, 5), NewPoint(5, 5), 1)
+	if err != nil {
+		t.Fatalf("GreatCircleGeoJSON(identical) error = %v", err)
+	}
+	if ls := geom.(LineString); len(ls.Coordinates) != 2 || !reflect.DeepEqual(ls.Coordinates[0], ls.Coordinates[1]) {
+		t.Errorf("GreatCircleGeoJSON(identical) = %v, want two equal points", ls.Coordinates)
+	}
+
+	// Nearly antipodal ends still have a well-defined route over the pole.
+	geom, err = GreatCircleGeoJSON(NewPoint(0, 1), NewPoint(180, -0.5), 9)
+	if err != nil {
+		t.Fatalf("GreatCircleGeoJSON(near antipodal) error = %v", err)
+	}
+	var length float64
+	var prev Position
+	geom.(Geometry).ForEachPosition(func(p Position) {
+		if math.IsNaN(p[0]) || math.IsNaN(p[1]) {
+			t.Fatalf("GreatCircleGeoJSON(near antipodal) has NaN position %v", p)
+		}
+		if prev != nil {
+			length += GreatCircleDistance(prev[1], prev[0], p[1], p[0])
+		}
+		prev = p
+	})
+	if want := GreatCircleDistance(1, 0, -0.5, 180); math.Abs(length-want) > 1 {
+		t.Errorf("near antipodal route length = %v km, want %v", length, want)
+	}
+
+	for _, c := range []struct {
+		name       string
+		start, end Point
+	}{
+		{"NaN", NewPoint(math.NaN(), 0), NewPoint(1, 1)},
+		{"Inf", NewPoint(0, 0), NewPoint(1, math.Inf(1))},
+		{"antipodal", NewPoint(0, 0), NewPoint(180, 0)},
+	} {
+		if _, err := GreatCircleGeoJSON(c.start, c.end, 5); err == nil {
+			t.Errorf("GreatCircleGeoJSON(%s) should fail", c.name)
+		}
+		if _, err := GreatCircleGeoJSONByDistance(c.start, c.end, 100); err == nil {
+			t.Errorf("GreatCircleGeoJSONByDistance(%s) should fail", c.name)
+		}
+	}
+}
+
+func TestGreatCircleGeoJSONWithOptions(t *testing.T) {
+	start, end := NewPoint(170, 0), NewPoint(-160, 20)
+	geom, err := GreatCircleGeoJSONWithOptions(start, end, GreatCircleGeoJSONOptions{MaxSegmentKm: 250})
+	if err != nil {
+		t.Fatalf("GreatCircleGeoJSONWithOptions() error = %v", err)
+	}
+	mls, ok := geom.(MultiLineString)
+	if !ok {
+		t.Fatalf("GreatCircleGeoJSONWithOptions() = %T, want MultiLineString across the antimeridian", geom)
+	}
+	total := GreatCircleDistance(0, 170, 20, -160)
+	points := 0
+	for _, line := range mls.Coordinates {
+		points += len(line)
+		for i := 1; i < len(line); i++ {
+			if d := GreatCircleDistance(line[i-1][1], line[i-1][0], line[i][1], line[i][0]); d > 250+1e-6 {
+				t.Errorf("segment of %v km, want at most 250", d)
+			}
+		}
+	}
+	if want := int(math.Ceil(total/250)) + 1; points != want {
+		t.Errorf("got %d points, want %d", points, want)
+	}
+
+	// NPoints wins when it asks for more points than the cap needs.
+	geom, err = GreatCircleGeoJSONWithOptions(NewPoint(0, 0), NewPoint(1, 0), GreatCircleGeoJSONOptions{MaxSegmentKm: 1000, NPoints: 6})
+	if err != nil {
+		t.Fatalf("GreatCircleGeoJSONWithOptions() error = %v", err)
+	}
+	if got := len(geom.(LineString).Coordinates); got != 6 {
+		t.Errorf("got %d points, want 6", got)
+	}
+
+	if _, err := GreatCircleGeoJSONWithOptions(start, end, GreatCircleGeoJSONOptions{MaxSegmentKm: math.NaN()}); err == nil {
+		t.Error("GreatCircleGeoJSONWithOptions() with NaN MaxSegmentKm should fail")
+	}
+	if _, err := GreatCircleGeoJSONWithOptions(start, end, GreatCircleGeoJSONOptions{MaxSegmentKm: 1e-6}); err == nil {
+		t.Error("GreatCircleGeoJSONWithOptions() with a tiny MaxSegmentKm should fail")
+	}
+	if _, err := GreatCircleGeoJSONByDistance(start, end, 1e-6); err == nil {
+		t.Error("GreatCircleGeoJSONByDistance() with a tiny distance should fail")
+	}
+}
+
 func TestGreatCircleGeoJSONByDistance(t *testing.T) {
 	geom, err := GreatCircleGeoJSONByDistance(NewPoint(179, 0), NewPoint(-179, 0), 200)
 	if err != nil {
@@ -132,6 +286,42 @@ func TestCrossTrackDistanceToLine(t *testing.T) {
 	}
 }
 
+func TestLinePointDistanceBeyondEnd(t *testing.T) {
+	// The point lies on the line's great circle, past its end. Its cross-track
+	// distance is 0 but the nearest point of the line is the end vertex.
+	line := NewLineString([]Position{
+		{0, 0},
+		{10, 0},
+		{20, 0},
+	})
+	point := NewPoint(25, 0)
+	dist, err := LinePointDistance(line, point)
+	if err != nil {
+		t.Fatalf("LinePointDistance() error = %v", err)
+	}
+	expected := GreatCircleDistance(0, 20, 0, 25)
+	if math.Abs(dist-expected) > 1e-6 {
+		t.Errorf("distance = %v, want %v", dist, expected)
+	}
+
+	// Near a shared vertex of a bent line, both segments are clamped and the
+	// result is the distance to the vertex.
+	bent := NewLineString([]Position{
+		{0, 0},
+		{10, 0},
+		{10, 10},
+	})
+	point = NewPoint(12, -2)
+	dist, err = LinePointDistance(bent, point)
+	if err != nil {
+		t.Fatalf("LinePointDistance() error = %v", err)
+	}
+	expected = GreatCircleDistance(-2, 12, 0, 10)
+	if math.Abs(dist-expected) > 1e-6 {
+		t.Errorf("distance near vertex = %v, want %v", dist, expected)
+	}
+}
+
 func TestPolygonPointDistance(t *testing.T) {
 	poly := NewPolygon([][]Position{
 		{
@@ -152,3 +342,1086 @@ func TestPolygonPointDistance(t *testing.T) {
 		t.Errorf("distance = %v, want negative approx %v", dist, expected)
 	}
 }
+
+func TestPolygonPointDistanceKeepsUnclosedRing(t *testing.T) {
+	// An unclosed ring sliced from a larger array, with spare capacity holding
+	// other data right after it.
+	backing := []Position{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {9, 9}, {8, 8}}
+	want := append([]Position(nil), backing...)
+	poly := NewPolygon([][]Position{backing[:4]})
+	if _, err := PolygonPointDistance(poly, NewPoint(1, 1)); err != nil {
+		t.Fatalf("PolygonPointDistance() error = %v", err)
+	}
+	if !reflect.DeepEqual(backing, want) {
+		t.Errorf("backing array = %v, want unchanged %v", backing, want)
+	}
+}
+
+func TestPointAltitudeJSONRoundTrip(t *testing.T) {
+	pt := Point{Type: "Point", Coordinates: Position{-122.4194, 37.7749, 52.5}}
+	data, err := json.Marshal(pt)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if string(data) != `{"type":"Point","coordinates":[-122.4194,37.7749,52.5]}` {
+		t.Errorf("json = %s", data)
+	}
+
+	var decoded Point
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, pt) {
+		t.Errorf("decoded = %v, want %v", decoded, pt)
+	}
+	if !decoded.Coordinates.HasAlt() || decoded.Coordinates.Alt() != 52.5 {
+		t.Errorf("altitude = %v, %v, want 52.5", decoded.Coordinates.Alt(), decoded.Coordinates.HasAlt())
+	}
+	seaLevel, err := json.Marshal(Point{Type: "Point", Coordinates: Position{1.5, 2.5, 0}})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if string(seaLevel) != `{"type":"Point","coordinates":[1.5,2.5,0]}` {
+		t.Errorf("json = %s, want the zero altitude kept", seaLevel)
+	}
+	if NewPoint(1.5, 2.5).Coordinates.HasAlt() {
+		t.Error("NewPoint() has an altitude")
+	}
+}
+
+func TestFeatureCollectionJSONRoundTrip(t *testing.T) {
+	square := NewPolygon([][]Position{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}})
+	fc := NewFeatureCollection([]Feature{
+		NewFeature(square),
+		NewFeature(NewPoint(5, 5)),
+		NewFeature(NewLineString([]Position{{3, 3}, {4, 4}})),
+	})
+	fc.Features[0].Properties = map[string]interface{}{"name": "square"}
+
+	data, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded FeatureCollection
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if len(decoded.Features) != 3 {
+		t.Fatalf("features = %d, want 3", len(decoded.Features))
+	}
+	poly, ok := decoded.Features[0].Geometry.(Polygon)
+	if !ok {
+		t.Fatalf("geometry = %T, want Polygon", decoded.Features[0].Geometry)
+	}
+	if !reflect.DeepEqual(poly.Coordinates[0][2], Position{2, 2}) {
+		t.Errorf("polygon coordinates = %v", poly.Coordinates)
+	}
+	if decoded.Features[0].Properties["name"] != "square" {
+		t.Errorf("properties = %v", decoded.Features[0].Properties)
+	}
+	if _, ok := decoded.Features[1].Geometry.(Point); !ok {
+		t.Errorf("geometry = %T, want Point", decoded.Features[1].Geometry)
+	}
+
+	center, err := GeoJSONCenter(decoded)
+	if err != nil {
+		t.Fatalf("GeoJSONCenter() error = %v", err)
+	}
+	if !reflect.DeepEqual(center.Coordinates, Position{2.5, 2.5}) {
+		t.Errorf("center = %v, want [2.5 2.5]", center.Coordinates)
+	}
+
+	dist, err := PolygonPointDistance(decoded, NewPoint(1, 1))
+	if err != nil {
+		t.Fatalf("PolygonPointDistance() error = %v", err)
+	}
+	want, _ := PolygonPointDistance(square, NewPoint(1, 1))
+	if dist != want {
+		t.Errorf("distance = %v, want %v", dist, want)
+	}
+}
+
+func TestParseGeoJSON(t *testing.T) {
+	obj, err := ParseGeoJSON([]byte(`{"type":"MultiLineString","coordinates":[[[0,0],[1,1]],[[2,2],[3,3,10]]]}`))
+	if err != nil {
+		t.Fatalf("ParseGeoJSON() error = %v", err)
+	}
+	ml, ok := obj.(MultiLineString)
+	if !ok {
+		t.Fatalf("ParseGeoJSON() = %T, want MultiLineString", obj)
+	}
+	if !reflect.DeepEqual(ml.Coordinates[1][1], Position{3, 3, 10}) {
+		t.Errorf("coordinates = %v", ml.Coordinates)
+	}
+
+	obj, err = ParseGeoJSON([]byte(`{"type":"Feature","geometry":null,"properties":{"id":1}}`))
+	if err != nil {
+		t.Fatalf("ParseGeoJSON() error = %v", err)
+	}
+	if f, ok := obj.(Feature); !ok || f.Geometry != nil {
+		t.Errorf("ParseGeoJSON() = %#v, want Feature with nil geometry", obj)
+	}
+
+	if _, err := ParseGeoJSON([]byte(`{"type":"Circle","radius":1}`)); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+	if _, err := ParseGeoJSON([]byte(`{"type":"Feature","geometry":{"type":"Circle"}}`)); err == nil {
+		t.Error("expected error for unsupported geometry type")
+	}
+	if _, err := ParseGeoJSON([]byte(`not json`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestSegmentBearingsAndTurnAngles(t *testing.T) {
+	// North along the prime meridian, then a right-angle dogleg east, then
+	// back north: a right turn followed by a left turn.
+	line := NewLineString([]Position{
+		{0, 0},
+		{0, 1},
+		{1, 1},
+		{1, 2},
+	})
+
+	bearings := SegmentBearings(line)
+	if len(bearings) != 3 {
+		t.Fatalf("bearings = %d, want 3", len(bearings))
+	}
+	for i, want := range []float64{0, 90, 0} {
+		if math.Abs(bearings[i]-want) > 0.1 {
+			t.Errorf("bearing %d = %v, want ~%v", i, bearings[i], want)
+		}
+	}
+
+	turns := TurnAngles(line)
+	if len(turns) != 2 {
+		t.Fatalf("turns = %d, want 2", len(turns))
+	}
+	if math.Abs(turns[0]-90) > 0.1 {
+		t.Errorf("turn 0 = %v, want ~90 (right)", turns[0])
+	}
+	if math.Abs(turns[1]+90) > 0.1 {
+		t.Errorf("turn 1 = %v, want ~-90 (left)", turns[1])
+	}
+
+	straight := NewLineString([]Position{{0, 0}, {1, 0}, {2, 0}})
+	if turns := TurnAngles(straight); math.Abs(turns[0]) > 1e-9 {
+		t.Errorf("straight turn = %v, want 0", turns[0])
+	}
+	if SegmentBearings(NewLineString([]Position{{0, 0}})) != nil {
+		t.Error("expected nil bearings for a single-point line")
+	}
+}
+
+func TestRhumbLineGeoJSON(t *testing.T) {
+	start := NewPoint(-74.0060, 40.7128) // New York
+	end := NewPoint(-0.1278, 51.5074)    // London
+	route, err := RhumbLineGeoJSON(start, end, 20)
+	if err != nil {
+		t.Fatalf("RhumbLineGeoJSON() error = %v", err)
+	}
+	line, ok := route.(LineString)
+	if !ok {
+		t.Fatalf("RhumbLineGeoJSON() = %T, want LineString", route)
+	}
+	if len(line.Coordinates) != 20 {
+		t.Fatalf("points = %d, want 20", len(line.Coordinates))
+	}
+	if !reflect.DeepEqual(line.Coordinates[0], start.Coordinates) || !reflect.DeepEqual(line.Coordinates[19], end.Coordinates) {
+		t.Errorf("endpoints = %v, %v", line.Coordinates[0], line.Coordinates[19])
+	}
+
+	want := RhumbLineBearing(40.7128, -74.0060, 51.5074, -0.1278)
+	for i := 0; i < len(line.Coordinates)-1; i++ {
+		lat1, lon1 := positionLatLon(line.Coordinates[i])
+		lat2, lon2 := positionLatLon(line.Coordinates[i+1])
+		if got := RhumbLineBearing(lat1, lon1, lat2, lon2); math.Abs(BearingDifference(want, got)) > 1e-6 {
+			t.Errorf("bearing of sample %d = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestRhumbLineGeoJSONAntimeridian(t *testing.T) {
+	route, err := RhumbLineGeoJSON(NewPoint(170, 10), NewPoint(-170, 20), 10)
+	if err != nil {
+		t.Fatalf("RhumbLineGeoJSON() error = %v", err)
+	}
+	ml, ok := route.(MultiLineString)
+	if !ok {
+		t.Fatalf("RhumbLineGeoJSON() = %T, want MultiLineString", route)
+	}
+	if len(ml.Coordinates) != 2 {
+		t.Errorf("parts = %d, want 2", len(ml.Coordinates))
+	}
+}
+
+func TestFeatureIDAndForeignMembers(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		wantID interface{}
+	}{
+		{"string id", `{"type":"Feature","id":"road-17","geometry":null}`, "road-17"},
+		{"numeric id", `{"type":"Feature","id":12345678901234567,"geometry":null}`, json.Number("12345678901234567")},
+		{"float id", `{"type":"Feature","id":1.5e3,"geometry":null}`, json.Number("1.5e3")},
+		{"no id", `{"type":"Feature","geometry":null}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f Feature
+			if err := json.Unmarshal([]byte(tt.input), &f); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if f.ID != tt.wantID {
+				t.Fatalf("ID = %#v, want %#v", f.ID, tt.wantID)
+			}
+			data, err := json.Marshal(f)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			var again Feature
+			if err := json.Unmarshal(data, &again); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if again.ID != tt.wantID {
+				t.Errorf("round-tripped ID = %#v, want %#v (json %s)", again.ID, tt.wantID, data)
+			}
+		})
+	}
+
+	input := `{"type":"Feature","id":7,"geometry":{"type":"Point","coordinates":[1,2]},` +
+		`"properties":{"name":"x"},"crs":{"type":"name","properties":{"name":"EPSG:4326"}},"promoteId":"name"}`
+	var f Feature
+	if err := json.Unmarshal([]byte(input), &f); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if len(f.Extra) != 2 || string(f.Extra["promoteId"]) != `"name"` {
+		t.Fatalf("Extra = %v, want crs and promoteId", f.Extra)
+	}
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if string(members["crs"]) != `{"type":"name","properties":{"name":"EPSG:4326"}}` {
+		t.Errorf("crs = %s", members["crs"])
+	}
+	if string(members["id"]) != "7" {
+		t.Errorf("id = %s, want 7", members["id"])
+	}
+
+	// Extra cannot override standard members.
+	f.Extra["type"] = json.RawMessage(`"Bogus"`)
+	data, err = json.Marshal(f)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if err := json.Unmarshal(data, &members); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if string(members["type"]) != `"Feature"` {
+		t.Errorf("type = %s, want \"Feature\"", members["type"])
+	}
+
+	if err := json.Unmarshal([]byte(`{"type":"Feature","id":[1],"geometry":null}`), &f); err == nil {
+		t.Error("expected error for array id")
+	}
+}
+
+func TestFeaturePropertyAccessors(t *testing.T) {
+	var f Feature
+	data := `{"type":"Feature","geometry":null,"properties":{"name":"Lund","population":94703,"area":25.7,"capital":false}}`
+	if err := json.Unmarshal([]byte(data), &f); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	f.Properties["rank"] = json.Number("12")
+	f.Properties["ratio"] = json.Number("0.25")
+	f.Properties["count"] = int64(7)
+
+	if got, ok := f.PropertyString("name"); !ok || got != "Lund" {
+		t.Errorf("PropertyString(name) = %q, %v, want Lund, true", got, ok)
+	}
+	if got, ok := f.PropertyFloat("area"); !ok || got != 25.7 {
+		t.Errorf("PropertyFloat(area) = %v, %v, want 25.7, true", got, ok)
+	}
+	if got, ok := f.PropertyFloat("ratio"); !ok || got != 0.25 {
+		t.Errorf("PropertyFloat(ratio) = %v, %v, want 0.25, true", got, ok)
+	}
+	if got, ok := f.PropertyFloat("count"); !ok || got != 7 {
+		t.Errorf("PropertyFloat(count) = %v, %v, want 7, true", got, ok)
+	}
+	for key, want := range map[string]int{"population": 94703, "rank": 12, "count": 7} {
+		if got, ok := f.PropertyInt(key); !ok || got != want {
+			t.Errorf("PropertyInt(%s) = %v, %v, want %v, true", key, got, ok, want)
+		}
+	}
+
+	// Absent keys and values of the wrong type report false.
+	if _, ok := f.PropertyString("missing"); ok {
+		t.Error("PropertyString(missing) reported ok")
+	}
+	if _, ok := f.PropertyString("population"); ok {
+		t.Error("PropertyString(population) reported ok for a number")
+	}
+	if _, ok := f.PropertyFloat("name"); ok {
+		t.Error("PropertyFloat(name) reported ok for a string")
+	}
+	if _, ok := f.PropertyFloat("capital"); ok {
+		t.Error("PropertyFloat(capital) reported ok for a bool")
+	}
+	if _, ok := f.PropertyInt("area"); ok {
+		t.Error("PropertyInt(area) reported ok for a fraction")
+	}
+	if _, ok := f.PropertyInt("ratio"); ok {
+		t.Error("PropertyInt(ratio) reported ok for a fraction")
+	}
+	if _, ok := f.PropertyInt("missing"); ok {
+		t.Error("PropertyInt(missing) reported ok")
+	}
+	if _, ok := (Feature{}).PropertyFloat("area"); ok {
+		t.Error("PropertyFloat() on a feature without properties reported ok")
+	}
+	f.Properties["huge"] = 1e300
+	if _, ok := f.PropertyInt("huge"); ok {
+		t.Error("PropertyInt(huge) reported ok for a value beyond int")
+	}
+}
+
+func TestCircle(t *testing.T) {
+	center := NewPoint(10, 50)
+	poly := Circle(center, 100, 32)
+	ring := poly.Coordinates[0]
+	if len(ring) != 33 || !reflect.DeepEqual(ring[0], ring[32]) {
+		t.Fatalf("ring has %d positions, want 33 and closed", len(ring))
+	}
+	for _, p := range ring {
+		if d := GreatCircleDistance(50, 10, p[1], p[0]); math.Abs(d-100) > 1e-6 {
+			t.Errorf("vertex %v is %v km from center, want 100", p, d)
+		}
+	}
+	if area, _, _ := ringAreaCentroid(ring); area <= 0 {
+		t.Errorf("ring area = %v, want counterclockwise", area)
+	}
+}
+
+func TestIsochrone(t *testing.T) {
+	center := NewPoint(-0.1278, 51.5074)
+	prev := 0.0
+	for _, hours := range []float64{0.5, 1, 2} {
+		poly := Isochrone(center, 60, hours, 48)
+		area, _, _ := ringAreaCentroid(poly.Coordinates[0])
+		if area <= prev {
+			t.Errorf("area after %v hours = %v, want more than %v", hours, area, prev)
+		}
+		prev = area
+		p := poly.Coordinates[0][0]
+		if d := GreatCircleDistance(51.5074, -0.1278, p[1], p[0]); math.Abs(d-60*hours) > 1e-6 {
+			t.Errorf("reach after %v hours = %v km, want %v", hours, d, 60*hours)
+		}
+	}
+}
+
+func TestPositionAltitudeJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want Position
+		out  string
+	}{
+		{"2d", `[1.5,2.5]`, Position{1.5, 2.5}, `[1.5,2.5]`},
+		{"3d", `[1.5,2.5,1200]`, Position{1.5, 2.5, 1200}, `[1.5,2.5,1200]`},
+		{"negative altitude", `[1.5,2.5,-30.5]`, Position{1.5, 2.5, -30.5}, `[1.5,2.5,-30.5]`},
+		{"extra elements", `[1.5,2.5,10,99]`, Position{1.5, 2.5, 10}, `[1.5,2.5,10]`},
+		{"zero altitude", `[1.5,2.5,0]`, Position{1.5, 2.5, 0}, `[1.5,2.5,0]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Position
+			if err := json.Unmarshal([]byte(tt.in), &p); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(p, tt.want) {
+				t.Errorf("decoded = %v, want %v", p, tt.want)
+			}
+			data, err := json.Marshal(p)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(data) != tt.out {
+				t.Errorf("json = %s, want %s", data, tt.out)
+			}
+		})
+	}
+
+	for _, in := range []string{`[]`, `[1]`, `{"lon":1}`, `[1,"a"]`} {
+		var p Position
+		if err := json.Unmarshal([]byte(in), &p); err == nil {
+			t.Errorf("json.Unmarshal(%s) error = nil, want error", in)
+		}
+	}
+
+	p := Position{-122.4, 37.8, 52}
+	if p.Lon() != -122.4 || p.Lat() != 37.8 || p.Alt() != 52 {
+		t.Errorf("Lon/Lat/Alt = %v %v %v", p.Lon(), p.Lat(), p.Alt())
+	}
+	if alt := (Position{1, 2}).Alt(); alt != 0 {
+		t.Errorf("Alt() = %v, want 0", alt)
+	}
+}
+
+func TestGeometryAltitudeRoundTrip(t *testing.T) {
+	inputs := []string{
+		`{"type":"Point","coordinates":[8.5,47.4,420]}`,
+		`{"type":"LineString","coordinates":[[8.5,47.4,420],[8.6,47.5],[8.7,47.6,1800.5]]}`,
+		`{"type":"Polygon","coordinates":[[[0,0,10],[1,0,10],[1,1,10],[0,1,10],[0,0,10]]]}`,
+		`{"type":"MultiPoint","coordinates":[[0,0],[1,1,5]]}`,
+		`{"type":"MultiLineString","coordinates":[[[0,0],[1,1,5]],[[2,2,-5],[3,3]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[[0,0,1],[1,0,2],[1,1,3],[0,0,1]]]]}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2,3]},{"type":"MultiPoint","coordinates":[[4,5]]}]}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2,3]},"properties":{"name":"a"}}`,
+		`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"LineString","coordinates":[[1,2,3],[4,5]]},"properties":{"name":"a"}}]}`,
+	}
+	for _, in := range inputs {
+		obj, err := ParseGeoJSON([]byte(in))
+		if err != nil {
+			t.Fatalf("ParseGeoJSON(%s) error = %v", in, err)
+		}
+		data, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		if string(data) != in {
+			t.Errorf("round trip = %s, want %s", data, in)
+		}
+	}
+}
+
+func TestHelpersKeepAltitude(t *testing.T) {
+	line := NewLineString([]Position{{0, 0, 100}, {1, 0, 300}})
+
+	start, err := LineStringPointAtDistance(line, 0)
+	if err != nil {
+		t.Fatalf("LineStringPointAtDistance() error = %v", err)
+	}
+	if !reflect.DeepEqual(start.Coordinates, line.Coordinates[0]) {
+		t.Errorf("start = %v, want %v", start.Coordinates, line.Coordinates[0])
+	}
+	end, _ := LineStringPointAtDistance(line, 1000)
+	if !reflect.DeepEqual(end.Coordinates, line.Coordinates[1]) {
+		t.Errorf("end = %v, want %v", end.Coordinates, line.Coordinates[1])
+	}
+	length := GreatCircleDistance(0, 0, 0, 1)
+	mid, _ := LineStringPointAtDistance(line, length/4)
+	if math.Abs(mid.Coordinates.Alt()-150) > 1e-9 || math.Abs(mid.Coordinates.Lon()-0.25) > 1e-9 {
+		t.Errorf("quarter point = %v, want [0.25 0 150]", mid.Coordinates)
+	}
+
+	surface, err := GeoJSONPointOnSurface(line)
+	if err != nil {
+		t.Fatalf("GeoJSONPointOnSurface() error = %v", err)
+	}
+	if math.Abs(surface.Coordinates.Alt()-200) > 1e-9 {
+		t.Errorf("midpoint altitude = %v, want 200", surface.Coordinates.Alt())
+	}
+
+	pt := Position{5, 6, 700}
+	got, err := GeoJSONPointOnSurface(Point{Type: "Point", Coordinates: pt})
+	if err != nil {
+		t.Fatalf("GeoJSONPointOnSurface() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Coordinates, pt) {
+		t.Errorf("point on surface = %v, want %v", got.Coordinates, pt)
+	}
+
+	bbox, err := ComputeBBox(line)
+	if err != nil {
+		t.Fatalf("ComputeBBox() error = %v", err)
+	}
+	if !equalBBox(bbox, []float64{0, 0, 1, 0}) {
+		t.Errorf("bbox = %v, want [0 0 1 0]", bbox)
+	}
+
+	withBBox, err := WithBBox(line)
+	if err != nil {
+		t.Fatalf("WithBBox() error = %v", err)
+	}
+	if coords := withBBox.(LineString).Coordinates; !reflect.DeepEqual(coords[0], line.Coordinates[0]) || !reflect.DeepEqual(coords[1], line.Coordinates[1]) {
+		t.Errorf("WithBBox() coordinates = %v", coords)
+	}
+}
+
+func TestHullIgnoresAltitude(t *testing.T) {
+	points := []Position{{0, 0, 10}, {1, 0, 20}, {0, 1, 30}, {0, 0, 40}, {1, 1}}
+	unique, index := dedupePositions(points)
+	if len(unique) != 4 || index[3] != 0 {
+		t.Errorf("dedupePositions() = %v, %v", unique, index)
+	}
+	hull, err := ConcaveHull(points, 500)
+	if err != nil {
+		t.Fatalf("ConcaveHull() error = %v", err)
+	}
+	if len(hull.Coordinates[0]) != 5 {
+		t.Errorf("hull ring = %v, want 4 corners", hull.Coordinates[0])
+	}
+}
+
+func TestPointInPolygonSpherical(t *testing.T) {
+	// A ring around the north pole along the 80th parallel. In degree space
+	// the ring is a flat line, so the planar test finds nothing inside it.
+	arctic := NewPolygon([][]Position{{{0, 80}, {90, 80}, {180, 80}, {-90, 80}, {0, 80}}})
+	pole := NewPoint(0, 90)
+	if pointInPolygon(pole.Coordinates, arctic) {
+		t.Fatal("planar test unexpectedly finds the pole inside")
+	}
+
+	tests := []struct {
+		name  string
+		point Point
+		want  bool
+	}{
+		{"north pole", pole, true},
+		{"near pole", NewPoint(45, 85), true},
+		// Great-circle edges bulge poleward: between two vertices the
+		// edge reaches about 82.9°N.
+		{"inside edge", NewPoint(135, 83), true},
+		{"outside edge", NewPoint(135, 82), false},
+		{"vertex", NewPoint(90, 80), true},
+		{"south of ring", NewPoint(45, 70), false},
+		{"equator", NewPoint(10, 0), false},
+		{"south pole", NewPoint(0, -90), false},
+	}
+	reversed := NewPolygon([][]Position{{{0, 80}, {-90, 80}, {180, 80}, {90, 80}, {0, 80}}})
+	for _, tt := range tests {
+		if got := PointInPolygonSpherical(tt.point, arctic); got != tt.want {
+			t.Errorf("%s: PointInPolygonSpherical() = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := PointInPolygonSpherical(tt.point, reversed); got != tt.want {
+			t.Errorf("%s: PointInPolygonSpherical(clockwise) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Across the antimeridian with a hole.
+	poly := NewPolygon([][]Position{
+		{{170, -10}, {-170, -10}, {-170, 10}, {170, 10}, {170, -10}},
+		{{178, -2}, {-178, -2}, {-178, 2}, {178, 2}, {178, -2}},
+	})
+	if !PointInPolygonSpherical(NewPoint(175, 0), poly) {
+		t.Error("expected point west of the antimeridian inside")
+	}
+	if !PointInPolygonSpherical(NewPoint(-172, 5), poly) {
+		t.Error("expected point east of the antimeridian inside")
+	}
+	if PointInPolygonSpherical(NewPoint(180, 0), poly) {
+		t.Error("expected point in hole outside")
+	}
+	if PointInPolygonSpherical(NewPoint(0, 0), poly) {
+		t.Error("expected point on the far side outside")
+	}
+
+	// Agrees with the planar test on a small polygon.
+	square := NewPolygon([][]Position{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}})
+	for _, pt := range []Point{NewPoint(0.5, 0.5), NewPoint(1.5, 0.5), NewPoint(0, 0.5)} {
+		if got, want := PointInPolygonSpherical(pt, square), pointInPolygon(pt.Coordinates, square); got != want {
+			t.Errorf("PointInPolygonSpherical(%v) = %v, planar = %v", pt.Coordinates, got, want)
+		}
+	}
+}
+
+func TestGeoJSONLength(t *testing.T) {
+	square := squarePolygon(0, 0, 1, 1)
+	side := GreatCircleDistance(0, 0, 0, 1)
+	top := GreatCircleDistance(1, 0, 1, 1)
+	perimeter := 2*side + top + GreatCircleDistance(0, 1, 1, 1)
+	got, err := GeoJSONLength(square, UnitKilometers)
+	if err != nil {
+		t.Fatalf("GeoJSONLength() error = %v", err)
+	}
+	if math.Abs(got-perimeter) > 1e-9 {
+		t.Errorf("perimeter = %v, want %v", got, perimeter)
+	}
+
+	line := NewLineString([]Position{{0, 0}, {1, 0}, {1, 1}})
+	lineKm := side + GreatCircleDistance(0, 1, 1, 1)
+	fc := NewFeatureCollection([]Feature{
+		NewFeature(square),
+		NewFeature(line),
+		NewFeature(NewPoint(5, 5)),
+		NewFeature(NewGeometryCollection([]interface{}{line, NewMultiLineString([][]Position{line.Coordinates})})),
+	})
+	got, err = GeoJSONLength(&fc, UnitMiles)
+	if err != nil {
+		t.Fatalf("GeoJSONLength() error = %v", err)
+	}
+	if want := ConvertDistanceFromKm(perimeter+3*lineKm, UnitMiles); math.Abs(got-want) > 1e-9 {
+		t.Errorf("collection length = %v, want %v", got, want)
+	}
+
+	// Open rings are measured as if closed.
+	open := NewMultiPolygon([][][]Position{{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}})
+	if got, _ := GeoJSONLength(open, UnitKilometers); math.Abs(got-perimeter) > 1e-9 {
+		t.Errorf("open ring perimeter = %v, want %v", got, perimeter)
+	}
+
+	rhumb, err := GeoJSONRhumbLength(line, UnitKilometers)
+	if err != nil {
+		t.Fatalf("GeoJSONRhumbLength() error = %v", err)
+	}
+	if want := RhumbLineDistance(0, 0, 0, 1) + RhumbLineDistance(0, 1, 1, 1); math.Abs(rhumb-want) > 1e-9 {
+		t.Errorf("rhumb length = %v, want %v", rhumb, want)
+	}
+
+	if _, err := GeoJSONLength(waypoints{{0, 0}, {1, 1}}, UnitKilometers); err == nil {
+		t.Error("expected error for user-defined geometry")
+	}
+	if km, err := GeoJSONLength(NewFeature(nil), UnitKilometers); err != nil || km != 0 {
+		t.Errorf("GeoJSONLength(feature without geometry) = %v, %v; want 0", km, err)
+	}
+}
+
+func TestPolygonPerimeter(t *testing.T) {
+	// A one-degree square at the equator: three sides are about 111.19 km and
+	// the northern one slightly shorter.
+	side := GreatCircleDistance(0, 0, 0, 1)
+	outer := 3*side + GreatCircleDistance(1, 0, 1, 1)
+	if math.Abs(side-111.195) > 0.001 {
+		t.Fatalf("side = %v, want about 111.195 km", side)
+	}
+	donut := NewPolygon([][]Position{
+		squarePolygon(0, 0, 1, 1).Coordinates[0],
+		squarePolygon(0.25, 0.25, 0.75, 0.75).Coordinates[0],
+	})
+	hole := 2*GreatCircleDistance(0.25, 0.25, 0.75, 0.25) +
+		GreatCircleDistance(0.25, 0.25, 0.25, 0.75) + GreatCircleDistance(0.75, 0.25, 0.75, 0.75)
+
+	if got := PolygonPerimeter(donut, false, UnitKilometers); math.Abs(got-outer) > 1e-9 {
+		t.Errorf("exterior perimeter = %v km, want %v", got, outer)
+	}
+	if got := PolygonPerimeter(donut, true, UnitKilometers); math.Abs(got-outer-hole) > 1e-9 {
+		t.Errorf("perimeter with hole = %v km, want %v", got, outer+hole)
+	}
+	if got, want := PolygonPerimeter(donut, false, UnitMeters), outer*MetersPerKm; math.Abs(got-want) > 1e-6 {
+		t.Errorf("exterior perimeter = %v m, want %v", got, want)
+	}
+	if got := PolygonPerimeter(Polygon{}, true, UnitKilometers); got != 0 {
+		t.Errorf("empty polygon perimeter = %v, want 0", got)
+	}
+}
+
+func TestCumulativeDistances(t *testing.T) {
+	line := NewLineString([]Position{{0, 0}, {1, 0}, {1, 2}})
+	first := GreatCircleDistance(0, 0, 0, 1)
+	second := GreatCircleDistance(0, 1, 2, 1)
+
+	got, err := CumulativeDistances(line, UnitMeters)
+	if err != nil {
+		t.Fatalf("CumulativeDistances() error = %v", err)
+	}
+	want := []float64{0, first * MetersPerKm, (first + second) * MetersPerKm}
+	if len(got) != len(want) {
+		t.Fatalf("CumulativeDistances() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-6 {
+			t.Errorf("distance[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+	length, _ := LineStringLength(line, UnitMeters)
+	if got[2] != length {
+		t.Errorf("last distance = %v, want line length %v", got[2], length)
+	}
+
+	if _, err := CumulativeDistances(NewLineString([]Position{{0, 0}}), UnitKilometers); err == nil {
+		t.Error("expected error for single-vertex line")
+	}
+}
+
+func TestDecodeFeatureCollection(t *testing.T) {
+	data := []byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"name":"a"},"geometry":{"type":"Point","coordinates":[1,2]}},
+		{"type":"Feature","properties":null,"geometry":{"type":"LineString","coordinates":[[0,0],[1,1,5]]}},
+		{"type":"Feature","properties":null,"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}}
+	]}`)
+	obj, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	fc, ok := obj.(FeatureCollection)
+	if !ok {
+		t.Fatalf("Decode = %T, want FeatureCollection", obj)
+	}
+	if len(fc.Features) != 3 {
+		t.Fatalf("got %d features, want 3", len(fc.Features))
+	}
+	if p, ok := fc.Features[0].Geometry.(Point); !ok || !reflect.DeepEqual(p.Coordinates, Position{1, 2}) {
+		t.Errorf("feature 0 geometry = %#v, want Point [1 2]", fc.Features[0].Geometry)
+	}
+	if l, ok := fc.Features[1].Geometry.(LineString); !ok || l.Coordinates[1].Alt() != 5 {
+		t.Errorf("feature 1 geometry = %#v, want LineString with altitude", fc.Features[1].Geometry)
+	}
+	if _, ok := fc.Features[2].Geometry.(Polygon); !ok {
+		t.Errorf("feature 2 geometry = %T, want Polygon", fc.Features[2].Geometry)
+	}
+
+	if _, err := Decode(bytes.NewReader([]byte(`{"type":"Circle"}`))); err == nil {
+		t.Error("expected error for unknown type")
+	}
+	if _, err := Decode(bytes.NewReader(nil)); err == nil {
+		t.Error("expected error for empty input")
+	}
+}
+
+func TestMarshalEmptyValues(t *testing.T) {
+	cases := []struct {
+		obj  interface{}
+		want string
+	}{
+		{NewFeatureCollection(nil), `{"type":"FeatureCollection","features":[]}`},
+		{NewLineString(nil), `{"type":"LineString","coordinates":[]}`},
+		{NewPolygon(nil), `{"type":"Polygon","coordinates":[]}`},
+		{NewMultiPoint(nil), `{"type":"MultiPoint","coordinates":[]}`},
+		{NewMultiLineString(nil), `{"type":"MultiLineString","coordinates":[]}`},
+		{NewMultiPolygon(nil), `{"type":"MultiPolygon","coordinates":[]}`},
+		{NewGeometryCollection(nil), `{"type":"GeometryCollection","geometries":[]}`},
+	}
+	for _, tc := range cases {
+		data, err := json.Marshal(tc.obj)
+		if err != nil {
+			t.Fatalf("Marshal(%T) error = %v", tc.obj, err)
+		}
+		if string(data) != tc.want {
+			t.Errorf("Marshal(%T) = %s, want %s", tc.obj, data, tc.want)
+		}
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	in := NewFeatureCollection([]Feature{
+		{Type: "Feature", Geometry: NewPoint(1, 2), Properties: map[string]interface{}{"name": "a"}},
+		{Type: "Feature", Geometry: NewLineString([]Position{{0, 0}, {1, 1}}), Properties: map[string]interface{}{"name": "b"}},
+	})
+	var buf bytes.Buffer
+	if err := Encode(&buf, in); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	out, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("round trip = %#v, want %#v", out, in)
+	}
+	if err := Encode(&buf, "nope"); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+}
+
+func TestNearestPointOnLine(t *testing.T) {
+	line := NewLineString([]Position{{0, 0}, {1, 0}, {1, 1, 100}, {2, 1.5}})
+	total, err := GeoJSONLength(line, UnitKilometers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seg0 := GreatCircleDistance(0, 0, 0, 1)
+
+	cases := []struct {
+		name    string
+		p       Point
+		segment int
+		along   float64 // expected location along the line, -1 to skip
+	}{
+		{"interior", NewPoint(0.4, 0.1), 0, GreatCircleDistance(0, 0, 0, 0.4)},
+		{"inner vertex", NewPoint(1.2, -0.2), 0, seg0},
+		{"start", NewPoint(-0.5, -0.1), 0, 0},
+		{"end", NewPoint(2.5, 2), 2, total},
+		{"later segment", NewPoint(1.05, 0.5), 1, -1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			nearest, segment, dist, along, err := NearestPointOnLine(line, tc.p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if segment != tc.segment {
+				t.Errorf("segment = %d, want %d", segment, tc.segment)
+			}
+			if tc.along >= 0 && math.Abs(along-tc.along) > 1e-6 {
+				t.Errorf("along = %v, want %v", along, tc.along)
+			}
+			want, err := CrossTrackDistanceToLine(line, tc.p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(dist-want) > 1e-9 {
+				t.Errorf("distance = %v, want %v", dist, want)
+			}
+
+			back, err := LineStringPointAtDistance(line, along)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lat1, lon1 := positionLatLon(nearest.Coordinates)
+			lat2, lon2 := positionLatLon(back.Coordinates)
+			if d := GreatCircleDistance(lat1, lon1, lat2, lon2); d > 0.001 {
+				t.Errorf("LineStringPointAtDistance(%v) is %v km from nearest %v", along, d, nearest.Coordinates)
+			}
+			if math.Abs(nearest.Coordinates.Alt()-back.Coordinates.Alt()) > 1e-6 {
+				t.Errorf("altitude = %v, want %v", nearest.Coordinates.Alt(), back.Coordinates.Alt())
+			}
+		})
+	}
+
+	if _, _, _, _, err := NearestPointOnLine(NewLineString([]Position{{0, 0}}), NewPoint(0, 0)); err == nil {
+		t.Error("expected error for a single-coordinate line")
+	}
+}
+
+func TestLineSliceAlong(t *testing.T) {
+	line := NewLineString([]Position{{0, 0}, {1, 0}, {1, 1}, {2, 1}})
+	seg := GreatCircleDistance(0, 0, 0, 1)
+
+	got, err := LineSliceAlong(line, seg/2, seg+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Coordinates) != 3 || !reflect.DeepEqual(got.Coordinates[1], Position{1, 0}) {
+		t.Fatalf("slice = %v, want start, vertex [1 0], stop", got.Coordinates)
+	}
+	for i, d := range []float64{seg / 2, seg + 1} {
+		want, _ := LineStringPointAtDistance(line, d)
+		p := got.Coordinates[2*i]
+		if dist := GreatCircleDistance(p.Lat(), p.Lon(), want.Coordinates.Lat(), want.Coordinates.Lon()); dist > 1e-9 {
+			t.Errorf("cut %d at %v, want %v", i, p, want.Coordinates)
+		}
+	}
+
+	whole, err := LineSliceAlong(line, -5, 1e6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(whole.Coordinates, line.Coordinates) {
+		t.Errorf("clamped slice = %v, want the whole line", whole.Coordinates)
+	}
+	if _, err := LineSliceAlong(line, 10, 5); err == nil {
+		t.Error("expected error when start exceeds stop")
+	}
+}
+
+func TestLineChunk(t *testing.T) {
+	line := NewLineString([]Position{{0, 0}, {1, 0, 10}, {1.5, 0.8}, {3, 1}})
+	total, _ := GeoJSONLength(line, UnitKilometers)
+	chunks, err := LineChunk(line, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int(math.Ceil(total / 40)); len(chunks) != want {
+		t.Fatalf("got %d chunks, want %d", len(chunks), want)
+	}
+
+	var sum float64
+	var joined []Position
+	for i, c := range chunks {
+		length, _ := GeoJSONLength(c, UnitKilometers)
+		sum += length
+		if i < len(chunks)-1 && math.Abs(length-40) > 1e-6 {
+			t.Errorf("chunk %d is %v km, want 40", i, length)
+		}
+		if i == 0 {
+			joined = append(joined, c.Coordinates...)
+			continue
+		}
+		if !reflect.DeepEqual(c.Coordinates[0], joined[len(joined)-1]) {
+			t.Errorf("chunk %d starts at %v, previous ended at %v", i, c.Coordinates[0], joined[len(joined)-1])
+		}
+		joined = append(joined, c.Coordinates[1:]...)
+	}
+	if math.Abs(sum-total) > 1e-6 {
+		t.Errorf("chunk lengths sum to %v, want %v", sum, total)
+	}
+
+	// The original vertices survive unchanged and in order; everything else
+	// lies on the line.
+	next := 0
+	for _, p := range joined {
+		if next < len(line.Coordinates) && reflect.DeepEqual(p, line.Coordinates[next]) {
+			next++
+			continue
+		}
+		if d, _ := CrossTrackDistanceToLine(line, Point{Coordinates: p}); d > 1e-6 {
+			t.Errorf("cut point %v is %v km off the line", p, d)
+		}
+	}
+	if next != len(line.Coordinates) {
+		t.Errorf("only %d of %d original vertices found in the chunks", next, len(line.Coordinates))
+	}
+
+	// Cuts that fall on vertices reproduce the line exactly.
+	equator := NewLineString([]Position{{0, 0}, {1, 0}, {2, 0}})
+	chunks, err = LineChunk(equator, GreatCircleDistance(0, 0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LineString{
+		NewLineString([]Position{{0, 0}, {1, 0}}),
+		NewLineString([]Position{{1, 0}, {2, 0}}),
+	}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Errorf("chunks = %v, want %v", chunks, want)
+	}
+
+	short, err := LineChunk(line, 1e4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(short) != 1 || !reflect.DeepEqual(short[0], line) {
+		t.Errorf("short line chunks = %v, want one copy", short)
+	}
+	if _, err := LineChunk(line, 0); err == nil {
+		t.Error("expected error for zero segment length")
+	}
+}
+
+func TestWeightedCentroid(t *testing.T) {
+	point := func(lon, lat float64, props map[string]interface{}) Feature {
+		return Feature{Type: "Feature", Geometry: NewPoint(lon, lat), Properties: props}
+	}
+	fc := NewFeatureCollection([]Feature{
+		point(0, 0, map[string]interface{}{"pop": 10.0}),
+		point(10, 0, map[string]interface{}{"pop": int64(1000)}),
+		point(0, 10, map[string]interface{}{"pop": json.Number("10")}),
+		point(-50, -50, map[string]interface{}{"pop": "many"}),
+		point(-50, 50, nil),
+		{Type: "Feature", Geometry: NewLineString([]Position{{0, 0}, {1, 1}}), Properties: map[string]interface{}{"pop": 1e6}},
+	})
+	got, err := WeightedCentroid(fc, "pop")
+	if err != nil {
+		t.Fatalf("WeightedCentroid: %v", err)
+	}
+	lat, lon := positionLatLon(got.Coordinates)
+	if d := GreatCircleDistance(lat, lon, 0, 10); d > 50 {
+		t.Errorf("centroid %v is %v km from the dominant point", got.Coordinates, d)
+	}
+	unweighted := SphericalMean([]Position{{0, 0}, {10, 0}, {0, 10}})
+	if GreatCircleDistance(lat, lon, 0, 10) >= GreatCircleDistance(unweighted.Lat(), unweighted.Lon(), 0, 10) {
+		t.Errorf("centroid %v not pulled toward the dominant point", got.Coordinates)
+	}
+
+	// Equal weights across the antimeridian average onto it.
+	across := NewFeatureCollection([]Feature{
+		point(179, 0, map[string]interface{}{"w": 1}),
+		point(-179, 0, map[string]interface{}{"w": 1}),
+	})
+	got, err = WeightedCentroid(across, "w")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(math.Abs(got.Coordinates.Lon())-180) > 1e-9 {
+		t.Errorf("antimeridian centroid = %v, want lon ±180", got.Coordinates)
+	}
+
+	if _, err := WeightedCentroid(fc, "missing"); err == nil {
+		t.Error("expected error when no feature has the weight")
+	}
+}
+
+func TestPointInPolygon(t *testing.T) {
+	donut := NewPolygon([][]Position{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}},
+	})
+	dateline := NewPolygon([][]Position{{{170, -10}, {-170, -10}, {-170, 10}, {170, 10}, {170, -10}}})
+	tests := []struct {
+		name           string
+		p              Point
+		obj            interface{}
+		ignoreBoundary bool
+		want           bool
+	}{
+		{"interior", NewPoint(2, 2), donut, false, true},
+		{"outside", NewPoint(12, 2), donut, false, false},
+		{"outer edge counted", NewPoint(10, 5), donut, false, true},
+		{"outer edge ignored", NewPoint(10, 5), donut, true, false},
+		{"vertex counted", NewPoint(0, 0), donut, false, true},
+		{"vertex ignored", NewPoint(0, 0), donut, true, false},
+		{"in hole", NewPoint(5, 5), donut, false, false},
+		{"hole edge counted", NewPoint(4, 5), donut, false, true},
+		{"hole edge ignored", NewPoint(4, 5), donut, true, false},
+		{"dateline east", NewPoint(179.9, 0), dateline, false, true},
+		{"dateline west", NewPoint(-179.9, 5), dateline, false, true},
+		{"dateline meridian", NewPoint(180, 0), dateline, true, true},
+		{"dateline outside", NewPoint(0, 0), dateline, false, false},
+		{"dateline outside east", NewPoint(160, 0), dateline, false, false},
+		{"multipolygon", NewPoint(179.9, 0), NewMultiPolygon([][][]Position{donut.Coordinates, dateline.Coordinates}), false, true},
+		{"feature", NewPoint(2, 2), NewFeature(donut), false, true},
+		{"collection", NewPoint(5, 5), NewFeatureCollection([]Feature{NewFeature(donut), NewFeature(dateline)}), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PointInPolygon(tt.p, tt.obj, tt.ignoreBoundary)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("PointInPolygon(%v) = %v, want %v", tt.p.Coordinates, got, tt.want)
+			}
+		})
+	}
+	if _, err := PointInPolygon(NewPoint(0, 0), NewPoint(0, 0), false); err == nil {
+		t.Error("expected error for a Point container")
+	}
+}
+
+func TestLinesNearestPoints(t *testing.T) {
+	a := NewLineString([]Position{{0, 0}, {5, 0}, {10, 0}})
+	b := NewLineString([]Position{{2, 0.5}, {8, 0.5}})
+	pa, pb, dist, err := LinesNearestPoints(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := GreatCircleDistance(0, 0, 0.5, 0)
+	if math.Abs(dist-want) > 1e-6 {
+		t.Errorf("distance = %v, want %v", dist, want)
+	}
+	if pb.Coordinates.Lat() != 0.5 || math.Abs(pa.Coordinates.Lat()) > 1e-9 ||
+		math.Abs(pa.Coordinates.Lon()-pb.Coordinates.Lon()) > 1e-9 {
+		t.Errorf("points = %v and %v, want one straight above the other", pa.Coordinates, pb.Coordinates)
+	}
+	if got := GreatCircleDistance(pa.Coordinates.Lat(), pa.Coordinates.Lon(), pb.Coordinates.Lat(), pb.Coordinates.Lon()); math.Abs(got-dist) > 1e-9 {
+		t.Errorf("points are %v apart, reported %v", got, dist)
+	}
+
+	// The nearest point of a can lie inside one of its segments.
+	c := NewLineString([]Position{{3, 2}, {4, 1}, {6, 3}})
+	pa, pb, dist, err = LinesNearestPoints(a, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pb.Coordinates, Position{4, 1}) || math.Abs(dist-GreatCircleDistance(0, 4, 1, 4)) > 1e-6 {
+		t.Errorf("nearest = %v, %v at %v km; want vertex [4 1] of c", pa.Coordinates, pb.Coordinates, dist)
+	}
+
+	crossing := NewLineString([]Position{{5, -1}, {5, 1}})
+	pa, pb, dist, err = LinesNearestPoints(a, crossing)
+	if err != nil || dist != 0 || !reflect.DeepEqual(pa.Coordinates, pb.Coordinates) {
+		t.Errorf("crossing lines: %v, %v, %v, %v; want a shared point at 0 km", pa, pb, dist, err)
+	}
+
+	if _, _, _, err := LinesNearestPoints(a, NewLineString([]Position{{0, 0}})); err == nil {
+		t.Error("expected error for a single-coordinate line")
+	}
+}
+
+func TestLatLonConstructors(t *testing.T) {
+	if got, want := NewPointLatLon(40.7, -74), NewPoint(-74, 40.7); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewPointLatLon(40.7, -74) = %v, want %v", got, want)
+	}
+	if got, want := PositionFromLatLon(40.7, -74), (Position{-74, 40.7}); !reflect.DeepEqual(got, want) {
+		t.Errorf("PositionFromLatLon(40.7, -74) = %v, want %v", got, want)
+	}
+	if lat, lon := positionLatLon(PositionFromLatLon(40.7, -74)); lat != 40.7 || lon != -74 {
+		t.Errorf("positionLatLon() = %v, %v, want 40.7, -74", lat, lon)
+	}
+}
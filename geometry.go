@@ -0,0 +1,568 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// BBox is a bounding box in degrees.
+type BBox struct {
+	MinLon float64
+	MinLat float64
+	MaxLon float64
+	MaxLat float64
+}
+
+// emptyBBox returns a box that contains nothing; extending it with a position
+// gives a box around that position alone.
+func emptyBBox() BBox {
+	return BBox{
+		MinLon: math.Inf(1),
+		MinLat: math.Inf(1),
+		MaxLon: math.Inf(-1),
+		MaxLat: math.Inf(-1),
+	}
+}
+
+// IsEmpty reports whether the box contains no positions, as returned by Bounds
+// for a geometry without coordinates.
+func (b BBox) IsEmpty() bool {
+	return b.MinLon > b.MaxLon || b.MinLat > b.MaxLat
+}
+
+func (b *BBox) extend(p Position) {
+	b.MinLon = math.Min(b.MinLon, p[0])
+	b.MinLat = math.Min(b.MinLat, p[1])
+	b.MaxLon = math.Max(b.MaxLon, p[0])
+	b.MaxLat = math.Max(b.MaxLat, p[1])
+}
+
+// Geometry is implemented by the GeoJSON types of this package and can be
+// implemented by user types to use them with the GeoJSON helpers. Feature and
+// FeatureCollection implement it too, visiting the positions of their members.
+//
+// Helpers that need to know the shape of a geometry, such as
+// GeoJSONPointOnSurface, treat user types as a set of positions.
+type Geometry interface {
+	// GeometryType returns the GeoJSON type name, e.g. "Polygon".
+	GeometryType() string
+	// ForEachPosition calls fn for every position in order.
+	ForEachPosition(fn func(Position))
+	// Bounds returns the bounding box of all positions.
+	Bounds() BBox
+}
+
+// boundsOf returns the bounding box of the positions of g.
+func boundsOf(g Geometry) BBox {
+	b := emptyBBox()
+	g.ForEachPosition(b.extend)
+	return b
+}
+
+// GeometryType returns "Point".
+func (p Point) GeometryType() string { return "Point" }
+
+// ForEachPosition calls fn with the point's position.
+func (p Point) ForEachPosition(fn func(Position)) { fn(p.Coordinates) }
+
+// Bounds returns the bounding box of the point.
+func (p Point) Bounds() BBox { return boundsOf(p) }
+
+// GeometryType returns "MultiPoint".
+func (mp MultiPoint) GeometryType() string { return "MultiPoint" }
+
+// ForEachPosition calls fn for every point.
+func (mp MultiPoint) ForEachPosition(fn func(Position)) {
+	for _, p := range mp.Coordinates {
+		fn(p)
+	}
+}
+
+// Bounds returns the bounding box of all points.
+func (mp MultiPoint) Bounds() BBox { return boundsOf(mp) }
+
+// GeometryType returns "LineString".
+func (l LineString) GeometryType() string { return "LineString" }
+
+// ForEachPosition calls fn for every vertex of the line.
+func (l LineString) ForEachPosition(fn func(Position)) {
+	for _, p := range l.Coordinates {
+		fn(p)
+	}
+}
+
+// Bounds returns the bounding box of the line.
+func (l LineString) Bounds() BBox { return boundsOf(l) }
+
+// GeometryType returns "Polygon".
+func (p Polygon) GeometryType() string { return "Polygon" }
+
+// ForEachPosition calls fn for every position of every ring.
+func (p Polygon) ForEachPosition(fn func(Position)) {
+	for _, ring := range p.Coordinates {
+		for _, pos := range ring {
+			fn(pos)
+		}
+	}
+}
+
+// Bounds returns the bounding box of the polygon.
+func (p Polygon) Bounds() BBox { return boundsOf(p) }
+
+// GeometryType returns "MultiLineString".
+func (ml MultiLineString) GeometryType() string { return "MultiLineString" }
+
+// ForEachPosition calls fn for every vertex of every line.
+func (ml MultiLineString) ForEachPosition(fn func(Position)) {
+	for _, line := range ml.Coordinates {
+		for _, p := range line {
+			fn(p)
+		}
+	}
+}
+
+// Bounds returns the bounding box of all lines.
+func (ml MultiLineString) Bounds() BBox { return boundsOf(ml) }
+
+// GeometryType returns "MultiPolygon".
+func (mp MultiPolygon) GeometryType() string { return "MultiPolygon" }
+
+// ForEachPosition calls fn for every position of every ring of every polygon.
+func (mp MultiPolygon) ForEachPosition(fn func(Position)) {
+	for _, poly := range mp.Coordinates {
+		for _, ring := range poly {
+			for _, p := range ring {
+				fn(p)
+			}
+		}
+	}
+}
+
+// Bounds returns the bounding box of all polygons.
+func (mp MultiPolygon) Bounds() BBox { return boundsOf(mp) }
+
+// GeometryType returns "GeometryCollection".
+func (gc GeometryCollection) GeometryType() string { return "GeometryCollection" }
+
+// ForEachPosition calls fn for every position of every member geometry. Members
+// that do not implement Geometry are skipped.
+func (gc GeometryCollection) ForEachPosition(fn func(Position)) {
+	for _, child := range gc.Geometries {
+		if g, err := geometryOf(child); err == nil {
+			g.ForEachPosition(fn)
+		}
+	}
+}
+
+// Bounds returns the bounding box of all member geometries.
+func (gc GeometryCollection) Bounds() BBox { return boundsOf(gc) }
+
+// GeometryType returns "Feature".
+func (f Feature) GeometryType() string { return "Feature" }
+
+// ForEachPosition calls fn for every position of the feature's geometry. A
+// geometry that does not implement Geometry is skipped.
+func (f Feature) ForEachPosition(fn func(Position)) {
+	if g, err := geometryOf(f.Geometry); err == nil {
+		g.ForEachPosition(fn)
+	}
+}
+
+// Bounds returns the bounding box of the feature's geometry.
+func (f Feature) Bounds() BBox { return boundsOf(f) }
+
+// GeometryType returns "FeatureCollection".
+func (fc FeatureCollection) GeometryType() string { return "FeatureCollection" }
+
+// ForEachPosition calls fn for every position of every feature.
+func (fc FeatureCollection) ForEachPosition(fn func(Position)) {
+	for _, f := range fc.Features {
+		f.ForEachPosition(fn)
+	}
+}
+
+// Bounds returns the bounding box of all features.
+func (fc FeatureCollection) Bounds() BBox { return boundsOf(fc) }
+
+// geometryOf returns obj as a Geometry. Pointers to types whose values
+// implement Geometry are dereferenced, so callers can switch on value types
+// only. A nil pointer gives an error naming its type, e.g. "nil polygon".
+func geometryOf(obj interface{}) (Geometry, error) {
+	if v := reflect.ValueOf(obj); v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, fmt.Errorf("nil %s", strings.ToLower(v.Type().Elem().Name()))
+		}
+		if g, ok := v.Elem().Interface().(Geometry); ok {
+			return g, nil
+		}
+	}
+	if g, ok := obj.(Geometry); ok {
+		return g, nil
+	}
+	return nil, fmt.Errorf("unsupported geojson type %T", obj)
+}
+
+// checkMembers returns an error if a Feature or GeometryCollection within g has
+// a geometry that geometryOf rejects. A Feature with a null geometry is valid
+// GeoJSON and is accepted; the functions checking it skip such features.
+func checkMembers(g Geometry) error {
+	switch g := g.(type) {
+	case GeometryCollection:
+		for _, child := range g.Geometries {
+			member, err := geometryOf(child)
+			if err != nil {
+				return err
+			}
+			if err := checkMembers(member); err != nil {
+				return err
+			}
+		}
+	case Feature:
+		if g.Geometry == nil {
+			return nil
+		}
+		child, err := geometryOf(g.Geometry)
+		if err != nil {
+			return err
+		}
+		return checkMembers(child)
+	case FeatureCollection:
+		for _, f := range g.Features {
+			if err := checkMembers(f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ComputeBBox returns the RFC 7946 bounding box [west, south, east, north] of a
+// GeoJSON object. A line or ring crosses the antimeridian where an edge spans
+// more than 180° of longitude, the rule CutAtAntimeridian uses; the box of a
+// geometry with such an edge wraps, with west > east, e.g. [170, -10, -170, 10].
+// Geometries without crossing edges get the plain longitude range, however
+// wide. Points have no edges, so for an object made only of points the
+// longitudes are treated as points on a circle and the box spans the narrowest
+// arc that contains them all.
+func ComputeBBox(obj interface{}) ([]float64, error) {
+	g, err := geometryOf(obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMembers(g); err != nil {
+		return nil, err
+	}
+
+	var spans [][2]float64
+	south, north := math.Inf(1), math.Inf(-1)
+	crosses, edges := false, false
+	err = forEachPath(g, func(path []Position) {
+		// Follow the path with longitudes unwrapped across the antimeridian,
+		// so a crossing path spans a range beyond ±180.
+		lon := path[0][0]
+		span := [2]float64{lon, lon}
+		for i, p := range path {
+			south, north = math.Min(south, p[1]), math.Max(north, p[1])
+			if i == 0 {
+				continue
+			}
+			edges = true
+			d := p[0] - path[i-1][0]
+			if math.Abs(d) > 180 {
+				crosses = true
+				d -= math.Copysign(360, d)
+			}
+			lon += d
+			span = [2]float64{math.Min(span[0], lon), math.Max(span[1], lon)}
+		}
+		spans = append(spans, span)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(spans) == 0 {
+		return nil, errors.New("no coordinates found")
+	}
+
+	if !crosses && edges {
+		west, east := spans[0][0], spans[0][1]
+		for _, s := range spans[1:] {
+			west, east = math.Min(west, s[0]), math.Max(east, s[1])
+		}
+		return []float64{west, south, east, north}, nil
+	}
+	west, east := narrowestLonArc(spans)
+	return []float64{west, south, east, north}, nil
+}
+
+// narrowestLonArc returns the west and east ends of the narrowest arc of
+// longitude covering every span, which is what is left after removing the
+// largest gap between them. Spans may run past ±180 and wider ones are
+// brought into range first. When the spans cover every longitude the arc is
+// [-180, 180].
+func narrowestLonArc(spans [][2]float64) (west, east float64) {
+	arcs := make([][2]float64, len(spans))
+	for i, s := range spans {
+		if s[1]-s[0] >= 360 {
+			return -180, 180
+		}
+		start := normalizeLongitude(s[0])
+		arcs[i] = [2]float64{start, start + s[1] - s[0]}
+	}
+	sort.Slice(arcs, func(i, j int) bool { return arcs[i][0] < arcs[j][0] })
+
+	// reach is the easternmost longitude covered by the arcs so far; a gap
+	// opens where the next arc starts beyond it.
+	reach := arcs[0][1]
+	type gap struct{ width, west, east float64 }
+	var gaps []gap
+	for _, a := range arcs[1:] {
+		if a[0] > reach {
+			gaps = append(gaps, gap{a[0] - reach, a[0], reach})
+		}
+		reach = math.Max(reach, a[1])
+	}
+	// The gap across the antimeridian, from the end of the arcs round to
+	// their start, wins ties so that boxes only wrap when they must.
+	widest := gap{arcs[0][0] + 360 - reach, arcs[0][0], reach}
+	for _, g := range gaps {
+		if g.width > widest.width {
+			widest = g
+		}
+	}
+	if widest.width <= 0 {
+		return -180, 180
+	}
+	west, east = widest.west, widest.east
+	if east > 180 {
+		east -= 360
+	}
+	return west, east
+}
+
+// forEachPath calls fn with every line and ring of g, and with every point as
+// a path of its own. User-defined geometries are taken as points.
+func forEachPath(g Geometry, fn func([]Position)) error {
+	switch g := g.(type) {
+	case Point:
+		fn([]Position{g.Coordinates})
+	case MultiPoint:
+		for _, p := range g.Coordinates {
+			fn([]Position{p})
+		}
+	case LineString:
+		if len(g.Coordinates) > 0 {
+			fn(g.Coordinates)
+		}
+	case MultiLineString:
+		for _, line := range g.Coordinates {
+			if len(line) > 0 {
+				fn(line)
+			}
+		}
+	case Polygon:
+		for _, ring := range g.Coordinates {
+			if len(ring) > 0 {
+				fn(ring)
+			}
+		}
+	case MultiPolygon:
+		for _, rings := range g.Coordinates {
+			for _, ring := range rings {
+				if len(ring) > 0 {
+					fn(ring)
+				}
+			}
+		}
+	case GeometryCollection:
+		for _, child := range g.Geometries {
+			member, err := geometryOf(child)
+			if err != nil {
+				return err
+			}
+			if err := forEachPath(member, fn); err != nil {
+				return err
+			}
+		}
+	case Feature:
+		if g.Geometry == nil {
+			return nil
+		}
+		child, err := geometryOf(g.Geometry)
+		if err != nil {
+			return err
+		}
+		return forEachPath(child, fn)
+	case FeatureCollection:
+		for _, f := range g.Features {
+			if err := forEachPath(f, fn); err != nil {
+				return err
+			}
+		}
+	default:
+		g.ForEachPosition(func(p Position) {
+			fn([]Position{p})
+		})
+	}
+	return nil
+}
+
+// WithBBox returns a copy of a GeoJSON object with the bbox member set on it
+// and, for features and collections, on every member geometry. Coordinates are
+// shared with the original. Geometries without coordinates get no bbox, and
+// user-defined geometries are returned unchanged.
+func WithBBox(obj interface{}) (interface{}, error) {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMembers(geom); err != nil {
+		return nil, err
+	}
+	bbox, _ := ComputeBBox(geom)
+
+	switch g := geom.(type) {
+	case Point:
+		g.BBox = bbox
+		return g, nil
+	case MultiPoint:
+		g.BBox = bbox
+		return g, nil
+	case LineString:
+		g.BBox = bbox
+		return g, nil
+	case Polygon:
+		g.BBox = bbox
+		return g, nil
+	case MultiLineString:
+		g.BBox = bbox
+		return g, nil
+	case MultiPolygon:
+		g.BBox = bbox
+		return g, nil
+	case GeometryCollection:
+		geometries := make([]interface{}, len(g.Geometries))
+		for i, child := range g.Geometries {
+			withBBox, err := WithBBox(child)
+			if err != nil {
+				return nil, err
+			}
+			geometries[i] = withBBox
+		}
+		g.Geometries = geometries
+		g.BBox = bbox
+		return g, nil
+	case Feature:
+		if g.Geometry == nil {
+			return g, nil
+		}
+		child, err := WithBBox(g.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		g.Geometry = child
+		g.BBox = bbox
+		return g, nil
+	case FeatureCollection:
+		features := make([]Feature, len(g.Features))
+		for i, f := range g.Features {
+			withBBox, err := WithBBox(f)
+			if err != nil {
+				return nil, err
+			}
+			features[i] = withBBox.(Feature)
+		}
+		g.Features = features
+		g.BBox = bbox
+		return g, nil
+	default:
+		return g, nil
+	}
+}
+
+// RingIsClockwise reports whether ring runs clockwise in the lon/lat plane.
+// Rings with fewer than three positions or no area are not clockwise.
+func RingIsClockwise(ring []Position) bool {
+	area, _, _ := ringAreaCentroid(ring)
+	return area < 0
+}
+
+// Rewind returns a copy of a GeoJSON object with the rings of every polygon
+// wound as RFC 7946 requires: outer rings counterclockwise and holes
+// clockwise. Rings are copied rather than reversed in place. Geometries without
+// rings and user-defined geometries are returned unchanged.
+func Rewind(obj interface{}) (interface{}, error) {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMembers(geom); err != nil {
+		return nil, err
+	}
+
+	switch g := geom.(type) {
+	case Polygon:
+		g.Coordinates = rewindRings(g.Coordinates)
+		return g, nil
+	case MultiPolygon:
+		polys := make([][][]Position, len(g.Coordinates))
+		for i, poly := range g.Coordinates {
+			polys[i] = rewindRings(poly)
+		}
+		g.Coordinates = polys
+		return g, nil
+	case GeometryCollection:
+		geometries := make([]interface{}, len(g.Geometries))
+		for i, child := range g.Geometries {
+			rewound, err := Rewind(child)
+			if err != nil {
+				return nil, err
+			}
+			geometries[i] = rewound
+		}
+		g.Geometries = geometries
+		return g, nil
+	case Feature:
+		if g.Geometry == nil {
+			return g, nil
+		}
+		child, err := Rewind(g.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		g.Geometry = child
+		return g, nil
+	case FeatureCollection:
+		features := make([]Feature, len(g.Features))
+		for i, f := range g.Features {
+			rewound, err := Rewind(f)
+			if err != nil {
+				return nil, err
+			}
+			features[i] = rewound.(Feature)
+		}
+		g.Features = features
+		return g, nil
+	default:
+		return g, nil
+	}
+}
+
+// rewindRings returns the rings of a polygon with the first counterclockwise
+// and the rest clockwise.
+func rewindRings(rings [][]Position) [][]Position {
+	out := make([][]Position, len(rings))
+	for i, ring := range rings {
+		out[i] = append([]Position(nil), ring...)
+		if RingIsClockwise(ring) != (i > 0) {
+			for a, b := 0, len(out[i])-1; a < b; a, b = a+1, b-1 {
+				out[i][a], out[i][b] = out[i][b], out[i][a]
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,461 @@
+package geo
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// waypoints is a user-defined geometry with value receivers.
+type waypoints []Position
+
+func (w waypoints) GeometryType() string { return "Waypoints" }
+
+func (w waypoints) ForEachPosition(fn func(Position)) {
+	for _, p := range w {
+		fn(p)
+	}
+}
+
+func (w waypoints) Bounds() BBox { return boundsOf(w) }
+
+// circleGeometry is a user-defined geometry with pointer receivers.
+type circleGeometry struct {
+	center Position
+	radius float64 // degrees
+}
+
+func (c *circleGeometry) GeometryType() string { return "Circle" }
+
+func (c *circleGeometry) ForEachPosition(fn func(Position)) {
+	fn(Position{c.center[0] - c.radius, c.center[1]})
+	fn(Position{c.center[0], c.center[1] - c.radius})
+	fn(Position{c.center[0] + c.radius, c.center[1]})
+	fn(Position{c.center[0], c.center[1] + c.radius})
+}
+
+func (c *circleGeometry) Bounds() BBox {
+	return BBox{
+		MinLon: c.center[0] - c.radius,
+		MinLat: c.center[1] - c.radius,
+		MaxLon: c.center[0] + c.radius,
+		MaxLat: c.center[1] + c.radius,
+	}
+}
+
+func TestGeometryBounds(t *testing.T) {
+	tests := []struct {
+		name string
+		geom Geometry
+		want BBox
+	}{
+		{"point", NewPoint(3, 4), BBox{3, 4, 3, 4}},
+		{"linestring", NewLineString([]Position{{0, 0}, {2, -1}, {1, 5}}), BBox{0, -1, 2, 5}},
+		{"polygon", squarePolygon(-1, -2, 3, 4), BBox{-1, -2, 3, 4}},
+		{"multilinestring", NewMultiLineString([][]Position{{{0, 0}, {1, 1}}, {{5, -5}, {6, 0}}}), BBox{0, -5, 6, 1}},
+		{"multipolygon", NewMultiPolygon([][][]Position{squarePolygon(0, 0, 1, 1).Coordinates, squarePolygon(4, 4, 5, 6).Coordinates}), BBox{0, 0, 5, 6}},
+		{"feature", NewFeature(NewLineString([]Position{{1, 1}, {2, 3}})), BBox{1, 1, 2, 3}},
+		{"featurecollection", NewFeatureCollection([]Feature{NewFeature(NewPoint(-5, 0)), NewFeature(NewPoint(5, 2))}), BBox{-5, 0, 5, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.geom.Bounds(); got != tt.want {
+				t.Errorf("Bounds() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if b := NewLineString(nil).Bounds(); !b.IsEmpty() {
+		t.Errorf("empty linestring Bounds() = %+v, want empty", b)
+	}
+}
+
+func TestGeometryTypeNames(t *testing.T) {
+	geoms := map[string]Geometry{
+		"Point":             Point{},
+		"LineString":        LineString{},
+		"Polygon":           Polygon{},
+		"MultiLineString":   MultiLineString{},
+		"MultiPolygon":      MultiPolygon{},
+		"Feature":           Feature{},
+		"FeatureCollection": FeatureCollection{},
+	}
+	for want, g := range geoms {
+		if got := g.GeometryType(); got != want {
+			t.Errorf("GeometryType() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestUserDefinedGeometry(t *testing.T) {
+	w := waypoints{{0, 0}, {4, 0}, {4, 2}, {1, 3}}
+
+	center, err := GeoJSONCenter(w)
+	if err != nil {
+		t.Fatalf("GeoJSONCenter() error = %v", err)
+	}
+	if !reflect.DeepEqual(center.Coordinates, Position{2, 1.5}) {
+		t.Errorf("GeoJSONCenter() = %v, want [2 1.5]", center.Coordinates)
+	}
+
+	mass, err := GeoJSONCenterOfMass(w)
+	if err != nil {
+		t.Fatalf("GeoJSONCenterOfMass() error = %v", err)
+	}
+	if !reflect.DeepEqual(mass.Coordinates, Position{2.25, 1.25}) {
+		t.Errorf("GeoJSONCenterOfMass() = %v, want [2.25 1.25]", mass.Coordinates)
+	}
+
+	surface, err := GeoJSONPointOnSurface(w)
+	if err != nil {
+		t.Fatalf("GeoJSONPointOnSurface() error = %v", err)
+	}
+	found := false
+	for _, p := range w {
+		if reflect.DeepEqual(p, surface.Coordinates) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("GeoJSONPointOnSurface() = %v, want one of the vertices", surface.Coordinates)
+	}
+
+	if _, err := PolygonPointDistance(w, NewPoint(1, 1)); err == nil {
+		t.Error("PolygonPointDistance() expected error for a non-polygonal geometry")
+	}
+
+	// User geometries work inside features and collections, including
+	// pointer-receiver implementations.
+	fc := NewFeatureCollection([]Feature{
+		NewFeature(w),
+		NewFeature(&circleGeometry{center: Position{10, 10}, radius: 1}),
+	})
+	center, err = GeoJSONCenter(fc)
+	if err != nil {
+		t.Fatalf("GeoJSONCenter(collection) error = %v", err)
+	}
+	if !reflect.DeepEqual(center.Coordinates, Position{5.5, 5.5}) {
+		t.Errorf("GeoJSONCenter(collection) = %v, want [5.5 5.5]", center.Coordinates)
+	}
+	positions, err := collectPositions(&fc)
+	if err != nil {
+		t.Fatalf("collectPositions() error = %v", err)
+	}
+	if len(positions) != 8 {
+		t.Errorf("positions = %d, want 8", len(positions))
+	}
+}
+
+func TestGeometryOfPointers(t *testing.T) {
+	poly := squarePolygon(0, 0, 2, 2)
+	g, err := geometryOf(&poly)
+	if err != nil {
+		t.Fatalf("geometryOf() error = %v", err)
+	}
+	if _, ok := g.(Polygon); !ok {
+		t.Errorf("geometryOf(*Polygon) = %T, want Polygon", g)
+	}
+
+	var nilPoly *Polygon
+	if _, err := GeoJSONCenter(nilPoly); err == nil || err.Error() != "nil polygon" {
+		t.Errorf("GeoJSONCenter(nil *Polygon) error = %v, want nil polygon", err)
+	}
+	var nilFC *FeatureCollection
+	if _, err := GeoJSONPointOnSurface(nilFC); err == nil || err.Error() != "nil featurecollection" {
+		t.Errorf("GeoJSONPointOnSurface(nil *FeatureCollection) error = %v, want nil featurecollection", err)
+	}
+	if _, err := GeoJSONCenter(42); err == nil {
+		t.Error("GeoJSONCenter(int) expected error")
+	}
+
+	// A pointer geometry inside a feature collection is found as well.
+	fc := NewFeatureCollection([]Feature{NewFeature(&poly)})
+	d, err := PolygonPointDistance(fc, NewPoint(1, 1))
+	if err != nil {
+		t.Fatalf("PolygonPointDistance() error = %v", err)
+	}
+	if d >= 0 {
+		t.Errorf("distance = %v, want negative (inside)", d)
+	}
+}
+
+func equalBBox(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestComputeBBox(t *testing.T) {
+	poly := NewPolygon([][]Position{{{-10, -5}, {20, -5}, {20, 15}, {-10, 15}, {-10, -5}}})
+	got, err := ComputeBBox(poly)
+	if err != nil {
+		t.Fatalf("ComputeBBox() error = %v", err)
+	}
+	if want := []float64{-10, -5, 20, 15}; !equalBBox(got, want) {
+		t.Errorf("ComputeBBox(polygon) = %v, want %v", got, want)
+	}
+
+	fc := NewFeatureCollection([]Feature{
+		NewFeature(NewPoint(1, 2)),
+		NewFeature(NewLineString([]Position{{3, -4}, {5, 6}})),
+	})
+	got, err = ComputeBBox(fc)
+	if err != nil {
+		t.Fatalf("ComputeBBox() error = %v", err)
+	}
+	if want := []float64{1, -4, 5, 6}; !equalBBox(got, want) {
+		t.Errorf("ComputeBBox(collection) = %v, want %v", got, want)
+	}
+
+	// A polygon from 170°E to 170°W crosses the antimeridian: west > east.
+	dateline := NewPolygon([][]Position{{{170, -10}, {-170, -10}, {-170, 10}, {170, 10}, {170, -10}}})
+	got, err = ComputeBBox(dateline)
+	if err != nil {
+		t.Fatalf("ComputeBBox() error = %v", err)
+	}
+	if want := []float64{170, -10, -170, 10}; !equalBBox(got, want) {
+		t.Errorf("ComputeBBox(dateline) = %v, want %v", got, want)
+	}
+
+	if _, err := ComputeBBox(NewLineString(nil)); err == nil {
+		t.Error("ComputeBBox(empty) expected error")
+	}
+}
+
+func TestComputeBBoxWideGeometry(t *testing.T) {
+	// No edge of this polygon is longer than 180°, so it does not cross the
+	// antimeridian even though its largest gap in longitude is elsewhere.
+	wide := NewPolygon([][]Position{{{-170, 0}, {-10, 0}, {150, 0}, {150, 10}, {-10, 10}, {-170, 10}, {-170, 0}}})
+	got, err := ComputeBBox(wide)
+	if err != nil {
+		t.Fatalf("ComputeBBox() error = %v", err)
+	}
+	if want := []float64{-170, 0, 150, 10}; !equalBBox(got, want) {
+		t.Errorf("ComputeBBox(wide) = %v, want %v", got, want)
+	}
+	if inside, _ := PointInPolygon(NewPoint(-90, 5), wide, false); !inside {
+		t.Fatal("test point should be inside the polygon")
+	}
+
+	// Parts that each stay clear of the antimeridian are not wrapped either.
+	parts := NewMultiLineString([][]Position{{{-175, 0}, {-170, 1}}, {{170, 0}, {175, 1}}})
+	if got, _ := ComputeBBox(parts); !equalBBox(got, []float64{-175, 0, 175, 1}) {
+		t.Errorf("ComputeBBox(parts) = %v, want [-175 0 175 1]", got)
+	}
+
+	// A crossing line next to a part on one side of it still wraps.
+	fc := NewFeatureCollection([]Feature{
+		NewFeature(NewLineString([]Position{{175, 0}, {-175, 0}})),
+		NewFeature(NewPoint(-160, 5)),
+	})
+	if got, _ := ComputeBBox(fc); !equalBBox(got, []float64{175, 0, -160, 5}) {
+		t.Errorf("ComputeBBox(crossing collection) = %v, want [175 0 -160 5]", got)
+	}
+
+	// Points have no edges and take the narrowest arc.
+	points := NewMultiPoint([]Position{{179, 0}, {-179, 1}})
+	if got, _ := ComputeBBox(points); !equalBBox(got, []float64{179, 0, -179, 1}) {
+		t.Errorf("ComputeBBox(points) = %v, want [179 0 -179 1]", got)
+	}
+}
+
+func TestWithBBox(t *testing.T) {
+	poly := squarePolygon(0, 0, 2, 3)
+	fc := NewFeatureCollection([]Feature{
+		NewFeature(&poly),
+		NewFeature(NewPoint(-4, 1)),
+	})
+
+	obj, err := WithBBox(fc)
+	if err != nil {
+		t.Fatalf("WithBBox() error = %v", err)
+	}
+	out := obj.(FeatureCollection)
+	if want := []float64{-4, 0, 2, 3}; !equalBBox(out.BBox, want) {
+		t.Errorf("collection bbox = %v, want %v", out.BBox, want)
+	}
+	if want := []float64{0, 0, 2, 3}; !equalBBox(out.Features[0].BBox, want) {
+		t.Errorf("feature bbox = %v, want %v", out.Features[0].BBox, want)
+	}
+	if g := out.Features[0].Geometry.(Polygon); !equalBBox(g.BBox, []float64{0, 0, 2, 3}) {
+		t.Errorf("geometry bbox = %v", g.BBox)
+	}
+	if fc.BBox != nil || poly.BBox != nil {
+		t.Error("WithBBox modified its input")
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded FeatureCollection
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !equalBBox(decoded.BBox, out.BBox) || !equalBBox(decoded.Features[0].BBox, out.Features[0].BBox) {
+		t.Errorf("decoded bboxes = %v, %v", decoded.BBox, decoded.Features[0].BBox)
+	}
+	if g := decoded.Features[1].Geometry.(Point); !equalBBox(g.BBox, []float64{-4, 1, -4, 1}) {
+		t.Errorf("decoded point bbox = %v", g.BBox)
+	}
+
+	// Without a bbox the member is omitted.
+	data, err = json.Marshal(NewPoint(1, 2))
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if strings.Contains(string(data), "bbox") {
+		t.Errorf("json = %s, want no bbox member", data)
+	}
+}
+
+func TestGeometryCollection(t *testing.T) {
+	gc := NewGeometryCollection([]interface{}{
+		NewMultiPoint([]Position{{-5, 1}, {3, 2}}),
+		&Polygon{Type: "Polygon", Coordinates: [][]Position{{{0, 0}, {10, 0}, {10, 10}, {0, 0}}}},
+	})
+	bbox, err := ComputeBBox(gc)
+	if err != nil {
+		t.Fatalf("ComputeBBox() error = %v", err)
+	}
+	if !equalBBox(bbox, []float64{-5, 0, 10, 10}) {
+		t.Errorf("bbox = %v, want [-5 0 10 10]", bbox)
+	}
+
+	withBBox, err := WithBBox(NewFeature(gc))
+	if err != nil {
+		t.Fatalf("WithBBox() error = %v", err)
+	}
+	members := withBBox.(Feature).Geometry.(GeometryCollection).Geometries
+	if mp := members[0].(MultiPoint); !equalBBox(mp.BBox, []float64{-5, 1, 3, 2}) {
+		t.Errorf("multipoint bbox = %v", mp.BBox)
+	}
+
+	gc.Geometries = append(gc.Geometries, "not a geometry")
+	if _, err := ComputeBBox(gc); err == nil {
+		t.Error("expected error for unsupported member")
+	}
+}
+
+func TestRewind(t *testing.T) {
+	clockwise := [][]Position{{{0, 0}, {0, 2}, {2, 2}, {2, 0}, {0, 0}}}
+	if !RingIsClockwise(clockwise[0]) {
+		t.Fatal("RingIsClockwise() = false for a clockwise ring")
+	}
+	if RingIsClockwise([]Position{{0, 0}, {1, 1}}) {
+		t.Error("RingIsClockwise() = true for a degenerate ring")
+	}
+
+	rewound, err := Rewind(NewPolygon(clockwise))
+	if err != nil {
+		t.Fatalf("Rewind() error = %v", err)
+	}
+	poly := rewound.(Polygon)
+	if RingIsClockwise(poly.Coordinates[0]) {
+		t.Errorf("outer ring still clockwise: %v", poly.Coordinates[0])
+	}
+	if !RingIsClockwise(clockwise[0]) {
+		t.Error("Rewind() modified its input")
+	}
+
+	center, err := GeoJSONCenterOfMass(poly)
+	if err != nil {
+		t.Fatalf("GeoJSONCenterOfMass() error = %v", err)
+	}
+	if math.Abs(center.Coordinates[0]-1) > 1e-9 || math.Abs(center.Coordinates[1]-1) > 1e-9 {
+		t.Errorf("center of mass = %v, want [1 1]", center.Coordinates)
+	}
+}
+
+func TestRewindNested(t *testing.T) {
+	// Outer ring clockwise and hole counterclockwise: both need flipping.
+	rings := [][]Position{
+		{{0, 0}, {0, 10}, {10, 10}, {10, 0}, {0, 0}},
+		{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}},
+	}
+	fc := NewFeatureCollection([]Feature{
+		NewFeature(NewMultiPolygon([][][]Position{rings})),
+		NewFeature(NewGeometryCollection([]interface{}{NewPolygon(rings), NewPoint(1, 1)})),
+		NewFeature(NewLineString([]Position{{0, 0}, {1, 1}})),
+	})
+	rewound, err := Rewind(&fc)
+	if err != nil {
+		t.Fatalf("Rewind() error = %v", err)
+	}
+	features := rewound.(FeatureCollection).Features
+	check := func(name string, poly [][]Position) {
+		if RingIsClockwise(poly[0]) || !RingIsClockwise(poly[1]) {
+			t.Errorf("%s: rings wound wrong: %v", name, poly)
+		}
+	}
+	check("multipolygon", features[0].Geometry.(MultiPolygon).Coordinates[0])
+	check("collection", features[1].Geometry.(GeometryCollection).Geometries[0].(Polygon).Coordinates)
+	if !reflect.DeepEqual(features[2].Geometry, fc.Features[2].Geometry) {
+		t.Errorf("line changed: %v", features[2].Geometry)
+	}
+
+	if _, err := Rewind(NewFeature("polygon")); err == nil {
+		t.Error("expected error for unsupported geometry")
+	}
+}
+
+func TestNullFeatureGeometry(t *testing.T) {
+	obj, err := ParseGeoJSON([]byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":null,"properties":{"name":"unlocated"}},
+		{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[2,0]]},"properties":null}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bbox, err := ComputeBBox(obj)
+	if err != nil {
+		t.Fatalf("ComputeBBox() error = %v", err)
+	}
+	if want := []float64{0, 0, 2, 0}; !reflect.DeepEqual(bbox, want) {
+		t.Errorf("ComputeBBox() = %v, want %v", bbox, want)
+	}
+	center, err := GeoJSONCenter(obj)
+	if err != nil {
+		t.Fatalf("GeoJSONCenter() error = %v", err)
+	}
+	if want := (Position{1, 0}); !reflect.DeepEqual(center.Coordinates, want) {
+		t.Errorf("GeoJSONCenter() = %v, want %v", center.Coordinates, want)
+	}
+	length, err := GeoJSONLength(obj, UnitKilometers)
+	if err != nil {
+		t.Fatalf("GeoJSONLength() error = %v", err)
+	}
+	if want := GreatCircleDistance(0, 0, 0, 2); math.Abs(length-want) > 1e-9 {
+		t.Errorf("GeoJSONLength() = %v, want %v", length, want)
+	}
+	rewound, err := Rewind(obj)
+	if err != nil {
+		t.Fatalf("Rewind() error = %v", err)
+	}
+	if f := rewound.(FeatureCollection).Features[0]; f.Geometry != nil {
+		t.Errorf("Rewind() geometry = %v, want nil", f.Geometry)
+	}
+	withBBox, err := WithBBox(obj)
+	if err != nil {
+		t.Fatalf("WithBBox() error = %v", err)
+	}
+	if f := withBBox.(FeatureCollection).Features[0]; f.Geometry != nil || f.BBox != nil {
+		t.Errorf("WithBBox() feature = %+v, want no geometry or bbox", f)
+	}
+	points, err := Explode(obj)
+	if err != nil {
+		t.Fatalf("Explode() error = %v", err)
+	}
+	if len(points.Features) != 2 {
+		t.Errorf("Explode() gave %d points, want 2", len(points.Features))
+	}
+}
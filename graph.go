@@ -0,0 +1,239 @@
+package geo
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+)
+
+// TopologicalSort returns the nodes in an order where every edge leads from
+// an earlier node to a later one, using Kahn's algorithm. Of the nodes whose
+// predecessors have all been placed, the smallest is placed next, so the
+// result is the lexicographically smallest order and does not depend on the
+// order edges were added. An error is returned if the graph contains a cycle.
+func (g *Graph) TopologicalSort() ([]int, error) {
+	inDegree := make([]int, g.Nodes)
+	for _, edges := range g.Edges {
+		for _, e := range edges {
+			inDegree[e.To]++
+		}
+	}
+
+	// The ready nodes are kept in a min-heap, so the smallest is taken next.
+	ready := make(nodeHeap, 0, g.Nodes)
+	for v, d := range inDegree {
+		if d == 0 {
+			ready = append(ready, v)
+		}
+	}
+	heap.Init(&ready)
+	order := make([]int, 0, g.Nodes)
+	for ready.Len() > 0 {
+		u := heap.Pop(&ready).(int)
+		order = append(order, u)
+		for _, e := range g.Edges[u] {
+			inDegree[e.To]--
+			if inDegree[e.To] == 0 {
+				heap.Push(&ready, e.To)
+			}
+		}
+	}
+
+	if len(order) < g.Nodes {
+		return nil, errors.New("graph contains a cycle")
+	}
+	return order, nil
+}
+
+// nodeHeap implements heap.Interface as a min-heap of node indices.
+type nodeHeap []int
+
+func (h nodeHeap) Len() int { return len(h) }
+
+func (h nodeHeap) Less(i, j int) bool { return h[i] < h[j] }
+
+func (h nodeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *nodeHeap) Push(x interface{}) { *h = append(*h, x.(int)) }
+
+func (h *nodeHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	v := old[n-1]
+	*h = old[:n-1]
+	return v
+}
+
+// EulerianCircuit returns a closed walk from start that follows every edge
+// exactly once, built with Hierholzer's algorithm. The walk lists nodes and
+// ends back at start, so it is one longer than the number of edges.
+//
+// Edges are directed: an edge added with AddBidirectionalEdge is two edges and
+// is walked once in each direction; use UndirectedEulerianCircuit to walk it
+// once. The graph must be Eulerian, with as many edges leaving each node as
+// arriving, and every edge must be reachable from start; otherwise an error is
+// returned.
+func (g *Graph) EulerianCircuit(start int) ([]int, error) {
+	if start < 0 || start >= g.Nodes {
+		return nil, fmt.Errorf("start node %d out of range [0, %d)", start, g.Nodes)
+	}
+	balance := make([]int, g.Nodes)
+	edgeCount := 0
+	for u, edges := range g.Edges {
+		balance[u] += len(edges)
+		for _, e := range edges {
+			balance[e.To]--
+		}
+		edgeCount += len(edges)
+	}
+	for v, b := range balance {
+		if b != 0 {
+			return nil, fmt.Errorf("graph is not eulerian: node %d has unequal in and out degree", v)
+		}
+	}
+
+	// next[u] is the first edge of u that has not been walked yet.
+	next := make([]int, g.Nodes)
+	stack := []int{start}
+	circuit := make([]int, 0, edgeCount+1)
+	for len(stack) > 0 {
+		u := stack[len(stack)-1]
+		if next[u] < len(g.Edges[u]) {
+			stack = append(stack, g.Edges[u][next[u]].To)
+			next[u]++
+			continue
+		}
+		stack = stack[:len(stack)-1]
+		circuit = append(circuit, u)
+	}
+	if len(circuit) != edgeCount+1 {
+		return nil, errors.New("graph is not eulerian: some edges are not reachable from start")
+	}
+	for i, j := 0, len(circuit)-1; i < j; i, j = i+1, j-1 {
+		circuit[i], circuit[j] = circuit[j], circuit[i]
+	}
+	return circuit, nil
+}
+
+// UndirectedEulerianCircuit is EulerianCircuit for an undirected graph, such
+// as the multigraph of a Christofides tour. Every edge must have been added
+// in both directions, as AddBidirectionalEdge does; each such pair is one
+// undirected edge and is walked once, in either direction. Parallel edges and
+// loops are allowed. An error is returned when an edge has no reverse, when a
+// node has odd degree, or when some edge is not reachable from start.
+func (g *Graph) UndirectedEulerianCircuit(start int) ([]int, error) {
+	if start < 0 || start >= g.Nodes {
+		return nil, fmt.Errorf("start node %d out of range [0, %d)", start, g.Nodes)
+	}
+
+	// Pair every edge u->v with an unpaired v->u, giving both halves the id
+	// of the undirected edge they make up.
+	type halfEdge struct{ to, id int }
+	adj := make([][]halfEdge, g.Nodes)
+	waiting := make(map[[2]int][]int) // ids of edges u->v awaiting v->u
+	var unpaired []bool               // by id, until the second half is found
+	for u, edges := range g.Edges {
+		for _, e := range edges {
+			reverse := [2]int{e.To, u}
+			if ids := waiting[reverse]; len(ids) > 0 {
+				adj[u] = append(adj[u], halfEdge{e.To, ids[0]})
+				unpaired[ids[0]] = false
+				waiting[reverse] = ids[1:]
+				continue
+			}
+			id := len(unpaired)
+			adj[u] = append(adj[u], halfEdge{e.To, id})
+			waiting[[2]int{u, e.To}] = append(waiting[[2]int{u, e.To}], id)
+			unpaired = append(unpaired, true)
+		}
+	}
+	for u, half := range adj {
+		for _, h := range half {
+			if unpaired[h.id] {
+				return nil, fmt.Errorf("graph is not undirected: edge %d -> %d has no reverse", u, h.to)
+			}
+		}
+	}
+	edgeCount := len(unpaired)
+	for v, half := range adj {
+		if len(half)%2 != 0 {
+			return nil, fmt.Errorf("graph is not eulerian: node %d has odd degree", v)
+		}
+	}
+
+	used := make([]bool, edgeCount)
+	next := make([]int, g.Nodes)
+	stack := []int{start}
+	circuit := make([]int, 0, edgeCount+1)
+	for len(stack) > 0 {
+		u := stack[len(stack)-1]
+		for next[u] < len(adj[u]) && used[adj[u][next[u]].id] {
+			next[u]++
+		}
+		if next[u] < len(adj[u]) {
+			h := adj[u][next[u]]
+			used[h.id] = true
+			stack = append(stack, h.to)
+			continue
+		}
+		stack = stack[:len(stack)-1]
+		circuit = append(circuit, u)
+	}
+	if len(circuit) != edgeCount+1 {
+		return nil, errors.New("graph is not eulerian: some edges are not reachable from start")
+	}
+	for i, j := 0, len(circuit)-1; i < j; i, j = i+1, j-1 {
+		circuit[i], circuit[j] = circuit[j], circuit[i]
+	}
+	return circuit, nil
+}
+
+// BFS returns the nodes reachable from source in breadth-first order, starting
+// with source itself. Neighbors are visited in the order their edges were
+// added and edge weights are ignored. It returns nil if source is out of range.
+func (g *Graph) BFS(source int) []int {
+	if source < 0 || source >= g.Nodes {
+		return nil
+	}
+	visited := make([]bool, g.Nodes)
+	visited[source] = true
+	order := []int{source}
+	for i := 0; i < len(order); i++ {
+		for _, e := range g.Edges[order[i]] {
+			if !visited[e.To] {
+				visited[e.To] = true
+				order = append(order, e.To)
+			}
+		}
+	}
+	return order
+}
+
+// DFS returns the nodes reachable from source in depth-first preorder,
+// starting with source itself. Neighbors are visited in the order their edges
+// were added and edge weights are ignored. It returns nil if source is out of
+// range.
+func (g *Graph) DFS(source int) []int {
+	if source < 0 || source >= g.Nodes {
+		return nil
+	}
+	visited := make([]bool, g.Nodes)
+	var order []int
+	stack := []int{source}
+	for len(stack) > 0 {
+		u := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[u] {
+			continue
+		}
+		visited[u] = true
+		order = append(order, u)
+		// Push in reverse so the first edge is explored first.
+		for i := len(g.Edges[u]) - 1; i >= 0; i-- {
+			if v := g.Edges[u][i].To; !visited[v] {
+				stack = append(stack, v)
+			}
+		}
+	}
+	return order
+}
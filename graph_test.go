@@ -0,0 +1,216 @@
+package geo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTopologicalSort(t *testing.T) {
+	// 0 -> 1 -> 3, 0 -> 2 -> 3, 3 -> 4, and 5 on its own.
+	g := NewGraph(6)
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(0, 2, 1)
+	g.AddEdge(1, 3, 1)
+	g.AddEdge(2, 3, 1)
+	g.AddEdge(3, 4, 1)
+
+	order, err := g.TopologicalSort()
+	if err != nil {
+		t.Fatalf("TopologicalSort() error = %v", err)
+	}
+	if len(order) != g.Nodes {
+		t.Fatalf("order %v has %d nodes, want %d", order, len(order), g.Nodes)
+	}
+	position := make([]int, g.Nodes)
+	for i, v := range order {
+		position[v] = i
+	}
+	for u, edges := range g.Edges {
+		for _, e := range edges {
+			if position[u] >= position[e.To] {
+				t.Errorf("edge %d -> %d goes backwards in %v", u, e.To, order)
+			}
+		}
+	}
+}
+
+func TestTopologicalSortSmallestFirst(t *testing.T) {
+	// Nodes 3 and 2 become ready together, in that edge order, and 1 is ready
+	// from the start: the smallest ready node always goes next.
+	g := NewGraph(4)
+	g.AddEdge(0, 3, 1)
+	g.AddEdge(0, 2, 1)
+	order, err := g.TopologicalSort()
+	if err != nil {
+		t.Fatalf("TopologicalSort() error = %v", err)
+	}
+	if want := []int{0, 1, 2, 3}; !reflect.DeepEqual(order, want) {
+		t.Errorf("TopologicalSort() = %v, want %v", order, want)
+	}
+
+	g = NewGraph(3)
+	g.AddEdge(2, 0, 1)
+	order, _ = g.TopologicalSort()
+	if want := []int{1, 2, 0}; !reflect.DeepEqual(order, want) {
+		t.Errorf("TopologicalSort() = %v, want %v", order, want)
+	}
+}
+
+func TestTopologicalSortCycle(t *testing.T) {
+	g := NewGraph(4)
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(1, 2, 1)
+	g.AddEdge(2, 3, 1)
+	g.AddEdge(3, 1, 1)
+	if order, err := g.TopologicalSort(); err == nil {
+		t.Errorf("TopologicalSort() = %v, want a cycle error", order)
+	}
+}
+
+func TestEulerianCircuit(t *testing.T) {
+	// Two directed triangles sharing node 0: every node has even degree, with
+	// as many edges in as out.
+	g := NewGraph(5)
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(1, 2, 1)
+	g.AddEdge(2, 0, 1)
+	g.AddEdge(0, 3, 1)
+	g.AddEdge(3, 4, 1)
+	g.AddEdge(4, 0, 1)
+
+	circuit, err := g.EulerianCircuit(1)
+	if err != nil {
+		t.Fatalf("EulerianCircuit() error = %v", err)
+	}
+	if len(circuit) != 7 || circuit[0] != 1 || circuit[len(circuit)-1] != 1 {
+		t.Fatalf("circuit = %v, want a closed walk of 6 edges from 1", circuit)
+	}
+	used := map[[2]int]int{}
+	for i := 0; i+1 < len(circuit); i++ {
+		used[[2]int{circuit[i], circuit[i+1]}]++
+	}
+	for u, edges := range g.Edges {
+		for _, e := range edges {
+			if used[[2]int{u, e.To}] != 1 {
+				t.Errorf("edge %d -> %d used %d times in %v", u, e.To, used[[2]int{u, e.To}], circuit)
+			}
+		}
+	}
+}
+
+func TestEulerianCircuitErrors(t *testing.T) {
+	path := NewGraph(3)
+	path.AddEdge(0, 1, 1)
+	path.AddEdge(1, 2, 1)
+	if _, err := path.EulerianCircuit(0); err == nil {
+		t.Error("expected error for unbalanced degrees")
+	}
+
+	// Two separate cycles cannot be covered by one walk.
+	split := NewGraph(4)
+	split.AddBidirectionalEdge(0, 1, 1)
+	split.AddBidirectionalEdge(2, 3, 1)
+	if _, err := split.EulerianCircuit(0); err == nil {
+		t.Error("expected error for unreachable edges")
+	}
+	if _, err := split.EulerianCircuit(4); err == nil {
+		t.Error("expected error for out-of-range start")
+	}
+}
+
+func TestUndirectedEulerianCircuit(t *testing.T) {
+	// Two undirected triangles sharing node 0, with edge 3-4 tripled and a
+	// loop at 2, as a Christofides multigraph may have: all degrees are even.
+	g := NewGraph(5)
+	g.AddBidirectionalEdge(0, 1, 1)
+	g.AddBidirectionalEdge(1, 2, 1)
+	g.AddBidirectionalEdge(2, 0, 1)
+	g.AddBidirectionalEdge(0, 3, 1)
+	g.AddBidirectionalEdge(3, 4, 1)
+	g.AddBidirectionalEdge(4, 0, 1)
+	g.AddBidirectionalEdge(3, 4, 2)
+	g.AddBidirectionalEdge(4, 3, 3)
+	g.AddBidirectionalEdge(2, 2, 1)
+
+	circuit, err := g.UndirectedEulerianCircuit(1)
+	if err != nil {
+		t.Fatalf("UndirectedEulerianCircuit() error = %v", err)
+	}
+	if len(circuit) != 10 || circuit[0] != 1 || circuit[len(circuit)-1] != 1 {
+		t.Fatalf("circuit = %v, want a closed walk of 9 edges from 1", circuit)
+	}
+	used := map[[2]int]int{}
+	for i := 0; i+1 < len(circuit); i++ {
+		u, v := circuit[i], circuit[i+1]
+		if u > v {
+			u, v = v, u
+		}
+		used[[2]int{u, v}]++
+	}
+	want := map[[2]int]int{{0, 1}: 1, {1, 2}: 1, {0, 2}: 1, {0, 3}: 1, {3, 4}: 3, {0, 4}: 1, {2, 2}: 1}
+	if !reflect.DeepEqual(used, want) {
+		t.Errorf("edges walked = %v, want %v", used, want)
+	}
+
+	// The path 0-1-2 balances as a directed graph but its ends have odd
+	// degree.
+	path := NewGraph(3)
+	path.AddBidirectionalEdge(0, 1, 1)
+	path.AddBidirectionalEdge(1, 2, 1)
+	if _, err := path.EulerianCircuit(0); err != nil {
+		t.Errorf("EulerianCircuit() error = %v, want the directed circuit", err)
+	}
+	if _, err := path.UndirectedEulerianCircuit(0); err == nil || !strings.Contains(err.Error(), "odd degree") {
+		t.Errorf("UndirectedEulerianCircuit() error = %v, want odd degree", err)
+	}
+
+	oneWay := NewGraph(3)
+	oneWay.AddEdge(0, 1, 1)
+	oneWay.AddEdge(1, 2, 1)
+	oneWay.AddEdge(2, 0, 1)
+	if _, err := oneWay.UndirectedEulerianCircuit(0); err == nil || !strings.Contains(err.Error(), "no reverse") {
+		t.Errorf("UndirectedEulerianCircuit() error = %v, want missing reverse", err)
+	}
+
+	split := NewGraph(6)
+	for _, e := range [][2]int{{0, 1}, {1, 2}, {2, 0}, {3, 4}, {4, 5}, {5, 3}} {
+		split.AddBidirectionalEdge(e[0], e[1], 1)
+	}
+	if _, err := split.UndirectedEulerianCircuit(0); err == nil {
+		t.Error("expected error for unreachable edges")
+	}
+	if _, err := split.UndirectedEulerianCircuit(-1); err == nil {
+		t.Error("expected error for out-of-range start")
+	}
+}
+
+func TestBFSAndDFS(t *testing.T) {
+	// 0 -> 1 -> 3 and 0 -> 2 -> 4.
+	g := NewGraph(5)
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(0, 2, 1)
+	g.AddEdge(1, 3, 1)
+	g.AddEdge(2, 4, 1)
+	if got, want := g.BFS(0), []int{0, 1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BFS(0) = %v, want %v", got, want)
+	}
+	if got, want := g.DFS(0), []int{0, 1, 3, 2, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DFS(0) = %v, want %v", got, want)
+	}
+	if g.BFS(5) != nil || g.DFS(-1) != nil {
+		t.Error("expected nil for out-of-range source")
+	}
+}
+
+func TestBFSAndDFSDisconnected(t *testing.T) {
+	g := NewGraph(4)
+	g.AddEdge(0, 1, 1.0)
+	g.AddEdge(2, 3, 1.0)
+	if got, want := g.BFS(0), []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BFS(0) = %v, want %v", got, want)
+	}
+	if got, want := g.DFS(2), []int{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DFS(2) = %v, want %v", got, want)
+	}
+}
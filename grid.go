@@ -0,0 +1,241 @@
+package geo
+
+import (
+	"math"
+)
+
+// hexLayout is a pointy-top hexagon tiling over a bounding box, laid out in a
+// local equirectangular projection in kilometers with the first hexagon
+// centered on the southwest corner. Odd rows are shifted half a hexagon east.
+type hexLayout struct {
+	box    BBox
+	size   float64 // side length and circumradius in km
+	kx, ky float64 // km per degree of longitude and latitude
+	rows   int
+	cols   int
+}
+
+func newHexLayout(box BBox, cellSizeKm float64) (hexLayout, bool) {
+	if cellSizeKm <= 0 || box.IsEmpty() {
+		return hexLayout{}, false
+	}
+	ky := EarthRadiusKm * math.Pi / 180
+	kx := ky * math.Cos(toRadians((box.MinLat+box.MaxLat)/2))
+	if kx < 1e-9 {
+		return hexLayout{}, false
+	}
+	width := (box.MaxLon - box.MinLon) * kx
+	height := (box.MaxLat - box.MinLat) * ky
+	h := hexLayout{box: box, size: cellSizeKm, kx: kx, ky: ky}
+	// Rows 0..rows-1 and columns -1..cols-1 cover the box: the last row and
+	// column have their centers on or beyond its far edges.
+	h.rows = int(math.Ceil(height/(1.5*cellSizeKm))) + 1
+	h.cols = int(math.Ceil(width/(math.Sqrt(3)*cellSizeKm))) + 1
+	return h, true
+}
+
+// cells calls fn for every hexagon of the layout in index order.
+func (h hexLayout) cells(fn func(row, col int)) {
+	for row := 0; row < h.rows; row++ {
+		first := 0
+		if row%2 == 1 {
+			first = -1
+		}
+		for col := first; col < h.cols; col++ {
+			fn(row, col)
+		}
+	}
+}
+
+// index returns the position of a hexagon in cells order, or -1 when it is not
+// part of the layout.
+func (h hexLayout) index(row, col int) int {
+	if row < 0 || row >= h.rows || col < -(row%2) || col >= h.cols {
+		return -1
+	}
+	// Even rows hold cols hexagons and odd rows one more.
+	return row*h.cols + row/2 + col + row%2
+}
+
+// polygon returns the closed, counterclockwise ring of a hexagon. Vertices lie
+// on a lattice of half widths and half sides, so neighbors share them exactly.
+func (h hexLayout) polygon(row, col int) Polygon {
+	halfWidth := math.Sqrt(3) * h.size / 2
+	halfSide := h.size / 2
+	cx := 2*col + row%2 // in half widths
+	cy := 3 * row       // in half sides
+	offsets := [6][2]int{{1, -1}, {1, 1}, {0, 2}, {-1, 1}, {-1, -1}, {0, -2}}
+	ring := make([]Position, 7)
+	for i, o := range offsets {
+		x := float64(cx+o[0]) * halfWidth
+		y := float64(cy+o[1]) * halfSide
+		ring[i] = Position{h.box.MinLon + x/h.kx, h.box.MinLat + y/h.ky}
+	}
+	ring[6] = ring[0]
+	return NewPolygon([][]Position{ring})
+}
+
+// locate returns the row and column of the hexagon containing p.
+func (h hexLayout) locate(p Position) (int, int) {
+	x := (p[0] - h.box.MinLon) * h.kx
+	y := (p[1] - h.box.MinLat) * h.ky
+	// Fractional axial coordinates, rounded in cube coordinates.
+	q := (math.Sqrt(3)/3*x - y/3) / h.size
+	r := (2.0 / 3 * y) / h.size
+	s := -q - r
+	rq, rr, rs := math.Round(q), math.Round(r), math.Round(s)
+	dq, dr, ds := math.Abs(rq-q), math.Abs(rr-r), math.Abs(rs-s)
+	switch {
+	case dq > dr && dq > ds:
+		rq = -rr - rs
+	case dr > ds:
+		rr = -rq - rs
+	}
+	row := int(rr)
+	col := int(rq) + (row-(row&1))/2
+	return row, col
+}
+
+// SquareGrid returns square cells with sides of cellKm that cover b, as one
+// Polygon Feature per cell with its "row" and "col" in the properties. Row 0
+// and column 0 start at the southwest corner of b; cells are square at the
+// box's center latitude, and the last row and column may extend past its
+// north and east edges. When mask polygons are given only the cells that
+// intersect one of them are kept. An empty box or a non-positive cell size
+// gives an empty collection.
+func SquareGrid(b BBox, cellKm float64, mask ...Polygon) FeatureCollection {
+	g, ok := newGridLayout(b, cellKm)
+	if !ok {
+		return NewFeatureCollection(nil)
+	}
+	rows := int(math.Ceil((b.MaxLat - b.MinLat) / g.latStep))
+	cols := int(math.Ceil((b.MaxLon - b.MinLon) / g.lonStep))
+	rows, cols = max(rows, 1), max(cols, 1)
+	var features []Feature
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			west := b.MinLon + float64(col)*g.lonStep
+			south := b.MinLat + float64(row)*g.latStep
+			east := b.MinLon + float64(col+1)*g.lonStep
+			north := b.MinLat + float64(row+1)*g.latStep
+			cell := NewPolygon([][]Position{{
+				{west, south}, {east, south}, {east, north}, {west, north}, {west, south},
+			}})
+			features = appendGridCell(features, cell, row, col, mask)
+		}
+	}
+	return NewFeatureCollection(features)
+}
+
+// HexGrid returns a tiling of pointy-top hexagons with sides of cellKm that
+// covers b, as one Polygon Feature per hexagon with its "row" and "col" in the
+// properties. Hexagons run row by row from the south and west to east within
+// a row; odd rows are shifted half a hexagon east and start at column -1.
+// Hexagons are regular in a local equirectangular projection centered on the
+// box, so they stay close to regular on the ground for boxes a few hundred
+// kilometers across. Neighbors share their edge vertices exactly. When mask
+// polygons are given only the hexagons that intersect one of them are kept.
+// An empty box or a non-positive cell size gives an empty collection.
+func HexGrid(b BBox, cellKm float64, mask ...Polygon) FeatureCollection {
+	h, ok := newHexLayout(b, cellKm)
+	if !ok {
+		return NewFeatureCollection(nil)
+	}
+	var features []Feature
+	h.cells(func(row, col int) {
+		features = appendGridCell(features, h.polygon(row, col), row, col, mask)
+	})
+	return NewFeatureCollection(features)
+}
+
+// PointGrid returns points spaced spacingKm apart within b, as one Point
+// Feature per point with its "row" and "col" in the properties. The first
+// point is the southwest corner of b and the spacing in longitude is taken at
+// the box's center latitude; points past the north or east edge are left out.
+// When mask polygons are given only the points inside one of them are kept.
+// An empty box or a non-positive spacing gives an empty collection.
+func PointGrid(b BBox, spacingKm float64, mask ...Polygon) FeatureCollection {
+	g, ok := newGridLayout(b, spacingKm)
+	if !ok {
+		return NewFeatureCollection(nil)
+	}
+	// A small tolerance keeps points that land on the far edge by rounding.
+	const eps = 1e-9
+	rows := int(math.Floor((b.MaxLat-b.MinLat)/g.latStep+eps)) + 1
+	cols := int(math.Floor((b.MaxLon-b.MinLon)/g.lonStep+eps)) + 1
+	var features []Feature
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			p := NewPoint(b.MinLon+float64(col)*g.lonStep, b.MinLat+float64(row)*g.latStep)
+			features = appendGridCell(features, p, row, col, mask)
+		}
+	}
+	return NewFeatureCollection(features)
+}
+
+// gridLayout holds the steps in degrees of a square grid over a box.
+type gridLayout struct {
+	lonStep, latStep float64
+}
+
+func newGridLayout(b BBox, cellKm float64) (gridLayout, bool) {
+	if !(cellKm > 0) || b.IsEmpty() {
+		return gridLayout{}, false
+	}
+	ky := EarthRadiusKm * math.Pi / 180
+	kx := ky * math.Cos(toRadians((b.MinLat+b.MaxLat)/2))
+	if kx < 1e-9 {
+		return gridLayout{}, false
+	}
+	return gridLayout{lonStep: cellKm / kx, latStep: cellKm / ky}, true
+}
+
+// appendGridCell appends geom as a Feature with row and col properties,
+// unless mask polygons are given and none of them intersects it.
+func appendGridCell(features []Feature, geom interface{}, row, col int, mask []Polygon) []Feature {
+	if len(mask) > 0 {
+		hit := false
+		for _, m := range mask {
+			if ok, err := Intersects(geom, m); err == nil && ok {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			return features
+		}
+	}
+	f := NewFeature(geom)
+	f.Properties = map[string]interface{}{"row": row, "col": col}
+	return append(features, f)
+}
+
+// HexBin counts points per hexagon of HexGrid(box, cellSizeKm), keyed by the
+// hexagon's index in its Features. Hexagons without points are left out, as
+// are points outside the grid.
+func HexBin(points []Position, box BBox, cellSizeKm float64) map[int]int {
+	counts := make(map[int]int)
+	h, ok := newHexLayout(box, cellSizeKm)
+	if !ok {
+		return counts
+	}
+	for _, p := range points {
+		if i := h.index(h.locate(p)); i >= 0 {
+			counts[i]++
+		}
+	}
+	return counts
+}
+
+// SnapToGrid rounds a coordinate to the nearest node of a grid with lines every
+// cellDeg degrees of latitude and longitude, starting at 0. Longitudes are
+// normalized to [-180, 180), so 180 snaps to -180, and latitudes are kept
+// within ±90. A non-positive cellDeg returns the coordinate unchanged.
+func SnapToGrid(lat, lon, cellDeg float64) (float64, float64) {
+	if cellDeg <= 0 {
+		return lat, lon
+	}
+	lat = math.Max(-90, math.Min(90, math.Round(lat/cellDeg)*cellDeg))
+	lon = normalizeLongitude(math.Round(lon/cellDeg) * cellDeg)
+	return lat, lon
+}
@@ -0,0 +1,220 @@
+package geo
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestHexGridNeighborsShareEdges(t *testing.T) {
+	box := BBox{MinLon: 10, MinLat: 50, MaxLon: 11, MaxLat: 50.6}
+	hexes := gridPolygons(HexGrid(box, 5))
+	if len(hexes) == 0 {
+		t.Fatal("HexGrid() returned no hexagons")
+	}
+
+	edges := make(map[[2][2]float64]int)
+	for _, hex := range hexes {
+		ring := hex.Coordinates[0]
+		if len(ring) != 7 || !reflect.DeepEqual(ring[0], ring[6]) {
+			t.Fatalf("ring = %v, want 6 vertices and closed", ring)
+		}
+		if area, _, _ := ringAreaCentroid(ring); area <= 0 {
+			t.Fatalf("ring %v is not counterclockwise", ring)
+		}
+		for i := 0; i < 6; i++ {
+			edges[edgeKey([2]Position{ring[i], ring[i+1]})]++
+		}
+	}
+	// Every hexagon away from the border has all six edges shared with a
+	// neighbor, which runs the edge in the opposite direction.
+	interior := 0
+	for _, hex := range hexes {
+		ring := hex.Coordinates[0]
+		shared := 0
+		for i := 0; i < 6; i++ {
+			if edges[edgeKey([2]Position{ring[i+1], ring[i]})] == 1 {
+				shared++
+			}
+		}
+		if shared == 6 {
+			interior++
+		}
+	}
+	if interior == 0 {
+		t.Error("no hexagon shares all its edges")
+	}
+	for e, n := range edges {
+		if n != 1 {
+			t.Errorf("edge %v appears %d times in the same direction", e, n)
+		}
+	}
+}
+
+func TestHexGridCoversBox(t *testing.T) {
+	box := BBox{MinLon: -3, MinLat: 40, MaxLon: -2, MaxLat: 41}
+	hexes := gridPolygons(HexGrid(box, 7))
+	rng := rand.New(rand.NewSource(1))
+	points := make([]Position, 500)
+	for i := range points {
+		points[i] = Position{
+			box.MinLon + rng.Float64()*(box.MaxLon-box.MinLon),
+			box.MinLat + rng.Float64()*(box.MaxLat-box.MinLat),
+		}
+	}
+	points = append(points,
+		Position{box.MinLon, box.MinLat}, Position{box.MaxLon, box.MaxLat},
+		Position{box.MinLon, box.MaxLat}, Position{box.MaxLon, box.MinLat})
+
+	total := 0
+	for i, n := range HexBin(points, box, 7) {
+		if i < 0 || i >= len(hexes) {
+			t.Fatalf("HexBin() index %d out of range", i)
+		}
+		total += n
+	}
+	if total != len(points) {
+		t.Errorf("binned %d points, want %d", total, len(points))
+	}
+	for _, p := range points {
+		bins := HexBin([]Position{p}, box, 7)
+		for i := range bins {
+			if !pointInPolygon(p, hexes[i]) {
+				t.Errorf("point %v binned into hexagon %d that does not contain it", p, i)
+			}
+		}
+	}
+
+	if counts := HexBin([]Position{{100, 0}}, box, 7); len(counts) != 0 {
+		t.Errorf("HexBin() outside grid = %v, want empty", counts)
+	}
+	if len(HexGrid(box, 0).Features) != 0 || len(HexGrid(emptyBBox(), 1).Features) != 0 {
+		t.Error("expected nil grid for invalid input")
+	}
+}
+
+func TestSnapToGrid(t *testing.T) {
+	tests := []struct {
+		lat, lon, cell   float64
+		wantLat, wantLon float64
+	}{
+		{59.334, 18.063, 0.01, 59.33, 18.06},
+		{59.336, 18.066, 0.01, 59.34, 18.07},
+		{-33.87, 151.21, 0.5, -34, 151},
+		{89.9, 179.8, 1, 90, -180},
+		{12.3, 45.6, 0, 12.3, 45.6},
+	}
+	for _, tt := range tests {
+		lat, lon := SnapToGrid(tt.lat, tt.lon, tt.cell)
+		if math.Abs(lat-tt.wantLat) > 1e-9 || math.Abs(lon-tt.wantLon) > 1e-9 {
+			t.Errorf("SnapToGrid(%v, %v, %v) = (%v, %v), want (%v, %v)",
+				tt.lat, tt.lon, tt.cell, lat, lon, tt.wantLat, tt.wantLon)
+		}
+	}
+
+	lat1, lon1 := SnapToGrid(59.3341, 18.0632, 0.01)
+	lat2, lon2 := SnapToGrid(59.3338, 18.0629, 0.01)
+	if lat1 != lat2 || lon1 != lon2 {
+		t.Errorf("nearby points snapped to (%v, %v) and (%v, %v)", lat1, lon1, lat2, lon2)
+	}
+}
+
+// gridPolygons returns the polygons of a grid's features in order.
+func gridPolygons(fc FeatureCollection) []Polygon {
+	polys := make([]Polygon, len(fc.Features))
+	for i, f := range fc.Features {
+		polys[i] = f.Geometry.(Polygon)
+	}
+	return polys
+}
+
+func TestGridCounts(t *testing.T) {
+	// A one-degree box at the equator is about 111.2 km on each side.
+	box := BBox{MinLon: 0, MinLat: 0, MaxLon: 1, MaxLat: 1}
+
+	// 111.2 / 25 = 4.4, so 5 rows of 5 squares cover the box.
+	if n := len(SquareGrid(box, 25).Features); n != 25 {
+		t.Errorf("SquareGrid() has %d cells, want 25", n)
+	}
+	// Rows are 1.5 sides apart: ceil(111.2 / 37.5) + 1 = 4 rows. Columns are
+	// √3 sides apart: ceil(111.2 / 43.3) + 1 = 4 columns, plus one more on
+	// each of the 2 shifted rows.
+	if n := len(HexGrid(box, 25).Features); n != 18 {
+		t.Errorf("HexGrid() has %d cells, want 18", n)
+	}
+	// Points at 0, 25, 50, 75 and 100 km in each direction.
+	if n := len(PointGrid(box, 25).Features); n != 25 {
+		t.Errorf("PointGrid() has %d points, want 25", n)
+	}
+	// Spacing that divides a box on the equator exactly keeps the far edge.
+	kmPerDeg := EarthRadiusKm * math.Pi / 180
+	if n := len(PointGrid(BBox{MinLat: -0.5, MaxLon: 1, MaxLat: 0.5}, kmPerDeg/4).Features); n != 25 {
+		t.Errorf("PointGrid() with exact spacing has %d points, want 25", n)
+	}
+
+	for _, fc := range []FeatureCollection{SquareGrid(box, 0), HexGrid(emptyBBox(), 5), PointGrid(box, -1)} {
+		if len(fc.Features) != 0 {
+			t.Errorf("grid for invalid input has %d features", len(fc.Features))
+		}
+	}
+}
+
+func TestSquareGridCells(t *testing.T) {
+	box := BBox{MinLon: 5, MinLat: 45, MaxLon: 6, MaxLat: 46}
+	fc := SquareGrid(box, 20)
+	seen := make(map[[2]int]bool)
+	for _, f := range fc.Features {
+		ring := f.Geometry.(Polygon).Coordinates[0]
+		if len(ring) != 5 || !reflect.DeepEqual(ring[0], ring[4]) {
+			t.Fatalf("ring = %v, want 4 vertices and closed", ring)
+		}
+		if area, _, _ := ringAreaCentroid(ring); area <= 0 {
+			t.Errorf("ring %v is degenerate or clockwise", ring)
+		}
+		// Cells are 20 km on each side at the center latitude.
+		width := GreatCircleDistance(45.5, ring[0][0], 45.5, ring[1][0])
+		height := GreatCircleDistance(ring[1][1], ring[1][0], ring[2][1], ring[2][0])
+		if math.Abs(width-20) > 0.01 || math.Abs(height-20) > 0.01 {
+			t.Errorf("cell is %.3f x %.3f km, want 20 x 20", width, height)
+		}
+		row, col := f.Properties["row"].(int), f.Properties["col"].(int)
+		if seen[[2]int{row, col}] {
+			t.Errorf("duplicate cell row %d col %d", row, col)
+		}
+		seen[[2]int{row, col}] = true
+		if want := box.MinLat + float64(row)*(ring[2][1]-ring[1][1]); math.Abs(ring[0][1]-want) > 1e-9 {
+			t.Errorf("row %d starts at lat %v, want %v", row, ring[0][1], want)
+		}
+	}
+}
+
+func TestGridMask(t *testing.T) {
+	box := BBox{MinLon: 0, MinLat: 0, MaxLon: 2, MaxLat: 2}
+	mask := squarePolygon(0.2, 0.2, 0.7, 0.7)
+	for name, grid := range map[string]func(BBox, float64, ...Polygon) FeatureCollection{
+		"SquareGrid": SquareGrid,
+		"HexGrid":    HexGrid,
+		"PointGrid":  PointGrid,
+	} {
+		all := grid(box, 20)
+		masked := grid(box, 20, mask)
+		if len(masked.Features) == 0 || len(masked.Features) >= len(all.Features) {
+			t.Errorf("%s: masked grid has %d of %d cells", name, len(masked.Features), len(all.Features))
+		}
+		for _, f := range masked.Features {
+			if ok, _ := Intersects(f.Geometry, mask); !ok {
+				t.Errorf("%s: cell %v outside the mask was kept", name, f.Properties)
+			}
+		}
+		kept := 0
+		for _, f := range all.Features {
+			if ok, _ := Intersects(f.Geometry, mask); ok {
+				kept++
+			}
+		}
+		if kept != len(masked.Features) {
+			t.Errorf("%s: masked grid has %d cells, want %d", name, len(masked.Features), kept)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package geo
+
+import (
+	"errors"
+)
+
+// ConcaveHull returns a concave boundary around points. The points are
+// Delaunay-triangulated and every triangle with an edge longer than maxEdgeKm
+// (great-circle length) is discarded; the outline of the remaining triangles is
+// the hull. A smaller maxEdgeKm hugs the points more tightly.
+//
+// If discarding long triangles splits the points into several disjoint areas,
+// only the largest one is returned. Gaps enclosed by the remaining triangles are
+// returned as holes. An error is returned when fewer than three distinct,
+// non-collinear points are given or when no triangle survives the edge limit.
+func ConcaveHull(points []Position, maxEdgeKm float64) (Polygon, error) {
+	if maxEdgeKm <= 0 {
+		return Polygon{}, errors.New("max edge length must be greater than 0")
+	}
+	unique, _ := dedupePositions(points)
+	tris, err := delaunay(unique)
+	if err != nil {
+		return Polygon{}, err
+	}
+
+	edgeKm := func(i, j int) float64 {
+		lat1, lon1 := positionLatLon(unique[i])
+		lat2, lon2 := positionLatLon(unique[j])
+		return GreatCircleDistance(lat1, lon1, lat2, lon2)
+	}
+	var kept []triangle
+	for _, t := range tris {
+		if edgeKm(t.a, t.b) <= maxEdgeKm && edgeKm(t.b, t.c) <= maxEdgeKm && edgeKm(t.c, t.a) <= maxEdgeKm {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == 0 {
+		return Polygon{}, errors.New("no triangles within max edge length")
+	}
+
+	component := largestTriangleComponent(unique, kept)
+	rings := triangleBoundaryRings(unique, component)
+	if len(rings) == 0 {
+		return Polygon{}, errors.New("unable to trace hull boundary")
+	}
+
+	// The outer ring is the counterclockwise one with the largest area; the
+	// rest are holes.
+	outer := 0
+	outerArea, _, _ := ringAreaCentroid(rings[0])
+	for i := range rings {
+		if area, _, _ := ringAreaCentroid(rings[i]); area > outerArea {
+			outer, outerArea = i, area
+		}
+	}
+	coords := [][]Position{rings[outer]}
+	for i := range rings {
+		if i != outer {
+			coords = append(coords, rings[i])
+		}
+	}
+	return NewPolygon(coords), nil
+}
+
+// largestTriangleComponent groups triangles that share an edge and returns the
+// group with the largest planar area.
+func largestTriangleComponent(points []Position, tris []triangle) []triangle {
+	edgeOwner := make(map[[2]int][]int)
+	for i, t := range tris {
+		for _, e := range [][2]int{{t.a, t.b}, {t.b, t.c}, {t.c, t.a}} {
+			if e[0] > e[1] {
+				e[0], e[1] = e[1], e[0]
+			}
+			edgeOwner[e] = append(edgeOwner[e], i)
+		}
+	}
+
+	component := make([]int, len(tris))
+	for i := range component {
+		component[i] = -1
+	}
+	var areas []float64
+	for start := range tris {
+		if component[start] != -1 {
+			continue
+		}
+		id := len(areas)
+		areas = append(areas, 0)
+		stack := []int{start}
+		component[start] = id
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			t := tris[i]
+			areas[id] += orient2D(points[t.a], points[t.b], points[t.c]) / 2
+			for _, e := range [][2]int{{t.a, t.b}, {t.b, t.c}, {t.c, t.a}} {
+				if e[0] > e[1] {
+					e[0], e[1] = e[1], e[0]
+				}
+				for _, j := range edgeOwner[e] {
+					if component[j] == -1 {
+						component[j] = id
+						stack = append(stack, j)
+					}
+				}
+			}
+		}
+	}
+
+	best := 0
+	for id := range areas {
+		if areas[id] > areas[best] {
+			best = id
+		}
+	}
+	var out []triangle
+	for i, t := range tris {
+		if component[i] == best {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// triangleBoundaryRings traces the closed rings formed by the edges that belong
+// to exactly one triangle. Triangles are counterclockwise, so the outer ring
+// comes out counterclockwise and holes clockwise.
+func triangleBoundaryRings(points []Position, tris []triangle) [][]Position {
+	directed := make(map[[2]int]bool)
+	for _, t := range tris {
+		directed[[2]int{t.a, t.b}] = true
+		directed[[2]int{t.b, t.c}] = true
+		directed[[2]int{t.c, t.a}] = true
+	}
+	next := make(map[int][]int)
+	var starts []int
+	for _, t := range tris {
+		for _, e := range [][2]int{{t.a, t.b}, {t.b, t.c}, {t.c, t.a}} {
+			if !directed[[2]int{e[1], e[0]}] {
+				if len(next[e[0]]) == 0 {
+					starts = append(starts, e[0])
+				}
+				next[e[0]] = append(next[e[0]], e[1])
+			}
+		}
+	}
+
+	var rings [][]Position
+	for _, start := range starts {
+		for len(next[start]) > 0 {
+			ring := []Position{points[start]}
+			current := start
+			for {
+				outs := next[current]
+				if len(outs) == 0 {
+					break
+				}
+				to := outs[len(outs)-1]
+				next[current] = outs[:len(outs)-1]
+				ring = append(ring, points[to])
+				current = to
+				if current == start {
+					break
+				}
+			}
+			if len(ring) >= 4 && samePosition(ring[0], ring[len(ring)-1]) {
+				rings = append(rings, ring)
+			}
+		}
+	}
+	return rings
+}
@@ -0,0 +1,72 @@
+package geo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestConcaveHullUShape(t *testing.T) {
+	// A U-shaped cloud on a 0.1° grid: two arms joined along the bottom.
+	var points []Position
+	for x := 0; x <= 10; x++ {
+		for y := 0; y <= 10; y++ {
+			inArm := x <= 2 || x >= 8
+			if inArm || y <= 2 {
+				points = append(points, Position{float64(x) * 0.1, float64(y) * 0.1})
+			}
+		}
+	}
+
+	hull, err := ConcaveHull(points, 20)
+	if err != nil {
+		t.Fatalf("ConcaveHull() error = %v", err)
+	}
+	if len(hull.Coordinates) != 1 {
+		t.Fatalf("rings = %d, want 1", len(hull.Coordinates))
+	}
+	ring := hull.Coordinates[0]
+	if !reflect.DeepEqual(ring[0], ring[len(ring)-1]) {
+		t.Errorf("ring is not closed")
+	}
+	area, _, _ := ringAreaCentroid(ring)
+	if area <= 0 {
+		t.Errorf("outer ring area = %v, want counterclockwise (positive)", area)
+	}
+
+	// The notch of the U is outside; every input point is inside or on the boundary.
+	if pointInPolygon(Position{0.5, 0.7}, hull) {
+		t.Errorf("notch point should be outside the concave hull")
+	}
+	for _, p := range points {
+		if !pointInPolygon(p, hull) {
+			t.Errorf("input point %v outside the hull", p)
+		}
+	}
+	// Two 0.2° x 1° arms, a 0.6° x 0.2° base, and a half-cell triangle
+	// cutting each inner corner (its diagonal is shorter than 20 km).
+	wantArea := 2*0.2*1.0 + 0.6*0.2 + 2*0.005
+	if math.Abs(area-wantArea) > 1e-9 {
+		t.Errorf("area = %v, want %v", area, wantArea)
+	}
+
+	convex, err := ConcaveHull(points, 1000)
+	if err != nil {
+		t.Fatalf("ConcaveHull() error = %v", err)
+	}
+	if !pointInPolygon(Position{0.5, 0.7}, convex) {
+		t.Errorf("a large edge limit should fill the notch")
+	}
+}
+
+func TestConcaveHullErrors(t *testing.T) {
+	if _, err := ConcaveHull([]Position{{0, 0}, {1, 1}, {2, 2}}, 1000); err == nil {
+		t.Error("expected error for collinear points")
+	}
+	if _, err := ConcaveHull([]Position{{0, 0}, {0, 0}, {1, 1}}, 1000); err == nil {
+		t.Error("expected error for fewer than three distinct points")
+	}
+	if _, err := ConcaveHull([]Position{{0, 0}, {1, 0}, {0, 1}}, 1); err == nil {
+		t.Error("expected error when no triangle fits the edge limit")
+	}
+}
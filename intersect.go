@@ -0,0 +1,221 @@
+package geo
+
+import (
+	"math"
+	"sort"
+)
+
+// arcEpsilon is the tolerance, in radians of arc, within which points on the
+// unit sphere count as the same point or as lying on an arc. It is about half
+// a millimeter on the Earth.
+const arcEpsilon = 1e-10
+
+// LineIntersections returns the points where a and b meet, treating their
+// segments as great-circle arcs. Crossings and touching points are reported
+// once each, even where they fall on a vertex shared by two segments, and a
+// stretch where the lines run along each other yields the two ends of the
+// overlap. Points are ordered along a; points that coincide with a vertex of
+// either line are that vertex exactly.
+func LineIntersections(a, b LineString) []Point {
+	arcsB := lineArcs(b.Coordinates)
+	var found []arcPoint
+	for _, arcA := range lineArcs(a.Coordinates) {
+		var hits []arcPoint
+		for _, arcB := range arcsB {
+			hits = append(hits, arcIntersections(arcA, arcB)...)
+		}
+		// Order the hits on this segment by their distance from its start.
+		sort.SliceStable(hits, func(i, j int) bool {
+			return angleBetween(arcA.v1, hits[i].v) < angleBetween(arcA.v1, hits[j].v)
+		})
+		for _, h := range hits {
+			if !containsArcPoint(found, h.v) {
+				found = append(found, h)
+			}
+		}
+	}
+	points := make([]Point, len(found))
+	for i, h := range found {
+		points[i] = positionPoint(h.p)
+	}
+	return points
+}
+
+// LinesIntersect reports whether a and b meet anywhere. It is LineIntersections
+// without collecting the points: segments whose bounding boxes are apart are
+// skipped, and it stops at the first meeting point.
+func LinesIntersect(a, b LineString) bool {
+	arcsA := lineArcs(a.Coordinates)
+	arcsB := lineArcs(b.Coordinates)
+	boxesB := make([]arcBox, len(arcsB))
+	for i, arc := range arcsB {
+		boxesB[i] = arc.bounds()
+	}
+	for _, arcA := range arcsA {
+		boxA := arcA.bounds()
+		for i, arcB := range arcsB {
+			if boxA.overlaps(boxesB[i]) && len(arcIntersections(arcA, arcB)) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// arc is a great-circle segment between two positions, with their unit
+// vectors and the unit normal of its plane.
+type arc struct {
+	p1, p2 Position
+	v1, v2 [3]float64
+	normal [3]float64
+}
+
+// arcPoint is a point on the sphere as a Position and as a unit vector.
+type arcPoint struct {
+	p Position
+	v [3]float64
+}
+
+// lineArcs returns the segments of a line, skipping zero-length ones. A line
+// whose vertices all coincide yields a single degenerate arc at that vertex.
+func lineArcs(coords []Position) []arc {
+	var arcs []arc
+	for i := 0; i+1 < len(coords); i++ {
+		v1, v2 := unitVector(coords[i]), unitVector(coords[i+1])
+		n := cross3(v1, v2)
+		if norm3(n) <= arcEpsilon {
+			continue
+		}
+		arcs = append(arcs, arc{p1: coords[i], p2: coords[i+1], v1: v1, v2: v2, normal: scale3(n, 1/norm3(n))})
+	}
+	if len(arcs) == 0 && len(coords) > 0 {
+		v := unitVector(coords[0])
+		arcs = append(arcs, arc{p1: coords[0], p2: coords[0], v1: v, v2: v})
+	}
+	return arcs
+}
+
+// contains reports whether the unit vector v lies on the arc.
+func (a arc) contains(v [3]float64) bool {
+	if a.normal == ([3]float64{}) {
+		return angleBetween(a.v1, v) <= arcEpsilon
+	}
+	if math.Abs(dot3(v, a.normal)) > arcEpsilon {
+		return false
+	}
+	if angleBetween(a.v1, v) <= arcEpsilon || angleBetween(a.v2, v) <= arcEpsilon {
+		return true
+	}
+	return dot3(cross3(a.v1, v), a.normal) > 0 && dot3(cross3(v, a.v2), a.normal) > 0
+}
+
+// arcIntersections returns the points where two arcs meet: nothing, one point,
+// or the ends of the stretch they share when they lie on the same great
+// circle. Points within arcEpsilon of an endpoint are that endpoint.
+func arcIntersections(a, b arc) []arcPoint {
+	ends := []arcPoint{{a.p1, a.v1}, {a.p2, a.v2}, {b.p1, b.v1}, {b.p2, b.v2}}
+
+	line := cross3(a.normal, b.normal)
+	if norm3(line) <= arcEpsilon {
+		// Same great circle, or a degenerate arc: the shared stretch is
+		// bounded by endpoints of the arcs.
+		var out []arcPoint
+		for i, e := range ends {
+			other := b
+			if i >= 2 {
+				other = a
+			}
+			if other.contains(e.v) && !containsArcPoint(out, e.v) {
+				out = append(out, e)
+			}
+		}
+		return out
+	}
+
+	line = scale3(line, 1/norm3(line))
+	for _, v := range [][3]float64{line, scale3(line, -1)} {
+		if !a.contains(v) || !b.contains(v) {
+			continue
+		}
+		for _, e := range ends {
+			if angleBetween(e.v, v) <= arcEpsilon {
+				return []arcPoint{e}
+			}
+		}
+		lat := toDegrees(math.Asin(math.Max(-1, math.Min(1, v[2]))))
+		lon := toDegrees(math.Atan2(v[1], v[0]))
+		return []arcPoint{{Position{lon, lat}, v}}
+	}
+	return nil
+}
+
+func containsArcPoint(points []arcPoint, v [3]float64) bool {
+	for _, p := range points {
+		if angleBetween(p.v, v) <= arcEpsilon {
+			return true
+		}
+	}
+	return false
+}
+
+// arcBox is a latitude/longitude box around an arc. A box with wraps set spans
+// the antimeridian and is only compared by latitude.
+type arcBox struct {
+	minLat, maxLat, minLon, maxLon float64
+	wraps                          bool
+}
+
+// bounds returns a box containing the whole arc, including the part of a
+// great circle that bulges toward a pole beyond both endpoints.
+func (a arc) bounds() arcBox {
+	box := arcBox{
+		minLat: math.Min(a.p1[1], a.p2[1]), maxLat: math.Max(a.p1[1], a.p2[1]),
+		minLon: math.Min(a.p1[0], a.p2[0]), maxLon: math.Max(a.p1[0], a.p2[0]),
+	}
+	box.wraps = box.maxLon-box.minLon > 180
+	// The northernmost point of the great circle is the north pole direction
+	// with its component along the normal removed.
+	top := [3]float64{-a.normal[2] * a.normal[0], -a.normal[2] * a.normal[1], 1 - a.normal[2]*a.normal[2]}
+	if n := norm3(top); n > arcEpsilon {
+		top = scale3(top, 1/n)
+		if a.contains(top) {
+			box.maxLat = toDegrees(math.Asin(math.Min(1, top[2])))
+		}
+		if bottom := scale3(top, -1); a.contains(bottom) {
+			box.minLat = toDegrees(math.Asin(math.Max(-1, bottom[2])))
+		}
+	}
+	return box
+}
+
+func (b arcBox) overlaps(o arcBox) bool {
+	const pad = 1e-9
+	if b.maxLat < o.minLat-pad || o.maxLat < b.minLat-pad {
+		return false
+	}
+	if b.wraps || o.wraps {
+		return true
+	}
+	return b.maxLon >= o.minLon-pad && o.maxLon >= b.minLon-pad
+}
+
+func cross3(a, b [3]float64) [3]float64 {
+	return [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+func dot3(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func norm3(a [3]float64) float64 {
+	return math.Sqrt(dot3(a, a))
+}
+
+func scale3(a [3]float64, s float64) [3]float64 {
+	return [3]float64{a[0] * s, a[1] * s, a[2] * s}
+}
+
+// angleBetween returns the angle in radians between two unit vectors.
+func angleBetween(a, b [3]float64) float64 {
+	return math.Atan2(norm3(cross3(a, b)), dot3(a, b))
+}
@@ -0,0 +1,99 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLineIntersections(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b LineString
+		want []Position
+	}{
+		{
+			name: "x crossing",
+			a:    NewLineString([]Position{{-1, -1}, {1, 1}}),
+			b:    NewLineString([]Position{{-1, 1}, {1, -1}}),
+			want: []Position{{0, 0}},
+		},
+		{
+			name: "zigzag",
+			a:    NewLineString([]Position{{0, 0}, {4, 0}}),
+			b:    NewLineString([]Position{{0.5, 1}, {1, -1}, {2, 1}, {3, -1}}),
+			want: nil, // three crossings, checked below
+		},
+		{
+			name: "disjoint",
+			a:    NewLineString([]Position{{0, 0}, {1, 0}}),
+			b:    NewLineString([]Position{{0, 1}, {1, 1}}),
+			want: []Position{},
+		},
+		{
+			name: "collinear overlap",
+			a:    NewLineString([]Position{{0, 0}, {10, 0}}),
+			b:    NewLineString([]Position{{15, 0}, {5, 0}}),
+			want: []Position{{5, 0}, {10, 0}},
+		},
+		{
+			name: "shared vertex",
+			a:    NewLineString([]Position{{0, 0}, {1, 1}, {2, 0}}),
+			b:    NewLineString([]Position{{1, 1}, {1, 5}}),
+			want: []Position{{1, 1}},
+		},
+		{
+			name: "touching end",
+			a:    NewLineString([]Position{{0, 0}, {2, 0}}),
+			b:    NewLineString([]Position{{1, 0}, {1, 3}}),
+			want: []Position{{1, 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LineIntersections(tt.a, tt.b)
+			if tt.want == nil {
+				if len(got) != 3 {
+					t.Fatalf("got %d points %v, want 3", len(got), got)
+				}
+				for i, p := range got {
+					if lat := p.Coordinates.Lat(); lat > 1e-12 || lat < -1e-12 {
+						t.Errorf("point %d = %v, want it on the equator", i, p.Coordinates)
+					}
+					if i > 0 && p.Coordinates.Lon() <= got[i-1].Coordinates.Lon() {
+						t.Errorf("points not ordered along a: %v", got)
+					}
+				}
+			} else {
+				if len(got) != len(tt.want) {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+				for i, p := range got {
+					if d := GreatCircleDistance(p.Coordinates.Lat(), p.Coordinates.Lon(), tt.want[i].Lat(), tt.want[i].Lon()); d > 1e-6 {
+						t.Errorf("point %d = %v, want %v", i, p.Coordinates, tt.want[i])
+					}
+				}
+			}
+			if intersects := LinesIntersect(tt.a, tt.b); intersects != (len(got) > 0) {
+				t.Errorf("LinesIntersect = %v, want %v", intersects, len(got) > 0)
+			}
+		})
+	}
+}
+
+func TestLinesIntersectGreatCircleBulge(t *testing.T) {
+	// The great circle from (-60, 60) to (60, 60) peaks near 73.9° on the
+	// prime meridian, well above both endpoints, so it crosses a meridian
+	// segment that a box around the endpoints would miss.
+	a := NewLineString([]Position{{-60, 60}, {60, 60}})
+	b := NewLineString([]Position{{0, 70}, {0, 80}})
+	if !LinesIntersect(a, b) {
+		t.Error("LinesIntersect = false, want true")
+	}
+	got := LineIntersections(a, b)
+	if len(got) != 1 || got[0].Coordinates.Lon() != 0 {
+		t.Fatalf("LineIntersections = %v, want one point on the meridian", got)
+	}
+	if lat := got[0].Coordinates.Lat(); math.Abs(lat-73.898) > 0.001 {
+		t.Errorf("crossing at latitude %v, want 73.898", lat)
+	}
+}
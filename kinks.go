@@ -0,0 +1,155 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Kinks returns the points where a line or polygon ring crosses or touches
+// itself. Each LineString and each polygon ring is checked on its own, so
+// places where two rings or two parts meet are not kinks. Consecutive
+// segments meeting at their shared vertex are not kinks either; a closed
+// LineString is treated as a ring. Every point is reported once, in the lon/lat
+// plane.
+//
+// LineString, MultiLineString, Polygon and MultiPolygon geometries are
+// checked, as are those inside features, feature collections and geometry
+// collections; points have no kinks.
+func Kinks(obj interface{}) ([]Point, error) {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return nil, err
+	}
+	var lines [][]Position
+	switch g := geom.(type) {
+	case Point, MultiPoint:
+		return nil, nil
+	case LineString:
+		lines = [][]Position{g.Coordinates}
+	case MultiLineString:
+		lines = g.Coordinates
+	case Polygon:
+		lines = g.Coordinates
+	case MultiPolygon:
+		for _, poly := range g.Coordinates {
+			lines = append(lines, poly...)
+		}
+	case GeometryCollection:
+		return kinksOfAll(len(g.Geometries), func(i int) interface{} { return g.Geometries[i] })
+	case Feature:
+		return Kinks(g.Geometry)
+	case FeatureCollection:
+		return kinksOfAll(len(g.Features), func(i int) interface{} { return g.Features[i] })
+	default:
+		return nil, fmt.Errorf("unsupported geojson type %T", obj)
+	}
+
+	var kinks []Point
+	seen := make(map[[2]float64]bool)
+	for _, line := range lines {
+		segs, closed := lineSegments(line), false
+		if open := openRing(line); len(open) >= 3 && samePosition(line[0], line[len(line)-1]) {
+			segs, closed = ringSegments([][]Position{open}), true
+		}
+		selfIntersections(segs, closed, func(_, _ int, p Position) {
+			if !seen[lonLat(p)] {
+				seen[lonLat(p)] = true
+				kinks = append(kinks, NewPoint(p[0], p[1]))
+			}
+		})
+	}
+	return kinks, nil
+}
+
+func kinksOfAll(n int, member func(int) interface{}) ([]Point, error) {
+	var kinks []Point
+	for i := 0; i < n; i++ {
+		pts, err := Kinks(member(i))
+		if err != nil {
+			return nil, err
+		}
+		kinks = append(kinks, pts...)
+	}
+	return kinks, nil
+}
+
+// Unkink splits a self-intersecting polygon into simple polygons at its
+// kinks. The rings are cut at every point where they cross themselves and
+// the pieces are joined back into loops that touch at those points without
+// crossing, so a bowtie becomes two triangles. Loops of the outer ring become
+// counterclockwise outer rings, and loops of the holes become holes of the
+// smallest outer ring around them. Where the outer ring winds around an area
+// twice, the inner loop comes out as a separate polygon overlapping the
+// outer one.
+//
+// A simple polygon comes back as a MultiPolygon of itself, with its rings
+// rewound to the usual orientation. Works in the lon/lat plane.
+func Unkink(poly Polygon) (MultiPolygon, error) {
+	if len(poly.Coordinates) == 0 || len(openRing(poly.Coordinates[0])) < 3 {
+		return MultiPolygon{}, errors.New("polygon has no outer ring")
+	}
+	var rings [][]Position
+	for i, ring := range poly.Coordinates {
+		segs := ringSegments([][]Position{openRing(ring)})
+		cuts := make([][]Position, len(segs))
+		selfIntersections(segs, true, func(a, b int, p Position) {
+			cuts[a] = append(cuts[a], p)
+			cuts[b] = append(cuts[b], p)
+		})
+		for _, loop := range linkOverlayEdges(cutSegments(segs, cuts)) {
+			area, _, _ := ringAreaCentroid(loop)
+			if (i == 0) != (area > 0) {
+				loop = reversedRing(loop)
+			}
+			rings = append(rings, loop)
+		}
+	}
+	assembled := assemblePolygons(rings)
+	if p, ok := assembled.(Polygon); ok {
+		return NewMultiPolygon([][][]Position{p.Coordinates}), nil
+	}
+	mp := assembled.(MultiPolygon)
+	if len(mp.Coordinates) == 0 {
+		return MultiPolygon{}, errors.New("polygon has no area")
+	}
+	return mp, nil
+}
+
+// selfIntersections calls fn with the indices a < b of every pair of segments
+// that meet and each point where they do, leaving out the shared vertex of
+// consecutive segments. When closed is true the last segment is followed by
+// the first. Segments are swept in order of their western ends so that only
+// pairs whose longitude ranges overlap are tested.
+func selfIntersections(segs [][2]Position, closed bool, fn func(a, b int, p Position)) {
+	n := len(segs)
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	west := func(i int) float64 { return math.Min(segs[i][0][0], segs[i][1][0]) }
+	east := func(i int) float64 { return math.Max(segs[i][0][0], segs[i][1][0]) }
+	sort.SliceStable(order, func(x, y int) bool { return west(order[x]) < west(order[y]) })
+
+	for x, i := range order {
+		for _, j := range order[x+1:] {
+			if west(j) > east(i)+clipEpsilon {
+				break
+			}
+			a, b := min(i, j), max(i, j)
+			var shared *Position
+			switch {
+			case b == a+1:
+				shared = &segs[a][1]
+			case closed && a == 0 && b == n-1:
+				shared = &segs[a][0]
+			}
+			for _, p := range segmentIntersections(segs[a][0], segs[a][1], segs[b][0], segs[b][1]) {
+				if shared == nil || !samePosition(p, *shared) {
+					fn(a, b, p)
+				}
+			}
+		}
+	}
+}
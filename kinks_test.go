@@ -0,0 +1,82 @@
+package geo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func bowtie() Polygon {
+	return NewPolygon([][]Position{{{0, 0}, {2, 2}, {2, 0}, {0, 2}, {0, 0}}})
+}
+
+func TestKinksBowtie(t *testing.T) {
+	kinks, err := Kinks(bowtie())
+	if err != nil {
+		t.Fatalf("Kinks() error = %v", err)
+	}
+	if len(kinks) != 1 || !reflect.DeepEqual(kinks[0].Coordinates, Position{1, 1}) {
+		t.Fatalf("Kinks() = %v, want one kink at (1, 1)", kinks)
+	}
+
+	mp, err := Unkink(bowtie())
+	if err != nil {
+		t.Fatalf("Unkink() error = %v", err)
+	}
+	if len(mp.Coordinates) != 2 {
+		t.Fatalf("Unkink() gave %d polygons, want 2", len(mp.Coordinates))
+	}
+	var total float64
+	for _, rings := range mp.Coordinates {
+		if len(rings) != 1 || len(rings[0]) != 4 {
+			t.Errorf("polygon %v, want a triangle", rings)
+		}
+		area, _, _ := ringAreaCentroid(rings[0])
+		if area <= 0 {
+			t.Errorf("triangle %v is not counterclockwise", rings[0])
+		}
+		if k, _ := Kinks(NewPolygon(rings)); len(k) != 0 {
+			t.Errorf("triangle %v has kinks %v", rings[0], k)
+		}
+		total += area
+	}
+	if math.Abs(total-2) > 1e-12 {
+		t.Errorf("total area = %v, want 2", total)
+	}
+}
+
+func TestKinksSimpleAndLines(t *testing.T) {
+	square := squarePolygon(0, 0, 1, 1)
+	if kinks, err := Kinks(NewFeature(square)); err != nil || len(kinks) != 0 {
+		t.Errorf("Kinks(square) = %v, %v, want none", kinks, err)
+	}
+	mp, err := Unkink(square)
+	if err != nil || len(mp.Coordinates) != 1 || polygonPlanarArea(NewPolygon(mp.Coordinates[0])) != 1 {
+		t.Errorf("Unkink(square) = %v, %v, want the square", mp, err)
+	}
+
+	// A line crossing the x axis it starts on, with three segments meeting at
+	// (1.5, 0), that ends on one of its own vertices.
+	line := NewLineString([]Position{{0, 0}, {4, 0}, {4, 2}, {2, -2}, {1, 2}, {1, -1}, {2, 1}, {4, 2}})
+	kinks, err := Kinks(line)
+	if err != nil {
+		t.Fatalf("Kinks() error = %v", err)
+	}
+	want := map[[2]float64]bool{{3, 0}: true, {1.5, 0}: true, {1, 0}: true, {4, 2}: true}
+	for _, k := range kinks {
+		if !want[lonLat(k.Coordinates)] {
+			t.Errorf("unexpected kink %v", k.Coordinates)
+		}
+		delete(want, lonLat(k.Coordinates))
+	}
+	for p := range want {
+		t.Errorf("missing kink %v", p)
+	}
+
+	if _, err := Kinks("nope"); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+	if _, err := Unkink(Polygon{}); err == nil {
+		t.Error("expected error for empty polygon")
+	}
+}
@@ -0,0 +1,174 @@
+package geo
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// PoleOfInaccessibility returns the point inside the polygon that is farthest
+// from its boundary, holes included. It is a better label position than the
+// centroid for concave shapes, where the centroid can fall outside or close to
+// an edge.
+//
+// The search uses the polylabel grid-refinement algorithm: the bounding box is
+// covered with square cells that are repeatedly split, and cells that cannot
+// contain a point better than the current best by more than precisionKm are
+// dropped. Distances are measured in an equirectangular projection centered on
+// the polygon, which is accurate for polygons that are small compared with the
+// Earth. A non-positive precisionKm uses 1/1000 of the polygon's extent.
+//
+// It also returns the pole's distance from the boundary in kilometers,
+// measured along great circles to the nearest ring edge as PolygonPointDistance
+// does. A polygon without coordinates or without area gives an error.
+func PoleOfInaccessibility(poly Polygon, precisionKm float64) (Point, float64, error) {
+	if len(poly.Coordinates) == 0 || len(poly.Coordinates[0]) == 0 {
+		return Point{}, 0, errors.New("polygon has no coordinates")
+	}
+	if math.IsNaN(precisionKm) || math.IsInf(precisionKm, 0) {
+		return Point{}, 0, fmt.Errorf("invalid precision %v", precisionKm)
+	}
+	outer := poly.Coordinates[0]
+
+	minLon, minLat := outer[0][0], outer[0][1]
+	maxLon, maxLat := minLon, minLat
+	for _, p := range outer[1:] {
+		minLon = math.Min(minLon, p[0])
+		minLat = math.Min(minLat, p[1])
+		maxLon = math.Max(maxLon, p[0])
+		maxLat = math.Max(maxLat, p[1])
+	}
+
+	// Project to kilometers around the middle latitude.
+	ky := EarthRadiusKm * math.Pi / 180
+	kx := ky * math.Cos(toRadians((minLat+maxLat)/2))
+	rings := make([][]Position, len(poly.Coordinates))
+	for i, ring := range poly.Coordinates {
+		rings[i] = make([]Position, len(ring))
+		for j, p := range ring {
+			rings[i][j] = Position{(p[0] - minLon) * kx, (p[1] - minLat) * ky}
+		}
+	}
+	unproject := func(p Position) Point {
+		return NewPoint(p[0]/kx+minLon, p[1]/ky+minLat)
+	}
+
+	width := (maxLon - minLon) * kx
+	height := (maxLat - minLat) * ky
+	cellSize := math.Min(width, height)
+	if cellSize == 0 {
+		return Point{}, 0, errors.New("polygon has no area")
+	}
+	if precisionKm <= 0 {
+		precisionKm = math.Max(width, height) / 1000
+	}
+
+	var cells labelCellQueue
+	h := cellSize / 2
+	for x := 0.0; x < width; x += cellSize {
+		for y := 0.0; y < height; y += cellSize {
+			heap.Push(&cells, newLabelCell(x+h, y+h, h, rings))
+		}
+	}
+
+	// Seed with the centroid of the outer ring and the center of the box.
+	best := newLabelCell(width/2, height/2, 0, rings)
+	if area, cx, cy := ringAreaCentroid(rings[0]); area != 0 {
+		if c := newLabelCell(cx, cy, 0, rings); c.d > best.d {
+			best = c
+		}
+	}
+
+	for cells.Len() > 0 {
+		cell := heap.Pop(&cells).(*labelCell)
+		if cell.d > best.d {
+			best = cell
+		}
+		if cell.max-best.d <= precisionKm {
+			continue
+		}
+		h = cell.h / 2
+		heap.Push(&cells, newLabelCell(cell.x-h, cell.y-h, h, rings))
+		heap.Push(&cells, newLabelCell(cell.x+h, cell.y-h, h, rings))
+		heap.Push(&cells, newLabelCell(cell.x-h, cell.y+h, h, rings))
+		heap.Push(&cells, newLabelCell(cell.x+h, cell.y+h, h, rings))
+	}
+	pole := unproject(Position{best.x, best.y})
+	d, err := polygonPointDistance(poly, pole)
+	if err != nil {
+		return Point{}, 0, err
+	}
+	return pole, math.Abs(d), nil
+}
+
+// labelCell is a square search cell for PoleOfInaccessibility.
+type labelCell struct {
+	x, y float64 // cell center
+	h    float64 // half the cell size
+	d    float64 // signed distance from the center to the polygon boundary
+	max  float64 // upper bound on the distance of any point in the cell
+}
+
+func newLabelCell(x, y, h float64, rings [][]Position) *labelCell {
+	d := planarPolygonSignedDistance(Position{x, y}, rings)
+	return &labelCell{x: x, y: y, h: h, d: d, max: d + h*math.Sqrt2}
+}
+
+// planarPolygonSignedDistance returns the distance from p to the nearest ring
+// edge, positive inside the polygon and negative outside.
+func planarPolygonSignedDistance(p Position, rings [][]Position) float64 {
+	inside := false
+	minDist := math.Inf(1)
+	for _, ring := range rings {
+		n := len(ring)
+		for i, j := 0, n-1; i < n; j, i = i, i+1 {
+			a, b := ring[i], ring[j]
+			if (a[1] > p[1]) != (b[1] > p[1]) &&
+				p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0] {
+				inside = !inside
+			}
+			minDist = math.Min(minDist, planarSegmentDistance(p, a, b))
+		}
+	}
+	if inside {
+		return minDist
+	}
+	return -minDist
+}
+
+// planarSegmentDistance returns the distance from p to segment a-b.
+func planarSegmentDistance(p, a, b Position) float64 {
+	x, y := a[0], a[1]
+	dx, dy := b[0]-x, b[1]-y
+	if dx != 0 || dy != 0 {
+		t := ((p[0]-x)*dx + (p[1]-y)*dy) / (dx*dx + dy*dy)
+		if t > 1 {
+			x, y = b[0], b[1]
+		} else if t > 0 {
+			x += dx * t
+			y += dy * t
+		}
+	}
+	return math.Hypot(p[0]-x, p[1]-y)
+}
+
+// labelCellQueue implements heap.Interface as a max-heap on labelCell.max.
+type labelCellQueue []*labelCell
+
+func (q labelCellQueue) Len() int { return len(q) }
+
+func (q labelCellQueue) Less(i, j int) bool { return q[i].max > q[j].max }
+
+func (q labelCellQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *labelCellQueue) Push(x interface{}) { *q = append(*q, x.(*labelCell)) }
+
+func (q *labelCellQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	cell := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return cell
+}
@@ -0,0 +1,107 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPoleOfInaccessibilityCShape(t *testing.T) {
+	// A C-shaped polygon opening to the east. Its centroid lies in the
+	// opening, outside the polygon.
+	poly := NewPolygon([][]Position{{
+		{0, 0}, {1, 0}, {1, 0.2}, {0.2, 0.2}, {0.2, 0.8}, {1, 0.8}, {1, 1}, {0, 1}, {0, 0},
+	}})
+	if centroid, _, _ := polygonCentroidArea(poly); pointInPolygon(centroid, poly) {
+		t.Fatalf("test polygon centroid %v should be outside", centroid)
+	}
+
+	pole, dist, err := PoleOfInaccessibility(poly, 0.1)
+	if err != nil {
+		t.Fatalf("PoleOfInaccessibility() error = %v", err)
+	}
+	if !pointInPolygon(pole.Coordinates, poly) {
+		t.Fatalf("pole %v is outside the polygon", pole.Coordinates)
+	}
+	// The widest part of the C is the western bar, 0.2 degrees across, so the
+	// best label point is in that bar about 0.1 degrees from the edges.
+	lon, lat := pole.Coordinates[0], pole.Coordinates[1]
+	if lon < 0.05 || lon > 0.15 {
+		t.Errorf("pole lon = %v, want within the western bar", lon)
+	}
+	if lat < 0.1 || lat > 0.9 {
+		t.Errorf("pole lat = %v, want away from the arms", lat)
+	}
+	d, err := PolygonPointDistance(poly, pole)
+	if err != nil {
+		t.Fatalf("PolygonPointDistance() error = %v", err)
+	}
+	// Inside points have a negative distance.
+	if d > -10 {
+		t.Errorf("distance to boundary = %.2f km, want at least 10 km inside", d)
+	}
+	if math.Abs(dist+d) > 1e-9 {
+		t.Errorf("returned distance = %v km, want %v", dist, -d)
+	}
+}
+
+func TestPoleOfInaccessibilitySquare(t *testing.T) {
+	pole, _, err := PoleOfInaccessibility(squarePolygon(10, 10, 12, 12), 0.01)
+	if err != nil {
+		t.Fatalf("PoleOfInaccessibility() error = %v", err)
+	}
+	if math.Abs(pole.Coordinates[0]-11) > 0.01 || math.Abs(pole.Coordinates[1]-11) > 0.01 {
+		t.Errorf("pole = %v, want near (11, 11)", pole.Coordinates)
+	}
+}
+
+func TestPoleOfInaccessibilityAvoidsHole(t *testing.T) {
+	poly := NewPolygon([][]Position{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}},
+	})
+	pole, _, err := PoleOfInaccessibility(poly, 0.1)
+	if err != nil {
+		t.Fatalf("PoleOfInaccessibility() error = %v", err)
+	}
+	if !pointInPolygon(pole.Coordinates, poly) {
+		t.Errorf("pole %v is not in the polygon body", pole.Coordinates)
+	}
+}
+
+func TestPoleOfInaccessibilityErrors(t *testing.T) {
+	if _, _, err := PoleOfInaccessibility(Polygon{}, 0); err == nil {
+		t.Error("PoleOfInaccessibility() of an empty polygon should fail")
+	}
+	flat := NewPolygon([][]Position{{{0, 0}, {1, 0}, {2, 0}, {0, 0}}})
+	if _, _, err := PoleOfInaccessibility(flat, 0); err == nil {
+		t.Error("PoleOfInaccessibility() of a polygon without area should fail")
+	}
+}
+
+func TestPointOnSurfaceUShape(t *testing.T) {
+	// A U opening to the north, with its centroid in the gap between the arms.
+	poly := NewPolygon([][]Position{{
+		{0, 0}, {3, 0}, {3, 3}, {2, 3}, {2, 1}, {1, 1}, {1, 3}, {0, 3}, {0, 0},
+	}})
+	if centroid, _, _ := polygonCentroidArea(poly); pointInPolygon(centroid, poly) {
+		t.Fatalf("test polygon centroid %v should be outside", centroid)
+	}
+
+	surface, err := GeoJSONPointOnSurface(poly)
+	if err != nil {
+		t.Fatalf("GeoJSONPointOnSurface() error = %v", err)
+	}
+	inside, err := PointInPolygon(surface, poly, true)
+	if err != nil || !inside {
+		t.Fatalf("GeoJSONPointOnSurface() = %v, want a point inside", surface.Coordinates)
+	}
+	d, err := PolygonPointDistance(poly, surface)
+	if err != nil {
+		t.Fatalf("PolygonPointDistance() error = %v", err)
+	}
+	// The first vertex, which used to be returned, is on the boundary.
+	vertex, _ := PolygonPointDistance(poly, positionPoint(poly.Coordinates[0][0]))
+	if -d < math.Abs(vertex) || -d < 50 {
+		t.Errorf("distance to boundary = %.2f km, want well inside", -d)
+	}
+}
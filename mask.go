@@ -0,0 +1,86 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// worldRing is the whole world as an open counterclockwise ring. Its edges
+// are at most 90° long, so no edge is mistaken for one crossing the
+// antimeridian.
+var worldRing = []Position{
+	{-180, -90}, {-90, -90}, {0, -90}, {90, -90}, {180, -90},
+	{180, 90}, {90, 90}, {0, 90}, {-90, 90}, {-180, 90},
+}
+
+// Mask returns everything inside outer except the area of poly, for shading
+// the surroundings of a region on a map. The mask's outer ring is the outer
+// ring of outer, or the whole world when outer is nil, and the outer rings of
+// poly become its holes. Holes of poly are not part of poly's area, so they
+// come out as islands: separate polygons, which make the result a
+// MultiPolygon. Rings are wound as RFC 7946 requires, outer rings
+// counterclockwise and holes clockwise.
+//
+// poly may be a Polygon or MultiPolygon, or a Feature holding one. Polygons
+// of poly that are not wholly inside outer are left out. Like Union, the mask
+// is built in the lon/lat plane, so poly must not cross the antimeridian.
+func Mask(poly interface{}, outer *Polygon) (interface{}, error) {
+	geom, err := geometryOf(poly)
+	if err != nil {
+		return nil, err
+	}
+	var polys [][][]Position
+	switch g := geom.(type) {
+	case Polygon:
+		polys = [][][]Position{g.Coordinates}
+	case MultiPolygon:
+		polys = g.Coordinates
+	case Feature:
+		return Mask(g.Geometry, outer)
+	default:
+		return nil, fmt.Errorf("unsupported geojson type %T", poly)
+	}
+
+	boundary := worldRing
+	if outer != nil {
+		if len(outer.Coordinates) == 0 || len(openRing(outer.Coordinates[0])) < 3 {
+			return nil, errors.New("outer polygon has no outer ring")
+		}
+		boundary = openRing(outer.Coordinates[0])
+	}
+
+	// Outer rings of poly are holes of the mask and holes of poly are
+	// islands; assemblePolygons puts each hole in the smallest ring around it,
+	// so polygons inside islands become holes of those islands.
+	rings := [][]Position{wound(boundary, true)}
+	for _, p := range polys {
+		if len(p) == 0 || !ringWithin(openRing(p[0]), boundary) {
+			continue
+		}
+		for i, ring := range p {
+			if open := openRing(ring); len(open) >= 3 {
+				rings = append(rings, wound(open, i > 0))
+			}
+		}
+	}
+	return assemblePolygons(rings), nil
+}
+
+// wound returns an open ring wound counterclockwise, or clockwise when ccw is
+// false.
+func wound(ring []Position, ccw bool) []Position {
+	if area, _, _ := ringAreaCentroid(ring); (area > 0) != ccw {
+		return reversedRing(ring)
+	}
+	return ring
+}
+
+// ringWithin reports whether every vertex of ring is inside or on boundary.
+func ringWithin(ring, boundary []Position) bool {
+	for _, p := range ring {
+		if !pointInRing(p, boundary) {
+			return false
+		}
+	}
+	return len(ring) > 0
+}
@@ -0,0 +1,69 @@
+package geo
+
+import "testing"
+
+func TestMask(t *testing.T) {
+	donut := NewPolygon([][]Position{
+		squarePolygon(0, 0, 10, 10).Coordinates[0],
+		squarePolygon(4, 4, 6, 6).Coordinates[0],
+	})
+	mask, err := Mask(NewFeature(donut), nil)
+	if err != nil {
+		t.Fatalf("Mask() error = %v", err)
+	}
+	mp, ok := mask.(MultiPolygon)
+	if !ok || len(mp.Coordinates) != 2 {
+		t.Fatalf("Mask() = %v, want the world with a hole and an island", mask)
+	}
+	for i, poly := range mp.Coordinates {
+		for j, ring := range poly {
+			if RingIsClockwise(ring) != (j > 0) {
+				t.Errorf("polygon %d ring %d is wound the wrong way", i, j)
+			}
+		}
+	}
+
+	tests := []struct {
+		p    Position
+		want bool
+	}{
+		{Position{2, 2}, false},  // in the donut
+		{Position{5, 5}, true},   // in the donut's hole
+		{Position{20, 20}, true}, // outside the donut
+		{Position{179.5, -60}, true},
+		{Position{-179.5, 60}, true},
+	}
+	for _, tt := range tests {
+		inDonut, _ := PointInPolygon(NewPoint(tt.p[0], tt.p[1]), donut, true)
+		inMask, err := PointInPolygon(NewPoint(tt.p[0], tt.p[1]), mask, true)
+		if err != nil || inMask != tt.want || inMask == inDonut {
+			t.Errorf("point %v: in mask = %v, in donut = %v, want in mask = %v", tt.p, inMask, inDonut, tt.want)
+		}
+	}
+}
+
+func TestMaskOuter(t *testing.T) {
+	outer := squarePolygon(-20, -20, 20, 20)
+	polys := NewMultiPolygon([][][]Position{
+		squarePolygon(0, 0, 1, 1).Coordinates,
+		squarePolygon(30, 30, 31, 31).Coordinates,
+	})
+	mask, err := Mask(polys, &outer)
+	if err != nil {
+		t.Fatalf("Mask() error = %v", err)
+	}
+	poly, ok := mask.(Polygon)
+	if !ok || len(poly.Coordinates) != 2 {
+		t.Fatalf("Mask() = %v, want the outer square with one hole", mask)
+	}
+	if got := polygonPlanarArea(poly); got != 1599 {
+		t.Errorf("mask area = %v, want 1599", got)
+	}
+
+	if _, err := Mask(NewPoint(0, 0), nil); err == nil {
+		t.Error("expected error for a point")
+	}
+	if _, err := Mask(polys, &Polygon{}); err == nil {
+		t.Error("expected error for an empty outer polygon")
+	}
+}
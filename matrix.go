@@ -0,0 +1,175 @@
+package geo
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DistanceFunc gives the distance between nodes 0..N()-1 of a TSP instance.
+// It lets the solvers read distances from storage other than a dense
+// [][]float64, such as a SymmetricMatrix.
+type DistanceFunc interface {
+	N() int
+	At(i, j int) float64
+}
+
+// DenseMatrix adapts a square [][]float64 distance matrix to DistanceFunc.
+type DenseMatrix [][]float64
+
+// N returns the number of nodes.
+func (m DenseMatrix) N() int { return len(m) }
+
+// At returns the distance from node i to node j.
+func (m DenseMatrix) At(i, j int) float64 { return m[i][j] }
+
+// DistanceMatrix returns the n x n great-circle distance matrix between points
+// in the requested unit. Each pair is computed once and mirrored, since the
+// distance is symmetric.
+func DistanceMatrix(points []LatLon, unit DistanceUnit) [][]float64 {
+	n := len(points)
+	backing := make([]float64, n*n)
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = backing[i*n : (i+1)*n : (i+1)*n]
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			d := ConvertDistanceFromKm(GreatCircleDistance(points[i].Lat, points[i].Lon, points[j].Lat, points[j].Lon), unit)
+			m[i][j] = d
+			m[j][i] = d
+		}
+	}
+	return m
+}
+
+// SymmetricMatrix stores a symmetric distance matrix with a zero diagonal in
+// n(n-1)/2 entries, half the memory of the dense form.
+type SymmetricMatrix struct {
+	n    int
+	data []float64
+}
+
+// NewSymmetricMatrix returns an n x n symmetric matrix with every distance 0.
+func NewSymmetricMatrix(n int) *SymmetricMatrix {
+	if n < 0 {
+		n = 0
+	}
+	return &SymmetricMatrix{n: n, data: make([]float64, n*(n-1)/2)}
+}
+
+// SymmetricMatrixFromPoints returns the great-circle distances between points
+// in kilometers.
+func SymmetricMatrixFromPoints(points []Position) *SymmetricMatrix {
+	m := NewSymmetricMatrix(len(points))
+	k := 0
+	for i := range points {
+		lat1, lon1 := positionLatLon(points[i])
+		for j := i + 1; j < len(points); j++ {
+			lat2, lon2 := positionLatLon(points[j])
+			m.data[k] = GreatCircleDistance(lat1, lon1, lat2, lon2)
+			k++
+		}
+	}
+	return m
+}
+
+// N returns the number of nodes.
+func (m *SymmetricMatrix) N() int { return m.n }
+
+// At returns the distance between nodes i and j. At(i, i) is always 0.
+func (m *SymmetricMatrix) At(i, j int) float64 {
+	if i == j {
+		return 0
+	}
+	return m.data[m.index(i, j)]
+}
+
+// Set sets the distance between nodes i and j in both directions. Setting the
+// diagonal has no effect.
+func (m *SymmetricMatrix) Set(i, j int, v float64) {
+	if i == j {
+		return
+	}
+	m.data[m.index(i, j)] = v
+}
+
+// index returns the position of pair (i, j) in the packed upper triangle,
+// stored row by row.
+func (m *SymmetricMatrix) index(i, j int) int {
+	if i > j {
+		i, j = j, i
+	}
+	if i < 0 || j >= m.n {
+		panic("geo: SymmetricMatrix index out of range")
+	}
+	return i*(2*m.n-i-1)/2 + j - i - 1
+}
+
+// lazyMatrixShards is the number of independently locked parts of a
+// LazyDistanceMatrix cache.
+const lazyMatrixShards = 64
+
+// LazyDistanceMatrix is a DistanceFunc that computes great-circle distances in
+// kilometers between points on first use and caches them. It suits heuristics
+// that only look at a fraction of all pairs, where building a full matrix up
+// front would be wasted work. It is safe for concurrent use.
+type LazyDistanceMatrix struct {
+	points []Position
+	shards [lazyMatrixShards]lazyMatrixShard
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+type lazyMatrixShard struct {
+	mu sync.Mutex
+	m  map[int]float64
+}
+
+// NewLazyDistanceMatrix returns a LazyDistanceMatrix over points. The slice is
+// not copied and must not be modified while the matrix is in use.
+func NewLazyDistanceMatrix(points []Position) *LazyDistanceMatrix {
+	l := &LazyDistanceMatrix{points: points}
+	for i := range l.shards {
+		l.shards[i].m = make(map[int]float64)
+	}
+	return l
+}
+
+// N returns the number of nodes.
+func (l *LazyDistanceMatrix) N() int { return len(l.points) }
+
+// At returns the distance between nodes i and j, computing it on first use.
+func (l *LazyDistanceMatrix) At(i, j int) float64 {
+	if i == j {
+		return 0
+	}
+	if i > j {
+		i, j = j, i
+	}
+	key := i*len(l.points) + j
+	shard := &l.shards[key%lazyMatrixShards]
+
+	shard.mu.Lock()
+	d, ok := shard.m[key]
+	shard.mu.Unlock()
+	if ok {
+		l.hits.Add(1)
+		return d
+	}
+
+	lat1, lon1 := positionLatLon(l.points[i])
+	lat2, lon2 := positionLatLon(l.points[j])
+	d = GreatCircleDistance(lat1, lon1, lat2, lon2)
+	shard.mu.Lock()
+	shard.m[key] = d
+	shard.mu.Unlock()
+	l.misses.Add(1)
+	return d
+}
+
+// Hits returns how many lookups were answered from the cache.
+func (l *LazyDistanceMatrix) Hits() int64 { return l.hits.Load() }
+
+// Misses returns how many lookups computed a distance. Concurrent lookups of
+// the same uncached pair may each count as a miss.
+func (l *LazyDistanceMatrix) Misses() int64 { return l.misses.Load() }
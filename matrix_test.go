@@ -0,0 +1,210 @@
+package geo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func randomPositions(rng *rand.Rand, n int) []Position {
+	points := make([]Position, n)
+	for i := range points {
+		points[i] = Position{rng.Float64()*40 - 20, rng.Float64()*40 + 20}
+	}
+	return points
+}
+
+func denseMatrixFromPoints(points []Position) [][]float64 {
+	m := make([][]float64, len(points))
+	for i := range m {
+		m[i] = make([]float64, len(points))
+		for j := range points {
+			if i != j {
+				lat1, lon1 := positionLatLon(points[i])
+				lat2, lon2 := positionLatLon(points[j])
+				m[i][j] = GreatCircleDistance(lat1, lon1, lat2, lon2)
+			}
+		}
+	}
+	return m
+}
+
+func TestSymmetricMatrix(t *testing.T) {
+	m := NewSymmetricMatrix(4)
+	if m.N() != 4 {
+		t.Fatalf("N() = %d, want 4", m.N())
+	}
+	if len(m.data) != 6 {
+		t.Fatalf("storage = %d entries, want 6", len(m.data))
+	}
+	v := 1.0
+	for i := 0; i < 4; i++ {
+		for j := i + 1; j < 4; j++ {
+			m.Set(j, i, v)
+			v++
+		}
+	}
+	v = 1.0
+	for i := 0; i < 4; i++ {
+		if m.At(i, i) != 0 {
+			t.Errorf("At(%d, %d) = %v, want 0", i, i, m.At(i, i))
+		}
+		for j := i + 1; j < 4; j++ {
+			if m.At(i, j) != v || m.At(j, i) != v {
+				t.Errorf("At(%d, %d) = %v, At(%d, %d) = %v, want %v", i, j, m.At(i, j), j, i, m.At(j, i), v)
+			}
+			v++
+		}
+	}
+}
+
+func TestSymmetricMatrixMatchesDense(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+	points := randomPositions(rng, 40)
+	dense := denseMatrixFromPoints(points)
+	sym := SymmetricMatrixFromPoints(points)
+
+	for i := range dense {
+		for j := range dense {
+			if sym.At(i, j) != dense[i][j] {
+				t.Fatalf("At(%d, %d) = %v, want %v", i, j, sym.At(i, j), dense[i][j])
+			}
+		}
+	}
+
+	denseNN := TSPNearestNeighbor(dense, 0)
+	symNN := TSPNearestNeighborFunc(sym, 0)
+	if !equalTours(denseNN.Tour, symNN.Tour) || denseNN.Distance != symNN.Distance {
+		t.Fatalf("nearest neighbor differs: %v (%v) vs %v (%v)", denseNN.Tour, denseNN.Distance, symNN.Tour, symNN.Distance)
+	}
+
+	dense2 := TSP2Opt(dense, denseNN.Tour, 0)
+	sym2 := TSP2OptFunc(sym, symNN.Tour, 0)
+	if !equalTours(dense2.Tour, sym2.Tour) || dense2.Distance != sym2.Distance {
+		t.Errorf("2-opt differs: %v (%v) vs %v (%v)", dense2.Tour, dense2.Distance, sym2.Tour, sym2.Distance)
+	}
+}
+
+func equalTours(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDenseMatrixFunc(t *testing.T) {
+	rng := rand.New(rand.NewSource(9))
+	m := randomSymmetricMatrix(rng, 10)
+	if got := TSPNearestNeighborFunc(DenseMatrix(m), 2); !equalTours(got.Tour, TSPNearestNeighbor(m, 2).Tour) {
+		t.Errorf("TSPNearestNeighborFunc(DenseMatrix) tour = %v", got.Tour)
+	}
+	tour := rng.Perm(10)
+	if got := TSP2OptFunc(DenseMatrix(m), tour, 0); !equalTours(got.Tour, TSP2Opt(m, tour, 0).Tour) {
+		t.Errorf("TSP2OptFunc(DenseMatrix) tour = %v", got.Tour)
+	}
+}
+
+func TestLazyDistanceMatrix(t *testing.T) {
+	rng := rand.New(rand.NewSource(10))
+	points := randomPositions(rng, 60)
+	n := len(points)
+	dense := denseMatrixFromPoints(points)
+	lazy := NewLazyDistanceMatrix(points)
+
+	want := TSPNearestNeighbor(dense, 0)
+	got := TSPNearestNeighborFunc(lazy, 0)
+	if !equalTours(got.Tour, want.Tour) || got.Distance != want.Distance {
+		t.Fatalf("nearest neighbor differs: %v (%v) vs %v (%v)", got.Tour, got.Distance, want.Tour, want.Distance)
+	}
+	// Each step looks only at unvisited nodes, so at most every unordered pair
+	// is computed once: half of the n² a dense matrix holds.
+	if misses := lazy.Misses(); misses > int64(n*(n-1)/2) {
+		t.Errorf("misses = %d, want at most %d", misses, n*(n-1)/2)
+	}
+
+	before := lazy.Misses()
+	again := TSPNearestNeighborFunc(lazy, 0)
+	if !equalTours(again.Tour, want.Tour) {
+		t.Errorf("second run tour = %v, want %v", again.Tour, want.Tour)
+	}
+	if lazy.Misses() != before {
+		t.Errorf("second run computed %d new pairs, want 0", lazy.Misses()-before)
+	}
+	if lazy.Hits() == 0 {
+		t.Error("hits = 0, want cached lookups")
+	}
+
+	want2 := TSP2Opt(dense, want.Tour, 0)
+	got2 := TSP2OptFunc(lazy, got.Tour, 0)
+	if !equalTours(got2.Tour, want2.Tour) || got2.Distance != want2.Distance {
+		t.Errorf("2-opt differs: %v (%v) vs %v (%v)", got2.Tour, got2.Distance, want2.Tour, want2.Distance)
+	}
+}
+
+func TestLazyDistanceMatrixConcurrent(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	points := randomPositions(rng, 30)
+	dense := denseMatrixFromPoints(points)
+	lazy := NewLazyDistanceMatrix(points)
+
+	done := make(chan bool)
+	for w := 0; w < 8; w++ {
+		go func() {
+			ok := true
+			for i := range points {
+				for j := range points {
+					if lazy.At(i, j) != dense[i][j] {
+						ok = false
+					}
+				}
+			}
+			done <- ok
+		}()
+	}
+	for w := 0; w < 8; w++ {
+		if !<-done {
+			t.Error("concurrent lookup returned a wrong distance")
+		}
+	}
+}
+
+func TestDistanceMatrix(t *testing.T) {
+	rng := rand.New(rand.NewSource(12))
+	positions := randomPositions(rng, 25)
+	points := make([]LatLon, len(positions))
+	for i, p := range positions {
+		points[i] = LatLon{Lat: p[1], Lon: p[0]}
+	}
+
+	m := DistanceMatrix(points, UnitKilometers)
+	if len(m) != len(points) {
+		t.Fatalf("rows = %d, want %d", len(m), len(points))
+	}
+	if err := ValidateDistanceMatrix(m); err != nil {
+		t.Fatalf("ValidateDistanceMatrix() error = %v", err)
+	}
+	if !IsSymmetric(m, 0) {
+		t.Error("matrix is not exactly symmetric")
+	}
+	dense := denseMatrixFromPoints(positions)
+	for i := range m {
+		for j := range m {
+			if math.Abs(m[i][j]-dense[i][j]) > 1e-9 {
+				t.Fatalf("m[%d][%d] = %v, want %v", i, j, m[i][j], dense[i][j])
+			}
+		}
+	}
+
+	miles := DistanceMatrix(points, UnitMiles)
+	if math.Abs(miles[0][1]-m[0][1]/KmPerMile) > 1e-9 {
+		t.Errorf("miles[0][1] = %v, want %v", miles[0][1], m[0][1]/KmPerMile)
+	}
+	if got := DistanceMatrix(nil, UnitKilometers); len(got) != 0 {
+		t.Errorf("DistanceMatrix(nil) = %v, want empty", got)
+	}
+}
@@ -0,0 +1,47 @@
+package geo
+
+import "math"
+
+// MercatorScaleFactor returns the scale factor of the Mercator projection at
+// the given latitude in degrees: sec(lat). Lengths near that latitude appear
+// this many times longer on a Mercator map than at the equator, and areas the
+// square of it. The factor is +Inf at the poles, where cos(lat) would
+// otherwise round to a tiny positive number rather than 0.
+func MercatorScaleFactor(lat float64) float64 {
+	if math.Abs(lat) >= 90 {
+		return math.Inf(1)
+	}
+	return 1 / math.Cos(toRadians(lat))
+}
+
+// TissotEllipse returns the Tissot indicatrix at (lat, lon): a geodesic circle
+// of radiusKm built with Circle and projected to spherical Mercator. The
+// polygon's positions are Mercator x, y in meters on the Earth sphere of
+// radius EarthRadiusKm rather than longitude and latitude, so comparing the
+// shapes for different latitudes shows the projection's distortion: the
+// circles stay round but grow by MercatorScaleFactor(lat). The ring does not
+// wrap at the antimeridian; vertices keep within 180° of lon.
+func TissotEllipse(lat, lon float64, radiusKm float64, steps int) Polygon {
+	circle := Circle(NewPoint(lon, lat), radiusKm, steps)
+	ring := circle.Coordinates[0]
+	projected := make([]Position, len(ring))
+	for i, p := range ring {
+		pLon := p[0]
+		if pLon-lon > 180 {
+			pLon -= 360
+		} else if pLon-lon < -180 {
+			pLon += 360
+		}
+		x, y := mercatorProject(p[1], pLon)
+		projected[i] = Position{x, y}
+	}
+	return NewPolygon([][]Position{projected})
+}
+
+// mercatorProject returns the spherical Mercator coordinates in meters of a
+// point given in degrees.
+func mercatorProject(lat, lon float64) (x, y float64) {
+	x = EarthRadiusMeters * toRadians(lon)
+	y = EarthRadiusMeters * math.Log(math.Tan(math.Pi/4+toRadians(lat)/2))
+	return x, y
+}
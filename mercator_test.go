@@ -0,0 +1,58 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMercatorScaleFactor(t *testing.T) {
+	if got := MercatorScaleFactor(0); math.Abs(got-1) > 1e-12 {
+		t.Errorf("scale at equator = %v, want 1", got)
+	}
+	if got := MercatorScaleFactor(60); math.Abs(got-2) > 1e-9 {
+		t.Errorf("scale at 60° = %v, want 2", got)
+	}
+	if got := MercatorScaleFactor(-60); math.Abs(got-2) > 1e-9 {
+		t.Errorf("scale at -60° = %v, want 2", got)
+	}
+	for _, lat := range []float64{90, -90} {
+		if got := MercatorScaleFactor(lat); !math.IsInf(got, 1) {
+			t.Errorf("scale at %v° = %v, want +Inf", lat, got)
+		}
+	}
+}
+
+func TestTissotEllipse(t *testing.T) {
+	const r = 10.0
+	extent := func(poly Polygon) (width, height float64) {
+		minX, minY := math.Inf(1), math.Inf(1)
+		maxX, maxY := math.Inf(-1), math.Inf(-1)
+		for _, p := range poly.Coordinates[0] {
+			minX, maxX = math.Min(minX, p[0]), math.Max(maxX, p[0])
+			minY, maxY = math.Min(minY, p[1]), math.Max(maxY, p[1])
+		}
+		return maxX - minX, maxY - minY
+	}
+
+	w0, h0 := extent(TissotEllipse(0, 30, r, 128))
+	if math.Abs(w0-2*r*MetersPerKm)/(2*r*MetersPerKm) > 0.001 {
+		t.Errorf("equator width = %v m, want %v m", w0, 2*r*MetersPerKm)
+	}
+	if math.Abs(h0-w0)/w0 > 0.001 {
+		t.Errorf("equator ellipse is not round: %v x %v m", w0, h0)
+	}
+
+	w60, h60 := extent(TissotEllipse(60, 30, r, 128))
+	if ratio := w60 / w0; math.Abs(ratio-2) > 0.01 {
+		t.Errorf("width at 60° is %v times the equator's, want 2", ratio)
+	}
+	if ratio := h60 / h0; math.Abs(ratio-2) > 0.01 {
+		t.Errorf("height at 60° is %v times the equator's, want 2", ratio)
+	}
+
+	// Near the antimeridian the ring stays in one piece.
+	wAnti, _ := extent(TissotEllipse(0, 179.95, r, 128))
+	if math.Abs(wAnti-w0)/w0 > 0.001 {
+		t.Errorf("width at the antimeridian = %v m, want %v m", wAnti, w0)
+	}
+}
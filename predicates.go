@@ -0,0 +1,323 @@
+package geo
+
+import (
+	"fmt"
+	"sort"
+)
+
+// The predicates in this file treat every geometry as a closed point set:
+// a polygon includes its boundary, hole edges included, and a line includes
+// its endpoints. Geometries are compared in the lon/lat plane, as in Union, so
+// those crossing the antimeridian should be cut with CutAntimeridian first.
+
+// Contains reports whether every point of b lies in a. a must be polygonal: a
+// Polygon, a MultiPolygon, or a Feature, FeatureCollection or
+// GeometryCollection of them. b may be any geometry. Points on the boundary of
+// a count as contained, so a polygon contains itself and a point on its edge.
+// With several polygons in a, each part of b must lie within a single one of
+// them. An empty b is not contained.
+func Contains(a, b interface{}) (bool, error) {
+	pa, err := collectParts(a)
+	if err != nil {
+		return false, err
+	}
+	if len(pa.polys) == 0 || len(pa.points) > 0 || len(pa.lines) > 0 {
+		return false, fmt.Errorf("contains needs a polygonal container, got %T", a)
+	}
+	pb, err := collectParts(b)
+	if err != nil {
+		return false, err
+	}
+	if pb.empty() {
+		return false, nil
+	}
+
+	within := func(test func(poly [][]Position) bool) bool {
+		for _, poly := range pa.polys {
+			if test(poly) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, p := range pb.points {
+		if !within(func(poly [][]Position) bool { return locateInPolygon(p, poly, false) }) {
+			return false, nil
+		}
+	}
+	for _, line := range pb.lines {
+		if !within(func(poly [][]Position) bool { return lineInPolygon(line, poly) }) {
+			return false, nil
+		}
+	}
+	for _, inner := range pb.polys {
+		if !within(func(poly [][]Position) bool { return polygonCoveredBy(inner, poly) }) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Within reports whether a lies within b; it is Contains(b, a).
+func Within(a, b interface{}) (bool, error) {
+	return Contains(b, a)
+}
+
+// Intersects reports whether a and b share at least one point. Any
+// combination of points, lines and polygons, their multi forms, features and
+// collections is accepted. Touching counts: a line ending on a polygon's edge
+// intersects it.
+func Intersects(a, b interface{}) (bool, error) {
+	pa, err := collectParts(a)
+	if err != nil {
+		return false, err
+	}
+	pb, err := collectParts(b)
+	if err != nil {
+		return false, err
+	}
+	return pa.intersects(pb), nil
+}
+
+// Disjoint reports whether a and b have no point in common; it is the
+// negation of Intersects.
+func Disjoint(a, b interface{}) (bool, error) {
+	intersects, err := Intersects(a, b)
+	return !intersects, err
+}
+
+// geometryParts holds the points, lines and polygons a geometry is made of.
+type geometryParts struct {
+	points []Position
+	lines  [][]Position
+	polys  [][][]Position
+}
+
+func collectParts(obj interface{}) (geometryParts, error) {
+	var parts geometryParts
+	err := parts.add(obj)
+	return parts, err
+}
+
+func (gp *geometryParts) add(obj interface{}) error {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return err
+	}
+	switch g := geom.(type) {
+	case Point:
+		gp.points = append(gp.points, g.Coordinates)
+	case MultiPoint:
+		gp.points = append(gp.points, g.Coordinates...)
+	case LineString:
+		gp.addLine(g.Coordinates)
+	case MultiLineString:
+		for _, line := range g.Coordinates {
+			gp.addLine(line)
+		}
+	case Polygon:
+		gp.addPolygon(g.Coordinates)
+	case MultiPolygon:
+		for _, poly := range g.Coordinates {
+			gp.addPolygon(poly)
+		}
+	case GeometryCollection:
+		for _, child := range g.Geometries {
+			if err := gp.add(child); err != nil {
+				return err
+			}
+		}
+	case Feature:
+		return gp.add(g.Geometry)
+	case FeatureCollection:
+		for _, f := range g.Features {
+			if err := gp.add(f); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported geojson type %T", obj)
+	}
+	return nil
+}
+
+// addLine adds a line, or a point if all its vertices coincide.
+func (gp *geometryParts) addLine(line []Position) {
+	for _, p := range line {
+		if !samePosition(p, line[0]) {
+			gp.lines = append(gp.lines, line)
+			return
+		}
+	}
+	if len(line) > 0 {
+		gp.points = append(gp.points, line[0])
+	}
+}
+
+func (gp *geometryParts) addPolygon(rings [][]Position) {
+	if len(rings) > 0 && len(openRing(rings[0])) >= 3 {
+		gp.polys = append(gp.polys, rings)
+	}
+}
+
+func (gp geometryParts) empty() bool {
+	return len(gp.points) == 0 && len(gp.lines) == 0 && len(gp.polys) == 0
+}
+
+func (gp geometryParts) intersects(o geometryParts) bool {
+	for _, p := range gp.points {
+		if o.containsPoint(p) {
+			return true
+		}
+	}
+	for _, p := range o.points {
+		if gp.containsPoint(p) {
+			return true
+		}
+	}
+	for _, line := range gp.lines {
+		for _, other := range o.lines {
+			if edgesMeet(lineSegments(line), lineSegments(other)) {
+				return true
+			}
+		}
+		for _, poly := range o.polys {
+			if linePolygonIntersect(line, poly) {
+				return true
+			}
+		}
+	}
+	for _, poly := range gp.polys {
+		for _, line := range o.lines {
+			if linePolygonIntersect(line, poly) {
+				return true
+			}
+		}
+		for _, other := range o.polys {
+			if polygonsIntersect(poly, other) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsPoint reports whether p lies on any part.
+func (gp geometryParts) containsPoint(p Position) bool {
+	for _, q := range gp.points {
+		if q[0] == p[0] && q[1] == p[1] {
+			return true
+		}
+	}
+	for _, line := range gp.lines {
+		for _, s := range lineSegments(line) {
+			if pointOnSegment(p, s[0], s[1]) {
+				return true
+			}
+		}
+	}
+	for _, poly := range gp.polys {
+		if locateInPolygon(p, poly, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineSegments returns the segments of a line, skipping zero-length ones.
+func lineSegments(line []Position) [][2]Position {
+	var segs [][2]Position
+	for i := 0; i+1 < len(line); i++ {
+		if !samePosition(line[i], line[i+1]) {
+			segs = append(segs, [2]Position{line[i], line[i+1]})
+		}
+	}
+	return segs
+}
+
+// polygonSegments returns the edges of all rings of a polygon.
+func polygonSegments(rings [][]Position) [][2]Position {
+	open := make([][]Position, 0, len(rings))
+	for _, ring := range rings {
+		if r := openRing(ring); len(r) >= 2 {
+			open = append(open, r)
+		}
+	}
+	return ringSegments(open)
+}
+
+// edgesMeet reports whether any segment of a touches any segment of b.
+func edgesMeet(a, b [][2]Position) bool {
+	for _, s := range a {
+		for _, t := range b {
+			if len(segmentIntersections(s[0], s[1], t[0], t[1])) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func linePolygonIntersect(line []Position, poly [][]Position) bool {
+	// Without a meeting edge the line is wholly inside or wholly outside.
+	return edgesMeet(lineSegments(line), polygonSegments(poly)) || locateInPolygon(line[0], poly, false)
+}
+
+func polygonsIntersect(a, b [][]Position) bool {
+	// Without meeting edges one polygon lies inside the other, or they are
+	// apart.
+	return edgesMeet(polygonSegments(a), polygonSegments(b)) ||
+		locateInPolygon(a[0][0], b, false) || locateInPolygon(b[0][0], a, false)
+}
+
+// lineInPolygon reports whether every point of the line lies in the closed
+// polygon. Each segment is cut where it meets the polygon's edges; the pieces
+// in between lie wholly inside or wholly outside, so their midpoints decide.
+func lineInPolygon(line []Position, poly [][]Position) bool {
+	for _, p := range line {
+		if !locateInPolygon(p, poly, false) {
+			return false
+		}
+	}
+	edges := polygonSegments(poly)
+	for _, s := range lineSegments(line) {
+		cuts := []Position{s[0], s[1]}
+		for _, e := range edges {
+			cuts = append(cuts, segmentIntersections(s[0], s[1], e[0], e[1])...)
+		}
+		dx, dy := s[1][0]-s[0][0], s[1][1]-s[0][1]
+		along := func(p Position) float64 { return (p[0]-s[0][0])*dx + (p[1]-s[0][1])*dy }
+		sort.Slice(cuts, func(i, j int) bool { return along(cuts[i]) < along(cuts[j]) })
+		for i := 0; i+1 < len(cuts); i++ {
+			if samePosition(cuts[i], cuts[i+1]) {
+				continue
+			}
+			if !locateInPolygon(segmentMidpoint([2]Position{cuts[i], cuts[i+1]}), poly, false) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// polygonCoveredBy reports whether the closed polygon inner lies in the closed
+// polygon outer. Every ring of inner must lie in outer; then a hole of outer is
+// either wholly inside inner's interior or wholly outside it, and one point
+// deep inside the hole tells which.
+func polygonCoveredBy(inner, outer [][]Position) bool {
+	for _, ring := range inner {
+		if !lineInPolygon(ring, outer) {
+			return false
+		}
+	}
+	for _, hole := range outer[1:] {
+		if len(openRing(hole)) < 3 {
+			continue
+		}
+		p, _, err := PoleOfInaccessibility(NewPolygon([][]Position{hole}), 0)
+		if err == nil && locateInPolygon(p.Coordinates, inner, true) {
+			return false
+		}
+	}
+	return true
+}
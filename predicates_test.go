@@ -0,0 +1,114 @@
+package geo
+
+import "testing"
+
+func TestPredicatesMatrix(t *testing.T) {
+	square := NewPolygon([][]Position{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}})
+	crossing := NewLineString([]Position{{-5, 5}, {15, 5}})
+	inside := NewLineString([]Position{{2, 2}, {8, 3}, {6, 4}})
+	external := NewPoint(20, 20)
+
+	geoms := map[string]interface{}{
+		"square":   square,
+		"crossing": crossing,
+		"inside":   inside,
+		"external": external,
+	}
+	tests := []struct {
+		a, b       string
+		contains   bool
+		intersects bool
+	}{
+		{"square", "square", true, true},
+		{"square", "crossing", false, true},
+		{"square", "inside", true, true},
+		{"square", "external", false, false},
+		{"crossing", "inside", false, false},
+		{"crossing", "external", false, false},
+		{"inside", "external", false, false},
+	}
+	for _, tt := range tests {
+		a, b := geoms[tt.a], geoms[tt.b]
+		if _, isPoly := a.(Polygon); isPoly {
+			got, err := Contains(a, b)
+			if err != nil {
+				t.Fatalf("Contains(%s, %s): %v", tt.a, tt.b, err)
+			}
+			if got != tt.contains {
+				t.Errorf("Contains(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.contains)
+			}
+			within, err := Within(b, a)
+			if err != nil || within != got {
+				t.Errorf("Within(%s, %s) = %v, %v; want %v", tt.b, tt.a, within, err, got)
+			}
+		} else if _, err := Contains(a, b); err == nil {
+			t.Errorf("Contains(%s, %s): expected error for a non-polygonal container", tt.a, tt.b)
+		}
+
+		for _, pair := range [][2]string{{tt.a, tt.b}, {tt.b, tt.a}} {
+			got, err := Intersects(geoms[pair[0]], geoms[pair[1]])
+			if err != nil {
+				t.Fatalf("Intersects(%s, %s): %v", pair[0], pair[1], err)
+			}
+			if got != tt.intersects {
+				t.Errorf("Intersects(%s, %s) = %v, want %v", pair[0], pair[1], got, tt.intersects)
+			}
+			disjoint, err := Disjoint(geoms[pair[0]], geoms[pair[1]])
+			if err != nil || disjoint == got {
+				t.Errorf("Disjoint(%s, %s) = %v, %v; want %v", pair[0], pair[1], disjoint, err, !got)
+			}
+		}
+	}
+}
+
+func TestPredicatesBoundaryAndHoles(t *testing.T) {
+	donut := NewPolygon([][]Position{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}},
+	})
+	tests := []struct {
+		name       string
+		b          interface{}
+		contains   bool
+		intersects bool
+	}{
+		{"point on edge", NewPoint(10, 5), true, true},
+		{"point in hole", NewPoint(5, 5), false, false},
+		{"point on hole edge", NewPoint(4, 5), true, true},
+		{"line along edge", NewLineString([]Position{{0, 0}, {10, 0}}), true, true},
+		{"line across hole", NewLineString([]Position{{2, 5}, {8, 5}}), false, true},
+		{"line around hole", NewLineString([]Position{{3, 3}, {7, 3}, {7, 7}}), true, true},
+		{"line touching from outside", NewLineString([]Position{{10, 5}, {12, 5}}), false, true},
+		{"polygon around hole", NewPolygon([][]Position{{{3, 3}, {7, 3}, {7, 7}, {3, 7}, {3, 3}}}), false, true},
+		{"polygon with matching hole", NewPolygon([][]Position{
+			{{3, 3}, {7, 3}, {7, 7}, {3, 7}, {3, 3}},
+			{{4, 4}, {4, 6}, {6, 6}, {6, 4}, {4, 4}},
+		}), true, true},
+		{"polygon in hole", NewPolygon([][]Position{{{4.5, 4.5}, {5.5, 4.5}, {5.5, 5.5}, {4.5, 4.5}}}), false, false},
+		{"polygon overlapping edge", NewPolygon([][]Position{{{8, 8}, {12, 8}, {12, 12}, {8, 8}}}), false, true},
+		{"multipoint inside", NewMultiPoint([]Position{{1, 1}, {9, 9}}), true, true},
+		{"feature", NewFeature(NewPoint(1, 1)), true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contains, err := Contains(donut, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if contains != tt.contains {
+				t.Errorf("Contains = %v, want %v", contains, tt.contains)
+			}
+			intersects, err := Intersects(donut, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if intersects != tt.intersects {
+				t.Errorf("Intersects = %v, want %v", intersects, tt.intersects)
+			}
+		})
+	}
+
+	if _, err := Intersects(donut, "nope"); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+}
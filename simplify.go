@@ -0,0 +1,108 @@
+package geo
+
+// SimplifyPolygonSafe simplifies every ring of poly with the Douglas-Peucker
+// algorithm, dropping vertices that lie within toleranceKm of the simplified
+// outline. A ring whose simplification intersects itself or crosses another
+// ring is simplified again at half the tolerance, and so on, falling back to
+// the original ring. Rings keep at least four positions.
+// Rings that intersect themselves to begin with are simplified unchecked.
+func SimplifyPolygonSafe(poly Polygon, toleranceKm float64) Polygon {
+	out := Polygon{Type: poly.Type, Coordinates: make([][]Position, 0, len(poly.Coordinates))}
+	if out.Type == "" {
+		out.Type = "Polygon"
+	}
+	for i, ring := range poly.Coordinates {
+		// Compare against the rings simplified so far and the originals of
+		// the rest.
+		others := append(append([][]Position(nil), out.Coordinates...), poly.Coordinates[i+1:]...)
+		out.Coordinates = append(out.Coordinates, simplifyRingSafe(ring, others, toleranceKm))
+	}
+	return out
+}
+
+// simplifyRingSafe simplifies ring, backing off the tolerance until the result
+// is simple and crosses none of others.
+func simplifyRingSafe(ring []Position, others [][]Position, toleranceKm float64) []Position {
+	if len(ring) <= 4 || toleranceKm <= 0 {
+		return append([]Position(nil), ring...)
+	}
+	check := !ringSelfIntersects(ring)
+	for tol, attempt := toleranceKm, 0; attempt < 32; tol, attempt = tol/2, attempt+1 {
+		simplified := douglasPeucker(ring, tol)
+		if len(simplified) < 4 {
+			continue
+		}
+		if !check {
+			return simplified
+		}
+		if !ringSelfIntersects(simplified) && !ringCrossesAny(simplified, others) {
+			return simplified
+		}
+	}
+	return append([]Position(nil), ring...)
+}
+
+// douglasPeucker returns the positions of coords that the Douglas-Peucker
+// algorithm keeps at toleranceKm, measuring great-circle distances to the
+// nearest point of each simplified segment. The first and last positions are
+// always kept.
+func douglasPeucker(coords []Position, toleranceKm float64) []Position {
+	if len(coords) < 3 {
+		return append([]Position(nil), coords...)
+	}
+	keep := make([]bool, len(coords))
+	keep[0], keep[len(coords)-1] = true, true
+	stack := [][2]int{{0, len(coords) - 1}}
+	for len(stack) > 0 {
+		span := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		first, last := span[0], span[1]
+		lat1, lon1 := positionLatLon(coords[first])
+		lat2, lon2 := positionLatLon(coords[last])
+		farthest, maxDist := -1, toleranceKm
+		for i := first + 1; i < last; i++ {
+			latP, lonP := positionLatLon(coords[i])
+			nearLat, nearLon, _, _ := GreatCircleProjectToSegment(lat1, lon1, lat2, lon2, latP, lonP)
+			if d := GreatCircleDistance(latP, lonP, nearLat, nearLon); d > maxDist {
+				farthest, maxDist = i, d
+			}
+		}
+		if farthest >= 0 {
+			keep[farthest] = true
+			stack = append(stack, [2]int{first, farthest}, [2]int{farthest, last})
+		}
+	}
+	out := make([]Position, 0, len(coords))
+	for i, p := range coords {
+		if keep[i] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// ringSelfIntersects reports whether two edges of ring meet anywhere other
+// than at the vertex shared by neighboring edges, the kinks Kinks finds.
+func ringSelfIntersects(ring []Position) bool {
+	found := false
+	selfIntersections(ringSegments([][]Position{openRing(ring)}), true, func(_, _ int, _ Position) {
+		found = true
+	})
+	return found
+}
+
+// ringCrossesAny reports whether an edge of ring crosses an edge of one of
+// others.
+func ringCrossesAny(ring []Position, others [][]Position) bool {
+	segs := ringSegments([][]Position{openRing(ring)})
+	for _, other := range others {
+		for _, f := range ringSegments([][]Position{openRing(other)}) {
+			for _, e := range segs {
+				if segmentsCross(e[0], e[1], f[0], f[1]) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
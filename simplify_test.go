@@ -0,0 +1,55 @@
+package geo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSimplifyPolygonSafe(t *testing.T) {
+	// A square with a spike reaching up from the south edge into a small
+	// bump on the north edge. Dropping the bump vertex at 30 km would run the
+	// north edge straight through the spike.
+	ring := []Position{
+		{0, 0}, {2, 0.01}, {5.4, 0}, {5.5, 10.1}, {5.6, 0}, {8, -0.01}, {10, 0},
+		{10, 10}, {7, 10}, {5.5, 10.2}, {4, 10}, {0, 10}, {0, 0},
+	}
+	if naive := douglasPeucker(ring, 30); !ringSelfIntersects(naive) {
+		t.Fatalf("naive simplification %v has no kinks; test shape needs adjusting", naive)
+	}
+
+	got := SimplifyPolygonSafe(NewPolygon([][]Position{ring}), 30)
+	out := got.Coordinates[0]
+	if ringSelfIntersects(out) {
+		t.Errorf("simplified ring %v intersects itself", out)
+	}
+	if len(out) >= len(ring) {
+		t.Errorf("simplified ring has %d positions, want fewer than %d", len(out), len(ring))
+	}
+	if !reflect.DeepEqual(out[0], out[len(out)-1]) {
+		t.Errorf("simplified ring %v is not closed", out)
+	}
+	for _, p := range out {
+		if reflect.DeepEqual(p, Position{2, 0.01}) || reflect.DeepEqual(p, Position{8, -0.01}) {
+			t.Errorf("noise vertex %v kept", p)
+		}
+	}
+}
+
+func TestSimplifyPolygonSafeHoles(t *testing.T) {
+	// The hole reaches into a bump on the outer ring; dropping the bump
+	// vertex would run the outer ring through the hole.
+	outer := []Position{{0, 0}, {10, 0}, {10, 10}, {5.2, 10}, {5, 10.15}, {4.8, 10}, {0, 10}, {0, 0}}
+	hole := []Position{{4.9, 9}, {5, 10.05}, {5.1, 9}, {4.9, 9}}
+	if naive := douglasPeucker(outer, 50); !ringCrossesAny(naive, [][]Position{hole}) {
+		t.Fatalf("naive simplification %v misses the hole; test shape needs adjusting", naive)
+	}
+	got := SimplifyPolygonSafe(NewPolygon([][]Position{outer, hole}), 50)
+	if ringCrossesAny(got.Coordinates[0], got.Coordinates[1:]) {
+		t.Errorf("simplified rings cross: %v", got.Coordinates)
+	}
+
+	tiny := NewPolygon([][]Position{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}})
+	if got := SimplifyPolygonSafe(tiny, 1000); len(got.Coordinates[0]) != 4 {
+		t.Errorf("triangle simplified to %v", got.Coordinates[0])
+	}
+}
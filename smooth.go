@@ -0,0 +1,224 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// maxSmoothIterations caps PolygonSmooth: every iteration doubles the number
+// of vertices, so 10 iterations already multiply it by 1024.
+const maxSmoothIterations = 10
+
+// PolygonSmooth rounds the corners of polygons and lines with Chaikin's corner
+// cutting, repeated iterations times. Each pass replaces every edge by two
+// points at a quarter and three quarters of its length, so every pass doubles
+// the number of vertices; at most 10 iterations are allowed. Polygon rings,
+// holes included, stay closed and shrink slightly inward at their corners.
+// Lines keep their first and last positions. Rings with fewer than 3 distinct
+// vertices and lines with fewer than 3 positions are left as they are.
+//
+// Polygon, MultiPolygon, LineString and MultiLineString geometries are
+// smoothed, as are those inside features, feature collections and geometry
+// collections; points pass through unchanged. Smoothing works in the lon/lat
+// plane.
+func PolygonSmooth(obj interface{}, iterations int) (interface{}, error) {
+	if iterations < 0 || iterations > maxSmoothIterations {
+		return nil, fmt.Errorf("iterations must be between 0 and %d, got %d", maxSmoothIterations, iterations)
+	}
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return nil, err
+	}
+	switch g := geom.(type) {
+	case Point, MultiPoint:
+		return g, nil
+	case LineString:
+		return NewLineString(chaikinLine(g.Coordinates, iterations)), nil
+	case MultiLineString:
+		lines := make([][]Position, len(g.Coordinates))
+		for i, line := range g.Coordinates {
+			lines[i] = chaikinLine(line, iterations)
+		}
+		return NewMultiLineString(lines), nil
+	case Polygon:
+		return NewPolygon(chaikinRings(g.Coordinates, iterations)), nil
+	case MultiPolygon:
+		polys := make([][][]Position, len(g.Coordinates))
+		for i, poly := range g.Coordinates {
+			polys[i] = chaikinRings(poly, iterations)
+		}
+		return NewMultiPolygon(polys), nil
+	case GeometryCollection:
+		children := make([]interface{}, len(g.Geometries))
+		for i, child := range g.Geometries {
+			smoothed, err := PolygonSmooth(child, iterations)
+			if err != nil {
+				return nil, err
+			}
+			children[i] = smoothed
+		}
+		return NewGeometryCollection(children), nil
+	case Feature:
+		smoothed, err := PolygonSmooth(g.Geometry, iterations)
+		if err != nil {
+			return nil, err
+		}
+		g.Geometry = smoothed
+		g.BBox = nil
+		return g, nil
+	case FeatureCollection:
+		features := make([]Feature, len(g.Features))
+		for i, f := range g.Features {
+			smoothed, err := PolygonSmooth(f, iterations)
+			if err != nil {
+				return nil, err
+			}
+			features[i] = smoothed.(Feature)
+		}
+		return NewFeatureCollection(features), nil
+	default:
+		return nil, fmt.Errorf("unsupported geojson type %T", obj)
+	}
+}
+
+func chaikinRings(rings [][]Position, iterations int) [][]Position {
+	out := make([][]Position, len(rings))
+	for i, ring := range rings {
+		open := openRing(ring)
+		if len(open) < 3 {
+			out[i] = ring
+			continue
+		}
+		for k := 0; k < iterations; k++ {
+			next := make([]Position, 0, 2*len(open))
+			for j, p := range open {
+				q := open[(j+1)%len(open)]
+				next = append(next, lerpPosition(p, q, 0.25), lerpPosition(p, q, 0.75))
+			}
+			open = next
+		}
+		out[i] = closeRing(open)
+	}
+	return out
+}
+
+func chaikinLine(line []Position, iterations int) []Position {
+	if len(line) < 3 {
+		return line
+	}
+	for k := 0; k < iterations; k++ {
+		next := make([]Position, 0, 2*len(line))
+		next = append(next, line[0])
+		for j := 0; j+1 < len(line); j++ {
+			p, q := line[j], line[j+1]
+			if j > 0 {
+				next = append(next, lerpPosition(p, q, 0.25))
+			}
+			if j+2 < len(line) {
+				next = append(next, lerpPosition(p, q, 0.75))
+			}
+		}
+		line = append(next, line[len(line)-1])
+	}
+	return line
+}
+
+// BezierSpline smooths a line with a cubic spline that passes through every
+// vertex. Each segment is a cubic Bézier curve whose control points follow the
+// Catmull-Rom tangents, scaled by sharpness: 0 keeps the straight segments
+// and 1 gives the full Catmull-Rom curve. At the ends the tangent points along
+// the first and last segment.
+//
+// The result has resolution positions, or the input vertices alone if
+// resolution is smaller than their number. The positions between vertices
+// are shared out among the segments in proportion to their length, and the
+// input vertices, the first and last included, are kept exactly. Altitudes
+// are smoothed along with the coordinates. The curve is built in the lon/lat
+// plane, so lines crossing the antimeridian must be unwrapped first. Lines
+// with fewer than 3 positions are returned unchanged.
+func BezierSpline(line LineString, resolution int, sharpness float64) (LineString, error) {
+	if sharpness < 0 || sharpness > 1 || math.IsNaN(sharpness) {
+		return LineString{}, errors.New("sharpness must be between 0 and 1")
+	}
+	pts := line.Coordinates
+	n := len(pts)
+	if n < 3 {
+		return line, nil
+	}
+
+	// Share the positions between vertices out over the segments by length,
+	// giving leftovers to the largest remainders.
+	extra := make([]int, n-1)
+	if spare := resolution - n; spare > 0 {
+		lengths := make([]float64, n-1)
+		var total float64
+		for i := range lengths {
+			lengths[i] = math.Hypot(pts[i+1][0]-pts[i][0], pts[i+1][1]-pts[i][1])
+			total += lengths[i]
+		}
+		remainders := make([]float64, n-1)
+		given := 0
+		for i, l := range lengths {
+			share := float64(spare) / float64(n-1)
+			if total > 0 {
+				share = float64(spare) * l / total
+			}
+			extra[i] = int(share)
+			remainders[i] = share - float64(extra[i])
+			given += extra[i]
+		}
+		for ; given < spare; given++ {
+			best := 0
+			for i, r := range remainders {
+				if r > remainders[best] {
+					best = i
+				}
+			}
+			extra[best]++
+			remainders[best] = -1
+		}
+	}
+
+	out := make([]Position, 0, max(resolution, n))
+	for i := 0; i+1 < n; i++ {
+		prev, next := pts[max(i-1, 0)], pts[min(i+2, n-1)]
+		hasAlt := prev.HasAlt() || pts[i].HasAlt() || pts[i+1].HasAlt() || next.HasAlt()
+		p0, p3 := bezierPoint(pts[i]), bezierPoint(pts[i+1])
+		var p1, p2 [3]float64
+		for k := 0; k < 3; k++ {
+			p1[k] = p0[k] + sharpness*(p3[k]-bezierPoint(prev)[k])/6
+			p2[k] = p3[k] - sharpness*(bezierPoint(next)[k]-p0[k])/6
+		}
+		out = append(out, pts[i])
+		steps := extra[i] + 1
+		for j := 1; j < steps; j++ {
+			p := cubicBezier(p0, p1, p2, p3, float64(j)/float64(steps))
+			if hasAlt {
+				out = append(out, Position{p[0], p[1], p[2]})
+			} else {
+				out = append(out, Position{p[0], p[1]})
+			}
+		}
+	}
+	out = append(out, pts[n-1])
+	return NewLineString(out), nil
+}
+
+// bezierPoint returns the longitude, latitude and altitude of p, with 0 for a
+// missing altitude.
+func bezierPoint(p Position) [3]float64 {
+	return [3]float64{p[0], p[1], p.Alt()}
+}
+
+// cubicBezier returns the point at parameter t of the cubic Bézier curve with
+// control points p0 to p3.
+func cubicBezier(p0, p1, p2, p3 [3]float64, t float64) [3]float64 {
+	u := 1 - t
+	a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+	var p [3]float64
+	for k := 0; k < 3; k++ {
+		p[k] = a*p0[k] + b*p1[k] + c*p2[k] + d*p3[k]
+	}
+	return p
+}
@@ -0,0 +1,133 @@
+package geo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestBezierSpline(t *testing.T) {
+	line := NewLineString([]Position{{0, 0}, {1, 1}, {2, 0}, {3, 1}, {4, 0.5}})
+	got, err := BezierSpline(line, 100, 0.85)
+	if err != nil {
+		t.Fatalf("BezierSpline() error = %v", err)
+	}
+	coords := got.Coordinates
+	if len(coords) != 100 {
+		t.Errorf("got %d positions, want 100", len(coords))
+	}
+	if !reflect.DeepEqual(coords[0], line.Coordinates[0]) || !reflect.DeepEqual(coords[len(coords)-1], line.Coordinates[4]) {
+		t.Errorf("endpoints = %v, %v, want the input's", coords[0], coords[len(coords)-1])
+	}
+	// The curve passes through every vertex.
+	for _, v := range line.Coordinates {
+		best := math.Inf(1)
+		for _, p := range coords {
+			best = math.Min(best, math.Hypot(p[0]-v[0], p[1]-v[1]))
+		}
+		if best > 1e-12 {
+			t.Errorf("vertex %v is %v from the curve", v, best)
+		}
+	}
+	// It is smooth: no sharp turns between consecutive samples.
+	for i := 1; i+1 < len(coords); i++ {
+		a, b, c := coords[i-1], coords[i], coords[i+1]
+		turn := math.Abs(math.Atan2(orient2D(a, b, c), (b[0]-a[0])*(c[0]-b[0])+(b[1]-a[1])*(c[1]-b[1])))
+		if turn > 0.5 {
+			t.Errorf("turn of %.2f rad at %v", turn, b)
+		}
+	}
+
+	chord := GreatCircleDistance(0, 0, 0.5, 4)
+	length, err := LineStringLength(got, UnitKilometers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if length < chord {
+		t.Errorf("curve length %v shorter than the chord %v", length, chord)
+	}
+}
+
+func TestBezierSplineEdgeCases(t *testing.T) {
+	short := NewLineString([]Position{{0, 0}, {1, 1}})
+	if got, err := BezierSpline(short, 50, 0.85); err != nil || !reflect.DeepEqual(got, short) {
+		t.Errorf("BezierSpline(2 points) = %v, %v, want the input", got, err)
+	}
+
+	line := NewLineString([]Position{{0, 0}, {1, 1}, {2, 0}})
+	if got, _ := BezierSpline(line, 2, 0.85); !reflect.DeepEqual(got.Coordinates, line.Coordinates) {
+		t.Errorf("low resolution = %v, want the vertices", got.Coordinates)
+	}
+	// Sharpness 0 keeps the straight segments.
+	got, _ := BezierSpline(line, 21, 0)
+	for _, p := range got.Coordinates {
+		if want := 1 - math.Abs(p[0]-1); math.Abs(p[1]-want) > 1e-12 {
+			t.Errorf("sharpness 0: %v off the polyline", p)
+		}
+	}
+	if _, err := BezierSpline(line, 10, 1.5); err == nil {
+		t.Error("expected error for sharpness above 1")
+	}
+}
+
+func TestPolygonSmoothSquare(t *testing.T) {
+	square := squarePolygon(0, 0, 1, 1)
+	got, err := PolygonSmooth(square, 2)
+	if err != nil {
+		t.Fatalf("PolygonSmooth() error = %v", err)
+	}
+	poly := got.(Polygon)
+	ring := poly.Coordinates[0]
+	if len(ring) != 17 || !reflect.DeepEqual(ring[0], ring[16]) {
+		t.Fatalf("ring has %d positions, want 16 vertices and closed", len(ring))
+	}
+	area := polygonPlanarArea(poly)
+	if !(area < 1 && area > 0.8) {
+		t.Errorf("smoothed area = %v, want between 0.8 and 1", area)
+	}
+	if same, _ := PolygonSmooth(square, 0); !reflect.DeepEqual(same, square) {
+		t.Errorf("zero iterations = %v, want the input", same)
+	}
+}
+
+func TestPolygonSmoothHolesAndLines(t *testing.T) {
+	donut := NewPolygon([][]Position{
+		squarePolygon(0, 0, 4, 4).Coordinates[0],
+		squarePolygon(1, 1, 3, 3).Coordinates[0],
+	})
+	got, err := PolygonSmooth(NewFeature(NewMultiPolygon([][][]Position{donut.Coordinates})), 1)
+	if err != nil {
+		t.Fatalf("PolygonSmooth() error = %v", err)
+	}
+	mp := got.(Feature).Geometry.(MultiPolygon)
+	if len(mp.Coordinates[0]) != 2 {
+		t.Fatalf("smoothed polygon has %d rings, want 2", len(mp.Coordinates[0]))
+	}
+	for _, ring := range mp.Coordinates[0] {
+		if len(ring) != 9 || !reflect.DeepEqual(ring[0], ring[8]) {
+			t.Errorf("ring %v, want 8 vertices and closed", ring)
+		}
+	}
+
+	line := NewLineString([]Position{{0, 0}, {1, 1}, {2, 0}})
+	got, err = PolygonSmooth(line, 3)
+	if err != nil {
+		t.Fatalf("PolygonSmooth() error = %v", err)
+	}
+	coords := got.(LineString).Coordinates
+	if !reflect.DeepEqual(coords[0], line.Coordinates[0]) || !reflect.DeepEqual(coords[len(coords)-1], line.Coordinates[2]) {
+		t.Errorf("line endpoints = %v, %v, want the input's", coords[0], coords[len(coords)-1])
+	}
+	for _, p := range coords {
+		if p[1] >= 1 {
+			t.Errorf("corner %v was not cut", p)
+		}
+	}
+
+	if _, err := PolygonSmooth(line, 11); err == nil {
+		t.Error("expected error for too many iterations")
+	}
+	if _, err := PolygonSmooth("nope", 1); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+}
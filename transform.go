@@ -0,0 +1,318 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// TransformTranslate moves a GeoJSON object distanceKm along the great circle
+// leaving its center of mass at bearingDeg. The whole object is turned about
+// the Earth's center as one piece, so its shape and area are kept, even far
+// from the equator; every coordinate moves the same distance, along the
+// circle parallel to the centroid's path. Moving each vertex due north on its
+// own meridian would instead squeeze the shape as the meridians converge.
+//
+// Like the other transforms it works on a deep copy, keeping altitudes, and
+// clears bboxes, which no longer fit. Longitudes are normalized to
+// [-180, 180).
+func TransformTranslate(obj interface{}, distanceKm, bearingDeg float64) (interface{}, error) {
+	center, err := GeoJSONCenterOfMass(obj)
+	if err != nil {
+		return nil, err
+	}
+	c := unitVector(center.Coordinates)
+	east := cross3([3]float64{0, 0, 1}, c)
+	if norm3(east) < 1e-12 {
+		// At a pole bearings are taken from the meridian at longitude 0.
+		east = [3]float64{0, 1, 0}
+	}
+	east = scale3(east, 1/norm3(east))
+	north := cross3(c, east)
+	θ := toRadians(bearingDeg)
+	var heading [3]float64
+	for i := range heading {
+		heading[i] = math.Cos(θ)*north[i] + math.Sin(θ)*east[i]
+	}
+	axis := cross3(c, heading)
+	return mapPositions(obj, func(p Position) Position {
+		return rotatePosition(p, axis, distanceKm/EarthRadiusKm)
+	})
+}
+
+// TransformRotate turns a GeoJSON object angleDeg clockwise, as seen from
+// above, about pivot, or about its center of mass when pivot is nil. The turn
+// is a rotation of the sphere about the axis through the pivot, so distances
+// and angles are kept and shapes do not shear at high latitudes.
+func TransformRotate(obj interface{}, angleDeg float64, pivot *Point) (interface{}, error) {
+	center, err := transformOrigin(obj, pivot)
+	if err != nil {
+		return nil, err
+	}
+	axis := unitVector(center)
+	return mapPositions(obj, func(p Position) Position {
+		return rotatePosition(p, axis, -toRadians(angleDeg))
+	})
+}
+
+// TransformScale scales the great-circle distance of every coordinate from
+// origin, or from the object's center of mass when origin is nil, by factor,
+// keeping its bearing from the origin. A factor of 2 doubles the size of a
+// shape and 0 collapses it onto the origin; factor must not be negative.
+func TransformScale(obj interface{}, factor float64, origin *Point) (interface{}, error) {
+	if factor < 0 || math.IsInf(factor, 0) || math.IsNaN(factor) {
+		return nil, fmt.Errorf("invalid scale factor %v", factor)
+	}
+	center, err := transformOrigin(obj, origin)
+	if err != nil {
+		return nil, err
+	}
+	lat0, lon0 := positionLatLon(center)
+	return mapPositions(obj, func(p Position) Position {
+		lat, lon := positionLatLon(p)
+		dist, bearing, _ := GreatCircleInverse(lat0, lon0, lat, lon)
+		if dist == 0 {
+			return withAlt(normalizeLongitude(lon0), lat0, p)
+		}
+		lat, lon = GreatCircleDestination(lat0, lon0, dist*factor, bearing)
+		return withAlt(lon, lat, p)
+	})
+}
+
+// transformOrigin returns the given point's position, or the center of mass
+// of obj when p is nil.
+func transformOrigin(obj interface{}, p *Point) (Position, error) {
+	if p != nil {
+		return p.Coordinates, nil
+	}
+	center, err := GeoJSONCenterOfMass(obj)
+	if err != nil {
+		return Position{}, err
+	}
+	return center.Coordinates, nil
+}
+
+// rotatePosition turns p by angle radians about axis, counterclockwise as
+// seen from outside the sphere where axis points, using Rodrigues' formula.
+// axis must be a unit vector.
+func rotatePosition(p Position, axis [3]float64, angle float64) Position {
+	v := unitVector(p)
+	sin, cos := math.Sincos(angle)
+	kv := cross3(axis, v)
+	kDotV := dot3(axis, v)
+	var r [3]float64
+	for i := range r {
+		r[i] = v[i]*cos + kv[i]*sin + axis[i]*kDotV*(1-cos)
+	}
+	lat := toDegrees(math.Atan2(r[2], math.Hypot(r[0], r[1])))
+	lon := toDegrees(math.Atan2(r[1], r[0]))
+	return withAlt(normalizeLongitude(lon), lat, p)
+}
+
+// mapPositions returns a deep copy of a GeoJSON object with every position
+// replaced by fn's result. fn must return a new position rather than modify
+// p. Bboxes are cleared, and Feature properties are copied into a new map.
+func mapPositions(obj interface{}, fn func(Position) Position) (interface{}, error) {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return nil, err
+	}
+	mapLine := func(line []Position) []Position {
+		out := make([]Position, len(line))
+		for i, p := range line {
+			out[i] = fn(p)
+		}
+		return out
+	}
+	mapLines := func(lines [][]Position) [][]Position {
+		out := make([][]Position, len(lines))
+		for i, line := range lines {
+			out[i] = mapLine(line)
+		}
+		return out
+	}
+	switch g := geom.(type) {
+	case Point:
+		g.Coordinates, g.BBox = fn(g.Coordinates), nil
+		return g, nil
+	case MultiPoint:
+		g.Coordinates, g.BBox = mapLine(g.Coordinates), nil
+		return g, nil
+	case LineString:
+		g.Coordinates, g.BBox = mapLine(g.Coordinates), nil
+		return g, nil
+	case MultiLineString:
+		g.Coordinates, g.BBox = mapLines(g.Coordinates), nil
+		return g, nil
+	case Polygon:
+		g.Coordinates, g.BBox = mapLines(g.Coordinates), nil
+		return g, nil
+	case MultiPolygon:
+		polys := make([][][]Position, len(g.Coordinates))
+		for i, rings := range g.Coordinates {
+			polys[i] = mapLines(rings)
+		}
+		g.Coordinates, g.BBox = polys, nil
+		return g, nil
+	case GeometryCollection:
+		geometries := make([]interface{}, len(g.Geometries))
+		for i, child := range g.Geometries {
+			if geometries[i], err = mapPositions(child, fn); err != nil {
+				return nil, err
+			}
+		}
+		g.Geometries, g.BBox = geometries, nil
+		return g, nil
+	case Feature:
+		if g.Geometry, err = mapPositions(g.Geometry, fn); err != nil {
+			return nil, err
+		}
+		g.Properties, g.BBox = copyProperties(g.Properties), nil
+		return g, nil
+	case FeatureCollection:
+		features := make([]Feature, len(g.Features))
+		for i, f := range g.Features {
+			mapped, err := mapPositions(f, fn)
+			if err != nil {
+				return nil, err
+			}
+			features[i] = mapped.(Feature)
+		}
+		g.Features, g.BBox = features, nil
+		return g, nil
+	default:
+		return nil, fmt.Errorf("unsupported geojson type %T", obj)
+	}
+}
+
+// FlipCoordinates returns a copy of a GeoJSON object with the first two
+// elements of every position swapped, to repair data read in lat,lon order.
+// Altitudes stay in place.
+func FlipCoordinates(obj interface{}) (interface{}, error) {
+	return mapPositions(obj, func(p Position) Position {
+		return withAlt(p[1], p[0], p)
+	})
+}
+
+// TruncateCoordinates returns a copy of a GeoJSON object with every
+// coordinate, altitudes included, rounded to the given number of decimal
+// places. Six decimals keep positions to about 0.11 m.
+func TruncateCoordinates(obj interface{}, decimals int) (interface{}, error) {
+	if decimals < 0 {
+		return nil, fmt.Errorf("invalid number of decimals %d", decimals)
+	}
+	scale := math.Pow(10, float64(decimals))
+	return mapPositions(obj, func(p Position) Position {
+		out := make(Position, len(p))
+		for i, v := range p {
+			out[i] = math.Round(v*scale) / scale
+		}
+		return out
+	})
+}
+
+// CleanCoordinates returns a copy of a GeoJSON object without repeated
+// positions: consecutive duplicates, and so zero-length segments, are removed
+// from lines and rings, and a MultiPoint keeps only the first of equal
+// positions. Rings stay closed. A line left with fewer than 2 positions or a
+// ring with fewer than 3 distinct vertices gives an error.
+func CleanCoordinates(obj interface{}) (interface{}, error) {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return nil, err
+	}
+	cleanRings := func(rings [][]Position) ([][]Position, error) {
+		out := make([][]Position, len(rings))
+		for i, ring := range rings {
+			open := openRing(ring)
+			if len(open) < 3 {
+				return nil, fmt.Errorf("ring has %d distinct positions, need at least 3", len(open))
+			}
+			out[i] = closeRing(open)
+		}
+		return out, nil
+	}
+	switch g := geom.(type) {
+	case Point:
+		return g, nil
+	case MultiPoint:
+		seen := make(map[[2]float64]bool, len(g.Coordinates))
+		var kept []Position
+		for _, p := range g.Coordinates {
+			if !seen[lonLat(p)] {
+				seen[lonLat(p)] = true
+				kept = append(kept, p)
+			}
+		}
+		g.Coordinates = kept
+		return g, nil
+	case LineString:
+		if g.Coordinates, err = cleanLine(g.Coordinates); err != nil {
+			return nil, err
+		}
+		return g, nil
+	case MultiLineString:
+		lines := make([][]Position, len(g.Coordinates))
+		for i, line := range g.Coordinates {
+			if lines[i], err = cleanLine(line); err != nil {
+				return nil, err
+			}
+		}
+		g.Coordinates = lines
+		return g, nil
+	case Polygon:
+		if g.Coordinates, err = cleanRings(g.Coordinates); err != nil {
+			return nil, err
+		}
+		return g, nil
+	case MultiPolygon:
+		polys := make([][][]Position, len(g.Coordinates))
+		for i, rings := range g.Coordinates {
+			if polys[i], err = cleanRings(rings); err != nil {
+				return nil, err
+			}
+		}
+		g.Coordinates = polys
+		return g, nil
+	case GeometryCollection:
+		geometries := make([]interface{}, len(g.Geometries))
+		for i, child := range g.Geometries {
+			if geometries[i], err = CleanCoordinates(child); err != nil {
+				return nil, err
+			}
+		}
+		g.Geometries = geometries
+		return g, nil
+	case Feature:
+		if g.Geometry, err = CleanCoordinates(g.Geometry); err != nil {
+			return nil, err
+		}
+		return g, nil
+	case FeatureCollection:
+		features := make([]Feature, len(g.Features))
+		for i, f := range g.Features {
+			cleaned, err := CleanCoordinates(f)
+			if err != nil {
+				return nil, err
+			}
+			features[i] = cleaned.(Feature)
+		}
+		g.Features = features
+		return g, nil
+	default:
+		return nil, fmt.Errorf("unsupported geojson type %T", obj)
+	}
+}
+
+// cleanLine returns a copy of line without consecutive duplicate positions.
+func cleanLine(line []Position) ([]Position, error) {
+	out := make([]Position, 0, len(line))
+	for _, p := range line {
+		if len(out) == 0 || !samePosition(out[len(out)-1], p) {
+			out = append(out, p)
+		}
+	}
+	if len(out) < 2 {
+		return nil, fmt.Errorf("line has %d distinct positions, need at least 2", len(out))
+	}
+	return out, nil
+}
@@ -0,0 +1,180 @@
+package geo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTransformTranslate(t *testing.T) {
+	poly := squarePolygon(10, 60, 12, 61)
+	before := append([]Position(nil), poly.Coordinates[0]...)
+	moved, err := TransformTranslate(NewFeature(poly), 100, 0)
+	if err != nil {
+		t.Fatalf("TransformTranslate() error = %v", err)
+	}
+	got := moved.(Feature).Geometry.(Polygon)
+	if !reflect.DeepEqual(poly.Coordinates[0], before) {
+		t.Error("TransformTranslate() changed its input")
+	}
+
+	c0, _ := GeoJSONCenterOfMass(poly)
+	c1, _ := GeoJSONCenterOfMass(got)
+	lat0, lon0 := positionLatLon(c0.Coordinates)
+	lat1, lon1 := positionLatLon(c1.Coordinates)
+	dist, bearing, _ := GreatCircleInverse(lat0, lon0, lat1, lon1)
+	if math.Abs(dist-100) > 1 || math.Abs(BearingDifference(bearing, 0)) > 1 {
+		t.Errorf("centroid moved %v km at %v°, want 100 km north", dist, bearing)
+	}
+
+	a0, a1 := sphericalRingArea(poly.Coordinates[0]), sphericalRingArea(got.Coordinates[0])
+	if math.Abs(a1-a0) > 0.001*a0 {
+		t.Errorf("area %v km², want %v", a1, a0)
+	}
+}
+
+func TestTransformRotate(t *testing.T) {
+	line := NewLineString([]Position{{0, 0}, {0, 1}})
+	pivot := NewPoint(0, 0)
+	rotated, err := TransformRotate(line, 90, &pivot)
+	if err != nil {
+		t.Fatalf("TransformRotate() error = %v", err)
+	}
+	end := rotated.(LineString).Coordinates[1]
+	if math.Abs(end[0]-1) > 1e-9 || math.Abs(end[1]) > 1e-9 {
+		t.Errorf("north end rotated 90° clockwise = %v, want (1, 0)", end)
+	}
+
+	// At high latitude the rotated square keeps its size and shape.
+	poly := squarePolygon(20, 70, 24, 71)
+	rotated, err = TransformRotate(poly, 45, nil)
+	if err != nil {
+		t.Fatalf("TransformRotate() error = %v", err)
+	}
+	ring, orig := rotated.(Polygon).Coordinates[0], poly.Coordinates[0]
+	for i := 0; i+1 < len(ring); i++ {
+		want := GreatCircleDistance(orig[i][1], orig[i][0], orig[i+1][1], orig[i+1][0])
+		if got := GreatCircleDistance(ring[i][1], ring[i][0], ring[i+1][1], ring[i+1][0]); math.Abs(got-want) > 1e-6 {
+			t.Errorf("edge %d is %v km, want %v", i, got, want)
+		}
+	}
+	center, _ := GeoJSONCenterOfMass(poly)
+	if back, _ := TransformRotate(rotated, -45, &center); !positionsClose(back.(Polygon).Coordinates[0], orig, 1e-6) {
+		t.Errorf("rotating back gave %v, want %v", back, orig)
+	}
+}
+
+func TestTransformScale(t *testing.T) {
+	origin := NewPoint(5, 5)
+	pts := NewMultiPoint([]Position{{5, 6, 30}, {6, 5}})
+	scaled, err := TransformScale(pts, 2, &origin)
+	if err != nil {
+		t.Fatalf("TransformScale() error = %v", err)
+	}
+	for i, p := range scaled.(MultiPoint).Coordinates {
+		q := pts.Coordinates[i]
+		want := 2 * GreatCircleDistance(5, 5, q[1], q[0])
+		if got := GreatCircleDistance(5, 5, p[1], p[0]); math.Abs(got-want) > 1e-6 {
+			t.Errorf("point %d is %v km from the origin, want %v", i, got, want)
+		}
+		if p.Alt() != q.Alt() {
+			t.Errorf("point %d altitude = %v, want %v", i, p.Alt(), q.Alt())
+		}
+	}
+
+	poly := squarePolygon(0, 0, 1, 1)
+	scaled, err = TransformScale(poly, 0.5, nil)
+	if err != nil {
+		t.Fatalf("TransformScale() error = %v", err)
+	}
+	if got, want := polygonPlanarArea(scaled.(Polygon)), 0.25; math.Abs(got-want) > 1e-3 {
+		t.Errorf("scaled area = %v, want about %v", got, want)
+	}
+	if _, err := TransformScale(poly, -1, nil); err == nil {
+		t.Error("expected error for a negative factor")
+	}
+}
+
+func positionsClose(a, b []Position, tol float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i][0]-b[i][0]) > tol || math.Abs(a[i][1]-b[i][1]) > tol {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFlipCoordinates(t *testing.T) {
+	fc := NewFeatureCollection([]Feature{
+		NewFeature(NewPoint(-74, 40.7)),
+		NewFeature(squarePolygon(1, 2, 3, 4)),
+	})
+	flipped, err := FlipCoordinates(fc)
+	if err != nil {
+		t.Fatalf("FlipCoordinates() error = %v", err)
+	}
+	if pt := flipped.(FeatureCollection).Features[0].Geometry.(Point); !reflect.DeepEqual(pt.Coordinates, Position{40.7, -74}) {
+		t.Errorf("flipped point = %v, want (40.7, -74)", pt.Coordinates)
+	}
+	twice, err := FlipCoordinates(flipped)
+	if err != nil || !reflect.DeepEqual(twice, fc) {
+		t.Errorf("flipping twice = %v, %v, want %v", twice, err, fc)
+	}
+}
+
+func TestTruncateCoordinates(t *testing.T) {
+	line := NewLineString([]Position{{12.123456789012345, 55.987654321098765}, {-0.1234565, 51.5000004999, 12.3456789}})
+	got, err := TruncateCoordinates(NewFeature(line), 6)
+	if err != nil {
+		t.Fatalf("TruncateCoordinates() error = %v", err)
+	}
+	truncated := got.(Feature).Geometry.(LineString).Coordinates
+	if !reflect.DeepEqual(truncated[0], Position{12.123457, 55.987654}) {
+		t.Errorf("truncated = %v, want (12.123457, 55.987654)", truncated[0])
+	}
+	for i, p := range truncated {
+		q := line.Coordinates[i]
+		if d := GreatCircleDistanceMeters(q[1], q[0], p[1], p[0]); d > 0.11 {
+			t.Errorf("position %d moved %v m", i, d)
+		}
+	}
+	if _, err := TruncateCoordinates(line, -1); err == nil {
+		t.Error("expected error for negative decimals")
+	}
+}
+
+func TestCleanCoordinates(t *testing.T) {
+	line := NewLineString([]Position{{0, 0}, {0, 0}, {1, 0}, {1, 0}, {1, 0}, {1, 1}, {1, 1}})
+	got, err := CleanCoordinates(line)
+	if err != nil {
+		t.Fatalf("CleanCoordinates() error = %v", err)
+	}
+	cleaned := got.(LineString)
+	if want := []Position{{0, 0}, {1, 0}, {1, 1}}; !reflect.DeepEqual(cleaned.Coordinates, want) {
+		t.Errorf("cleaned line = %v, want %v", cleaned.Coordinates, want)
+	}
+	before, _ := GeoJSONLength(line, UnitKilometers)
+	after, _ := GeoJSONLength(cleaned, UnitKilometers)
+	if before != after {
+		t.Errorf("length %v after cleaning, want %v", after, before)
+	}
+
+	poly := NewPolygon([][]Position{{{0, 0}, {1, 0}, {1, 0}, {1, 1}, {0, 0}, {0, 0}}})
+	got, err = CleanCoordinates(NewFeature(poly))
+	if err != nil {
+		t.Fatalf("CleanCoordinates() error = %v", err)
+	}
+	if ring := got.(Feature).Geometry.(Polygon).Coordinates[0]; !reflect.DeepEqual(ring, []Position{{0, 0}, {1, 0}, {1, 1}, {0, 0}}) {
+		t.Errorf("cleaned ring = %v", ring)
+	}
+
+	if _, err := CleanCoordinates(NewLineString([]Position{{1, 1}, {1, 1}})); err == nil {
+		t.Error("expected error for a line of one position")
+	}
+	if _, err := CleanCoordinates(NewPolygon([][]Position{{{0, 0}, {1, 0}, {1, 0}, {0, 0}}})); err == nil {
+		t.Error("expected error for a ring of two vertices")
+	}
+}
@@ -0,0 +1,307 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Tin returns the Delaunay triangulation of points as a triangulated
+// irregular network: one Feature per triangle, whose geometry is the closed,
+// counterclockwise triangle Polygon. When propertyValues is given it must hold
+// one value per point, and the values at each triangle's vertices are stored
+// in its properties "a", "b" and "c", in ring order, for interpolation.
+//
+// Duplicate points are triangulated once, keeping the value of their first
+// occurrence. The points are taken in the lon/lat plane. An error is returned
+// when fewer than three distinct points are given or all are collinear.
+func Tin(points []Position, propertyValues []float64) (FeatureCollection, error) {
+	if propertyValues != nil && len(propertyValues) != len(points) {
+		return FeatureCollection{}, fmt.Errorf("got %d property values for %d points", len(propertyValues), len(points))
+	}
+	unique, index := dedupePositions(points)
+	tris, err := delaunay(unique)
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+
+	var values []float64
+	if propertyValues != nil {
+		values = make([]float64, len(unique))
+		for i := len(points) - 1; i >= 0; i-- {
+			values[index[i]] = propertyValues[i]
+		}
+	}
+
+	features := make([]Feature, len(tris))
+	for i, t := range tris {
+		ring := []Position{unique[t.a], unique[t.b], unique[t.c], unique[t.a]}
+		f := NewFeature(NewPolygon([][]Position{ring}))
+		if values != nil {
+			f.Properties = map[string]interface{}{
+				"a": values[t.a],
+				"b": values[t.b],
+				"c": values[t.c],
+			}
+		}
+		features[i] = f
+	}
+	return NewFeatureCollection(features), nil
+}
+
+// triangle is a Delaunay triangle over indices into a point slice, stored in
+// counterclockwise order together with its circumcircle.
+type triangle struct {
+	a, b, c int
+	cx, cy  float64 // circumcenter
+	r2      float64 // squared circumradius
+}
+
+// Voronoi returns the Voronoi diagram of points, clipped to clipBBox: one
+// Feature per point whose Polygon is the part of the box closer to that point
+// than to any other, with the point's index in the "index" property. Cells are
+// computed in the lon/lat plane from the Delaunay neighbors of each point and
+// cut to the box with BBoxClip, so the cells tile the box.
+//
+// Duplicate points share a cell. Collinear points, which have no
+// triangulation, get parallel strips. Points whose cell lies entirely outside
+// the box have no feature. An error is returned for an empty or invalid box
+// or when no points are given.
+func Voronoi(points []Position, clipBBox BBox) (FeatureCollection, error) {
+	if clipBBox.IsEmpty() {
+		return FeatureCollection{}, fmt.Errorf("invalid bbox %v", clipBBox)
+	}
+	if len(points) == 0 {
+		return FeatureCollection{}, errors.New("voronoi needs at least one point")
+	}
+	unique, index := dedupePositions(points)
+
+	// neighbors[i] lists the points whose bisectors can bound cell i. With a
+	// triangulation these are the Delaunay neighbors; without one every other
+	// point is used, which is cheap for the few points that are degenerate.
+	neighbors := make([][]int, len(unique))
+	if tris, err := delaunay(unique); err == nil {
+		seen := make(map[[2]int]bool)
+		link := func(i, j int) {
+			if !seen[[2]int{i, j}] {
+				seen[[2]int{i, j}], seen[[2]int{j, i}] = true, true
+				neighbors[i] = append(neighbors[i], j)
+				neighbors[j] = append(neighbors[j], i)
+			}
+		}
+		for _, t := range tris {
+			link(t.a, t.b)
+			link(t.b, t.c)
+			link(t.c, t.a)
+		}
+	} else {
+		for i := range unique {
+			for j := range unique {
+				if i != j {
+					neighbors[i] = append(neighbors[i], j)
+				}
+			}
+		}
+	}
+
+	// Start every cell from a frame around both the box and the points, so
+	// that BBoxClip does the final cut.
+	frame := clipBBox
+	for _, p := range unique {
+		frame.MinLon = math.Min(frame.MinLon, p[0])
+		frame.MinLat = math.Min(frame.MinLat, p[1])
+		frame.MaxLon = math.Max(frame.MaxLon, p[0])
+		frame.MaxLat = math.Max(frame.MaxLat, p[1])
+	}
+	pad := math.Max(frame.MaxLon-frame.MinLon, frame.MaxLat-frame.MinLat) + 1
+	start := []Position{
+		{frame.MinLon - pad, frame.MinLat - pad},
+		{frame.MaxLon + pad, frame.MinLat - pad},
+		{frame.MaxLon + pad, frame.MaxLat + pad},
+		{frame.MinLon - pad, frame.MaxLat + pad},
+	}
+
+	cells := make([]interface{}, len(unique))
+	for i, site := range unique {
+		ring := start
+		for _, j := range neighbors[i] {
+			ring = clipRingToBisector(ring, site, unique[j])
+		}
+		if len(ring) < 3 {
+			continue
+		}
+		clipped, err := BBoxClip(NewPolygon([][]Position{closeRing(ring)}), clipBBox)
+		if err != nil {
+			return FeatureCollection{}, err
+		}
+		cells[i] = clipped
+	}
+
+	features := make([]Feature, 0, len(points))
+	for i := range points {
+		cell := cells[index[i]]
+		if cell == nil {
+			continue
+		}
+		f := NewFeature(cell)
+		f.Properties = map[string]interface{}{"index": i}
+		features = append(features, f)
+	}
+	return NewFeatureCollection(features), nil
+}
+
+// clipRingToBisector clips an open ring to the half-plane of points at least
+// as close to a as to b.
+func clipRingToBisector(ring []Position, a, b Position) []Position {
+	mx, my := (a[0]+b[0])/2, (a[1]+b[1])/2
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	// side is negative on a's side of the bisector and positive on b's.
+	side := func(p Position) float64 { return (p[0]-mx)*dx + (p[1]-my)*dy }
+
+	var out []Position
+	prev := ring[len(ring)-1]
+	for _, p := range ring {
+		sp, sPrev := side(p), side(prev)
+		if (sp <= 0) != (sPrev <= 0) {
+			out = append(out, lerpPosition(prev, p, sPrev/(sPrev-sp)))
+		}
+		if sp <= 0 {
+			out = append(out, p)
+		}
+		prev = p
+	}
+	return openRing(out)
+}
+
+// delaunay triangulates points in the lon/lat plane using the incremental
+// Bowyer–Watson algorithm. Duplicate points must already be removed. Returned
+// triangles are counterclockwise and index into points.
+func delaunay(points []Position) ([]triangle, error) {
+	n := len(points)
+	if n < 3 {
+		return nil, errors.New("triangulation needs at least 3 distinct points")
+	}
+
+	minX, minY := points[0][0], points[0][1]
+	maxX, maxY := minX, minY
+	for _, p := range points[1:] {
+		minX = math.Min(minX, p[0])
+		minY = math.Min(minY, p[1])
+		maxX = math.Max(maxX, p[0])
+		maxY = math.Max(maxY, p[1])
+	}
+	span := math.Max(maxX-minX, maxY-minY)
+	if span == 0 {
+		span = 1
+	}
+	midX := (minX + maxX) / 2
+	midY := (minY + maxY) / 2
+
+	// Work on a copy with a super-triangle appended that contains every point.
+	verts := make([]Position, n, n+3)
+	copy(verts, points)
+	verts = append(verts,
+		Position{midX - 20*span, midY - span},
+		Position{midX, midY + 20*span},
+		Position{midX + 20*span, midY - span},
+	)
+
+	tris := []triangle{newTriangle(verts, n, n+1, n+2)}
+	for i := 0; i < n; i++ {
+		p := verts[i]
+		var edges [][2]int
+		kept := tris[:0]
+		for _, t := range tris {
+			dx := p[0] - t.cx
+			dy := p[1] - t.cy
+			if dx*dx+dy*dy <= t.r2 {
+				edges = append(edges, [2]int{t.a, t.b}, [2]int{t.b, t.c}, [2]int{t.c, t.a})
+			} else {
+				kept = append(kept, t)
+			}
+		}
+		tris = kept
+
+		// Edges shared by two removed triangles are interior to the cavity.
+		for j, e := range edges {
+			shared := false
+			for k, f := range edges {
+				if j != k && e[0] == f[1] && e[1] == f[0] {
+					shared = true
+					break
+				}
+			}
+			if !shared {
+				tris = append(tris, newTriangle(verts, e[0], e[1], i))
+			}
+		}
+	}
+
+	result := make([]triangle, 0, len(tris))
+	for _, t := range tris {
+		if t.a >= n || t.b >= n || t.c >= n {
+			continue
+		}
+		if orient2D(points[t.a], points[t.b], points[t.c]) == 0 {
+			continue
+		}
+		result = append(result, t)
+	}
+	if len(result) == 0 {
+		return nil, errors.New("points are collinear")
+	}
+	return result, nil
+}
+
+// newTriangle builds a counterclockwise triangle and its circumcircle.
+func newTriangle(verts []Position, a, b, c int) triangle {
+	if orient2D(verts[a], verts[b], verts[c]) < 0 {
+		b, c = c, b
+	}
+	ax, ay := verts[a][0], verts[a][1]
+	bx, by := verts[b][0], verts[b][1]
+	cx, cy := verts[c][0], verts[c][1]
+
+	d := 2 * (ax*(by-cy) + bx*(cy-ay) + cx*(ay-by))
+	if d == 0 {
+		// Degenerate triangle: give it an infinite circumcircle so it is
+		// always replaced.
+		return triangle{a: a, b: b, c: c, r2: math.Inf(1)}
+	}
+	a2 := ax*ax + ay*ay
+	b2 := bx*bx + by*by
+	c2 := cx*cx + cy*cy
+	ux := (a2*(by-cy) + b2*(cy-ay) + c2*(ay-by)) / d
+	uy := (a2*(cx-bx) + b2*(ax-cx) + c2*(bx-ax)) / d
+	return triangle{
+		a: a, b: b, c: c,
+		cx: ux, cy: uy,
+		r2: (ax-ux)*(ax-ux) + (ay-uy)*(ay-uy),
+	}
+}
+
+// orient2D returns twice the signed area of triangle abc in the lon/lat plane;
+// positive when a, b, c turn counterclockwise.
+func orient2D(a, b, c Position) float64 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+// dedupePositions returns the distinct positions in order of first appearance
+// and, for every input position, the index of its distinct copy. Positions that
+// differ only in altitude count as duplicates.
+func dedupePositions(points []Position) ([]Position, []int) {
+	seen := make(map[[2]float64]int, len(points))
+	unique := make([]Position, 0, len(points))
+	index := make([]int, len(points))
+	for i, p := range points {
+		key := [2]float64{p[0], p[1]}
+		if j, ok := seen[key]; ok {
+			index[i] = j
+			continue
+		}
+		seen[key] = len(unique)
+		index[i] = len(unique)
+		unique = append(unique, p)
+	}
+	return unique, index
+}
@@ -0,0 +1,195 @@
+package geo
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestDelaunay(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	points := make([]Position, 60)
+	for i := range points {
+		points[i] = Position{rng.Float64() * 10, rng.Float64() * 10}
+	}
+
+	tris, err := delaunay(points)
+	if err != nil {
+		t.Fatalf("delaunay() error = %v", err)
+	}
+	// A triangulation of n points with h on the hull has 2n - 2 - h triangles.
+	if len(tris) < len(points) || len(tris) > 2*len(points)-5 {
+		t.Errorf("triangle count = %d", len(tris))
+	}
+	for _, tri := range tris {
+		if orient2D(points[tri.a], points[tri.b], points[tri.c]) <= 0 {
+			t.Errorf("triangle %v is not counterclockwise", tri)
+		}
+		for i, p := range points {
+			if i == tri.a || i == tri.b || i == tri.c {
+				continue
+			}
+			dx, dy := p[0]-tri.cx, p[1]-tri.cy
+			if dx*dx+dy*dy < tri.r2*(1-1e-9) {
+				t.Errorf("point %d lies inside the circumcircle of %v", i, tri)
+			}
+		}
+	}
+}
+
+func TestDedupePositions(t *testing.T) {
+	unique, index := dedupePositions([]Position{{0, 0}, {1, 1}, {0, 0}, {2, 2}})
+	if len(unique) != 3 {
+		t.Errorf("unique = %v, want 3 positions", unique)
+	}
+	if !equalIntSlice(index, []int{0, 1, 0, 2}) {
+		t.Errorf("index = %v, want [0 1 0 2]", index)
+	}
+}
+
+func TestTinSquare(t *testing.T) {
+	square := []Position{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {2, 0}}
+	fc, err := Tin(square, []float64{1, 2, 3, 4, 99})
+	if err != nil {
+		t.Fatalf("Tin() error = %v", err)
+	}
+	if len(fc.Features) != 2 {
+		t.Fatalf("got %d triangles, want 2", len(fc.Features))
+	}
+	value := map[[2]float64]float64{{0, 0}: 1, {2, 0}: 2, {2, 2}: 3, {0, 2}: 4}
+	total := 0.0
+	for _, f := range fc.Features {
+		ring := f.Geometry.(Polygon).Coordinates[0]
+		if len(ring) != 4 || !reflect.DeepEqual(ring[0], ring[3]) {
+			t.Fatalf("triangle ring %v is not closed", ring)
+		}
+		area := orient2D(ring[0], ring[1], ring[2]) / 2
+		if area <= 0 {
+			t.Errorf("triangle %v is not counterclockwise", ring)
+		}
+		total += area
+		for i, key := range []string{"a", "b", "c"} {
+			if got := f.Properties[key]; got != value[lonLat(ring[i])] {
+				t.Errorf("property %s = %v, want %v", key, got, value[lonLat(ring[i])])
+			}
+		}
+	}
+	if total != 4 {
+		t.Errorf("triangle areas sum to %v, want 4", total)
+	}
+}
+
+func TestTinDelaunayProperty(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	points := make([]Position, 40)
+	for i := range points {
+		points[i] = Position{rng.Float64() * 5, rng.Float64() * 5}
+	}
+	fc, err := Tin(points, nil)
+	if err != nil {
+		t.Fatalf("Tin() error = %v", err)
+	}
+	for _, f := range fc.Features {
+		if f.Properties != nil {
+			t.Errorf("properties = %v, want none without values", f.Properties)
+		}
+		ring := f.Geometry.(Polygon).Coordinates[0]
+		tri := newTriangle(ring, 0, 1, 2)
+		for _, p := range points {
+			if reflect.DeepEqual(p, ring[0]) || reflect.DeepEqual(p, ring[1]) || reflect.DeepEqual(p, ring[2]) {
+				continue
+			}
+			dx, dy := p[0]-tri.cx, p[1]-tri.cy
+			if dx*dx+dy*dy < tri.r2*(1-1e-9) {
+				t.Errorf("point %v lies inside the circumcircle of %v", p, ring)
+			}
+		}
+	}
+}
+
+func TestTinErrors(t *testing.T) {
+	if _, err := Tin([]Position{{0, 0}, {1, 1}, {2, 2}, {3, 3}}, nil); err == nil {
+		t.Error("expected error for collinear points")
+	}
+	if _, err := Tin([]Position{{0, 0}, {1, 0}, {0, 1}}, []float64{1}); err == nil {
+		t.Error("expected error for mismatched property values")
+	}
+}
+
+func TestVoronoi(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	points := make([]Position, 25)
+	for i := range points {
+		points[i] = Position{rng.Float64() * 10, rng.Float64() * 10}
+	}
+	box := BBox{MinLon: -1, MinLat: -1, MaxLon: 11, MaxLat: 11}
+	fc, err := Voronoi(points, box)
+	if err != nil {
+		t.Fatalf("Voronoi() error = %v", err)
+	}
+	if len(fc.Features) != len(points) {
+		t.Fatalf("got %d cells, want %d", len(fc.Features), len(points))
+	}
+	checkVoronoiCells(t, fc, points, box)
+}
+
+func TestVoronoiCollinear(t *testing.T) {
+	points := []Position{{1, 5}, {3, 5}, {7, 5}, {3, 5}}
+	box := BBox{MinLon: 0, MinLat: 0, MaxLon: 10, MaxLat: 10}
+	fc, err := Voronoi(points, box)
+	if err != nil {
+		t.Fatalf("Voronoi() error = %v", err)
+	}
+	if len(fc.Features) != len(points) {
+		t.Fatalf("got %d cells, want %d", len(fc.Features), len(points))
+	}
+	// The cells are vertical strips split halfway between the points, and
+	// the duplicate shares its cell.
+	widths := []float64{2, 3, 5}
+	for i, w := range widths {
+		area := polygonPlanarArea(fc.Features[i].Geometry.(Polygon))
+		if math.Abs(area-10*w) > 1e-9 {
+			t.Errorf("cell %d area = %v, want %v", i, area, 10*w)
+		}
+	}
+	if !reflect.DeepEqual(fc.Features[3].Geometry, fc.Features[1].Geometry) {
+		t.Errorf("duplicate point cell = %v, want %v", fc.Features[3].Geometry, fc.Features[1].Geometry)
+	}
+	checkVoronoiCells(t, NewFeatureCollection(fc.Features[:3]), points, box)
+}
+
+func checkVoronoiCells(t *testing.T, fc FeatureCollection, points []Position, box BBox) {
+	t.Helper()
+	total := 0.0
+	for _, f := range fc.Features {
+		cell := f.Geometry.(Polygon)
+		total += polygonPlanarArea(cell)
+		i := f.Properties["index"].(int)
+		inside, err := PointInPolygon(Point{Coordinates: points[i]}, cell, false)
+		if err != nil || !inside {
+			t.Errorf("point %d %v is not inside its cell", i, points[i])
+		}
+	}
+	want := (box.MaxLon - box.MinLon) * (box.MaxLat - box.MinLat)
+	if math.Abs(total-want) > 1e-6*want {
+		t.Errorf("cell areas sum to %v, want %v", total, want)
+	}
+}
+
+func TestVoronoiSinglePointAndErrors(t *testing.T) {
+	box := BBox{MinLon: 0, MinLat: 0, MaxLon: 4, MaxLat: 2}
+	fc, err := Voronoi([]Position{{1, 1}}, box)
+	if err != nil {
+		t.Fatalf("Voronoi() error = %v", err)
+	}
+	if len(fc.Features) != 1 || polygonPlanarArea(fc.Features[0].Geometry.(Polygon)) != 8 {
+		t.Errorf("single point diagram = %v, want the whole box", fc)
+	}
+	if _, err := Voronoi(nil, box); err == nil {
+		t.Error("expected error for no points")
+	}
+	if _, err := Voronoi([]Position{{1, 1}}, BBox{MinLon: 1, MaxLon: 0}); err == nil {
+		t.Error("expected error for invalid bbox")
+	}
+}
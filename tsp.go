@@ -1,24 +1,274 @@
 package geo
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"math"
 	"math/rand"
+	"sort"
+	"time"
 )
 
 // TSPResult contains the result of a TSP solution
 type TSPResult struct {
-	Tour     []int   // order of nodes to visit
-	Distance float64 // total distance of the tour
+	Tour     []int    // order of nodes to visit
+	Distance float64  // total distance of the tour
+	Stats    TSPStats // statistics about the solver run
+}
+
+// TSPStats describes the work a solver did to produce a TSPResult.
+type TSPStats struct {
+	Iterations     int           // passes (local search) or iterations (annealing) run
+	ImprovingMoves int           // moves applied that shortened the tour
+	WallTime       time.Duration // time spent in the solver
+}
+
+// TSPProgressFunc is called periodically by the TSP solvers with the current
+// iteration, the best tour distance so far and the current temperature (0 for
+// solvers that do not anneal). Returning false stops the solver early; the
+// best tour found so far is returned.
+type TSPProgressFunc func(iteration int, bestDistance float64, temperature float64) bool
+
+// TSPOption configures optional solver behavior.
+type TSPOption func(*tspOptions)
+
+type tspOptions struct {
+	progress      TSPProgressFunc
+	progressEvery int
+}
+
+// WithTSPProgress registers a progress callback. Local search solvers call it
+// after every `every` passes; TSPSimulatedAnnealing calls it every `every`
+// iterations. A non-positive every uses the default of one pass for local
+// search and 1000 iterations for annealing.
+func WithTSPProgress(fn TSPProgressFunc, every int) TSPOption {
+	return func(o *tspOptions) {
+		o.progress = fn
+		o.progressEvery = every
+	}
+}
+
+func newTSPOptions(opts []TSPOption, defaultEvery int) tspOptions {
+	o := tspOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.progressEvery <= 0 {
+		o.progressEvery = defaultEvery
+	}
+	return o
+}
+
+// report invokes the progress callback when one is due for this iteration and
+// returns false if the solver should stop.
+func (o tspOptions) report(iteration int, bestDistance, temperature float64) bool {
+	if o.progress == nil || iteration%o.progressEvery != 0 {
+		return true
+	}
+	return o.progress(iteration, bestDistance, temperature)
 }
 
 // TSPNearestNeighbor solves the TSP using the nearest neighbor heuristic.
 // distanceMatrix[i][j] represents the distance from node i to node j.
 // Returns a tour starting from the specified start node.
 func TSPNearestNeighbor(distanceMatrix [][]float64, start int) *TSPResult {
+	return TSPNearestNeighborFunc(DenseMatrix(distanceMatrix), start)
+}
+
+// TSPGreedyEdge builds a tour by greedy edge insertion: edges are taken from
+// shortest to longest and added whenever neither end already has two tour
+// edges and the edge does not close a cycle early, which a union-find over
+// the path fragments detects. The last edge joins the two ends of the single
+// remaining path. Where nearest neighbor follows one path and is left with
+// long edges back across the map at the end, the greedy tour usually ends up
+// shorter, making it a better start for 2-opt. The matrix is read as
+// symmetric, using distanceMatrix[i][j] for i < j, and the tour starts at
+// node 0.
+func TSPGreedyEdge(distanceMatrix [][]float64) *TSPResult {
 	n := len(distanceMatrix)
+	if n == 0 {
+		return nil
+	}
+	began := time.Now()
+
+	type edge struct{ i, j int }
+	edges := make([]edge, 0, n*(n-1)/2)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			edges = append(edges, edge{i, j})
+		}
+	}
+	sort.Slice(edges, func(a, b int) bool {
+		return distanceMatrix[edges[a].i][edges[a].j] < distanceMatrix[edges[b].i][edges[b].j]
+	})
+
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	adj := make([][2]int, n)
+	degree := make([]int, n)
+	added := 0
+	for _, e := range edges {
+		if added == n-1 {
+			break
+		}
+		if degree[e.i] == 2 || degree[e.j] == 2 {
+			continue
+		}
+		ri, rj := find(e.i), find(e.j)
+		if ri == rj {
+			continue
+		}
+		parent[ri] = rj
+		adj[e.i][degree[e.i]] = e.j
+		adj[e.j][degree[e.j]] = e.i
+		degree[e.i]++
+		degree[e.j]++
+		added++
+	}
+
+	// The edges form one path through every node. Walk it from node 0 to
+	// the end of each side and join the two halves.
+	walk := func(from, next int) []int {
+		path := []int{next}
+		for degree[next] == 2 {
+			step := adj[next][0]
+			if step == from {
+				step = adj[next][1]
+			}
+			from, next = next, step
+			path = append(path, next)
+		}
+		return path
+	}
+	tour := make([]int, 0, n)
+	tour = append(tour, 0)
+	if degree[0] > 0 {
+		tour = append(tour, walk(0, adj[0][0])...)
+	}
+	if degree[0] == 2 {
+		backward := walk(0, adj[0][1])
+		for k := len(backward) - 1; k >= 0; k-- {
+			tour = append(tour, backward[k])
+		}
+	}
+
+	return &TSPResult{
+		Tour:     tour,
+		Distance: calculateTourDistance(distanceMatrix, tour),
+		Stats: TSPStats{
+			Iterations: added,
+			WallTime:   time.Since(began),
+		},
+	}
+}
+
+// TSPClarkeWright builds a tour with the Clarke-Wright savings heuristic,
+// the classic construction for routes out of a depot. Every other node starts
+// on its own route from the depot and back. Routes are then joined end to end
+// in order of decreasing savings s(i, j) = d(depot, i) + d(depot, j) - d(i, j),
+// the distance saved by driving from i to j instead of returning to the depot
+// in between, as long as i and j are still at the ends of different routes.
+// Without vehicle capacities everything ends up on one route, which is
+// returned as a tour starting at the depot. The matrix is read as symmetric.
+// It returns nil when depot is out of range.
+func TSPClarkeWright(distanceMatrix [][]float64, depot int) *TSPResult {
+	n := len(distanceMatrix)
+	if n == 0 || depot < 0 || depot >= n {
+		return nil
+	}
+	began := time.Now()
+	d := distanceMatrix
+
+	type saving struct {
+		i, j  int
+		value float64
+	}
+	var savings []saving
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if i != depot && j != depot {
+				savings = append(savings, saving{i, j, d[depot][i] + d[depot][j] - d[i][j]})
+			}
+		}
+	}
+	sort.SliceStable(savings, func(a, b int) bool { return savings[a].value > savings[b].value })
+
+	routes := make([][]int, n)
+	routeOf := make([]int, n)
+	for i := range routes {
+		if i != depot {
+			routes[i] = []int{i}
+			routeOf[i] = i
+		}
+	}
+	reversed := func(r []int) []int {
+		out := make([]int, len(r))
+		for k, v := range r {
+			out[len(r)-1-k] = v
+		}
+		return out
+	}
+	merges := 0
+	for _, s := range savings {
+		a, b := routeOf[s.i], routeOf[s.j]
+		if a == b {
+			continue
+		}
+		ra, rb := routes[a], routes[b]
+		// Turn the routes so that i ends the first and j starts the second.
+		switch {
+		case ra[len(ra)-1] == s.i:
+		case ra[0] == s.i:
+			ra = reversed(ra)
+		default:
+			continue
+		}
+		switch {
+		case rb[0] == s.j:
+		case rb[len(rb)-1] == s.j:
+			rb = reversed(rb)
+		default:
+			continue
+		}
+		routes[a], routes[b] = append(ra, rb...), nil
+		for _, v := range rb {
+			routeOf[v] = a
+		}
+		merges++
+	}
+
+	tour := []int{depot}
+	for _, r := range routes {
+		tour = append(tour, r...)
+	}
+	return &TSPResult{
+		Tour:     tour,
+		Distance: calculateTourDistance(distanceMatrix, tour),
+		Stats: TSPStats{
+			Iterations: merges,
+			WallTime:   time.Since(began),
+		},
+	}
+}
+
+// TSPNearestNeighborFunc is TSPNearestNeighbor for any DistanceFunc, such as a
+// SymmetricMatrix.
+func TSPNearestNeighborFunc(dist DistanceFunc, start int) *TSPResult {
+	n := dist.N()
 	if n == 0 || start < 0 || start >= n {
 		return nil
 	}
+	began := time.Now()
 
 	visited := make([]bool, n)
 	tour := []int{start}
@@ -33,16 +283,17 @@ func TSPNearestNeighbor(distanceMatrix [][]float64, start int) *TSPResult {
 
 		// Find nearest unvisited neighbor
 		for j := 0; j < n; j++ {
-			if !visited[j] && distanceMatrix[current][j] < minDist {
-				minDist = distanceMatrix[current][j]
+			if visited[j] {
+				continue
+			}
+			if d := dist.At(current, j); d < minDist {
+				minDist = d
 				nearest = j
 			}
 		}
-
 		if nearest == -1 {
 			break
 		}
-
 		tour = append(tour, nearest)
 		visited[nearest] = true
 		totalDistance += minDist
@@ -51,74 +302,347 @@ func TSPNearestNeighbor(distanceMatrix [][]float64, start int) *TSPResult {
 
 	// Return to start
 	if len(tour) == n {
-		totalDistance += distanceMatrix[current][start]
+		totalDistance += dist.At(current, start)
 	}
 
 	return &TSPResult{
 		Tour:     tour,
 		Distance: totalDistance,
+		Stats: TSPStats{
+			Iterations: len(tour) - 1,
+			WallTime:   time.Since(began),
+		},
 	}
 }
 
 // TSP2Opt improves a TSP tour using the 2-opt local search heuristic.
 // This algorithm iteratively improves the tour by removing crossing edges.
-func TSP2Opt(distanceMatrix [][]float64, initialTour []int, maxIterations int) *TSPResult {
-	n := len(distanceMatrix)
-	if n == 0 || len(initialTour) == 0 {
+// A progress callback, if given, is invoked after passes over the tour.
+func TSP2Opt(distanceMatrix [][]float64, initialTour []int, maxIterations int, opts ...TSPOption) *TSPResult {
+	return TSP2OptFunc(DenseMatrix(distanceMatrix), initialTour, maxIterations, opts...)
+}
+
+// TwoOptOptions controls when TSP2OptWithOptions stops. The zero value runs
+// until a pass finds no improving move, like TSP2Opt.
+type TwoOptOptions struct {
+	// MaxIterations caps the number of passes over the tour; 0 means no cap.
+	MaxIterations int
+	// MinImprovement stops the search after a pass that shortens the tour by
+	// less than this fraction of its length before the pass, e.g. 0.001 for
+	// 0.1%. 0 disables the check.
+	MinImprovement float64
+	// Context, if set, bounds the run: the search stops with the best tour so
+	// far once it is cancelled or its deadline passes.
+	Context context.Context
+}
+
+// TSP2OptWithOptions is TSP2Opt with additional stopping rules, for trading
+// tour quality against running time on large instances.
+func TSP2OptWithOptions(matrix [][]float64, tour []int, opts TwoOptOptions) *TSPResult {
+	return twoOpt(DenseMatrix(matrix), tour, opts, newTSPOptions(nil, 1))
+}
+
+// TSP2OptNeighborList is TSP2Opt restricted to candidate moves between each
+// node and its k nearest neighbors. Instead of trying every pair of edges, a
+// pass tries, for each node a, only the moves that give a a new edge to one of
+// its neighbors, and stops looking once a neighbor is no closer than a's
+// current tour neighbor, as no further move can then shorten the tour. A pass
+// then costs about n·k moves instead of n²/2, so large instances get close to
+// a 2-opt local optimum in a fraction of the time. A k of 0 or less uses 10.
+// Like TSP2Opt it returns nil when the tour does not cover the matrix.
+func TSP2OptNeighborList(matrix [][]float64, tour []int, k, maxIterations int, opts ...TSPOption) *TSPResult {
+	n := len(matrix)
+	if n == 0 || len(tour) != n {
 		return nil
 	}
+	o := newTSPOptions(opts, 1)
+	began := time.Now()
+	if k <= 0 {
+		k = 10
+	}
+	neighbors := nearestNeighborLists(matrix, min(k, n-1))
+
+	t := make([]int, n)
+	copy(t, tour)
+	pos := make([]int, n)
+	for i, node := range t {
+		pos[node] = i
+	}
+	distance := calculateTourDistance(matrix, t)
+	moves := 0
+
+	// tryMove replaces the tour edges leaving positions p and q with edges
+	// joining their starts and their ends, if that makes the tour shorter.
+	tryMove := func(p, q int) bool {
+		lo, hi := min(p, q), max(p, q)
+		if hi-lo < 2 || lo == 0 && hi == n-1 {
+			return false
+		}
+		a, b, c, d := t[lo], t[lo+1], t[hi], t[(hi+1)%n]
+		delta := matrix[a][c] + matrix[b][d] - matrix[a][b] - matrix[c][d]
+		if delta >= -1e-10 {
+			return false
+		}
+		reverse(t, lo+1, hi)
+		for i := lo + 1; i <= hi; i++ {
+			pos[t[i]] = i
+		}
+		distance += delta
+		moves++
+		return true
+	}
+
+	improved := true
+	iteration := 0
+	for improved && (maxIterations <= 0 || iteration < maxIterations) {
+		improved = false
+		iteration++
+		for a := 0; a < n; a++ {
+			// New edge from a to c replacing a's edge to its successor, then
+			// replacing its edge from its predecessor.
+			for _, c := range neighbors[a] {
+				i := pos[a]
+				if matrix[a][c] >= matrix[a][t[(i+1)%n]] {
+					break
+				}
+				if tryMove(i, pos[c]) {
+					improved = true
+					break
+				}
+			}
+			for _, c := range neighbors[a] {
+				i := pos[a]
+				if matrix[a][c] >= matrix[t[(i+n-1)%n]][a] {
+					break
+				}
+				if tryMove((i+n-1)%n, (pos[c]+n-1)%n) {
+					improved = true
+					break
+				}
+			}
+		}
+		if !o.report(iteration, distance, 0) {
+			break
+		}
+	}
+
+	return &TSPResult{
+		Tour:     t,
+		Distance: distance,
+		Stats: TSPStats{
+			Iterations:     iteration,
+			ImprovingMoves: moves,
+			WallTime:       time.Since(began),
+		},
+	}
+}
+
+// nearestNeighborLists returns for each node the k other nodes nearest to it,
+// closest first. Each list is kept sorted as the row is scanned, which for a
+// small k is much cheaper than sorting the whole row.
+func nearestNeighborLists(matrix [][]float64, k int) [][]int {
+	lists := make([][]int, len(matrix))
+	for a, row := range matrix {
+		list := make([]int, 0, k)
+		for b, d := range row {
+			if b == a || len(list) == k && d >= row[list[k-1]] {
+				continue
+			}
+			if len(list) < k {
+				list = append(list, b)
+			}
+			i := len(list) - 1
+			for ; i > 0 && row[list[i-1]] > d; i-- {
+				list[i] = list[i-1]
+			}
+			list[i] = b
+		}
+		lists[a] = list
+	}
+	return lists
+}
+
+func twoOpt(dist DistanceFunc, initialTour []int, stop TwoOptOptions, o tspOptions) *TSPResult {
+	n := dist.N()
+	if n == 0 || len(initialTour) != n {
+		return nil
+	}
+	began := time.Now()
+	moves := 0
 
 	tour := make([]int, len(initialTour))
 	copy(tour, initialTour)
 
 	// Calculate initial distance
-	distance := calculateTourDistance(distanceMatrix, tour)
+	distance := tourDistance(dist, tour)
 
 	improved := true
 	iteration := 0
+	cancelled := func() bool { return stop.Context != nil && stop.Context.Err() != nil }
 
-	for improved && (maxIterations <= 0 || iteration < maxIterations) {
+	for improved && (stop.MaxIterations <= 0 || iteration < stop.MaxIterations) && !cancelled() {
 		improved = false
 		iteration++
+		before := distance
 
-		for i := 0; i < n-1; i++ {
+		for i := 0; i < n-1 && !cancelled(); i++ {
 			for j := i + 2; j < n; j++ {
 				// Try swapping edges (i, i+1) and (j, j+1)
 				// Calculate change in distance
-				delta := -distanceMatrix[tour[i]][tour[i+1]] -
-					distanceMatrix[tour[j]][tour[(j+1)%n]]
-				delta += distanceMatrix[tour[i]][tour[j]] +
-					distanceMatrix[tour[i+1]][tour[(j+1)%n]]
+				delta := -dist.At(tour[i], tour[i+1]) -
+					dist.At(tour[j], tour[(j+1)%n])
+				delta += dist.At(tour[i], tour[j]) +
+					dist.At(tour[i+1], tour[(j+1)%n])
 
 				if delta < -1e-10 { // improvement found
 					// Reverse the segment between i+1 and j
 					reverse(tour, i+1, j)
 					distance += delta
 					improved = true
+					moves++
 				}
 			}
 		}
+
+		if !o.report(iteration, distance, 0) {
+			break
+		}
+		if stop.MinImprovement > 0 && before > 0 && (before-distance)/before < stop.MinImprovement {
+			break
+		}
 	}
 
 	return &TSPResult{
 		Tour:     tour,
 		Distance: distance,
+		Stats: TSPStats{
+			Iterations:     iteration,
+			ImprovingMoves: moves,
+			WallTime:       time.Since(began),
+		},
+	}
+}
+
+// TSP2OptFunc is TSP2Opt for any DistanceFunc, such as a SymmetricMatrix.
+func TSP2OptFunc(dist DistanceFunc, initialTour []int, maxIterations int, opts ...TSPOption) *TSPResult {
+	return twoOpt(dist, initialTour, TwoOptOptions{MaxIterations: maxIterations}, newTSPOptions(opts, 1))
+}
+
+// TSPAsymmetric2h improves a tour for an asymmetric distance matrix.
+// TSP2Opt reverses tour segments, which changes the cost of every edge in the
+// segment when distanceMatrix[i][j] != distanceMatrix[j][i]. This solver only
+// uses moves that keep the direction of travel: Or-opt relocations of one to
+// three nodes and 3-opt segment moves that cut out a longer run of nodes and
+// reinsert it elsewhere unchanged. The first node of the tour stays in place.
+// Each iteration applies at most one improving move; a progress callback, if
+// given, is invoked between iterations.
+func TSPAsymmetric2h(distanceMatrix [][]float64, tour []int, maxIterations int, opts ...TSPOption) *TSPResult {
+	n := len(distanceMatrix)
+	if n == 0 || len(tour) != n {
+		return nil
+	}
+	began := time.Now()
+	o := newTSPOptions(opts, 1)
+	moves := 0
+
+	current := make([]int, n)
+	copy(current, tour)
+	distance := calculateTourDistance(distanceMatrix, current)
+
+	improved := true
+	iteration := 0
+	for improved && (maxIterations <= 0 || iteration < maxIterations) {
+		improved = false
+		iteration++
+
+		for segLen := 1; segLen < n-1 && !improved; segLen++ {
+			for i := 1; i+segLen <= n && !improved; i++ {
+				j := i + segLen - 1
+				prev := current[i-1]
+				next := current[(j+1)%n]
+				first := current[i]
+				last := current[j]
+				removeDelta := distanceMatrix[prev][next] -
+					distanceMatrix[prev][first] - distanceMatrix[last][next]
+
+				for p := 0; p < n; p++ {
+					if p >= i-1 && p <= j {
+						continue
+					}
+					a := current[p]
+					b := current[(p+1)%n]
+					delta := removeDelta - distanceMatrix[a][b] +
+						distanceMatrix[a][first] + distanceMatrix[last][b]
+					if delta < -1e-10 {
+						current = moveSegment(current, i, j, p)
+						distance += delta
+						improved = true
+						moves++
+						break
+					}
+				}
+			}
+		}
+
+		if !o.report(iteration, distance, 0) {
+			break
+		}
+	}
+
+	return &TSPResult{
+		Tour:     current,
+		Distance: distance,
+		Stats: TSPStats{
+			Iterations:     iteration,
+			ImprovingMoves: moves,
+			WallTime:       time.Since(began),
+		},
 	}
 }
 
+// moveSegment returns a copy of tour with tour[i..j] (inclusive) moved to sit
+// directly after tour[p], keeping the segment's direction.
+func moveSegment(tour []int, i, j, p int) []int {
+	out := make([]int, 0, len(tour))
+	segment := tour[i : j+1]
+	for k, node := range tour {
+		if k >= i && k <= j {
+			continue
+		}
+		out = append(out, node)
+		if k == p {
+			out = append(out, segment...)
+		}
+	}
+	return out
+}
+
 // TSPSimulatedAnnealing solves TSP using simulated annealing metaheuristic.
 // This is more robust for larger instances but slower.
-func TSPSimulatedAnnealing(distanceMatrix [][]float64, start int, iterations int, temperature float64, coolingRate float64) *TSPResult {
+// A progress callback, if given, is invoked every 1000 iterations by default.
+func TSPSimulatedAnnealing(distanceMatrix [][]float64, start int, iterations int, temperature float64, coolingRate float64, opts ...TSPOption) *TSPResult {
+	result, _ := TSPSimulatedAnnealingCtx(context.Background(), distanceMatrix, start, iterations, temperature, coolingRate, opts...)
+	return result
+}
+
+// ctxCheckInterval is how many iterations the context-aware solvers run
+// between checks of ctx.Err().
+const ctxCheckInterval = 1024
+
+// TSPSimulatedAnnealingCtx is TSPSimulatedAnnealing with cancellation. The
+// context is checked every ctxCheckInterval iterations; once it is done the
+// best tour found so far is returned together with ctx.Err().
+func TSPSimulatedAnnealingCtx(ctx context.Context, distanceMatrix [][]float64, start int, iterations int, temperature float64, coolingRate float64, opts ...TSPOption) (*TSPResult, error) {
 	n := len(distanceMatrix)
-	if n == 0 || start < 0 || start >= n {
-		return nil
+	if err := validateStart(start, n); err != nil {
+		return nil, err
 	}
+	began := time.Now()
+	o := newTSPOptions(opts, 1000)
+	moves := 0
 
 	// Create initial tour using nearest neighbor
 	current := TSPNearestNeighbor(distanceMatrix, start)
-	if current == nil {
-		return nil
-	}
 
 	best := &TSPResult{
 		Tour:     make([]int, len(current.Tour)),
@@ -129,7 +653,18 @@ func TSPSimulatedAnnealing(distanceMatrix [][]float64, start int, iterations int
 	temp := temperature
 	rng := rand.New(rand.NewSource(42))
 
-	for iter := 0; iter < iterations; iter++ {
+	var err error
+	iter := 0
+	for ; iter < iterations; iter++ {
+		if iter > 0 && !o.report(iter, best.Distance, temp) {
+			break
+		}
+		if iter%ctxCheckInterval == 0 {
+			if err = ctx.Err(); err != nil {
+				break
+			}
+		}
+
 		// Generate neighbor solution by swapping two random cities
 		i := rng.Intn(n)
 		j := rng.Intn(n)
@@ -152,6 +687,9 @@ func TSPSimulatedAnnealing(distanceMatrix [][]float64, start int, iterations int
 		if delta < 0 || rng.Float64() < math.Exp(-delta/temp) {
 			current.Tour = newTour
 			current.Distance = newDistance
+			if delta < 0 {
+				moves++
+			}
 
 			// Update best solution
 			if newDistance < best.Distance {
@@ -165,9 +703,492 @@ func TSPSimulatedAnnealing(distanceMatrix [][]float64, start int, iterations int
 		temp *= coolingRate
 	}
 
+	best.Stats = TSPStats{
+		Iterations:     iter,
+		ImprovingMoves: moves,
+		WallTime:       time.Since(began),
+	}
+	return best, err
+}
+
+// TSPGuidedLocalSearch solves the TSP with Guided Local Search on top of 2-opt.
+// Each iteration runs 2-opt to a local optimum under an augmented cost
+// d(i,j) + λ·α·p(i,j), where p counts how often an edge has been penalized and
+// α is the average edge length of the first local optimum. The tour edges with
+// the highest utility d(i,j) / (1 + p(i,j)) are then penalized, pushing the
+// search out of the local optimum. The best tour under the original costs is
+// returned. Results are deterministic for a given input. The matrix is assumed
+// to be symmetric. A progress callback, if given, is invoked between iterations.
+func TSPGuidedLocalSearch(distanceMatrix [][]float64, start int, iterations int, lambda float64, opts ...TSPOption) *TSPResult {
+	n := len(distanceMatrix)
+	initial := TSPNearestNeighbor(distanceMatrix, start)
+	if initial == nil {
+		return nil
+	}
+	began := time.Now()
+	o := newTSPOptions(opts, 1)
+
+	local := TSP2Opt(distanceMatrix, initial.Tour, 0)
+	best := &TSPResult{
+		Tour:     local.Tour,
+		Distance: calculateTourDistance(distanceMatrix, local.Tour),
+	}
+	alpha := lambda * best.Distance / float64(n)
+
+	augmented := make([][]float64, n)
+	penalties := make([][]int, n)
+	for i := range augmented {
+		augmented[i] = make([]float64, n)
+		copy(augmented[i], distanceMatrix[i])
+		penalties[i] = make([]int, n)
+	}
+
+	tour := local.Tour
+	moves := local.Stats.ImprovingMoves
+	iter := 0
+	for ; iter < iterations; iter++ {
+		// Penalize the tour edges with maximum utility.
+		maxUtility := math.Inf(-1)
+		for k := range tour {
+			a, b := tour[k], tour[(k+1)%n]
+			if u := distanceMatrix[a][b] / float64(1+penalties[a][b]); u > maxUtility {
+				maxUtility = u
+			}
+		}
+		for k := range tour {
+			a, b := tour[k], tour[(k+1)%n]
+			if distanceMatrix[a][b]/float64(1+penalties[a][b]) == maxUtility {
+				penalties[a][b]++
+				penalties[b][a]++
+				augmented[a][b] = distanceMatrix[a][b] + alpha*float64(penalties[a][b])
+				augmented[b][a] = distanceMatrix[b][a] + alpha*float64(penalties[b][a])
+			}
+		}
+
+		local = TSP2Opt(augmented, tour, 0)
+		tour = local.Tour
+		moves += local.Stats.ImprovingMoves
+		if d := calculateTourDistance(distanceMatrix, tour); d < best.Distance-1e-10 {
+			best.Tour = append([]int(nil), tour...)
+			best.Distance = d
+		}
+
+		if !o.report(iter+1, best.Distance, 0) {
+			iter++
+			break
+		}
+	}
+
+	best.Stats = TSPStats{
+		Iterations:     iter,
+		ImprovingMoves: moves,
+		WallTime:       time.Since(began),
+	}
 	return best
 }
 
+// maxExactNodes bounds the instance size accepted by TSPExact.
+const maxExactNodes = 16
+
+// TSPExact solves the TSP optimally with the Held–Karp dynamic program.
+// It runs in O(n²·2ⁿ) time and memory, so it is limited to instances of at
+// most 16 nodes and returns nil for larger ones. The tour starts at node 0.
+func TSPExact(distanceMatrix [][]float64) *TSPResult {
+	n := len(distanceMatrix)
+	if n == 0 || n > maxExactNodes {
+		return nil
+	}
+	began := time.Now()
+	if n == 1 {
+		return &TSPResult{Tour: []int{0}, Stats: TSPStats{WallTime: time.Since(began)}}
+	}
+
+	// cost[mask][j] is the cheapest path from node 0 through the nodes in mask
+	// (over nodes 1..n-1) ending at node j.
+	full := 1 << (n - 1)
+	cost := make([][]float64, full)
+	parent := make([][]int, full)
+	for mask := range cost {
+		cost[mask] = make([]float64, n)
+		parent[mask] = make([]int, n)
+		for j := range cost[mask] {
+			cost[mask][j] = math.Inf(1)
+			parent[mask][j] = -1
+		}
+	}
+	for j := 1; j < n; j++ {
+		cost[1<<(j-1)][j] = distanceMatrix[0][j]
+		parent[1<<(j-1)][j] = 0
+	}
+
+	for mask := 1; mask < full; mask++ {
+		for j := 1; j < n; j++ {
+			bit := 1 << (j - 1)
+			if mask&bit == 0 || math.IsInf(cost[mask][j], 1) {
+				continue
+			}
+			for k := 1; k < n; k++ {
+				kbit := 1 << (k - 1)
+				if mask&kbit != 0 {
+					continue
+				}
+				next := mask | kbit
+				alt := cost[mask][j] + distanceMatrix[j][k]
+				if alt < cost[next][k] {
+					cost[next][k] = alt
+					parent[next][k] = j
+				}
+			}
+		}
+	}
+
+	best := math.Inf(1)
+	last := -1
+	for j := 1; j < n; j++ {
+		total := cost[full-1][j] + distanceMatrix[j][0]
+		if total < best {
+			best = total
+			last = j
+		}
+	}
+	if last == -1 {
+		return nil
+	}
+
+	tour := make([]int, n)
+	mask := full - 1
+	for i := n - 1; i > 0; i-- {
+		tour[i] = last
+		prev := parent[mask][last]
+		mask &^= 1 << (last - 1)
+		last = prev
+	}
+
+	return &TSPResult{
+		Tour:     tour,
+		Distance: best,
+		Stats: TSPStats{
+			Iterations: full,
+			WallTime:   time.Since(began),
+		},
+	}
+}
+
+// lowerBoundIterations is the number of subgradient steps TSPLowerBound takes.
+const lowerBoundIterations = 100
+
+// TSPLowerBound returns the Held–Karp 1-tree lower bound on the length of an
+// optimal tour for a symmetric distance matrix. A 1-tree is a minimum spanning
+// tree over nodes 1..n-1 plus the two cheapest edges at node 0; every tour is a
+// 1-tree, so its cost bounds the optimum from below. The bound is tightened with
+// a fixed number of subgradient steps on node penalties.
+func TSPLowerBound(distanceMatrix [][]float64) float64 {
+	n := len(distanceMatrix)
+	switch {
+	case n < 2:
+		return 0
+	case n == 2:
+		return distanceMatrix[0][1] + distanceMatrix[1][0]
+	}
+
+	penalties := make([]float64, n)
+	degrees := make([]int, n)
+	best := math.Inf(-1)
+
+	var edgeSum float64
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			edgeSum += distanceMatrix[i][j]
+		}
+	}
+	step := edgeSum / float64(n*(n-1)/2) / 10
+
+	for iter := 0; iter < lowerBoundIterations; iter++ {
+		treeCost := oneTree(distanceMatrix, penalties, degrees)
+		var penaltySum float64
+		for _, p := range penalties {
+			penaltySum += p
+		}
+		bound := treeCost - 2*penaltySum
+		if bound > best {
+			best = bound
+		}
+
+		tour := true
+		for i := 0; i < n; i++ {
+			if degrees[i] != 2 {
+				tour = false
+			}
+			penalties[i] += step * float64(degrees[i]-2)
+		}
+		if tour {
+			break
+		}
+		step *= 0.95
+	}
+
+	return best
+}
+
+// oneTree computes the minimum 1-tree under the penalized costs
+// d[i][j] + p[i] + p[j] and records each node's degree.
+func oneTree(distanceMatrix [][]float64, penalties []float64, degrees []int) float64 {
+	n := len(distanceMatrix)
+	cost := func(i, j int) float64 {
+		return distanceMatrix[i][j] + penalties[i] + penalties[j]
+	}
+	for i := range degrees {
+		degrees[i] = 0
+	}
+
+	// Prim's algorithm over nodes 1..n-1.
+	inTree := make([]bool, n)
+	minEdge := make([]float64, n)
+	from := make([]int, n)
+	for i := range minEdge {
+		minEdge[i] = math.Inf(1)
+	}
+	minEdge[1] = 0
+	from[1] = -1
+
+	var total float64
+	for k := 1; k < n; k++ {
+		u := -1
+		for v := 1; v < n; v++ {
+			if !inTree[v] && (u == -1 || minEdge[v] < minEdge[u]) {
+				u = v
+			}
+		}
+		inTree[u] = true
+		if from[u] >= 0 {
+			total += minEdge[u]
+			degrees[u]++
+			degrees[from[u]]++
+		}
+		for v := 1; v < n; v++ {
+			if !inTree[v] && cost(u, v) < minEdge[v] {
+				minEdge[v] = cost(u, v)
+				from[v] = u
+			}
+		}
+	}
+
+	// Attach node 0 with its two cheapest edges.
+	first, second := -1, -1
+	for v := 1; v < n; v++ {
+		switch {
+		case first == -1 || cost(0, v) < cost(0, first):
+			first, second = v, first
+		case second == -1 || cost(0, v) < cost(0, second):
+			second = v
+		}
+	}
+	total += cost(0, first) + cost(0, second)
+	degrees[0] = 2
+	degrees[first]++
+	degrees[second]++
+
+	return total
+}
+
+// Gap returns the relative optimality gap of the tour against a lower bound,
+// (Distance - lowerBound) / lowerBound. A gap of 0.05 means the tour is at most
+// 5% longer than optimal. Returns +Inf when the bound is not positive.
+func (r *TSPResult) Gap(lowerBound float64) float64 {
+	if lowerBound <= 0 {
+		if r.Distance <= 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return (r.Distance - lowerBound) / lowerBound
+}
+
+// LegDistances returns the distance of every leg of the tour, read from the
+// matrix, including the leg back to the start. The legs sum to the tour
+// distance.
+func (r *TSPResult) LegDistances(matrix [][]float64) []float64 {
+	n := len(r.Tour)
+	if n == 0 {
+		return nil
+	}
+	legs := make([]float64, n)
+	for i := range r.Tour {
+		legs[i] = matrix[r.Tour[i]][r.Tour[(i+1)%n]]
+	}
+	return legs
+}
+
+// tourLegStepKm is the spacing, in kilometers, of the great-circle points
+// TourToGeoJSON places along each leg.
+const tourLegStepKm = 100.0
+
+// TourToGeoJSON returns a tour as a Feature for drawing. Each leg follows the
+// great circle between its points, and the route is split at the antimeridian
+// like GreatCircleGeoJSON, so the geometry is a LineString or MultiLineString.
+// When closed is true the route returns to the first point.
+//
+// The feature has a "distance" property with the total great-circle length in
+// kilometers and a "legs" property with the length of every leg.
+func TourToGeoJSON(tour []int, points []Position, closed bool) (Feature, error) {
+	if len(tour) < 2 {
+		return Feature{}, errors.New("tour must have at least 2 nodes")
+	}
+	for _, node := range tour {
+		if node < 0 || node >= len(points) {
+			return Feature{}, fmt.Errorf("tour node %d is out of range", node)
+		}
+	}
+
+	stops := len(tour) - 1
+	if closed {
+		stops = len(tour)
+	}
+	legs := make([]float64, 0, stops)
+	total := 0.0
+	var coords []Position
+	for i := 0; i < stops; i++ {
+		lat1, lon1 := positionLatLon(points[tour[i]])
+		lat2, lon2 := positionLatLon(points[tour[(i+1)%len(tour)]])
+		d := GreatCircleDistance(lat1, lon1, lat2, lon2)
+		legs = append(legs, d)
+		total += d
+
+		leg := greatCircleCoordsByDistance(lat1, lon1, lat2, lon2, tourLegStepKm)
+		if len(coords) > 0 {
+			leg = leg[1:]
+		}
+		coords = append(coords, leg...)
+	}
+
+	geom, err := splitAntimeridian(coords)
+	if err != nil {
+		return Feature{}, err
+	}
+	feature := NewFeature(geom)
+	feature.Properties = map[string]interface{}{
+		"distance": total,
+		"legs":     legs,
+	}
+	return feature, nil
+}
+
+// ValidateDistanceMatrix checks that a distance matrix is non-empty and square,
+// that its diagonal is zero, and that no entry is negative or NaN.
+// Positive infinity is accepted and means the pair is not connected.
+func ValidateDistanceMatrix(matrix [][]float64) error {
+	n := len(matrix)
+	if n == 0 {
+		return errors.New("distance matrix is empty")
+	}
+	for i, row := range matrix {
+		if len(row) != n {
+			return fmt.Errorf("distance matrix row %d has %d entries, want %d", i, len(row), n)
+		}
+		for j, d := range row {
+			switch {
+			case math.IsNaN(d):
+				return fmt.Errorf("distance matrix entry [%d][%d] is NaN", i, j)
+			case d < 0:
+				return fmt.Errorf("distance matrix entry [%d][%d] is negative (%v)", i, j, d)
+			case i == j && d != 0:
+				return fmt.Errorf("distance matrix diagonal entry [%d][%d] is %v, want 0", i, j, d)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateTour checks that tour is a permutation of the nodes 0..n-1.
+func ValidateTour(tour []int, n int) error {
+	if len(tour) != n {
+		return fmt.Errorf("tour has %d nodes, want %d", len(tour), n)
+	}
+	seen := make([]bool, n)
+	for i, node := range tour {
+		if node < 0 || node >= n {
+			return fmt.Errorf("tour position %d references node %d, out of range [0, %d)", i, node, n)
+		}
+		if seen[node] {
+			return fmt.Errorf("tour position %d repeats node %d", i, node)
+		}
+		seen[node] = true
+	}
+	return nil
+}
+
+// TSPNearestNeighborChecked is like TSPNearestNeighbor but validates its inputs
+// and reports problems as errors instead of returning nil or panicking.
+func TSPNearestNeighborChecked(distanceMatrix [][]float64, start int) (*TSPResult, error) {
+	if err := ValidateDistanceMatrix(distanceMatrix); err != nil {
+		return nil, err
+	}
+	if err := validateStart(start, len(distanceMatrix)); err != nil {
+		return nil, err
+	}
+	return TSPNearestNeighbor(distanceMatrix, start), nil
+}
+
+// TSP2OptChecked is like TSP2Opt but validates the matrix and the initial tour
+// and reports problems as errors instead of returning nil or panicking.
+func TSP2OptChecked(distanceMatrix [][]float64, initialTour []int, maxIterations int, opts ...TSPOption) (*TSPResult, error) {
+	if err := ValidateDistanceMatrix(distanceMatrix); err != nil {
+		return nil, err
+	}
+	if !IsSymmetric(distanceMatrix, 0) {
+		return nil, errors.New("distance matrix is not symmetric; use TSPAsymmetric2h")
+	}
+	if err := ValidateTour(initialTour, len(distanceMatrix)); err != nil {
+		return nil, err
+	}
+	return TSP2Opt(distanceMatrix, initialTour, maxIterations, opts...), nil
+}
+
+// TSPSimulatedAnnealingChecked is like TSPSimulatedAnnealing but validates its
+// inputs and reports problems as errors instead of returning nil or panicking.
+func TSPSimulatedAnnealingChecked(distanceMatrix [][]float64, start int, iterations int, temperature float64, coolingRate float64, opts ...TSPOption) (*TSPResult, error) {
+	if err := ValidateDistanceMatrix(distanceMatrix); err != nil {
+		return nil, err
+	}
+	if err := validateStart(start, len(distanceMatrix)); err != nil {
+		return nil, err
+	}
+	if temperature <= 0 {
+		return nil, fmt.Errorf("temperature must be greater than 0, got %v", temperature)
+	}
+	if coolingRate <= 0 || coolingRate > 1 {
+		return nil, fmt.Errorf("cooling rate must be in (0, 1], got %v", coolingRate)
+	}
+	return TSPSimulatedAnnealing(distanceMatrix, start, iterations, temperature, coolingRate, opts...), nil
+}
+
+// IsSymmetric reports whether matrix is square and matrix[i][j] is within tol
+// of matrix[j][i] for every pair of nodes.
+func IsSymmetric(matrix [][]float64, tol float64) bool {
+	n := len(matrix)
+	for i := range matrix {
+		if len(matrix[i]) != n {
+			return false
+		}
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if math.Abs(matrix[i][j]-matrix[j][i]) > tol {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validateStart checks that start is a valid node index for n nodes.
+func validateStart(start, n int) error {
+	if start < 0 || start >= n {
+		return fmt.Errorf("start node %d out of range [0, %d)", start, n)
+	}
+	return nil
+}
+
 // calculateTourDistance computes the total distance of a tour
 func calculateTourDistance(distanceMatrix [][]float64, tour []int) float64 {
 	distance := 0.0
@@ -181,6 +1202,18 @@ func calculateTourDistance(distanceMatrix [][]float64, tour []int) float64 {
 	return distance
 }
 
+// tourDistance is calculateTourDistance for any DistanceFunc.
+func tourDistance(dist DistanceFunc, tour []int) float64 {
+	distance := 0.0
+	for i := 0; i < len(tour)-1; i++ {
+		distance += dist.At(tour[i], tour[i+1])
+	}
+	if len(tour) > 0 {
+		distance += dist.At(tour[len(tour)-1], tour[0])
+	}
+	return distance
+}
+
 // reverse reverses a segment of the tour between indices i and j (inclusive)
 func reverse(tour []int, i, j int) {
 	for i < j {
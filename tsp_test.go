@@ -1,8 +1,13 @@
 package geo
 
 import (
+	"context"
 	"math"
+	"math/rand"
+	"reflect"
+	"sort"
 	"testing"
+	"time"
 )
 
 func TestTSPNearestNeighbor(t *testing.T) {
@@ -174,6 +179,90 @@ func TestReverse(t *testing.T) {
 	}
 }
 
+func TestTSPGreedyEdge(t *testing.T) {
+	distanceMatrix := [][]float64{
+		{0, 2, 9, 10},
+		{2, 0, 6, 4},
+		{9, 6, 0, 3},
+		{10, 4, 3, 0},
+	}
+	result := TSPGreedyEdge(distanceMatrix)
+	if result == nil || !reflect.DeepEqual(result.Tour, []int{0, 1, 3, 2}) || result.Distance != 18 {
+		t.Errorf("TSPGreedyEdge() = %+v, want tour [0 1 3 2] of length 18", result)
+	}
+
+	// Greedy edge does not win on every instance, but it should on the whole.
+	var greedyTotal, nearestTotal float64
+	var matrix [][]float64
+	for seed := int64(0); seed < 10; seed++ {
+		points := randomPositions(rand.New(rand.NewSource(seed)), 150)
+		matrix = denseMatrixFromPoints(points)
+		greedy := TSPGreedyEdge(matrix)
+		if err := ValidateTour(greedy.Tour, len(points)); err != nil {
+			t.Fatalf("seed %d: invalid tour: %v", seed, err)
+		}
+		if got := calculateTourDistance(matrix, greedy.Tour); math.Abs(got-greedy.Distance) > 1e-6 {
+			t.Errorf("seed %d: Distance = %v, tour measures %v", seed, greedy.Distance, got)
+		}
+		greedyTotal += greedy.Distance
+		nearestTotal += TSPNearestNeighbor(matrix, 0).Distance
+	}
+	if greedyTotal >= 0.97*nearestTotal {
+		t.Errorf("greedy tours total %v km, want at least 3%% below nearest neighbor's %v km", greedyTotal, nearestTotal)
+	}
+
+	for n := 1; n <= 3; n++ {
+		if got := TSPGreedyEdge(matrix[:n:n]); got == nil || ValidateTour(got.Tour, n) != nil {
+			t.Errorf("TSPGreedyEdge() on %d nodes = %+v", n, got)
+		}
+	}
+	if TSPGreedyEdge(nil) != nil {
+		t.Error("TSPGreedyEdge(nil) should be nil")
+	}
+}
+
+func TestTSPClarkeWright(t *testing.T) {
+	// A depot in the middle of four customers set out in a square.
+	points := [][2]float64{{0, 0}, {-1, -1}, {1, -1}, {1, 1}, {-1, 1}}
+	matrix := make([][]float64, len(points))
+	for i := range matrix {
+		matrix[i] = make([]float64, len(points))
+		for j := range matrix[i] {
+			matrix[i][j] = math.Hypot(points[i][0]-points[j][0], points[i][1]-points[j][1])
+		}
+	}
+	result := TSPClarkeWright(matrix, 0)
+	if result == nil || result.Tour[0] != 0 {
+		t.Fatalf("TSPClarkeWright() = %+v, want a tour from the depot", result)
+	}
+	if err := ValidateTour(result.Tour, len(points)); err != nil {
+		t.Fatalf("invalid tour: %v", err)
+	}
+	if nearest := TSPNearestNeighbor(matrix, 0); result.Distance > nearest.Distance+1e-9 {
+		t.Errorf("distance %v, want no worse than nearest neighbor's %v", result.Distance, nearest.Distance)
+	}
+	// The best tour runs around three sides and out to the depot and back.
+	if want := 6 + 2*math.Sqrt2; math.Abs(result.Distance-want) > 1e-9 {
+		t.Errorf("distance %v, want %v", result.Distance, want)
+	}
+
+	geo := denseMatrixFromPoints(randomPositions(rand.New(rand.NewSource(4)), 120))
+	big := TSPClarkeWright(geo, 17)
+	if err := ValidateTour(big.Tour, 120); err != nil || big.Tour[0] != 17 {
+		t.Fatalf("tour %v, err %v, want a valid tour from 17", big.Tour, err)
+	}
+	if got := calculateTourDistance(geo, big.Tour); math.Abs(got-big.Distance) > 1e-6 {
+		t.Errorf("Distance = %v, tour measures %v", big.Distance, got)
+	}
+
+	if TSPClarkeWright(matrix, 5) != nil {
+		t.Error("TSPClarkeWright() with depot out of range should be nil")
+	}
+	if got := TSPClarkeWright(matrix[:1], 0); got == nil || !reflect.DeepEqual(got.Tour, []int{0}) {
+		t.Errorf("TSPClarkeWright() on one node = %+v", got)
+	}
+}
+
 func TestTSPWithGeographicDistances(t *testing.T) {
 	// Test with actual geographic coordinates
 	locations := []struct {
@@ -231,3 +320,732 @@ func equalIntSlice(a, b []int) bool {
 	}
 	return true
 }
+
+func TestValidateDistanceMatrix(t *testing.T) {
+	tests := []struct {
+		name    string
+		matrix  [][]float64
+		wantErr string
+	}{
+		{
+			name:    "valid",
+			matrix:  [][]float64{{0, 1}, {1, 0}},
+			wantErr: "",
+		},
+		{
+			name:    "unreachable pair",
+			matrix:  [][]float64{{0, math.Inf(1)}, {1, 0}},
+			wantErr: "",
+		},
+		{
+			name:    "empty",
+			matrix:  nil,
+			wantErr: "distance matrix is empty",
+		},
+		{
+			name:    "ragged",
+			matrix:  [][]float64{{0, 1}, {1}},
+			wantErr: "distance matrix row 1 has 1 entries, want 2",
+		},
+		{
+			name:    "non-square",
+			matrix:  [][]float64{{0, 1, 2}, {1, 0, 3}},
+			wantErr: "distance matrix row 0 has 3 entries, want 2",
+		},
+		{
+			name:    "negative",
+			matrix:  [][]float64{{0, 1}, {-1, 0}},
+			wantErr: "distance matrix entry [1][0] is negative (-1)",
+		},
+		{
+			name:    "NaN",
+			matrix:  [][]float64{{0, math.NaN()}, {1, 0}},
+			wantErr: "distance matrix entry [0][1] is NaN",
+		},
+		{
+			name:    "non-zero diagonal",
+			matrix:  [][]float64{{0, 1}, {1, 2}},
+			wantErr: "distance matrix diagonal entry [1][1] is 2, want 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDistanceMatrix(tt.matrix)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateDistanceMatrix() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ValidateDistanceMatrix() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTour(t *testing.T) {
+	tests := []struct {
+		name    string
+		tour    []int
+		wantErr string
+	}{
+		{"valid", []int{2, 0, 1}, ""},
+		{"too short", []int{0, 1}, "tour has 2 nodes, want 3"},
+		{"out of range", []int{0, 1, 3}, "tour position 2 references node 3, out of range [0, 3)"},
+		{"negative", []int{0, -1, 2}, "tour position 1 references node -1, out of range [0, 3)"},
+		{"duplicate", []int{0, 1, 1}, "tour position 2 repeats node 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTour(tt.tour, 3)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateTour() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ValidateTour() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTSPCheckedEntryPoints(t *testing.T) {
+	distanceMatrix := [][]float64{
+		{0, 10, 15, 20},
+		{10, 0, 35, 25},
+		{15, 35, 0, 30},
+		{20, 25, 30, 0},
+	}
+	ragged := [][]float64{{0, 1}, {1}}
+
+	if _, err := TSPNearestNeighborChecked(ragged, 0); err == nil {
+		t.Error("TSPNearestNeighborChecked() with ragged matrix: expected error")
+	}
+	if _, err := TSPNearestNeighborChecked(distanceMatrix, 4); err == nil {
+		t.Error("TSPNearestNeighborChecked() with out-of-range start: expected error")
+	}
+	nn, err := TSPNearestNeighborChecked(distanceMatrix, 0)
+	if err != nil {
+		t.Fatalf("TSPNearestNeighborChecked() error = %v", err)
+	}
+
+	if _, err := TSP2OptChecked(distanceMatrix, []int{0, 1, 2}, 100); err == nil {
+		t.Error("TSP2OptChecked() with short tour: expected error")
+	}
+	if _, err := TSP2OptChecked(distanceMatrix, []int{0, 1, 2, 7}, 100); err == nil {
+		t.Error("TSP2OptChecked() with out-of-range city: expected error")
+	}
+	improved, err := TSP2OptChecked(distanceMatrix, nn.Tour, 100)
+	if err != nil {
+		t.Fatalf("TSP2OptChecked() error = %v", err)
+	}
+	if improved.Distance > nn.Distance {
+		t.Errorf("2-opt should not worsen the solution")
+	}
+
+	if _, err := TSPSimulatedAnnealingChecked(distanceMatrix, 0, 100, 0, 0.95); err == nil {
+		t.Error("TSPSimulatedAnnealingChecked() with zero temperature: expected error")
+	}
+	if _, err := TSPSimulatedAnnealingChecked(distanceMatrix, 0, 100, 100, 1.5); err == nil {
+		t.Error("TSPSimulatedAnnealingChecked() with cooling rate > 1: expected error")
+	}
+	if _, err := TSPSimulatedAnnealingChecked(distanceMatrix, 0, 100, 100, 0.95); err != nil {
+		t.Errorf("TSPSimulatedAnnealingChecked() error = %v", err)
+	}
+}
+
+func TestTSP2OptRejectsShortTour(t *testing.T) {
+	distanceMatrix := [][]float64{
+		{0, 2, 9, 10},
+		{2, 0, 6, 4},
+		{9, 6, 0, 8},
+		{10, 4, 8, 0},
+	}
+	if result := TSP2Opt(distanceMatrix, []int{0, 2, 1}, 100); result != nil {
+		t.Errorf("TSP2Opt() with short tour = %v, want nil", result)
+	}
+}
+
+func TestIsSymmetric(t *testing.T) {
+	symmetric := [][]float64{
+		{0, 10, 15},
+		{10, 0, 35},
+		{15, 35, 0},
+	}
+	if !IsSymmetric(symmetric, 0) {
+		t.Error("IsSymmetric() = false, want true")
+	}
+
+	nearly := [][]float64{
+		{0, 10},
+		{10.001, 0},
+	}
+	if IsSymmetric(nearly, 0) {
+		t.Error("IsSymmetric() tol 0 = true, want false")
+	}
+	if !IsSymmetric(nearly, 0.01) {
+		t.Error("IsSymmetric() tol 0.01 = false, want true")
+	}
+
+	if IsSymmetric([][]float64{{0, 1}, {1}}, 0) {
+		t.Error("IsSymmetric() on ragged matrix = true, want false")
+	}
+}
+
+func TestTSPAsymmetric2h(t *testing.T) {
+	// One-way costs: reversing a segment changes the cost of every edge in it.
+	distanceMatrix := [][]float64{
+		{0, 15, 15, 14, 7},
+		{16, 0, 17, 8, 18},
+		{9, 9, 0, 9, 8},
+		{20, 9, 13, 0, 7},
+		{12, 11, 11, 15, 0},
+	}
+	initialTour := []int{0, 1, 2, 3, 4}
+
+	naive := TSP2Opt(distanceMatrix, initialTour, 100)
+	if math.Abs(naive.Distance-calculateTourDistance(distanceMatrix, naive.Tour)) < 1e-9 {
+		t.Fatalf("expected TSP2Opt to misreport distance on an asymmetric matrix")
+	}
+
+	if _, err := TSP2OptChecked(distanceMatrix, initialTour, 100); err == nil {
+		t.Error("TSP2OptChecked() with asymmetric matrix: expected error")
+	}
+
+	result := TSPAsymmetric2h(distanceMatrix, initialTour, 100)
+	if result == nil {
+		t.Fatal("TSPAsymmetric2h returned nil")
+	}
+	if err := ValidateTour(result.Tour, len(distanceMatrix)); err != nil {
+		t.Fatalf("invalid tour: %v", err)
+	}
+	if result.Tour[0] != 0 {
+		t.Errorf("tour should start at city 0, got %d", result.Tour[0])
+	}
+	recomputed := calculateTourDistance(distanceMatrix, result.Tour)
+	if math.Abs(result.Distance-recomputed) > 1e-9 {
+		t.Errorf("reported distance %v, recomputed %v", result.Distance, recomputed)
+	}
+	if initial := calculateTourDistance(distanceMatrix, initialTour); result.Distance > initial {
+		t.Errorf("distance %v worse than initial %v", result.Distance, initial)
+	}
+}
+
+func TestTSPAsymmetric2hRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	for trial := 0; trial < 20; trial++ {
+		n := 6 + rng.Intn(6)
+		matrix := make([][]float64, n)
+		for i := range matrix {
+			matrix[i] = make([]float64, n)
+			for j := range matrix[i] {
+				if i != j {
+					matrix[i][j] = 1 + rng.Float64()*100
+				}
+			}
+		}
+		tour := rng.Perm(n)
+
+		var reported []float64
+		progress := WithTSPProgress(func(_ int, distance, _ float64) bool {
+			reported = append(reported, distance)
+			return true
+		}, 1)
+		result := TSPAsymmetric2h(matrix, tour, 0, progress)
+		if err := ValidateTour(result.Tour, n); err != nil {
+			t.Fatalf("trial %d: invalid tour: %v", trial, err)
+		}
+		recomputed := calculateTourDistance(matrix, result.Tour)
+		if math.Abs(result.Distance-recomputed) > 1e-9 {
+			t.Errorf("trial %d: reported distance %v, recomputed %v", trial, result.Distance, recomputed)
+		}
+		if len(reported) != result.Stats.Iterations || math.Abs(reported[len(reported)-1]-recomputed) > 1e-9 {
+			t.Errorf("trial %d: progress reported %v over %d iterations, want a last distance of %v", trial, reported, result.Stats.Iterations, recomputed)
+		}
+		if initial := calculateTourDistance(matrix, tour); result.Distance > initial+1e-9 {
+			t.Errorf("trial %d: distance %v worse than initial %v", trial, result.Distance, initial)
+		}
+	}
+}
+
+func TestTSP2OptNeighborList(t *testing.T) {
+	matrix := randomSymmetricMatrix(rand.New(rand.NewSource(7)), 200)
+	initial := rand.New(rand.NewSource(8)).Perm(200)
+	result := TSP2OptNeighborList(matrix, initial, 8, 0)
+	if result == nil {
+		t.Fatal("TSP2OptNeighborList returned nil")
+	}
+	if err := ValidateTour(result.Tour, 200); err != nil {
+		t.Fatalf("invalid tour: %v", err)
+	}
+	if got := calculateTourDistance(matrix, result.Tour); math.Abs(got-result.Distance) > 1e-6 {
+		t.Errorf("Distance = %v, tour measures %v", result.Distance, got)
+	}
+	before := calculateTourDistance(matrix, initial)
+	if result.Distance > before/3 {
+		t.Errorf("distance %v, want well below the initial %v", result.Distance, before)
+	}
+	full := TSP2Opt(matrix, initial, 0)
+	if result.Distance > 1.1*full.Distance {
+		t.Errorf("distance %v, want within 10%% of full 2-opt's %v", result.Distance, full.Distance)
+	}
+	if result.Stats.ImprovingMoves == 0 || result.Stats.Iterations == 0 {
+		t.Errorf("Stats = %+v, want moves and passes counted", result.Stats)
+	}
+
+	for a, list := range nearestNeighborLists(matrix, 8) {
+		others := make([]int, 0, 199)
+		for b := range matrix {
+			if b != a {
+				others = append(others, b)
+			}
+		}
+		sort.Slice(others, func(x, y int) bool { return matrix[a][others[x]] < matrix[a][others[y]] })
+		if !reflect.DeepEqual(list, others[:8]) {
+			t.Fatalf("neighbors of %d = %v, want %v", a, list, others[:8])
+		}
+	}
+
+	if TSP2OptNeighborList(matrix, initial[:10], 8, 0) != nil {
+		t.Error("TSP2OptNeighborList() with short tour, want nil")
+	}
+}
+
+func randomSymmetricMatrix(rng *rand.Rand, n int) [][]float64 {
+	points := make([][2]float64, n)
+	for i := range points {
+		points[i] = [2]float64{rng.Float64() * 100, rng.Float64() * 100}
+	}
+	matrix := make([][]float64, n)
+	for i := range matrix {
+		matrix[i] = make([]float64, n)
+		for j := range matrix[i] {
+			matrix[i][j] = math.Hypot(points[i][0]-points[j][0], points[i][1]-points[j][1])
+		}
+	}
+	return matrix
+}
+
+func TestTSPProgressCallback(t *testing.T) {
+	matrix := randomSymmetricMatrix(rand.New(rand.NewSource(1)), 40)
+	initial := rand.New(rand.NewSource(2)).Perm(40)
+
+	t.Run("2-opt fires once per pass", func(t *testing.T) {
+		calls := 0
+		result := TSP2Opt(matrix, initial, 0, WithTSPProgress(func(iteration int, best, temp float64) bool {
+			calls++
+			if iteration != calls {
+				t.Errorf("iteration = %d, want %d", iteration, calls)
+			}
+			if temp != 0 {
+				t.Errorf("temperature = %v, want 0", temp)
+			}
+			return true
+		}, 0))
+		if calls == 0 || calls != result.Stats.Iterations {
+			t.Errorf("callback calls = %d, want %d", calls, result.Stats.Iterations)
+		}
+		if result.Stats.ImprovingMoves == 0 {
+			t.Error("expected improving moves on a random tour")
+		}
+	})
+
+	t.Run("2-opt abort", func(t *testing.T) {
+		full := TSP2Opt(matrix, initial, 0)
+		aborted := TSP2Opt(matrix, initial, 0, WithTSPProgress(func(int, float64, float64) bool {
+			return false
+		}, 0))
+		if aborted.Stats.Iterations != 1 {
+			t.Errorf("iterations = %d, want 1", aborted.Stats.Iterations)
+		}
+		if full.Stats.Iterations <= 1 {
+			t.Fatalf("full run only took %d passes", full.Stats.Iterations)
+		}
+		if err := ValidateTour(aborted.Tour, len(matrix)); err != nil {
+			t.Errorf("aborted tour invalid: %v", err)
+		}
+		if math.Abs(aborted.Distance-calculateTourDistance(matrix, aborted.Tour)) > 1e-6 {
+			t.Errorf("aborted distance %v does not match tour", aborted.Distance)
+		}
+	})
+
+	t.Run("annealing interval and abort", func(t *testing.T) {
+		var iterations []int
+		result := TSPSimulatedAnnealing(matrix, 0, 10000, 100, 0.999, WithTSPProgress(func(iteration int, best, temp float64) bool {
+			iterations = append(iterations, iteration)
+			if temp <= 0 || temp >= 100 {
+				t.Errorf("temperature = %v, want in (0, 100)", temp)
+			}
+			return iteration < 3000
+		}, 500))
+		want := []int{500, 1000, 1500, 2000, 2500, 3000}
+		if !equalIntSlice(iterations, want) {
+			t.Errorf("callback iterations = %v, want %v", iterations, want)
+		}
+		if result.Stats.Iterations != 3000 {
+			t.Errorf("stats iterations = %d, want 3000", result.Stats.Iterations)
+		}
+		if err := ValidateTour(result.Tour, len(matrix)); err != nil {
+			t.Errorf("aborted tour invalid: %v", err)
+		}
+	})
+
+	t.Run("asymmetric abort", func(t *testing.T) {
+		result := TSPAsymmetric2h(matrix, initial, 0, WithTSPProgress(func(int, float64, float64) bool {
+			return false
+		}, 0))
+		if result.Stats.Iterations != 1 {
+			t.Errorf("iterations = %d, want 1", result.Stats.Iterations)
+		}
+		if err := ValidateTour(result.Tour, len(matrix)); err != nil {
+			t.Errorf("aborted tour invalid: %v", err)
+		}
+	})
+}
+
+func TestTSPExact(t *testing.T) {
+	distanceMatrix := [][]float64{
+		{0, 10, 15, 20},
+		{10, 0, 35, 25},
+		{15, 35, 0, 30},
+		{20, 25, 30, 0},
+	}
+	result := TSPExact(distanceMatrix)
+	if result == nil {
+		t.Fatal("TSPExact returned nil")
+	}
+	if math.Abs(result.Distance-80) > 1e-9 {
+		t.Errorf("optimal distance = %v, want 80", result.Distance)
+	}
+	if math.Abs(calculateTourDistance(distanceMatrix, result.Tour)-result.Distance) > 1e-9 {
+		t.Errorf("tour %v does not match distance %v", result.Tour, result.Distance)
+	}
+
+	rng := rand.New(rand.NewSource(3))
+	for trial := 0; trial < 5; trial++ {
+		matrix := randomSymmetricMatrix(rng, 7)
+		exact := TSPExact(matrix)
+		bestPerm := math.Inf(1)
+		permute([]int{1, 2, 3, 4, 5, 6}, 0, func(p []int) {
+			tour := append([]int{0}, p...)
+			if d := calculateTourDistance(matrix, tour); d < bestPerm {
+				bestPerm = d
+			}
+		})
+		if math.Abs(exact.Distance-bestPerm) > 1e-9 {
+			t.Errorf("trial %d: TSPExact = %v, brute force = %v", trial, exact.Distance, bestPerm)
+		}
+	}
+
+	if TSPExact(randomSymmetricMatrix(rng, maxExactNodes+1)) != nil {
+		t.Error("TSPExact should refuse instances above the size limit")
+	}
+}
+
+func TestTSPLowerBound(t *testing.T) {
+	matrices := [][][]float64{
+		{
+			{0, 10, 15, 20},
+			{10, 0, 35, 25},
+			{15, 35, 0, 30},
+			{20, 25, 30, 0},
+		},
+		{
+			{0, 2, 9, 10},
+			{2, 0, 6, 4},
+			{9, 6, 0, 8},
+			{10, 4, 8, 0},
+		},
+	}
+	for i, matrix := range matrices {
+		bound := TSPLowerBound(matrix)
+		optimum := TSPExact(matrix)
+		if bound <= 0 || bound > optimum.Distance+1e-9 {
+			t.Errorf("matrix %d: bound = %v, optimum = %v", i, bound, optimum.Distance)
+		}
+		if gap := optimum.Gap(bound); gap < 0 {
+			t.Errorf("matrix %d: gap = %v, want >= 0", i, gap)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(4))
+	for trial := 0; trial < 10; trial++ {
+		matrix := randomSymmetricMatrix(rng, 30)
+		bound := TSPLowerBound(matrix)
+		result := TSP2Opt(matrix, TSPNearestNeighbor(matrix, 0).Tour, 0)
+		gap := result.Gap(bound)
+		if gap < -1e-9 || gap > 0.5 {
+			t.Errorf("trial %d: gap = %v, want in [0, 0.5]", trial, gap)
+		}
+	}
+}
+
+func permute(a []int, k int, visit func([]int)) {
+	if k == len(a) {
+		visit(a)
+		return
+	}
+	for i := k; i < len(a); i++ {
+		a[k], a[i] = a[i], a[k]
+		permute(a, k+1, visit)
+		a[k], a[i] = a[i], a[k]
+	}
+}
+
+func TestTSPGuidedLocalSearch(t *testing.T) {
+	// 2-opt from the nearest-neighbor tour gets stuck about 11% above the
+	// optimum on this instance.
+	matrix := randomSymmetricMatrix(rand.New(rand.NewSource(12)), 12)
+	stuck := TSP2Opt(matrix, TSPNearestNeighbor(matrix, 0).Tour, 0)
+	optimum := TSPExact(matrix)
+	if stuck.Distance <= optimum.Distance+1e-6 {
+		t.Fatalf("expected 2-opt to be stuck in a local optimum")
+	}
+
+	result := TSPGuidedLocalSearch(matrix, 0, 100, 0.3)
+	if result == nil {
+		t.Fatal("TSPGuidedLocalSearch returned nil")
+	}
+	if err := ValidateTour(result.Tour, len(matrix)); err != nil {
+		t.Fatalf("invalid tour: %v", err)
+	}
+	if result.Tour[0] != 0 {
+		t.Errorf("tour should start at city 0, got %d", result.Tour[0])
+	}
+	if math.Abs(result.Distance-calculateTourDistance(matrix, result.Tour)) > 1e-9 {
+		t.Errorf("reported distance %v does not match tour", result.Distance)
+	}
+	if result.Distance >= stuck.Distance {
+		t.Errorf("GLS distance %v did not improve on 2-opt %v", result.Distance, stuck.Distance)
+	}
+	if math.Abs(result.Distance-optimum.Distance) > 1e-6 {
+		t.Errorf("GLS distance %v, optimum %v", result.Distance, optimum.Distance)
+	}
+
+	again := TSPGuidedLocalSearch(matrix, 0, 100, 0.3)
+	if !equalIntSlice(again.Tour, result.Tour) {
+		t.Errorf("GLS is not deterministic: %v vs %v", again.Tour, result.Tour)
+	}
+}
+
+func TestTSPGuidedLocalSearchConsistency(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+	for trial := 0; trial < 10; trial++ {
+		matrix := randomSymmetricMatrix(rng, 25)
+		result := TSPGuidedLocalSearch(matrix, trial%25, 50, 0.3)
+		if err := ValidateTour(result.Tour, len(matrix)); err != nil {
+			t.Fatalf("trial %d: invalid tour: %v", trial, err)
+		}
+		if math.Abs(result.Distance-calculateTourDistance(matrix, result.Tour)) > 1e-9 {
+			t.Errorf("trial %d: reported distance %v does not match tour", trial, result.Distance)
+		}
+	}
+}
+
+func TestTSPResultLegDistances(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	m := randomSymmetricMatrix(rng, 7)
+	result := TSPNearestNeighbor(m, 0)
+	legs := result.LegDistances(m)
+	if len(legs) != len(result.Tour) {
+		t.Fatalf("legs = %d, want %d", len(legs), len(result.Tour))
+	}
+	sum := 0.0
+	for _, d := range legs {
+		sum += d
+	}
+	if math.Abs(sum-result.Distance) > 1e-9 {
+		t.Errorf("sum of legs = %v, want %v", sum, result.Distance)
+	}
+}
+
+func TestTourToGeoJSON(t *testing.T) {
+	points := []Position{
+		{-0.1278, 51.5074}, // London
+		{2.3522, 48.8566},  // Paris
+		{13.4050, 52.5200}, // Berlin
+	}
+	tour := []int{0, 1, 2}
+
+	for _, closed := range []bool{false, true} {
+		feature, err := TourToGeoJSON(tour, points, closed)
+		if err != nil {
+			t.Fatalf("TourToGeoJSON(closed=%v) error = %v", closed, err)
+		}
+		line, ok := feature.Geometry.(LineString)
+		if !ok {
+			t.Fatalf("geometry = %T, want LineString", feature.Geometry)
+		}
+		legs := feature.Properties["legs"].([]float64)
+		wantLegs := 2
+		if closed {
+			wantLegs = 3
+		}
+		if len(legs) != wantLegs {
+			t.Fatalf("legs = %d, want %d", len(legs), wantLegs)
+		}
+		sum := 0.0
+		for _, d := range legs {
+			sum += d
+		}
+		if total := feature.Properties["distance"].(float64); math.Abs(sum-total) > 1e-9 {
+			t.Errorf("sum of legs = %v, want %v", sum, total)
+		}
+		first, last := line.Coordinates[0], line.Coordinates[len(line.Coordinates)-1]
+		if !reflect.DeepEqual(first, points[0]) {
+			t.Errorf("first = %v, want %v", first, points[0])
+		}
+		if closed && !reflect.DeepEqual(last, points[0]) {
+			t.Errorf("closed route ends at %v, want %v", last, points[0])
+		}
+		if !closed && !reflect.DeepEqual(last, points[2]) {
+			t.Errorf("open route ends at %v, want %v", last, points[2])
+		}
+	}
+}
+
+func TestTourToGeoJSONAcrossPacific(t *testing.T) {
+	points := []Position{
+		{139.6917, 35.6895},  // Tokyo
+		{-122.4194, 37.7749}, // San Francisco
+		{-157.8583, 21.3069}, // Honolulu
+	}
+	matrix := make([][]float64, len(points))
+	for i := range points {
+		matrix[i] = make([]float64, len(points))
+		for j := range points {
+			lat1, lon1 := positionLatLon(points[i])
+			lat2, lon2 := positionLatLon(points[j])
+			matrix[i][j] = GreatCircleDistance(lat1, lon1, lat2, lon2)
+		}
+	}
+	result := TSPNearestNeighbor(matrix, 0)
+
+	feature, err := TourToGeoJSON(result.Tour, points, true)
+	if err != nil {
+		t.Fatalf("TourToGeoJSON() error = %v", err)
+	}
+	if _, ok := feature.Geometry.(MultiLineString); !ok {
+		t.Fatalf("geometry = %T, want MultiLineString", feature.Geometry)
+	}
+	if total := feature.Properties["distance"].(float64); math.Abs(total-result.Distance) > 1e-6 {
+		t.Errorf("distance = %v, want %v", total, result.Distance)
+	}
+	legs := feature.Properties["legs"].([]float64)
+	want := result.LegDistances(matrix)
+	for i := range want {
+		if math.Abs(legs[i]-want[i]) > 1e-6 {
+			t.Errorf("leg %d = %v, want %v", i, legs[i], want[i])
+		}
+	}
+}
+
+func TestTourToGeoJSONErrors(t *testing.T) {
+	points := []Position{{0, 0}, {1, 1}}
+	if _, err := TourToGeoJSON([]int{0}, points, false); err == nil {
+		t.Error("expected error for single-node tour")
+	}
+	if _, err := TourToGeoJSON([]int{0, 2}, points, false); err == nil {
+		t.Error("expected error for out-of-range node")
+	}
+}
+
+func TestTSP2OptWithOptionsMinImprovement(t *testing.T) {
+	rng := rand.New(rand.NewSource(21))
+	m := randomSymmetricMatrix(rng, 80)
+	tour := rng.Perm(80)
+
+	// Record the tour length after every pass of an unrestricted run.
+	lengths := []float64{calculateTourDistance(m, tour)}
+	full := TSP2Opt(m, tour, 0, WithTSPProgress(func(_ int, best, _ float64) bool {
+		lengths = append(lengths, best)
+		return true
+	}, 1))
+	if full.Stats.Iterations < 3 {
+		t.Fatalf("full run took %d passes, want at least 3", full.Stats.Iterations)
+	}
+
+	// Stop at the first pass that improves by less than the threshold.
+	threshold := 0.05
+	wantPasses := 0
+	for i := 1; i < len(lengths); i++ {
+		wantPasses = i
+		if (lengths[i-1]-lengths[i])/lengths[i-1] < threshold {
+			break
+		}
+	}
+	if wantPasses >= full.Stats.Iterations {
+		t.Fatalf("threshold %v does not stop early; pass lengths %v", threshold, lengths)
+	}
+
+	result := TSP2OptWithOptions(m, tour, TwoOptOptions{MinImprovement: threshold})
+	if result.Stats.Iterations != wantPasses {
+		t.Errorf("passes = %d, want %d", result.Stats.Iterations, wantPasses)
+	}
+	if math.Abs(result.Distance-lengths[wantPasses]) > 1e-9 {
+		t.Errorf("distance = %v, want %v", result.Distance, lengths[wantPasses])
+	}
+	if err := ValidateTour(result.Tour, 80); err != nil {
+		t.Errorf("invalid tour: %v", err)
+	}
+}
+
+func TestTSP2OptWithOptionsContext(t *testing.T) {
+	rng := rand.New(rand.NewSource(22))
+	m := randomSymmetricMatrix(rng, 30)
+	tour := rng.Perm(30)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := TSP2OptWithOptions(m, tour, TwoOptOptions{Context: ctx})
+	if result.Stats.Iterations != 0 || !equalTours(result.Tour, tour) {
+		t.Errorf("cancelled run = %d passes, tour %v; want 0 passes and the input tour", result.Stats.Iterations, result.Tour)
+	}
+
+	want := TSP2Opt(m, tour, 5)
+	got := TSP2OptWithOptions(m, tour, TwoOptOptions{MaxIterations: 5, Context: context.Background()})
+	if !equalTours(got.Tour, want.Tour) || got.Distance != want.Distance {
+		t.Errorf("TSP2OptWithOptions = %v (%v), want %v (%v)", got.Tour, got.Distance, want.Tour, want.Distance)
+	}
+}
+
+func TestTSPSimulatedAnnealingCtxDeadline(t *testing.T) {
+	rng := rand.New(rand.NewSource(23))
+	m := randomSymmetricMatrix(rng, 200)
+	const iterations = 1 << 40
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	began := time.Now()
+	result, err := TSPSimulatedAnnealingCtx(ctx, m, 0, iterations, 100, 0.999999)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(began); elapsed > 2*time.Second {
+		t.Errorf("run took %v after a 20ms deadline", elapsed)
+	}
+	if result == nil {
+		t.Fatal("expected the best tour so far")
+	}
+	if err := ValidateTour(result.Tour, 200); err != nil {
+		t.Errorf("invalid tour: %v", err)
+	}
+	if result.Stats.Iterations >= iterations {
+		t.Errorf("iterations = %d, want the run cut short", result.Stats.Iterations)
+	}
+	if nn := TSPNearestNeighbor(m, 0); result.Distance > nn.Distance {
+		t.Errorf("distance = %v, want no worse than the starting tour %v", result.Distance, nn.Distance)
+	}
+}
+
+func TestTSPSimulatedAnnealingCtxInvalidStart(t *testing.T) {
+	m := [][]float64{{0, 1}, {1, 0}}
+	if _, err := TSPSimulatedAnnealingCtx(context.Background(), m, 2, 10, 1, 0.9); err == nil {
+		t.Error("expected error for out-of-range start")
+	}
+}
@@ -0,0 +1,449 @@
+package geo
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// WKB geometry type codes.
+const (
+	wkbPoint              = 1
+	wkbLineString         = 2
+	wkbPolygon            = 3
+	wkbMultiPoint         = 4
+	wkbMultiLineString    = 5
+	wkbMultiPolygon       = 6
+	wkbGeometryCollection = 7
+
+	// EWKB flags in the high bits of the type code.
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+
+	// wgs84SRID is the only SRID accepted by ParseEWKBHex.
+	wgs84SRID = 4326
+)
+
+// ParseWKB decodes Well-Known Binary into the matching type of this package,
+// covering the same geometries as ParseWKT. Little- and big-endian input is
+// accepted, as are Z coordinates in both the ISO (type code + 1000) and the
+// PostGIS EWKB (flag) form; M coordinates and SRIDs are not. A point with NaN
+// coordinates, the WKB form of POINT EMPTY, is an error. Errors give the byte
+// offset where decoding failed.
+func ParseWKB(b []byte) (interface{}, error) {
+	return parseWKB(b, false)
+}
+
+// ParseEWKBHex decodes hex-encoded PostGIS Extended WKB, as returned for
+// geometry columns. An embedded SRID must be 4326; input without an SRID is
+// taken to be in WGS 84 as well.
+func ParseEWKBHex(s string) (interface{}, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return parseWKB(b, true)
+}
+
+func parseWKB(b []byte, allowSRID bool) (interface{}, error) {
+	r := &wkbReader{b: b, allowSRID: allowSRID}
+	geom, err := r.geometry(true)
+	if err != nil {
+		return nil, err
+	}
+	if r.pos < len(r.b) {
+		return nil, r.errorf("%d unexpected bytes after geometry", len(r.b)-r.pos)
+	}
+	return geom, nil
+}
+
+// wkbReader decodes WKB, tracking the byte order of the geometry being read.
+type wkbReader struct {
+	b         []byte
+	pos       int
+	order     binary.ByteOrder
+	allowSRID bool
+}
+
+func (r *wkbReader) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid wkb at offset %d: %s", r.pos, fmt.Sprintf(format, args...))
+}
+
+func (r *wkbReader) uint32() (uint32, error) {
+	if len(r.b)-r.pos < 4 {
+		return 0, r.errorf("unexpected end of input")
+	}
+	v := r.order.Uint32(r.b[r.pos:])
+	r.pos += 4
+	return v, nil
+}
+
+// count reads an element count and checks that the remaining input can hold
+// that many elements of at least minSize bytes.
+func (r *wkbReader) count(minSize int) (int, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(n)*uint64(minSize) > uint64(len(r.b)-r.pos) {
+		r.pos -= 4
+		return 0, r.errorf("count %d exceeds the remaining input", n)
+	}
+	return int(n), nil
+}
+
+func (r *wkbReader) position(hasZ bool) (Position, error) {
+	dims := 2
+	if hasZ {
+		dims = 3
+	}
+	if len(r.b)-r.pos < 8*dims {
+		return nil, r.errorf("unexpected end of input")
+	}
+	p := make(Position, dims)
+	for i := range p {
+		p[i] = math.Float64frombits(r.order.Uint64(r.b[r.pos:]))
+		r.pos += 8
+	}
+	return p, nil
+}
+
+func (r *wkbReader) positions(hasZ bool) ([]Position, error) {
+	size := 16
+	if hasZ {
+		size = 24
+	}
+	n, err := r.count(size)
+	if err != nil {
+		return nil, err
+	}
+	coords := make([]Position, n)
+	for i := range coords {
+		if coords[i], err = r.position(hasZ); err != nil {
+			return nil, err
+		}
+	}
+	return coords, nil
+}
+
+func (r *wkbReader) rings(hasZ bool) ([][]Position, error) {
+	n, err := r.count(4)
+	if err != nil {
+		return nil, err
+	}
+	rings := make([][]Position, n)
+	for i := range rings {
+		if rings[i], err = r.positions(hasZ); err != nil {
+			return nil, err
+		}
+	}
+	return rings, nil
+}
+
+// header reads the byte order and type code of a geometry and returns the
+// base type and whether it has Z coordinates.
+func (r *wkbReader) header(top bool) (uint32, bool, error) {
+	if r.pos >= len(r.b) {
+		return 0, false, r.errorf("unexpected end of input")
+	}
+	switch r.b[r.pos] {
+	case 0:
+		r.order = binary.BigEndian
+	case 1:
+		r.order = binary.LittleEndian
+	default:
+		return 0, false, r.errorf("invalid byte order %d", r.b[r.pos])
+	}
+	r.pos++
+	start := r.pos
+	typ, err := r.uint32()
+	if err != nil {
+		return 0, false, err
+	}
+
+	hasZ := typ&ewkbZ != 0
+	if typ&ewkbM != 0 {
+		r.pos = start
+		return 0, false, r.errorf("m coordinates are not supported")
+	}
+	if typ&ewkbSRID != 0 {
+		if !r.allowSRID || !top {
+			r.pos = start
+			return 0, false, r.errorf("unexpected srid")
+		}
+		sridStart := r.pos
+		srid, err := r.uint32()
+		if err != nil {
+			return 0, false, err
+		}
+		if srid != wgs84SRID {
+			r.pos = sridStart
+			return 0, false, r.errorf("unsupported srid %d, want %d", srid, wgs84SRID)
+		}
+	}
+	base := typ &^ (ewkbZ | ewkbM | ewkbSRID)
+	switch base / 1000 {
+	case 0:
+	case 1:
+		hasZ = true
+	default:
+		r.pos = start
+		return 0, false, r.errorf("m coordinates are not supported")
+	}
+	return base % 1000, hasZ, nil
+}
+
+func (r *wkbReader) geometry(top bool) (interface{}, error) {
+	start := r.pos
+	typ, hasZ, err := r.header(top)
+	if err != nil {
+		return nil, err
+	}
+
+	switch typ {
+	case wkbPoint:
+		p, err := r.position(hasZ)
+		if err != nil {
+			return nil, err
+		}
+		if math.IsNaN(p[0]) || math.IsNaN(p[1]) {
+			r.pos = start
+			return nil, r.errorf("empty point is not supported")
+		}
+		return Point{Type: "Point", Coordinates: p}, nil
+	case wkbLineString:
+		coords, err := r.positions(hasZ)
+		if err != nil {
+			return nil, err
+		}
+		return NewLineString(coords), nil
+	case wkbPolygon:
+		rings, err := r.rings(hasZ)
+		if err != nil {
+			return nil, err
+		}
+		return NewPolygon(rings), nil
+	case wkbMultiPoint, wkbMultiLineString, wkbMultiPolygon, wkbGeometryCollection:
+		n, err := r.count(5)
+		if err != nil {
+			return nil, err
+		}
+		members := make([]interface{}, n)
+		for i := range members {
+			memberStart := r.pos
+			if members[i], err = r.geometry(false); err != nil {
+				return nil, err
+			}
+			if typ != wkbGeometryCollection && !wkbMemberOf(typ, members[i]) {
+				r.pos = memberStart
+				return nil, r.errorf("unexpected %s in multi geometry", members[i].(Geometry).GeometryType())
+			}
+		}
+		return wkbMulti(typ, members), nil
+	default:
+		r.pos = start + 1
+		return nil, r.errorf("unsupported geometry type %d", typ)
+	}
+}
+
+// wkbMemberOf reports whether member has the geometry type the multi geometry
+// typ is made of.
+func wkbMemberOf(typ uint32, member interface{}) bool {
+	switch member.(type) {
+	case Point:
+		return typ == wkbMultiPoint
+	case LineString:
+		return typ == wkbMultiLineString
+	case Polygon:
+		return typ == wkbMultiPolygon
+	}
+	return false
+}
+
+// wkbMulti assembles the members of a multi geometry or collection.
+func wkbMulti(typ uint32, members []interface{}) interface{} {
+	switch typ {
+	case wkbMultiPoint:
+		coords := make([]Position, len(members))
+		for i, m := range members {
+			coords[i] = m.(Point).Coordinates
+		}
+		return NewMultiPoint(coords)
+	case wkbMultiLineString:
+		coords := make([][]Position, len(members))
+		for i, m := range members {
+			coords[i] = m.(LineString).Coordinates
+		}
+		return NewMultiLineString(coords)
+	case wkbMultiPolygon:
+		coords := make([][][]Position, len(members))
+		for i, m := range members {
+			coords[i] = m.(Polygon).Coordinates
+		}
+		return NewMultiPolygon(coords)
+	default:
+		return NewGeometryCollection(members)
+	}
+}
+
+// ToWKB encodes a geometry as ISO Well-Known Binary in the given byte order.
+// Features and feature collections are handled as in ToWKT. Geometries in which
+// any position has an altitude are written with Z coordinates (type code
+// + 1000).
+func ToWKB(obj interface{}, order binary.ByteOrder) ([]byte, error) {
+	if order == nil {
+		return nil, errors.New("byte order is nil")
+	}
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return nil, err
+	}
+	w := &wkbWriter{order: order, marker: 1}
+	if order.Uint16([]byte{0, 1}) == 1 {
+		w.marker = 0
+	}
+	if err := w.geometry(geom); err != nil {
+		return nil, err
+	}
+	return w.b, nil
+}
+
+// wkbWriter appends WKB to a buffer.
+type wkbWriter struct {
+	b      []byte
+	order  binary.ByteOrder
+	marker byte
+}
+
+func (w *wkbWriter) uint32(v uint32) {
+	var buf [4]byte
+	w.order.PutUint32(buf[:], v)
+	w.b = append(w.b, buf[:]...)
+}
+
+func (w *wkbWriter) float64(v float64) {
+	var buf [8]byte
+	w.order.PutUint64(buf[:], math.Float64bits(v))
+	w.b = append(w.b, buf[:]...)
+}
+
+func (w *wkbWriter) header(typ uint32, hasZ bool) {
+	w.b = append(w.b, w.marker)
+	if hasZ {
+		typ += 1000
+	}
+	w.uint32(typ)
+}
+
+func (w *wkbWriter) position(p Position, hasZ bool) {
+	w.float64(p[0])
+	w.float64(p[1])
+	if hasZ {
+		w.float64(p.Alt())
+	}
+}
+
+func (w *wkbWriter) positions(coords []Position, hasZ bool) {
+	w.uint32(uint32(len(coords)))
+	for _, p := range coords {
+		w.position(p, hasZ)
+	}
+}
+
+func (w *wkbWriter) rings(rings [][]Position, hasZ bool) {
+	w.uint32(uint32(len(rings)))
+	for _, ring := range rings {
+		w.positions(ring, hasZ)
+	}
+}
+
+func (w *wkbWriter) geometry(geom Geometry) error {
+	hasZ := false
+	geom.ForEachPosition(func(p Position) {
+		if p.HasAlt() {
+			hasZ = true
+		}
+	})
+
+	switch g := geom.(type) {
+	case Point:
+		w.header(wkbPoint, hasZ)
+		w.position(g.Coordinates, hasZ)
+	case MultiPoint:
+		w.header(wkbMultiPoint, hasZ)
+		w.uint32(uint32(len(g.Coordinates)))
+		for _, p := range g.Coordinates {
+			w.header(wkbPoint, hasZ)
+			w.position(p, hasZ)
+		}
+	case LineString:
+		w.header(wkbLineString, hasZ)
+		w.positions(g.Coordinates, hasZ)
+	case Polygon:
+		w.header(wkbPolygon, hasZ)
+		w.rings(g.Coordinates, hasZ)
+	case MultiLineString:
+		w.header(wkbMultiLineString, hasZ)
+		w.uint32(uint32(len(g.Coordinates)))
+		for _, line := range g.Coordinates {
+			w.header(wkbLineString, hasZ)
+			w.positions(line, hasZ)
+		}
+	case MultiPolygon:
+		w.header(wkbMultiPolygon, hasZ)
+		w.uint32(uint32(len(g.Coordinates)))
+		for _, poly := range g.Coordinates {
+			w.header(wkbPolygon, hasZ)
+			w.rings(poly, hasZ)
+		}
+	case GeometryCollection:
+		var members []Geometry
+		for _, child := range g.Geometries {
+			member, err := geometryOf(child)
+			if err != nil {
+				return err
+			}
+			members = append(members, member)
+		}
+		return w.collection(members)
+	case Feature:
+		if g.Geometry == nil {
+			return errors.New("feature has no geometry")
+		}
+		member, err := geometryOf(g.Geometry)
+		if err != nil {
+			return err
+		}
+		return w.geometry(member)
+	case FeatureCollection:
+		var members []Geometry
+		for _, f := range g.Features {
+			if f.Geometry == nil {
+				continue
+			}
+			member, err := geometryOf(f.Geometry)
+			if err != nil {
+				return err
+			}
+			members = append(members, member)
+		}
+		return w.collection(members)
+	default:
+		return fmt.Errorf("unsupported geojson type %T", geom)
+	}
+	return nil
+}
+
+func (w *wkbWriter) collection(members []Geometry) error {
+	w.header(wkbGeometryCollection, false)
+	w.uint32(uint32(len(members)))
+	for _, member := range members {
+		if err := w.geometry(member); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package geo
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Fixtures match the ISO WKB that PostGIS ST_AsBinary writes for each WKT.
+var wkbFixtures = []struct {
+	wkt   string
+	order binary.ByteOrder
+	hex   string
+}{
+	{"POINT (1 2)", binary.LittleEndian, "0101000000000000000000F03F0000000000000040"},
+	{"POINT (1 2)", binary.BigEndian, "00000000013FF00000000000004000000000000000"},
+	{"POINT Z (1 2 3)", binary.LittleEndian, "01E9030000000000000000F03F00000000000000400000000000000840"},
+	{"LINESTRING (0 0, 1 1, 2 1)", binary.LittleEndian, "01020000000300000000000000000000000000000000000000000000000000F03F000000000000F03F0000000000000040000000000000F03F"},
+	{"LINESTRING EMPTY", binary.LittleEndian, "010200000000000000"},
+	{
+		"POLYGON ((0 0, 4 0, 4 4, 0 4, 0 0), (1 1, 2 1, 2 2, 1 1))",
+		binary.LittleEndian,
+		"01030000000200000005000000000000000000000000000000000000000000000000001040000000000000000000000000000010400000000000001040000000000000000000000000000010400000000000000000000000000000000004000000000000000000F03F000000000000F03F0000000000000040000000000000F03F00000000000000400000000000000040000000000000F03F000000000000F03F",
+	},
+	{"MULTIPOINT ((10 40), (40 30))", binary.LittleEndian, "010400000002000000010100000000000000000024400000000000004440010100000000000000000044400000000000003E40"},
+	{
+		"MULTILINESTRING ((10 10, 20 20), (40 40, 30 30))",
+		binary.BigEndian,
+		"000000000500000002000000000200000002402400000000000040240000000000004034000000000000403400000000000000000000020000000240440000000000004044000000000000403E000000000000403E000000000000",
+	},
+	{
+		"MULTIPOLYGON (((30 20, 45 40, 10 40, 30 20)))",
+		binary.LittleEndian,
+		"010600000001000000010300000001000000040000000000000000003E40000000000000344000000000008046400000000000004440000000000000244000000000000044400000000000003E400000000000003440",
+	},
+	{
+		"GEOMETRYCOLLECTION (POINT (4 6), LINESTRING (4 6, 7 10))",
+		binary.LittleEndian,
+		"010700000002000000010100000000000000000010400000000000001840010200000002000000000000000000104000000000000018400000000000001C400000000000002440",
+	},
+	{"GEOMETRYCOLLECTION EMPTY", binary.LittleEndian, "010700000000000000"},
+}
+
+func TestWKBRoundTrip(t *testing.T) {
+	for _, tt := range wkbFixtures {
+		t.Run(tt.wkt, func(t *testing.T) {
+			want, err := ParseWKT(tt.wkt)
+			if err != nil {
+				t.Fatalf("ParseWKT() error = %v", err)
+			}
+			b, _ := hex.DecodeString(tt.hex)
+			got, err := ParseWKB(b)
+			if err != nil {
+				t.Fatalf("ParseWKB() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseWKB() = %#v, want %#v", got, want)
+			}
+			encoded, err := ToWKB(got, tt.order)
+			if err != nil {
+				t.Fatalf("ToWKB() error = %v", err)
+			}
+			if h := strings.ToUpper(hex.EncodeToString(encoded)); h != tt.hex {
+				t.Errorf("ToWKB() = %s, want %s", h, tt.hex)
+			}
+		})
+	}
+}
+
+func TestParseEWKBHex(t *testing.T) {
+	got, err := ParseEWKBHex("0101000020E6100000000000000000F03F0000000000000040")
+	if err != nil {
+		t.Fatalf("ParseEWKBHex() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, NewPoint(1, 2)) {
+		t.Errorf("ParseEWKBHex() = %#v, want POINT (1 2)", got)
+	}
+
+	got, err = ParseEWKBHex("01020000a0e610000002000000000000000000f03f00000000000000400000000000000840000000000000104000000000000014400000000000001840")
+	if err != nil {
+		t.Fatalf("ParseEWKBHex() error = %v", err)
+	}
+	if want := NewLineString([]Position{{1, 2, 3}, {4, 5, 6}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseEWKBHex() = %#v, want %#v", got, want)
+	}
+
+	// Plain WKB is accepted too.
+	if _, err := ParseEWKBHex("0101000000000000000000F03F0000000000000040"); err != nil {
+		t.Errorf("ParseEWKBHex(wkb) error = %v", err)
+	}
+
+	_, err = ParseEWKBHex("0101000020110F0000000000000000F03F0000000000000040")
+	if err == nil || !strings.Contains(err.Error(), "srid 3857") {
+		t.Errorf("ParseEWKBHex(srid 3857) error = %v, want unsupported srid", err)
+	}
+	if _, err := ParseEWKBHex("zz"); err == nil {
+		t.Error("expected error for invalid hex")
+	}
+}
+
+func TestParseWKBErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		hex    string
+		offset string
+	}{
+		{"empty", "", "offset 0"},
+		{"byte order", "0201000000", "offset 0"},
+		{"truncated point", "0101000000000000000000F03F", "offset 5"},
+		{"trailing bytes", "0101000000000000000000F03F000000000000004000", "offset 21"},
+		{"unknown type", "0109000000", "offset 1"},
+		{"m coordinates", "01D1070000", "offset 1"},
+		{"srid without ewkb", "0101000020E6100000000000000000F03F0000000000000040", "offset 1"},
+		{"empty point", "0101000000000000000000F87F000000000000F87F", "offset 0"},
+		{"huge count", "0102000000FFFFFFFF", "offset 5"},
+		{"wrong member", "010400000001000000010200000000000000", "offset 9"},
+	}
+	for _, tt := range tests {
+		b, _ := hex.DecodeString(tt.hex)
+		_, err := ParseWKB(b)
+		if err == nil {
+			t.Errorf("%s: ParseWKB() error = nil, want error", tt.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.offset) {
+			t.Errorf("%s: ParseWKB() error = %q, want %s", tt.name, err, tt.offset)
+		}
+	}
+}
+
+func TestToWKBFeatures(t *testing.T) {
+	fc := NewFeatureCollection([]Feature{
+		NewFeature(NewPoint(4, 6)),
+		NewFeature(nil),
+		NewFeature(NewLineString([]Position{{4, 6}, {7, 10}})),
+	})
+	got, err := ToWKB(fc, binary.LittleEndian)
+	if err != nil {
+		t.Fatalf("ToWKB() error = %v", err)
+	}
+	want := "010700000002000000010100000000000000000010400000000000001840010200000002000000000000000000104000000000000018400000000000001C400000000000002440"
+	if h := strings.ToUpper(hex.EncodeToString(got)); h != want {
+		t.Errorf("ToWKB(collection) = %s, want %s", h, want)
+	}
+
+	if _, err := ToWKB(NewPoint(1, 2), nil); err == nil {
+		t.Error("expected error for nil byte order")
+	}
+	if _, err := ToWKB(NewFeature(nil), binary.BigEndian); err == nil {
+		t.Error("expected error for feature without geometry")
+	}
+}
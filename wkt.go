@@ -0,0 +1,451 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseWKT parses a Well-Known Text geometry into the matching type of this
+// package: Point, MultiPoint, LineString, Polygon, MultiLineString,
+// MultiPolygon or GeometryCollection. Keywords are case-insensitive. A third
+// coordinate, with or without the Z tag, becomes the altitude; M coordinates
+// are not supported.
+//
+// EMPTY geometries are returned with empty coordinates, except POINT EMPTY,
+// which Point cannot represent and which is an error. Errors give the byte
+// offset in s where parsing failed.
+func ParseWKT(s string) (interface{}, error) {
+	p := &wktParser{s: s}
+	geom, err := p.geometry()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q after geometry", p.s[p.pos])
+	}
+	return geom, nil
+}
+
+// wktParser is a recursive descent parser over a WKT string.
+type wktParser struct {
+	s   string
+	pos int
+}
+
+func (p *wktParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid wkt at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *wktParser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// word reads a keyword and returns it in upper case, or "" if none follows.
+func (p *wktParser) word() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && (p.s[p.pos]|0x20 >= 'a' && p.s[p.pos]|0x20 <= 'z') {
+		p.pos++
+	}
+	return strings.ToUpper(p.s[start:p.pos])
+}
+
+// peekWord returns the next keyword without consuming it.
+func (p *wktParser) peekWord() string {
+	pos := p.pos
+	w := p.word()
+	p.pos = pos
+	return w
+}
+
+// peek reports whether the next non-space byte is c.
+func (p *wktParser) peek(c byte) bool {
+	p.skipSpace()
+	return p.pos < len(p.s) && p.s[p.pos] == c
+}
+
+func (p *wktParser) expect(c byte) error {
+	if !p.peek(c) {
+		if p.pos == len(p.s) {
+			return p.errorf("expected %q, found end of input", c)
+		}
+		return p.errorf("expected %q, found %q", c, p.s[p.pos])
+	}
+	p.pos++
+	return nil
+}
+
+// list parses "(item, item, ...)".
+func (p *wktParser) list(item func() error) error {
+	if err := p.expect('('); err != nil {
+		return err
+	}
+	for {
+		if err := item(); err != nil {
+			return err
+		}
+		if p.peek(',') {
+			p.pos++
+			continue
+		}
+		return p.expect(')')
+	}
+}
+
+// atNumber reports whether a number starts at the next non-space byte.
+func (p *wktParser) atNumber() bool {
+	p.skipSpace()
+	return p.pos < len(p.s) && strings.IndexByte("0123456789+-.", p.s[p.pos]) >= 0
+}
+
+func (p *wktParser) number() (float64, error) {
+	if !p.atNumber() {
+		if p.pos == len(p.s) {
+			return 0, p.errorf("expected number, found end of input")
+		}
+		return 0, p.errorf("expected number, found %q", p.s[p.pos])
+	}
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte("0123456789+-.eE", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+	token := p.s[start:p.pos]
+	v, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		p.pos = start
+		return 0, p.errorf("invalid number %q", token)
+	}
+	return v, nil
+}
+
+// position parses "x y" or "x y z".
+func (p *wktParser) position() (Position, error) {
+	var pos Position
+	for i := 0; i < 2; i++ {
+		v, err := p.number()
+		if err != nil {
+			return nil, err
+		}
+		pos = append(pos, v)
+	}
+	if p.atNumber() {
+		v, err := p.number()
+		if err != nil {
+			return nil, err
+		}
+		pos = append(pos, v)
+		if p.atNumber() {
+			return nil, p.errorf("more than 3 coordinates")
+		}
+	}
+	return pos, nil
+}
+
+// positions parses "(x y, x y, ...)".
+func (p *wktParser) positions() ([]Position, error) {
+	coords := []Position{}
+	err := p.list(func() error {
+		pos, err := p.position()
+		coords = append(coords, pos)
+		return err
+	})
+	return coords, err
+}
+
+// rings parses "((x y, ...), (x y, ...))".
+func (p *wktParser) rings() ([][]Position, error) {
+	rings := [][]Position{}
+	err := p.list(func() error {
+		ring, err := p.positions()
+		rings = append(rings, ring)
+		return err
+	})
+	return rings, err
+}
+
+// empty consumes an EMPTY keyword if one follows.
+func (p *wktParser) empty() bool {
+	if p.peekWord() == "EMPTY" {
+		p.word()
+		return true
+	}
+	return false
+}
+
+func (p *wktParser) geometry() (interface{}, error) {
+	p.skipSpace()
+	start := p.pos
+	tag := p.word()
+	switch p.peekWord() {
+	case "Z":
+		p.word()
+	case "M", "ZM":
+		p.skipSpace()
+		return nil, p.errorf("m coordinates are not supported")
+	}
+
+	switch tag {
+	case "POINT":
+		if p.peekWord() == "EMPTY" {
+			p.skipSpace()
+			return nil, p.errorf("empty point is not supported")
+		}
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		pos, err := p.position()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		return Point{Type: "Point", Coordinates: pos}, nil
+	case "MULTIPOINT":
+		coords := []Position{}
+		if p.empty() {
+			return NewMultiPoint(coords), nil
+		}
+		err := p.list(func() error {
+			// Both "MULTIPOINT (1 2, 3 4)" and "MULTIPOINT ((1 2), (3 4))"
+			// are in use.
+			if !p.peek('(') {
+				pos, err := p.position()
+				coords = append(coords, pos)
+				return err
+			}
+			p.pos++
+			pos, err := p.position()
+			if err != nil {
+				return err
+			}
+			coords = append(coords, pos)
+			return p.expect(')')
+		})
+		return NewMultiPoint(coords), err
+	case "LINESTRING":
+		if p.empty() {
+			return NewLineString([]Position{}), nil
+		}
+		coords, err := p.positions()
+		return NewLineString(coords), err
+	case "POLYGON":
+		if p.empty() {
+			return NewPolygon([][]Position{}), nil
+		}
+		rings, err := p.rings()
+		return NewPolygon(rings), err
+	case "MULTILINESTRING":
+		if p.empty() {
+			return NewMultiLineString([][]Position{}), nil
+		}
+		lines, err := p.rings()
+		return NewMultiLineString(lines), err
+	case "MULTIPOLYGON":
+		polys := [][][]Position{}
+		if p.empty() {
+			return NewMultiPolygon(polys), nil
+		}
+		err := p.list(func() error {
+			rings, err := p.rings()
+			polys = append(polys, rings)
+			return err
+		})
+		return NewMultiPolygon(polys), err
+	case "GEOMETRYCOLLECTION":
+		geometries := []interface{}{}
+		if p.empty() {
+			return NewGeometryCollection(geometries), nil
+		}
+		err := p.list(func() error {
+			g, err := p.geometry()
+			geometries = append(geometries, g)
+			return err
+		})
+		return NewGeometryCollection(geometries), err
+	case "":
+		return nil, p.errorf("expected geometry type")
+	default:
+		p.pos = start
+		return nil, p.errorf("unknown geometry type %q", tag)
+	}
+}
+
+// ToWKT returns the Well-Known Text of a geometry. A Feature is written as its
+// geometry and a FeatureCollection as a GEOMETRYCOLLECTION of the geometries of
+// its features, skipping features without one. Geometries in which any
+// position has an altitude are written with the Z tag and three coordinates per
+// position. Geometries without coordinates are written as EMPTY.
+func ToWKT(obj interface{}) (string, error) {
+	geom, err := geometryOf(obj)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := writeWKT(&b, geom); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func writeWKT(b *strings.Builder, geom Geometry) error {
+	hasZ := false
+	geom.ForEachPosition(func(p Position) {
+		if p.HasAlt() {
+			hasZ = true
+		}
+	})
+	tag := func(name string) {
+		b.WriteString(name)
+		if hasZ {
+			b.WriteString(" Z")
+		}
+	}
+	position := func(p Position) {
+		b.WriteString(strconv.FormatFloat(p[0], 'f', -1, 64))
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatFloat(p[1], 'f', -1, 64))
+		if hasZ {
+			b.WriteByte(' ')
+			b.WriteString(strconv.FormatFloat(p.Alt(), 'f', -1, 64))
+		}
+	}
+	positions := func(coords []Position) {
+		b.WriteByte('(')
+		for i, p := range coords {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			position(p)
+		}
+		b.WriteByte(')')
+	}
+	rings := func(rings [][]Position) {
+		b.WriteByte('(')
+		for i, ring := range rings {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			positions(ring)
+		}
+		b.WriteByte(')')
+	}
+
+	switch g := geom.(type) {
+	case Point:
+		tag("POINT")
+		b.WriteByte(' ')
+		positions([]Position{g.Coordinates})
+	case MultiPoint:
+		tag("MULTIPOINT")
+		if len(g.Coordinates) == 0 {
+			b.WriteString(" EMPTY")
+			return nil
+		}
+		b.WriteString(" (")
+		for i, p := range g.Coordinates {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			positions([]Position{p})
+		}
+		b.WriteByte(')')
+	case LineString:
+		tag("LINESTRING")
+		if len(g.Coordinates) == 0 {
+			b.WriteString(" EMPTY")
+			return nil
+		}
+		b.WriteByte(' ')
+		positions(g.Coordinates)
+	case Polygon:
+		tag("POLYGON")
+		if len(g.Coordinates) == 0 {
+			b.WriteString(" EMPTY")
+			return nil
+		}
+		b.WriteByte(' ')
+		rings(g.Coordinates)
+	case MultiLineString:
+		tag("MULTILINESTRING")
+		if len(g.Coordinates) == 0 {
+			b.WriteString(" EMPTY")
+			return nil
+		}
+		b.WriteByte(' ')
+		rings(g.Coordinates)
+	case MultiPolygon:
+		tag("MULTIPOLYGON")
+		if len(g.Coordinates) == 0 {
+			b.WriteString(" EMPTY")
+			return nil
+		}
+		b.WriteString(" (")
+		for i, poly := range g.Coordinates {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			rings(poly)
+		}
+		b.WriteByte(')')
+	case GeometryCollection:
+		var members []Geometry
+		for _, child := range g.Geometries {
+			member, err := geometryOf(child)
+			if err != nil {
+				return err
+			}
+			members = append(members, member)
+		}
+		return writeWKTCollection(b, members)
+	case Feature:
+		if g.Geometry == nil {
+			return errors.New("feature has no geometry")
+		}
+		member, err := geometryOf(g.Geometry)
+		if err != nil {
+			return err
+		}
+		return writeWKT(b, member)
+	case FeatureCollection:
+		var members []Geometry
+		for _, f := range g.Features {
+			if f.Geometry == nil {
+				continue
+			}
+			member, err := geometryOf(f.Geometry)
+			if err != nil {
+				return err
+			}
+			members = append(members, member)
+		}
+		return writeWKTCollection(b, members)
+	default:
+		return fmt.Errorf("unsupported geojson type %T", geom)
+	}
+	return nil
+}
+
+func writeWKTCollection(b *strings.Builder, members []Geometry) error {
+	b.WriteString("GEOMETRYCOLLECTION")
+	if len(members) == 0 {
+		b.WriteString(" EMPTY")
+		return nil
+	}
+	b.WriteString(" (")
+	for i, member := range members {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if err := writeWKT(b, member); err != nil {
+			return err
+		}
+	}
+	b.WriteByte(')')
+	return nil
+}
@@ -0,0 +1,163 @@
+package geo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWKTRoundTrip(t *testing.T) {
+	tests := []struct {
+		wkt  string
+		want interface{}
+	}{
+		{"POINT (30 10)", NewPoint(30, 10)},
+		{"POINT Z (30 10 5.5)", Point{Type: "Point", Coordinates: Position{30, 10, 5.5}}},
+		{"MULTIPOINT ((10 40), (40 30))", NewMultiPoint([]Position{{10, 40}, {40, 30}})},
+		{"MULTIPOINT EMPTY", NewMultiPoint([]Position{})},
+		{"LINESTRING (30 10, 10 30, 40 40)", NewLineString([]Position{{30, 10}, {10, 30}, {40, 40}})},
+		{"LINESTRING EMPTY", NewLineString([]Position{})},
+		{
+			"POLYGON ((35 10, 45 45, 15 40, 10 20, 35 10), (20 30, 35 35, 30 20, 20 30))",
+			NewPolygon([][]Position{
+				{{35, 10}, {45, 45}, {15, 40}, {10, 20}, {35, 10}},
+				{{20, 30}, {35, 35}, {30, 20}, {20, 30}},
+			}),
+		},
+		{"POLYGON EMPTY", NewPolygon([][]Position{})},
+		{
+			"MULTILINESTRING ((10 10, 20 20), (40 40, 30 30, 40 20))",
+			NewMultiLineString([][]Position{{{10, 10}, {20, 20}}, {{40, 40}, {30, 30}, {40, 20}}}),
+		},
+		{"MULTILINESTRING EMPTY", NewMultiLineString([][]Position{})},
+		{
+			"MULTIPOLYGON (((30 20, 45 40, 10 40, 30 20)), ((15 5, 40 10, 10 20, 5 10, 15 5)))",
+			NewMultiPolygon([][][]Position{
+				{{{30, 20}, {45, 40}, {10, 40}, {30, 20}}},
+				{{{15, 5}, {40, 10}, {10, 20}, {5, 10}, {15, 5}}},
+			}),
+		},
+		{"MULTIPOLYGON EMPTY", NewMultiPolygon([][][]Position{})},
+		{
+			"GEOMETRYCOLLECTION (POINT (40 10), LINESTRING Z (10 10 1, 20 20 2), GEOMETRYCOLLECTION EMPTY)",
+			NewGeometryCollection([]interface{}{
+				NewPoint(40, 10),
+				NewLineString([]Position{{10, 10, 1}, {20, 20, 2}}),
+				NewGeometryCollection([]interface{}{}),
+			}),
+		},
+		{"GEOMETRYCOLLECTION EMPTY", NewGeometryCollection([]interface{}{})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wkt, func(t *testing.T) {
+			got, err := ParseWKT(tt.wkt)
+			if err != nil {
+				t.Fatalf("ParseWKT() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseWKT() = %#v, want %#v", got, tt.want)
+			}
+			text, err := ToWKT(got)
+			if err != nil {
+				t.Fatalf("ToWKT() error = %v", err)
+			}
+			if text != tt.wkt {
+				t.Errorf("ToWKT() = %q, want %q", text, tt.wkt)
+			}
+		})
+	}
+}
+
+func TestParseWKTVariants(t *testing.T) {
+	tests := []struct {
+		wkt  string
+		want interface{}
+	}{
+		{"point(1 2)", NewPoint(1, 2)},
+		{"  Point  ( -1.5e2   +2.25 )  ", NewPoint(-150, 2.25)},
+		{"POINT (1 2 3)", Point{Type: "Point", Coordinates: Position{1, 2, 3}}},
+		{"MultiPoint (1 2, 3 4)", NewMultiPoint([]Position{{1, 2}, {3, 4}})},
+		{"polygon empty", NewPolygon([][]Position{})},
+		{"POLYGON((0 0,1 0,1 1,0 0))", NewPolygon([][]Position{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}})},
+	}
+	for _, tt := range tests {
+		got, err := ParseWKT(tt.wkt)
+		if err != nil {
+			t.Errorf("ParseWKT(%q) error = %v", tt.wkt, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseWKT(%q) = %#v, want %#v", tt.wkt, got, tt.want)
+		}
+	}
+}
+
+func TestParseWKTErrors(t *testing.T) {
+	tests := []struct {
+		wkt     string
+		offset  string
+		message string
+	}{
+		{"POINT (1 2", "offset 10", "expected ')', found end of input"},
+		{"LINESTRING (1 2, 3 4))", "offset 21", "unexpected ')' after geometry"},
+		{"POLYGON ((0 0, 1 0, 1 1, 0 0)", "offset 29", "expected ')', found end of input"},
+		{"POINT (1 2) garbage", "offset 12", "unexpected 'g' after geometry"},
+		{"POINT (1)", "offset 8", "expected number, found ')'"},
+		{"POINT (1 2 3 4)", "offset 13", "more than 3 coordinates"},
+		{"POINT (1 x)", "offset 9", "expected number, found 'x'"},
+		{"POINT (1 1e)", "offset 9", `invalid number "1e"`},
+		{"POINT (1-2 3)", "offset 7", `invalid number "1-2"`},
+		{"POINT EMPTY", "offset 6", "empty point is not supported"},
+		{"POINT M (1 2 3)", "offset 6", "m coordinates are not supported"},
+		{"CIRCLE (1 2)", "offset 0", `unknown geometry type "CIRCLE"`},
+		{"", "offset 0", "expected geometry type"},
+		{"GEOMETRYCOLLECTION (POINT (1 2),)", "offset 32", "expected geometry type"},
+	}
+	for _, tt := range tests {
+		_, err := ParseWKT(tt.wkt)
+		if err == nil {
+			t.Errorf("ParseWKT(%q) error = nil, want error", tt.wkt)
+			continue
+		}
+		if want := tt.offset + ": " + tt.message; !strings.HasSuffix(err.Error(), want) {
+			t.Errorf("ParseWKT(%q) error = %q, want %s", tt.wkt, err, want)
+		}
+	}
+}
+
+func TestToWKTFeatures(t *testing.T) {
+	f := NewFeature(NewLineString([]Position{{1, 2}, {3, 4.5}}))
+	f.Properties = map[string]interface{}{"name": "route"}
+	got, err := ToWKT(f)
+	if err != nil {
+		t.Fatalf("ToWKT() error = %v", err)
+	}
+	if got != "LINESTRING (1 2, 3 4.5)" {
+		t.Errorf("ToWKT(feature) = %q", got)
+	}
+
+	fc := NewFeatureCollection([]Feature{f, NewFeature(nil), NewFeature(NewPoint(0, 0))})
+	got, err = ToWKT(&fc)
+	if err != nil {
+		t.Fatalf("ToWKT() error = %v", err)
+	}
+	if got != "GEOMETRYCOLLECTION (LINESTRING (1 2, 3 4.5), POINT (0 0))" {
+		t.Errorf("ToWKT(collection) = %q", got)
+	}
+
+	// One position with altitude puts the whole geometry in Z.
+	got, _ = ToWKT(NewLineString([]Position{{0, 0}, {1, 1, 10}}))
+	if got != "LINESTRING Z (0 0 0, 1 1 10)" {
+		t.Errorf("ToWKT(mixed altitude) = %q", got)
+	}
+
+	if _, err := ToWKT(NewFeature(nil)); err == nil {
+		t.Error("expected error for feature without geometry")
+	}
+	if _, err := ToWKT(waypoints{{0, 0}}); err == nil {
+		t.Error("expected error for user-defined geometry")
+	}
+	if _, err := ToWKT("POINT (1 2)"); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+}